@@ -0,0 +1,92 @@
+// ./aberration.go
+package jpleph
+
+/*
+Package jpleph provides the stellar aberration of directions for moving observers.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// ApplyAberration returns the apparent direction of a source for an observer moving with
+// observerVelocity, to first order in v/c: the direction is rotated toward the velocity by
+// asin(|u x v|/c), as in SPICE (stelab). This is the correction of SpkEzr "+S", Direction,
+// and SkyPosition, and it applies unchanged to star positions from a catalog. For the
+// transmission case (SPICE "X" corrections), pass the negated velocity.
+//
+// Parameters:
+//   - direction: Vector toward the source, of any length (e.g., a light-time corrected
+//     position, or a catalog unit vector), in an inertial frame such as the ICRF.
+//   - observerVelocity: Velocity of the observer in the same frame in km/s, usually
+//     barycentric.
+//
+// Returns:
+//   - [3]float64: The aberrated vector, of the same length as direction.
+func ApplyAberration(direction, observerVelocity [3]float64) [3]float64 {
+	p := linalg.Vec3(direction)
+	r := p.Norm()
+	if r == 0 {
+		return direction
+	}
+	u := p.Scale(1 / r)
+	h := u.Cross(linalg.Vec3(observerVelocity).Scale(1 / speedOfLightKMS))
+	sinPhi := h.Norm()
+	if sinPhi == 0 {
+		return direction
+	}
+	// Rotate u by phi about h, i.e. toward the velocity: u' = u cos(phi) + (h/|h| x u) sin(phi).
+	s, c := math.Sincos(math.Asin(math.Min(sinPhi, 1)))
+	w := h.Scale(1 / sinPhi).Cross(u)
+	return [3]float64(u.Scale(c).Add(w.Scale(s)).Scale(r))
+}
+
+// ApplyRelativisticAberration returns the apparent direction of a source for an observer
+// moving with observerVelocity, by the Lorentz transformation of the direction of light (as
+// in the IERS Conventions and SOFA iauAb, without the gravitational term). It differs from
+// ApplyAberration by terms of order (v/c)^2, under a milliarcsecond for the orbital velocity
+// of the Earth, and stays exact for fast observers.
+//
+// Parameters:
+//   - direction: Vector toward the source, of any length, in an inertial frame.
+//   - observerVelocity: Velocity of the observer in the same frame in km/s, below the speed
+//     of light.
+//
+// Returns:
+//   - [3]float64: The aberrated vector, of the same length as direction.
+func ApplyRelativisticAberration(direction, observerVelocity [3]float64) [3]float64 {
+	p := linalg.Vec3(direction)
+	r := p.Norm()
+	if r == 0 {
+		return direction
+	}
+	u := p.Scale(1 / r)
+	beta := linalg.Vec3(observerVelocity).Scale(1 / speedOfLightKMS)
+	invGamma := math.Sqrt(1 - beta.Dot(beta))
+	ub := u.Dot(beta)
+	// u' = (u/gamma + (1 + u.beta/(1 + 1/gamma)) beta) / (1 + u.beta)
+	v := u.Scale(invGamma).Add(beta.Scale(1 + ub/(1+invGamma))).Scale(1 / (1 + ub))
+	return [3]float64(v.Unit().Scale(r))
+}
@@ -0,0 +1,148 @@
+// ./adaptive_sampling.go
+package jpleph
+
+/*
+Package jpleph provides adaptive sampling of trajectories for plotting and compact storage.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxSampleDepth bounds the bisections of an interval of AdaptiveSample, so that a tolerance
+// below the accuracy of float64 cannot recurse without end.
+const maxSampleDepth = 40
+
+// SampleOptions configures AdaptiveSample. The zero value samples for plots with a linear
+// interpolation error below 1e-6 AU (150 km).
+type SampleOptions struct {
+	Tolerance float64 // Maximum position error of the interpolation between samples in AU (0: 1e-6)
+	MinStep   float64 // Smallest spacing of the samples in days, where the tolerance is not met (0: 1 minute)
+	MaxStep   float64 // Largest spacing of the samples in days (0: the record span of the file)
+	Hermite   bool    // Whether the error is that of cubic Hermite interpolation of the positions and velocities, for storage, instead of linear interpolation of the positions, for plots
+}
+
+// AdaptiveSample samples the state of a body from start to end with a step that adapts to
+// the motion: each interval between samples is halved until the interpolation of the
+// position between its ends is within the tolerance at its middle, so the samples crowd
+// around perihelion passages and close approaches and thin out elsewhere. Intervals are
+// first cut to MaxStep, which keeps the check at the middle from missing faster motion in
+// between.
+//
+// Parameters:
+//   - target: Body to sample (any body accepted by CalculatePV).
+//   - center: Body it is relative to.
+//   - start, end: Time span (TDB Julian Dates).
+//   - opts: Tolerance, interpolation, and step bounds.
+//
+// Returns:
+//   - []StateVector: The samples in chronological order, from start to end, in AU and AU/day.
+//   - error: ErrEventSearch for an empty span or invalid options, or an error from CalculatePV.
+func (e *Ephemeris) AdaptiveSample(target Planet, center CenterBody, start, end float64, opts SampleOptions) ([]StateVector, error) {
+	tol, minStep, maxStep := opts.Tolerance, opts.MinStep, opts.MaxStep
+	if tol == 0 {
+		tol = 1e-6
+	}
+	if minStep == 0 {
+		minStep = 1.0 / 1440
+	}
+	if maxStep == 0 {
+		maxStep = e.ephemData.ephemStep
+	}
+	if !(end > start) || !(tol > 0) || !(minStep > 0) || !(maxStep >= minStep) {
+		return nil, fmt.Errorf("%w: sampling span %v to %v, tolerance %g, steps %g to %g", ErrEventSearch, start, end, tol, minStep, maxStep)
+	}
+
+	state := func(et float64) (StateVector, error) {
+		pos, vel, err := e.CalculatePV(et, target, center, true)
+		return StateVector{ET: et, Position: pos, Velocity: vel}, err
+	}
+	if tr, err := e.Trajectory(target, center); err == nil { // Faster for the bodies of the kernel
+		state = func(et float64) (StateVector, error) {
+			pos, vel, err := tr.At(et)
+			return StateVector{ET: et, Position: pos, Velocity: vel}, err
+		}
+	}
+
+	// interpolationError returns the distance between the middle sample m and its
+	// interpolation from a and b.
+	interpolationError := func(a, b, m StateVector) float64 {
+		pa, pb := [3]float64{a.Position.X, a.Position.Y, a.Position.Z}, [3]float64{b.Position.X, b.Position.Y, b.Position.Z}
+		va, vb := [3]float64{a.Velocity.DX, a.Velocity.DY, a.Velocity.DZ}, [3]float64{b.Velocity.DX, b.Velocity.DY, b.Velocity.DZ}
+		pm := [3]float64{m.Position.X, m.Position.Y, m.Position.Z}
+		h := b.ET - a.ET
+		var d [3]float64
+		for i := range d {
+			p := (pa[i] + pb[i]) / 2
+			if opts.Hermite {
+				p += h / 8 * (va[i] - vb[i])
+			}
+			d[i] = p - pm[i]
+		}
+		return norm3(d)
+	}
+
+	var samples []StateVector
+	var refine func(a, b StateVector, depth int) error
+	refine = func(a, b StateVector, depth int) error {
+		if (b.ET-a.ET)/2 < minStep || depth >= maxSampleDepth {
+			samples = append(samples, b)
+			return nil
+		}
+		m, err := state((a.ET + b.ET) / 2)
+		if err != nil {
+			return err
+		}
+		if interpolationError(a, b, m) <= tol {
+			samples = append(samples, b)
+			return nil
+		}
+		if err := refine(a, m, depth+1); err != nil {
+			return err
+		}
+		return refine(m, b, depth+1)
+	}
+
+	a, err := state(start)
+	if err != nil {
+		return nil, err
+	}
+	samples = append(samples, a)
+	n := int(math.Ceil((end - start) / maxStep))
+	for i := 1; i <= n; i++ {
+		et := end
+		if i < n {
+			et = start + (end-start)*float64(i)/float64(n)
+		}
+		b, err := state(et)
+		if err != nil {
+			return nil, err
+		}
+		if err := refine(a, b, 0); err != nil {
+			return nil, err
+		}
+		a = b
+	}
+	return samples, nil
+}
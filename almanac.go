@@ -0,0 +1,268 @@
+// ./almanac.go
+package jpleph
+
+/*
+Package jpleph provides geocentric observer tables formatted like JPL Horizons OBSERVER output.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// AlmanacRow holds the geocentric observer quantities of a body at one epoch.
+type AlmanacRow struct {
+	ET            float64 // Epoch (TDB Julian Date)
+	RA            float64 // Astrometric right ascension (ICRF) in degrees, in [0, 360)
+	Dec           float64 // Astrometric declination (ICRF) in degrees
+	Delta         float64 // Distance from the Earth in AU (at the light-time corrected epoch)
+	R             float64 // Distance from the Sun in AU (at the light-time corrected epoch)
+	Elongation    float64 // Sun-Observer-Target angle in degrees
+	Trailing      bool    // Whether the body is east of the Sun (trails it, evening sky)
+	PhaseAngle    float64 // Sun-Target-Observer angle in degrees
+	Magnitude     float64 // Apparent visual magnitude, NaN if there is no model for the body
+	Constellation string  // IAU abbreviation of the constellation containing the body (e.g., "Leo")
+}
+
+// Almanac computes a geocentric observer table of a body from start to end: astrometric
+// right ascension and declination, distances, solar elongation, phase angle, visual
+// magnitude, and constellation, comparable to the JPL Horizons OBSERVER quantities 1, 9, 19,
+// 20, 23, 24, and 29. Positions are corrected for light time but not for aberration or
+// deflection; magnitudes use the formulas of the Explanatory Supplement (1992), without
+// Saturn's rings.
+//
+// Parameters:
+//   - target: Body observed (a kernel body other than the Earth, or a custom or SPK body).
+//   - start, end: Time span (TDB Julian Dates).
+//   - step: Spacing of the rows in days.
+//
+// Returns:
+//   - []AlmanacRow: One row per step from start to end.
+//   - error: ErrInvalidIndex for an invalid target, ErrEventSearch for an invalid span or
+//     step, or an error from CalculatePV.
+func (e *Ephemeris) Almanac(target Planet, start, end, step float64) ([]AlmanacRow, error) {
+	if target == Earth || target == SolarSystemBarycenter || target == EarthMoonBarycenter ||
+		(target >= Nutations && target <= TT_TDB) {
+		return nil, fmt.Errorf("%v cannot be observed from the Earth: %w", target, ErrInvalidIndex)
+	}
+	if !(step > 0) || !(end >= start) {
+		return nil, fmt.Errorf("%w: almanac span %v to %v with step %v", ErrEventSearch, start, end, step)
+	}
+	n := int(math.Floor((end-start)/step+1e-9)) + 1
+	rows := make([]AlmanacRow, 0, n)
+	for i := 0; i < n; i++ {
+		row, err := e.almanacRow(target, start+float64(i)*step)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// almanacRow computes the observer quantities of target at et.
+func (e *Ephemeris) almanacRow(target Planet, et float64) (AlmanacRow, error) {
+	earth, _, err := e.CalculatePV(et, Earth, CenterSolarSystemBarycenter, false)
+	if err != nil {
+		return AlmanacRow{}, err
+	}
+	obs := [3]float64{earth.X, earth.Y, earth.Z}
+	body, err := e.lightTimePosition(et, target, obs)
+	if err != nil {
+		return AlmanacRow{}, err
+	}
+	sun, err := e.lightTimePosition(et, Sun, obs)
+	if err != nil {
+		return AlmanacRow{}, err
+	}
+	// Heliocentric position of the body when it emitted the light.
+	bodySun, err := e.lightTimeHeliocentric(et, target, body)
+	if err != nil {
+		return AlmanacRow{}, err
+	}
+
+	row := AlmanacRow{ET: et}
+	row.RA, row.Dec, row.Delta = sphericalDegrees(body)
+	row.R = norm3(bodySun)
+	row.Elongation = angleDegrees(body, sun)
+	row.PhaseAngle = angleDegrees([3]float64{-bodySun[0], -bodySun[1], -bodySun[2]}, [3]float64{-body[0], -body[1], -body[2]})
+	eclBody, eclSun := linalg.RotX(obliquityJ2000).Apply(body), linalg.RotX(obliquityJ2000).Apply(sun)
+	row.Trailing = eclSun[0]*eclBody[1]-eclSun[1]*eclBody[0] > 0 // Body east of the Sun in ecliptic longitude
+	row.Magnitude = visualMagnitude(target, row.R, row.Delta, row.PhaseAngle)
+	row.Constellation, _ = Constellation(row.RA, row.Dec)
+	return row, nil
+}
+
+// lightTimePosition returns the position of target relative to an observer at obs (barycentric,
+// AU), at the epoch when the light received at et left it.
+func (e *Ephemeris) lightTimePosition(et float64, target Planet, obs [3]float64) ([3]float64, error) {
+	var x [3]float64
+	tau := 0.0
+	for iter := 0; iter < 3; iter++ {
+		pos, _, err := e.CalculatePV(et-tau, target, CenterSolarSystemBarycenter, false)
+		if err != nil {
+			return x, err
+		}
+		x = [3]float64{pos.X - obs[0], pos.Y - obs[1], pos.Z - obs[2]}
+		tau = norm3(x) * e.ephemData.au / (speedOfLightKMS * secondsPerDay)
+	}
+	return x, nil
+}
+
+// lightTimeHeliocentric returns the heliocentric position of target at the emission epoch of
+// the light reaching the Earth at et, given its geocentric light-time corrected position.
+func (e *Ephemeris) lightTimeHeliocentric(et float64, target Planet, geocentric [3]float64) ([3]float64, error) {
+	if target == Sun {
+		return [3]float64{}, nil
+	}
+	tau := norm3(geocentric) * e.ephemData.au / (speedOfLightKMS * secondsPerDay)
+	pos, _, err := e.CalculatePV(et-tau, target, CenterSun, false)
+	return [3]float64{pos.X, pos.Y, pos.Z}, err
+}
+
+// visualMagnitude returns the apparent visual magnitude of a kernel body from its distances
+// to the Sun (r) and observer (delta) in AU and its phase angle i in degrees
+// (Explanatory Supplement to the Astronomical Almanac, 1992, table 7.2.1). It returns NaN for
+// bodies without a model.
+func visualMagnitude(body Planet, r, delta, i float64) float64 {
+	d := 5 * math.Log10(r*delta)
+	switch body {
+	case Mercury:
+		return -0.42 + d + (0.0380+(-0.000273+0.000002*i)*i)*i
+	case Venus:
+		return -4.40 + d + (0.0009+(0.000239-0.00000065*i)*i)*i
+	case Mars:
+		return -1.52 + d + 0.016*i
+	case Jupiter:
+		return -9.40 + d + 0.005*i
+	case Saturn:
+		return -8.88 + d + 0.044*i
+	case Uranus:
+		return -7.19 + d + 0.002*i
+	case Neptune:
+		return -6.87 + d
+	case Pluto:
+		return -1.00 + d
+	case Moon:
+		return 0.23 + d + 0.026*i + 4.0e-9*i*i*i*i
+	case Sun:
+		return -26.74 + 5*math.Log10(delta)
+	}
+	return math.NaN()
+}
+
+// norm3 returns the length of a vector.
+func norm3(x [3]float64) float64 {
+	return linalg.Vec3(x).Norm()
+}
+
+// angleDegrees returns the angle between two vectors in degrees.
+func angleDegrees(a, b [3]float64) float64 {
+	na, nb := norm3(a), norm3(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	c := (a[0]*b[0] + a[1]*b[1] + a[2]*b[2]) / (na * nb)
+	return math.Acos(math.Max(-1, math.Min(1, c))) * 180 / math.Pi
+}
+
+// WriteAlmanac writes an observer table in the layout of JPL Horizons OBSERVER output: a short
+// header, then one line per row between the $$SOE and $$EOE markers, with the date (TDB),
+// sexagesimal right ascension and declination, magnitude, distances, elongation with /T
+// (trailing, evening) or /L (leading, morning), phase angle, and constellation. Scripts that extracted the
+// lines between the markers of Horizons output can read it the same way.
+//
+// Parameters:
+//   - w: Destination of the table.
+//   - target: Body of the rows, for the header.
+//   - rows: Rows from Almanac.
+//
+// Returns:
+//   - error: A write error.
+func WriteAlmanac(w io.Writer, target Planet, rows []AlmanacRow) error {
+	return WriteAlmanacLocalized(w, target, rows, nil)
+}
+
+// WriteAlmanacLocalized writes an observer table as WriteAlmanac does, with the names of
+// the target and center bodies translated; the layout and the column labels stay those of
+// Horizons, so that scripts reading Horizons output still parse it.
+//
+// Parameters:
+//   - w: Destination of the table.
+//   - target: Body of the rows, for the header.
+//   - rows: Rows from Almanac.
+//   - names: Translations of the body names (see LookupLocalization); nil for English.
+//
+// Returns:
+//   - error: A write error.
+func WriteAlmanacLocalized(w io.Writer, target Planet, rows []AlmanacRow, names Localization) error {
+	bw := bufio.NewWriter(w)
+	rule := "*******************************************************************************\n"
+	fmt.Fprint(bw, rule)
+	fmt.Fprintf(bw, " Target body name: %-30s Center body name: %s (geocentric)\n", names.Body(target), names.Body(Earth))
+	fmt.Fprintf(bw, " Positions: astrometric (ICRF), light-time corrected; time scale: TDB\n")
+	fmt.Fprint(bw, rule)
+	fmt.Fprintf(bw, " Date__(TDB)__HR:MN     R.A._____(ICRF)_____DEC    APmag             delta                 r     S-O-T /r     S-T-O Cnst\n")
+	fmt.Fprint(bw, rule)
+	fmt.Fprint(bw, "$$SOE\n")
+	for _, row := range rows {
+		mag := "   n.a."
+		if !math.IsNaN(row.Magnitude) {
+			mag = fmt.Sprintf("%7.3f", row.Magnitude)
+		}
+		side := "/L"
+		if row.Trailing {
+			side = "/T"
+		}
+		fmt.Fprintf(bw, " %s     %s %s %s  %16.13f  %16.13f  %8.4f %s  %8.4f %s\n",
+			timeFromJulianDate(row.ET+30.0/secondsPerDay).Format("2006-Jan-02 15:04"), // Rounded to the minute
+			sexagesimal(row.RA/15, 2, false), sexagesimal(row.Dec, 1, true),
+			mag, row.Delta, row.R, row.Elongation, side, row.PhaseAngle, row.Constellation)
+	}
+	fmt.Fprint(bw, "$$EOE\n")
+	fmt.Fprint(bw, rule)
+	return bw.Flush()
+}
+
+// sexagesimal formats x (hours or degrees) as "hh mm ss.ss" with the given number of decimals
+// of seconds, with a sign if signed.
+func sexagesimal(x float64, decimals int, signed bool) string {
+	sign := "+"
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	scale := math.Pow(10, float64(decimals))
+	units := math.Round(x * 3600 * scale) // Rounded once, so carries propagate into minutes and degrees
+	secs := math.Mod(units, 60*scale) / scale
+	mins := int(math.Mod(math.Floor(units/(60*scale)), 60))
+	whole := int(math.Floor(units / (3600 * scale)))
+	s := fmt.Sprintf("%02d %02d %0*.*f", whole, mins, decimals+3, decimals, secs)
+	if signed {
+		return sign + s
+	}
+	return s
+}
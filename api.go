@@ -90,6 +90,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 )
 
 // ErrQuantityNotInEphemeris is returned when the requested quantity is not available in the ephemeris file.
@@ -113,6 +114,90 @@ var ErrInitialization = errors.New("ephemeris initialization error") // For wrap
 // ErrConstantNotFound is returned when a requested constant is not found in the ephemeris data.
 var ErrConstantNotFound = errors.New("constant not found")
 
+// ErrInvalidFit is returned when a Chebyshev fit is requested with an invalid interval or coefficient count.
+var ErrInvalidFit = errors.New("invalid Chebyshev fit parameters")
+
+// ErrCenterIgnored is returned when a special quantity (Nutations, Librations, LunarMantleOmega, TT_TDB),
+// which has no center, is requested relative to a center other than CenterSun or the zero CenterBody.
+var ErrCenterIgnored = errors.New("center is not applicable to special quantities")
+
+// ErrBadBodyForCenter is returned when a value that is not a body, such as a special quantity, is used as a center.
+var ErrBadBodyForCenter = errors.New("invalid center body")
+
+// ErrSelfTest is returned by SelfTest when a computed state disagrees with its reference value.
+var ErrSelfTest = errors.New("ephemeris self-test failed")
+
+// ErrUnit is returned when unmarshaling a position or velocity with an unknown or missing unit.
+var ErrUnit = errors.New("unknown unit")
+
+// ErrRangeRequest is returned when an HTTP server does not honor range requests.
+var ErrRangeRequest = errors.New("HTTP range request failed")
+
+// ErrCustomBody is returned when a user-defined body is invalid, conflicts with an existing body, or cannot be decoded.
+var ErrCustomBody = errors.New("invalid custom body")
+
+// ErrSPKFormat is returned when an SPK file is malformed or holds no supported segments.
+var ErrSPKFormat = errors.New("invalid SPK file")
+
+// ErrElementsFormat is returned when orbital elements are malformed or describe an invalid orbit.
+var ErrElementsFormat = errors.New("invalid orbital elements")
+
+// ErrKepler is returned when a two-body orbit cannot be propagated (for example a degenerate orbit).
+var ErrKepler = errors.New("kepler propagation failed")
+
+// ErrPropagation is returned when a numerical propagation fails to reach the requested epoch.
+var ErrPropagation = errors.New("numerical propagation failed")
+
+// ErrEventSearch is returned when an event search is given an invalid interval, step, or site.
+var ErrEventSearch = errors.New("invalid event search parameters")
+
+// ErrConstantsFormat is returned when a text constants kernel is malformed.
+var ErrConstantsFormat = errors.New("invalid text constants kernel")
+
+// ErrOEMFormat is returned when a CCSDS Orbit Ephemeris Message is malformed or uses unsupported features.
+var ErrOEMFormat = errors.New("invalid CCSDS OEM")
+
+// ErrSPICEName is returned when a SPICE-style call is given an unknown body, frame, or aberration correction name.
+var ErrSPICEName = errors.New("unknown SPICE name")
+
+// ErrTimeData is returned when a Delta T or leap-second table is malformed.
+var ErrTimeData = errors.New("invalid time data")
+
+// ErrEpochFormat is returned when an epoch string cannot be parsed or lacks its time scale.
+var ErrEpochFormat = errors.New("invalid epoch")
+
+// ErrFrame is returned when a reference frame is unknown, already registered, or cannot be reached.
+var ErrFrame = errors.New("unknown reference frame")
+
+// ErrTruncatedFile is returned when an ephemeris file is shorter than its header requires.
+var ErrTruncatedFile = errors.New("ephemeris file is truncated")
+
+// ErrByteOrder is returned when an ephemeris file reads correctly only in the other byte order.
+var ErrByteOrder = errors.New("ephemeris file has an unexpected byte order")
+
+// ErrUnknownLayout is returned when the records of an ephemeris file do not match the layout
+// described by its header.
+var ErrUnknownLayout = errors.New("ephemeris record layout does not match the header")
+
+// ErrSite is returned when an observer site has coordinates out of range or lies on a body
+// without a rotation model.
+var ErrSite = errors.New("invalid observer site")
+
+// ErrCR3BP is returned by HaloSeed for an invalid mass ratio, libration point, or amplitude.
+var ErrCR3BP = errors.New("invalid circular restricted three-body problem parameters")
+
+// ErrDeadline is returned by CalculatePVBefore when a record is not read before the deadline
+// and no recent state can be extrapolated.
+var ErrDeadline = errors.New("record not read before the deadline")
+
+// ErrCovariance is returned for an uncertainty that is not a valid standard deviation or
+// covariance matrix.
+var ErrCovariance = errors.New("invalid covariance")
+
+// ErrNoGoldenVectors is returned by VerifyAgainstEmbedded when no reference states are
+// embedded for the DE number of the file.
+var ErrNoGoldenVectors = errors.New("no embedded reference states for this DE version")
+
 // Planet represents the celestial bodies available as targets in the ephemeris.
 type Planet int
 
@@ -185,6 +270,35 @@ const (
 	CenterEarthMoonBarycenter CenterBody = 13
 )
 
+// planetNames holds the display names of the kernel bodies and quantities, indexed by Planet value.
+var planetNames = [...]string{
+	Mercury: "Mercury", Venus: "Venus", Earth: "Earth", Mars: "Mars", Jupiter: "Jupiter",
+	Saturn: "Saturn", Uranus: "Uranus", Neptune: "Neptune", Pluto: "Pluto", Moon: "Moon", Sun: "Sun",
+	SolarSystemBarycenter: "Solar System Barycenter", EarthMoonBarycenter: "Earth-Moon Barycenter",
+	Nutations: "Nutations", Librations: "Librations", LunarMantleOmega: "Lunar Mantle Omega", TT_TDB: "TT-TDB",
+}
+
+// String returns the display name of the body or quantity (e.g., "Mars", "Earth-Moon Barycenter").
+// Values without a name, such as custom body identifiers, are formatted as "Planet(n)".
+func (p Planet) String() string {
+	if p > 0 && int(p) < len(planetNames) {
+		return planetNames[p]
+	}
+	if name, ok := spkBodyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Planet(%d)", int(p))
+}
+
+// String returns the display name of the center body (e.g., "Sun", "Solar System Barycenter").
+// Values without a name are formatted as "CenterBody(n)".
+func (c CenterBody) String() string {
+	if c >= CenterMercury && c <= CenterEarthMoonBarycenter {
+		return planetNames[c]
+	}
+	return fmt.Sprintf("CenterBody(%d)", int(c))
+}
+
 // ValueType represents the type of value to retrieve from ephemeris data using GetDouble or GetLong.
 type ValueType int
 
@@ -212,9 +326,63 @@ const (
 	// KernelSwapBytes indicates whether byte swapping is needed for this kernel (1 for swap, 0 for no swap).
 	KernelSwapBytes ValueType = JPL_EPHEM_KERNEL_SWAP_BYTES
 	// IPTArrayOffset represents the offset to access the IPT array elements. Use IPTArrayOffset + index to access individual IPT array elements.
+	//
+	// Deprecated: Use Ephemeris.IPT with a Quantity instead of offset arithmetic.
 	IPTArrayOffset ValueType = JPL_EPHEM_IPT_ARRAY // Use IPTArrayOffset + index to access IPT array elements
 )
 
+// Quantity identifies an interpolated quantity of the ephemeris file, in the order of the
+// IPT (Interpolation Parameter Table) entries.
+type Quantity int
+
+const (
+	// QuantityMercury is the barycentric position of Mercury.
+	QuantityMercury Quantity = iota
+	// QuantityVenus is the barycentric position of Venus.
+	QuantityVenus
+	// QuantityEarthMoonBarycenter is the barycentric position of the Earth-Moon barycenter.
+	QuantityEarthMoonBarycenter
+	// QuantityMars is the barycentric position of the Mars system barycenter.
+	QuantityMars
+	// QuantityJupiter is the barycentric position of the Jupiter system barycenter.
+	QuantityJupiter
+	// QuantitySaturn is the barycentric position of the Saturn system barycenter.
+	QuantitySaturn
+	// QuantityUranus is the barycentric position of the Uranus system barycenter.
+	QuantityUranus
+	// QuantityNeptune is the barycentric position of the Neptune system barycenter.
+	QuantityNeptune
+	// QuantityPluto is the barycentric position of the Pluto system barycenter.
+	QuantityPluto
+	// QuantityMoon is the geocentric position of the Moon.
+	QuantityMoon
+	// QuantitySun is the barycentric position of the Sun.
+	QuantitySun
+	// QuantityNutations is the nutation in longitude and obliquity.
+	QuantityNutations
+	// QuantityLibrations is the lunar mantle Euler angles (librations).
+	QuantityLibrations
+	// QuantityLunarMantleOmega is the angular velocity of the lunar mantle.
+	QuantityLunarMantleOmega
+	// QuantityTTmTDB is the difference TT - TDB at the geocenter.
+	QuantityTTmTDB
+)
+
+// quantityNames holds the display names of the quantities, indexed by Quantity.
+var quantityNames = [...]string{
+	"Mercury", "Venus", "Earth-Moon Barycenter", "Mars", "Jupiter", "Saturn", "Uranus", "Neptune",
+	"Pluto", "Moon (geocentric)", "Sun", "Nutations", "Librations", "Lunar Mantle Omega", "TT-TDB",
+}
+
+// String returns the display name of the quantity. Values without a name are formatted as
+// "Quantity(n)".
+func (q Quantity) String() string {
+	if q >= QuantityMercury && q <= QuantityTTmTDB {
+		return quantityNames[q]
+	}
+	return fmt.Sprintf("Quantity(%d)", int(q))
+}
+
 // Position represents a 3D position vector in Astronomical Units (AU).
 type Position struct {
 	// X is the X component of the position in AU.
@@ -238,9 +406,19 @@ type Velocity struct {
 // Ephemeris is a wrapper struct holding the ephemeris data interface and optional caches for constants.
 // It provides methods to access ephemeris data and perform calculations.
 type Ephemeris struct {
-	ephemData   *jplEphData // Holds the underlying jplEphData directly
-	constNames  [][]byte    // Cache for constant names (optional)
-	constValues []float64   // Cache for constant values (optional)
+	ephemData        *jplEphData            // Holds the underlying jplEphData directly
+	constNames       [][]byte               // Cache for constant names (optional)
+	constValues      []float64              // Cache for constant values (optional)
+	customBodies     map[Planet]*CustomBody // User-defined bodies addressable by CalculatePV (optional)
+	sources          map[Planet]sourceBody  // State sources registered with AddSource (optional)
+	spkKernels       []*SPKKernel           // Satellite kernels loaded with LoadSPK, in load order (optional)
+	constOverlay     map[string][]float64   // Constants from text kernels, overriding the file's constants (optional)
+	constOrigin      map[string]string      // Text kernel each overlay constant was last loaded from
+	clampTolerance   float64                // Days outside the file span clamped to it (WithClampToRange)
+	clamped          bool                   // Whether the last CalculatePV epoch was clamped
+	nutationFallback NutationTheory         // Nutations for files without them (WithNutationFallback)
+	watch            *fileWatch             // File watcher started by WithFileWatch (optional)
+	deadline         *deadlineReader        // Background record reads of CalculatePVBefore (optional)
 }
 
 // newEphemeris creates a new Ephemeris instance from a jplEphData interface.
@@ -258,33 +436,36 @@ func newEphemeris(data *jplEphData) *Ephemeris {
 //   - ephemerisFilename: Path to the binary ephemeris file (e.g., "de405.bin").
 //   - loadConstants: Boolean flag to indicate whether to load and cache constant names and values.
 //     Setting this to true can improve performance for repeated access to constants.
+//   - opts: Optional settings, such as WithClampToRange.
 //
 // Returns:
 //   - *Ephemeris: Pointer to the initialized Ephemeris wrapper on success, nil on failure.
 //   - error: Standard Go error if initialization fails. The error can be checked using errors.Is for specific error types
 //     like ErrFileRead, ErrFileSeek, ErrInitialization.
-func NewEphemeris(ephemerisFilename string, loadConstants bool) (*Ephemeris, error) {
+func NewEphemeris(ephemerisFilename string, loadConstants bool, opts ...Option) (*Ephemeris, error) {
 	setDebugFlag(false)                                          // Disable debug flag by default
 	ephemData, err := initEphemeris(ephemerisFilename, nil, nil) // Initialize ephemeris data
 	if err != nil {
 		return nil, fmt.Errorf("initialization failed: %w", err)
 	}
+	return finishEphemeris(ephemData, loadConstants, opts)
+}
 
+// finishEphemeris wraps initialized ephemeris data in an Ephemeris, loading the constant cache
+// if requested and applying the options. The data is closed if loading the constants fails.
+func finishEphemeris(ephemData *jplEphData, loadConstants bool, opts []Option) (*Ephemeris, error) {
+	var err error
 	ephemWrapper := newEphemeris(ephemData) // Create Ephemeris wrapper
 	if loadConstants {                      // Load constants if requested
-		numConstants := ephemWrapper.GetEphemerisLong(NumberOfConstants)
-		if numConstants <= 0 {
-			return nil, fmt.Errorf("initialization failed: invalid number of constants: %d", numConstants)
-		}
-		ephemWrapper.constNames = make([][]byte, numConstants)   // Initialize slice for constant names
-		ephemWrapper.constValues = make([]float64, numConstants) // Initialize slice for constant values
-		for i := 0; i < int(numConstants); i++ {
-			nameBuf := make([]byte, 7) // Buffer to read constant name
-			value := getConstant(i, ephemData, nameBuf)
-			ephemWrapper.constValues[i] = value
-			ephemWrapper.constNames[i] = bytes.TrimRight(nameBuf[:6], "\x00") // Store name without null terminator
+		ephemWrapper.constNames, ephemWrapper.constValues, err = loadConstantCache(ephemData)
+		if err != nil {
+			closeEphemeris(ephemData)
+			return nil, err
 		}
 	}
+	for _, opt := range opts {
+		opt(ephemWrapper)
+	}
 	return ephemWrapper, nil
 }
 
@@ -295,7 +476,75 @@ func NewEphemeris(ephemerisFilename string, loadConstants bool) (*Ephemeris, err
 // Returns:
 //   - error: nil on success, or an error if closing the file fails.
 func (e *Ephemeris) Close() error {
-	return closeEphemeris(e.ephemData)
+	e.stopWatch()
+	e.stopDeadlineReads()
+	err := closeEphemeris(e.ephemData)
+	for _, k := range e.spkKernels {
+		if kerr := k.Close(); err == nil {
+			err = kerr
+		}
+	}
+	return err
+}
+
+// Clone returns an independent Ephemeris reading the same file through its own file handle
+// and record cache. An Ephemeris is not safe for concurrent use; goroutines that need to
+// share an ephemeris should each work on their own clone. Cached constants are shared,
+// custom bodies are copied with their own interpolation state, SPK kernels are reopened, and
+// sources registered with AddSource are rebound to the clone if they are a CloneableSource
+// (such as a Trajectory or SmallBody made from e) and shared otherwise.
+//
+// Returns:
+//   - *Ephemeris: The clone on success. It must be closed independently of the original.
+//   - error: An error if the ephemeris file cannot be reopened or a source cannot be rebound.
+func (e *Ephemeris) Clone() (*Ephemeris, error) {
+	data, err := cloneEphemeris(e.ephemData)
+	if err != nil {
+		return nil, fmt.Errorf("clone failed: %w", err)
+	}
+	clone := newEphemeris(data)
+	clone.constNames = e.constNames
+	clone.constValues = e.constValues
+	clone.clampTolerance = e.clampTolerance
+	clone.nutationFallback = e.nutationFallback
+	if e.constOverlay != nil {
+		clone.constOverlay = make(map[string][]float64, len(e.constOverlay))
+		for name, values := range e.constOverlay {
+			clone.constOverlay[name] = values // Value slices are never modified in place
+		}
+		clone.constOrigin = make(map[string]string, len(e.constOrigin))
+		for name, file := range e.constOrigin {
+			clone.constOrigin[name] = file
+		}
+	}
+	if e.customBodies != nil {
+		clone.customBodies = make(map[Planet]*CustomBody, len(e.customBodies))
+		for id, body := range e.customBodies {
+			copied := *body // Segments are read-only and shared; interpolation state is not
+			copied.iinfo.reset()
+			clone.customBodies[id] = &copied
+		}
+	}
+	if e.sources != nil {
+		clone.sources = make(map[Planet]sourceBody, len(e.sources))
+		for id, s := range e.sources {
+			src, err := cloneSource(s.src, e, clone)
+			if err != nil {
+				clone.Close()
+				return nil, fmt.Errorf("clone failed: source body %d: %w", id, err)
+			}
+			clone.sources[id] = sourceBody{center: s.center, src: src}
+		}
+	}
+	for _, k := range e.spkKernels {
+		kc, err := k.clone()
+		if err != nil {
+			clone.Close()
+			return nil, fmt.Errorf("clone failed: %w", err)
+		}
+		clone.spkKernels = append(clone.spkKernels, kc)
+	}
+	return clone, nil
 }
 
 // CalculatePV calculates the position and optionally velocity of a target Planet relative to a CenterBody at a given time.
@@ -306,21 +555,89 @@ func (e *Ephemeris) Close() error {
 //   - et: Julian Ephemeris Date (JED) at which to interpolate.
 //   - target: Target Planet for which to calculate position and velocity. Use Planet constants (e.g., jpleph.Mars).
 //   - center: Center CenterBody relative to which the position and velocity are calculated. Use CenterBody constants (e.g., jpleph.Sun).
+//     Custom bodies registered with AddCustomBody may be used as target or center (convert the ID with CenterBody(id)),
+//     as may the satellites and planet centers (e.g., jpleph.Titan) of SPK kernels loaded with LoadSPK and
+//     the sources registered with AddSource. States of these bodies are chained through their centers and
+//     differenced at the first body the two paths share (see AddSource).
 //   - calcVelocity: Flag to indicate whether to calculate velocities. Set to true to calculate velocities, false for positions only.
 //
+// The special quantities (Nutations, Librations, LunarMantleOmega, TT_TDB) are not relative to any body: pass
+// CenterSun, as the original C and Fortran interfaces do, or the zero CenterBody. Special quantities cannot be
+// used as centers.
+//
 // Returns:
 //   - Position: Calculated position vector.
 //   - Velocity: Calculated velocity vector (will be a zero vector if calcVelocity is false).
 //   - error: nil on success, or a standard Go error if the underlying Pleph function returns an error code.
 //     The error can be checked using errors.Is() to determine the specific error type, such as:
 //     ErrQuantityNotInEphemeris, ErrInvalidIndex, ErrOutsideRange, ErrFileSeek, ErrFileRead.
+//     ErrCenterIgnored is returned when a special quantity is requested relative to another center, and
+//     ErrBadBodyForCenter (which also matches ErrInvalidIndex) when center is not a usable center body.
+//     With WithClampToRange, epochs slightly outside the file span are clamped instead (see Clamped).
 func (e *Ephemeris) CalculatePV(et float64, target Planet, center CenterBody, calcVelocity bool) (Position, Velocity, error) {
+	return e.CalculatePVSplit(et, 0, target, center, calcVelocity)
+}
+
+// CalculatePVSplit is CalculatePV for an epoch given as a two-part Julian Date jd1 + jd2, as
+// in SOFA: typically the day number (e.g., 2451545.0) and the fraction of the day, or J2000
+// and the days since. The two parts are combined only within the record, so the time
+// resolution stays that of the fraction even at the distant epochs of DE431/DE441, where a
+// single float64 Julian Date resolves about 80 microseconds. Epochs on a record boundary are
+// therefore located exactly. SPK, custom, and source bodies use jd1 + jd2.
+//
+// Parameters:
+//   - jd1, jd2: Ephemeris time (TDB) as a two-part Julian Date; either part may be the larger.
+//   - target, center, calcVelocity: As for CalculatePV.
+//
+// Returns:
+//   - Position, Velocity, error: As for CalculatePV.
+func (e *Ephemeris) CalculatePVSplit(jd1, jd2 float64, target Planet, center CenterBody, calcVelocity bool) (Position, Velocity, error) {
+	return e.calculatePV(jd1, jd2, target, center, calcVelocity, false)
+}
+
+// CalculatePVKm is CalculatePV in km and km/day, the units of the file. The interpolated
+// states are returned as stored, without the division by the AU and the multiplication
+// that converting back to km would take, so they carry no rounding of the AU. States of SPK,
+// custom, and source bodies are computed in AU and scaled by the AU of the ephemeris.
+//
+// Parameters:
+//   - et, target, center, calcVelocity: As for CalculatePV.
+//
+// Returns:
+//   - Position: Position of the target relative to the center in km.
+//   - Velocity: Velocity in km/day, zero unless calcVelocity is true.
+//   - error: As for CalculatePV.
+func (e *Ephemeris) CalculatePVKm(et float64, target Planet, center CenterBody, calcVelocity bool) (Position, Velocity, error) {
+	return e.calculatePV(et, 0, target, center, calcVelocity, true)
+}
+
+// calculatePV is CalculatePVSplit, in km and km/day when km is set.
+func (e *Ephemeris) calculatePV(jd1, jd2 float64, target Planet, center CenterBody, calcVelocity, km bool) (Position, Velocity, error) {
+	if err := e.validateCenter(target, center); err != nil {
+		return Position{}, Velocity{}, err
+	}
+	e.applyReload()
+	if math.Abs(jd2) > math.Abs(jd1) {
+		jd1, jd2 = jd2, jd1
+	}
+	if et := e.clampEpoch(jd1 + jd2); e.clamped {
+		jd1, jd2 = et, 0
+	}
+	if e.isChained(target) || e.isChained(Planet(center)) {
+		pos, vel, err := e.chainedPV(jd1+jd2, target, center, calcVelocity)
+		if km && err == nil {
+			au := e.ephemData.au
+			pos = Position{X: pos.X * au, Y: pos.Y * au, Z: pos.Z * au}
+			vel = Velocity{DX: vel.DX * au, DY: vel.DY * au, DZ: vel.DZ * au}
+		}
+		return pos, vel, err
+	}
 	velFlag := 0
 	if calcVelocity {
 		velFlag = 2
 	}
-	rrd, err := Pleph(e.ephemData, et, int(target), int(center), velFlag)
-	if err != nil {
+	var rrd [6]float64
+	if err := plephInto(e.ephemData, jd1, jd2, int(target), int(center), velFlag, rrd[:], km); err != nil {
 		return Position{}, Velocity{}, err
 	}
 	pos := Position{X: rrd[0], Y: rrd[1], Z: rrd[2]}
@@ -365,6 +682,8 @@ func (e *Ephemeris) GetEphemerisLong(valueType ValueType) int64 {
 //
 // Returns:
 //   - int64: The requested IPT array value. Returns -1 if the index is invalid (out of range).
+//
+// Deprecated: Use IPT, which takes a typed Quantity and reports errors instead of sentinels.
 func (e *Ephemeris) GetIPTArrayValue(index int) int64 {
 	if index < 0 || index > 44 {
 		return -1 // Invalid index
@@ -372,6 +691,30 @@ func (e *Ephemeris) GetIPTArrayValue(index int) int64 {
 	return GetLong(e.ephemData, JPL_EPHEM_IPT_ARRAY+index)
 }
 
+// IPT returns the Interpolation Parameter Table entry of a quantity: where its coefficients
+// start in a record, how many Chebyshev coefficients each component has, and how many
+// sub-intervals each record is split into.
+//
+// Parameters:
+//   - q: Quantity to look up (e.g., jpleph.QuantityMoon).
+//
+// Returns:
+//   - start: 1-based index of the first coefficient of the quantity within a record.
+//   - ncoeff: Number of Chebyshev coefficients per component and sub-interval.
+//   - nsub: Number of sub-intervals per record.
+//   - err: ErrInvalidIndex for an unknown quantity, or ErrQuantityNotInEphemeris if the file
+//     does not contain it (the entry is still returned).
+func (e *Ephemeris) IPT(q Quantity) (start, ncoeff, nsub uint32, err error) {
+	if q < QuantityMercury || q > QuantityTTmTDB {
+		return 0, 0, 0, fmt.Errorf("IPT entry for %v: %w", q, ErrInvalidIndex)
+	}
+	entry := e.ephemData.ipt[q]
+	if entry[1] == 0 || entry[2] == 0 {
+		return entry[0], entry[1], entry[2], fmt.Errorf("%v: %w", q, ErrQuantityNotInEphemeris)
+	}
+	return entry[0], entry[1], entry[2], nil
+}
+
 // GetEphemName returns the name of the ephemeris file as stored in the kernel.
 // This name typically includes the ephemeris series (e.g., DE405) and the date range.
 //
@@ -414,3 +757,97 @@ func (e *Ephemeris) GetConstantValue(index int) (float64, error) {
 	}
 	return e.constValues[index], nil
 }
+
+// ConstantByName retrieves a constant by name, e.g. "AU", "EMRAT", or "GMS". Constants loaded
+// from text kernels with LoadTextConstants take precedence over those of the ephemeris file;
+// for a vector constant (e.g., "BODY399_RADII") the first element is returned.
+// Unlike GetConstantValue, it also works when constants were not loaded during initialization.
+//
+// Parameters:
+//   - name: Name of the constant (case-sensitive).
+//
+// Returns:
+//   - float64: Constant value.
+//   - error: ErrConstantNotFound if neither the text kernels nor the file define the constant.
+func (e *Ephemeris) ConstantByName(name string) (float64, error) {
+	value, ok := e.constantByName(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrConstantNotFound, name)
+	}
+	return value, nil
+}
+
+// ConstantValues retrieves all elements of a constant by name, such as the three radii of
+// "BODY399_RADII" from a text kernel. Constants of the ephemeris file have a single element.
+//
+// Parameters:
+//   - name: Name of the constant (case-sensitive).
+//
+// Returns:
+//   - []float64: A copy of the constant's values.
+//   - error: ErrConstantNotFound if neither the text kernels nor the file define the constant.
+func (e *Ephemeris) ConstantValues(name string) ([]float64, error) {
+	if values, ok := e.constOverlay[name]; ok {
+		return append([]float64(nil), values...), nil
+	}
+	value, err := e.ConstantByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return []float64{value}, nil
+}
+
+// constantByName looks up a constant by name in the text kernel overlay, then in the header
+// constants, using the cached constants when they were loaded and reading the file otherwise.
+func (e *Ephemeris) constantByName(name string) (float64, bool) {
+	if values, ok := e.constOverlay[name]; ok && len(values) > 0 {
+		return values[0], true
+	}
+	if e.constNames != nil {
+		for i, n := range e.constNames {
+			if string(bytes.TrimRight(n, " ")) == name { // Cached names keep the file's blank padding
+				return e.constValues[i], true
+			}
+		}
+		return 0, false
+	}
+	nameBuf := make([]byte, 7)
+	for i := 0; i < int(e.ephemData.ncon); i++ {
+		value := getConstant(i, e.ephemData, nameBuf)
+		if string(bytes.TrimRight(nameBuf[:6], "\x00 ")) == name {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// validateCenter checks that center can be used with target in CalculatePV.
+func (e *Ephemeris) validateCenter(target Planet, center CenterBody) error {
+	if target >= Nutations && target <= TT_TDB {
+		if center != 0 && center != CenterSun {
+			return fmt.Errorf("%w: %v requested relative to %v", ErrCenterIgnored, target, center)
+		}
+		return nil
+	}
+	c := Planet(center)
+	if (c >= Mercury && c <= EarthMoonBarycenter) || e.isChained(c) {
+		return nil
+	}
+	return fmt.Errorf("%w (%w): %v cannot be used as a center", ErrBadBodyForCenter, ErrInvalidIndex, center)
+}
+
+// loadConstantCache reads the names and values of all header constants of an ephemeris file.
+func loadConstantCache(ephemData *jplEphData) ([][]byte, []float64, error) {
+	numConstants := GetLong(ephemData, JPL_EPHEM_N_CONSTANTS)
+	if numConstants <= 0 {
+		return nil, nil, initFailed(JPL_INIT_FILE_CORRUPT, fmt.Errorf("initialization failed: invalid number of constants: %d", numConstants))
+	}
+	names := make([][]byte, numConstants)   // Initialize slice for constant names
+	values := make([]float64, numConstants) // Initialize slice for constant values
+	for i := 0; i < int(numConstants); i++ {
+		nameBuf := make([]byte, 7) // Buffer to read constant name
+		values[i] = getConstant(i, ephemData, nameBuf)
+		names[i] = bytes.TrimRight(nameBuf[:6], "\x00") // Store name without null terminator
+	}
+	return names, values, nil
+}
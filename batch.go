@@ -0,0 +1,124 @@
+// ./batch.go
+package jpleph
+
+/*
+Package jpleph provides parallel evaluation of many state requests.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// batchChunk is the number of consecutive requests handed to a worker at a time.
+// Requests are usually ordered by time, so chunks keep each worker within few records.
+const batchChunk = 64
+
+// StateRequest describes one CalculatePV call in a batch.
+type StateRequest struct {
+	ET           float64    // ET is the Julian Ephemeris Date.
+	Target       Planet     // Target is the target body or quantity.
+	Center       CenterBody // Center is the center body.
+	CalcVelocity bool       // CalcVelocity requests velocities as well as positions.
+}
+
+// StateResult holds the outcome of one StateRequest.
+type StateResult struct {
+	Position Position // Position is the calculated position.
+	Velocity Velocity // Velocity is the calculated velocity (zero if not requested).
+	Err      error    // Err is the error returned by CalculatePV for this request, if any.
+	Clamped  bool     // Clamped reports that the epoch was clamped to the file span (WithClampToRange).
+}
+
+// ComputeBatch evaluates requests in parallel and returns the results in request order.
+// The requests are sharded in chunks across a pool of workers, each with its own cloned
+// Ephemeris handle, since the record cache of a single Ephemeris must not be shared
+// between goroutines. The receiver is used by one of the workers and must not be used
+// concurrently by the caller while ComputeBatch runs. Sources registered with AddSource that
+// Clone cannot rebind would be shared by the workers, so the requests are then evaluated by
+// one worker.
+//
+// Parameters:
+//   - requests: The states to compute.
+//   - parallelism: Number of workers; values below 1 use runtime.GOMAXPROCS(0).
+//
+// Returns:
+//   - []StateResult: One result per request, in the same order. Per-request failures are reported in StateResult.Err.
+//   - error: An error if the worker handles cannot be cloned.
+func (e *Ephemeris) ComputeBatch(requests []StateRequest, parallelism int) ([]StateResult, error) {
+	results := make([]StateResult, len(requests))
+	if parallelism < 1 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	nChunks := (len(requests) + batchChunk - 1) / batchChunk
+	if parallelism > nChunks {
+		parallelism = nChunks
+	}
+	for _, s := range e.sources {
+		if !rebindable(s.src, e) {
+			parallelism = min(parallelism, 1) // Shared by every clone, and perhaps not safe for concurrent use
+		}
+	}
+	if parallelism == 0 {
+		return results, nil
+	}
+
+	handles := []*Ephemeris{e}
+	defer func() {
+		for _, h := range handles[1:] {
+			h.Close()
+		}
+	}()
+	for len(handles) < parallelism {
+		clone, err := e.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("batch setup failed: %w", err)
+		}
+		handles = append(handles, clone)
+	}
+
+	chunks := make(chan int, nChunks)
+	for i := 0; i < nChunks; i++ {
+		chunks <- i * batchChunk
+	}
+	close(chunks)
+
+	var wg sync.WaitGroup
+	for _, h := range handles {
+		wg.Add(1)
+		go func(h *Ephemeris) {
+			defer wg.Done()
+			for first := range chunks {
+				last := min(first+batchChunk, len(requests))
+				for i := first; i < last; i++ {
+					r := requests[i]
+					pos, vel, err := h.CalculatePV(r.ET, r.Target, r.Center, r.CalcVelocity)
+					results[i] = StateResult{Position: pos, Velocity: vel, Err: err, Clamped: h.Clamped()}
+				}
+			}
+		}(h)
+	}
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,85 @@
+// ./batch_test.go
+package jpleph_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// exclusiveSource is a StateSource that records whether it was ever called concurrently.
+type exclusiveSource struct {
+	busy       atomic.Bool
+	overlapped atomic.Bool
+}
+
+func (s *exclusiveSource) StateAt(et float64) (jpleph.Position, jpleph.Velocity, error) {
+	if !s.busy.CompareAndSwap(false, true) {
+		s.overlapped.Store(true)
+		return jpleph.Position{}, jpleph.Velocity{}, nil
+	}
+	defer s.busy.Store(false)
+	for i := 0; i < 1000; i++ { // Widen the window for an overlapping call
+		et += 1e-30
+	}
+	return jpleph.Position{X: 1e-3}, jpleph.Velocity{}, nil
+}
+
+// TestComputeBatchSources checks that ComputeBatch gives every worker its own Trajectory and
+// SmallBody sources (run with -race), with the results of sequential queries, and that it
+// does not call other sources concurrently.
+func TestComputeBatchSources(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	e := openSynthetic(t, cfg)
+	tr, err := e.Trajectory(jpleph.Mars, jpleph.CenterSun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := e.NewSmallBody(jpleph.OrbitalElements{
+		Designation: "test", Epoch: cfg.Start + 100, PerihelionDistance: 2.5, Eccentricity: 0.1,
+		Inclination: 10, LongitudeOfNode: 80, ArgumentOfPerihelion: 70, PerihelionTime: cfg.Start + 50,
+	}, jpleph.CenterEarth, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trajectory, smallBody := jpleph.FirstCustomBody, jpleph.FirstCustomBody+1
+	if err := e.AddSource(trajectory, jpleph.CenterSun, tr); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddSource(smallBody, jpleph.CenterEarth, sb); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []jpleph.StateRequest
+	for et := cfg.Start; et <= cfg.End(); et += 0.25 {
+		requests = append(requests,
+			jpleph.StateRequest{ET: et, Target: trajectory, Center: jpleph.CenterEarth, CalcVelocity: true},
+			jpleph.StateRequest{ET: et, Target: smallBody, Center: jpleph.CenterSun, CalcVelocity: true})
+	}
+	results, err := e.ComputeBatch(requests, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range requests {
+		pos, vel, err := e.CalculatePV(r.ET, r.Target, r.Center, r.CalcVelocity)
+		if got := results[i]; got.Err != nil || err != nil || got.Position != pos || got.Velocity != vel {
+			t.Fatalf("request %d: %+v, want %v %v (%v)", i, got, pos, vel, err)
+		}
+	}
+
+	shared := &exclusiveSource{}
+	if err := e.AddSource(smallBody+1, jpleph.CenterSun, shared); err != nil {
+		t.Fatal(err)
+	}
+	for i := range requests {
+		requests[i].Target = smallBody + 1
+	}
+	if _, err := e.ComputeBatch(requests, 4); err != nil {
+		t.Fatal(err)
+	}
+	if shared.overlapped.Load() {
+		t.Error("a source that cannot be cloned was called concurrently")
+	}
+}
@@ -0,0 +1,281 @@
+// ./benchmarks/benchmarks.go
+
+/*
+Package benchmarks provides reproducible workloads for measuring the performance of jpleph
+on a given file and platform: a dense scan of one body, states of several bodies at random
+epochs, and access to the header constants. Each workload can run under go test, through
+Workload.Benchmark, or standalone with Run, which the "jpleph bench" command uses:
+
+	eph, err := jpleph.NewEphemeris("de440.bin", true)
+	...
+	for _, w := range benchmarks.Workloads() {
+		res, err := benchmarks.Run(eph, w, time.Second)
+		...
+		fmt.Println(res)
+	}
+
+The epochs of every workload are derived from the time span of the file and a fixed seed,
+so runs on the same file are comparable across versions and machines.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+// Package benchmarks provides reproducible performance workloads for jpleph.
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// randomEpochs is the number of distinct epochs of the random-epoch workload.
+const randomEpochs = 4096
+
+// scanStep is the step of the dense scan in days (one hour).
+const scanStep = 1.0 / 24
+
+// Workload is a benchmark of one kind of use of an ephemeris. One operation is one call of
+// the library: a state for the state workloads, a constant lookup for the constants one.
+type Workload struct {
+	Name        string // Short name selecting the workload (e.g., "dense-scan")
+	Description string // One-line summary of what an operation does
+
+	prepare func(e *jpleph.Ephemeris) (func(i int) error, error) // Returns operation i
+}
+
+// multiBodies are the targets and centers of the random-epoch workload.
+var multiBodies = []struct {
+	target jpleph.Planet
+	center jpleph.CenterBody
+}{
+	{jpleph.Mercury, jpleph.CenterSun}, {jpleph.Venus, jpleph.CenterSun}, {jpleph.Earth, jpleph.CenterSolarSystemBarycenter},
+	{jpleph.Moon, jpleph.CenterEarth}, {jpleph.Mars, jpleph.CenterEarth}, {jpleph.Jupiter, jpleph.CenterSun},
+	{jpleph.Saturn, jpleph.CenterSun}, {jpleph.Uranus, jpleph.CenterSun}, {jpleph.Neptune, jpleph.CenterSun},
+	{jpleph.Pluto, jpleph.CenterSun}, {jpleph.Sun, jpleph.CenterSolarSystemBarycenter},
+}
+
+// workloads lists the workloads in the order they are run by default.
+var workloads = []Workload{
+	{
+		Name:        "dense-scan",
+		Description: "geocentric Moon with velocity at hourly steps from the start of the file, as for a table",
+		prepare: func(e *jpleph.Ephemeris) (func(i int) error, error) {
+			start, end := e.GetEphemerisDouble(jpleph.EphemerisStartJD), e.GetEphemerisDouble(jpleph.EphemerisEndJD)
+			n := int((end - start) / scanStep)
+			if n < 1 {
+				return nil, fmt.Errorf("the file spans %g days, less than one step", end-start)
+			}
+			return func(i int) error {
+				_, _, err := e.CalculatePV(start+float64(i%n)*scanStep, jpleph.Moon, jpleph.CenterEarth, true)
+				return err
+			}, nil
+		},
+	},
+	{
+		Name:        "random-multibody",
+		Description: "states of the Sun, Moon, and planets at random epochs, each epoch for all bodies in turn",
+		prepare: func(e *jpleph.Ephemeris) (func(i int) error, error) {
+			start, end := e.GetEphemerisDouble(jpleph.EphemerisStartJD), e.GetEphemerisDouble(jpleph.EphemerisEndJD)
+			rng := rand.New(rand.NewSource(1))
+			epochs := make([]float64, randomEpochs)
+			for k := range epochs {
+				epochs[k] = start + rng.Float64()*(end-start)
+			}
+			nb := len(multiBodies)
+			return func(i int) error {
+				b := multiBodies[i%nb]
+				_, _, err := e.CalculatePV(epochs[(i/nb)%randomEpochs], b.target, b.center, true)
+				return err
+			}, nil
+		},
+	},
+	{
+		Name:        "record-churn",
+		Description: "Mars from the Sun, each epoch in a different record, so that every operation reads the file",
+		prepare: func(e *jpleph.Ephemeris) (func(i int) error, error) {
+			start, end := e.GetEphemerisDouble(jpleph.EphemerisStartJD), e.GetEphemerisDouble(jpleph.EphemerisEndJD)
+			step := e.GetEphemerisDouble(jpleph.EphemerisStep)
+			n := int((end - start) / step)
+			if n < 2 {
+				return nil, fmt.Errorf("the file has %d records, fewer than two", n)
+			}
+			return func(i int) error {
+				// Stride through the records by about half the file, so that no two successive
+				// epochs share one; the memory per operation should stay at zero allocations
+				r := (i * (n/2 + 1)) % n
+				_, _, err := e.CalculatePV(start+(float64(r)+0.5)*step, jpleph.Mars, jpleph.CenterSun, true)
+				return err
+			}, nil
+		},
+	},
+	{
+		Name:        "light-time",
+		Description: "heliocentric Earth and Mars alternately at an epoch and 0.01 day before, as in a light-time iteration",
+		prepare: func(e *jpleph.Ephemeris) (func(i int) error, error) {
+			start, end := e.GetEphemerisDouble(jpleph.EphemerisStartJD), e.GetEphemerisDouble(jpleph.EphemerisEndJD)
+			n := int((end - start - 1) / scanStep)
+			if n < 1 {
+				return nil, fmt.Errorf("the file spans %g days, less than one step", end-start)
+			}
+			return func(i int) error {
+				// Three iterations of observer and target per epoch, the target at the retarded epoch
+				et := start + 1 + float64((i/6)%n)*scanStep
+				if i%2 == 0 {
+					_, _, err := e.CalculatePV(et, jpleph.Earth, jpleph.CenterSun, true)
+					return err
+				}
+				_, _, err := e.CalculatePV(et-0.01, jpleph.Mars, jpleph.CenterSun, true)
+				return err
+			}, nil
+		},
+	},
+	{
+		Name:        "constants",
+		Description: "lookups of the header constants by name, cycling through all of them",
+		prepare: func(e *jpleph.Ephemeris) (func(i int) error, error) {
+			var names []string
+			for k := 0; k < int(e.GetEphemerisLong(jpleph.NumberOfConstants)); k++ {
+				name, err := e.GetConstantName(k)
+				if err != nil {
+					break // Constants not loaded: look up the standard ones
+				}
+				names = append(names, strings.TrimRight(name, " "))
+			}
+			if len(names) == 0 {
+				names = []string{"AU", "EMRAT"}
+			}
+			return func(i int) error {
+				_, err := e.ConstantByName(names[i%len(names)])
+				return err
+			}, nil
+		},
+	},
+}
+
+// Workloads returns the available workloads.
+func Workloads() []Workload {
+	return append([]Workload(nil), workloads...)
+}
+
+// Lookup returns the workload of a given name.
+func Lookup(name string) (Workload, bool) {
+	for _, w := range workloads {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Workload{}, false
+}
+
+// Benchmark returns the workload as a benchmark function for go test:
+//
+//	func BenchmarkDenseScan(b *testing.B) {
+//		w, _ := benchmarks.Lookup("dense-scan")
+//		w.Benchmark(eph)(b)
+//	}
+func (w Workload) Benchmark(e *jpleph.Ephemeris) func(b *testing.B) {
+	return func(b *testing.B) {
+		op, err := w.prepare(e)
+		if err != nil {
+			b.Fatalf("%s: %v", w.Name, err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := op(i); err != nil {
+				b.Fatalf("%s: operation %d: %v", w.Name, i, err)
+			}
+		}
+	}
+}
+
+// Result is the measurement of a workload by Run.
+type Result struct {
+	Workload    string        // Name of the workload
+	Ops         int           // Operations timed
+	Elapsed     time.Duration // Time taken by the timed operations
+	NsPerOp     float64       // Mean time per operation in nanoseconds
+	OpsPerSec   float64       // Throughput in operations per second
+	AllocsPerOp float64       // Mean heap allocations per operation
+	BytesPerOp  float64       // Mean bytes allocated per operation
+}
+
+// String formats the result in the layout of go test -bench.
+func (r Result) String() string {
+	return fmt.Sprintf("%-18s %12d %12.1f ns/op %14.0f ops/s %10.2f allocs/op %10.1f B/op",
+		r.Workload, r.Ops, r.NsPerOp, r.OpsPerSec, r.AllocsPerOp, r.BytesPerOp)
+}
+
+// Run times a workload on an ephemeris for about the given duration, outside go test. As
+// in go test, the number of operations grows until a run lasts long enough, and only the
+// last run is reported.
+//
+// Parameters:
+//   - e: Ephemeris to measure; it is used from the calling goroutine only.
+//   - w: Workload to run.
+//   - duration: Minimum duration of the reported run.
+//
+// Returns:
+//   - Result: The measurement.
+//   - error: An error from the preparation of the workload or from one of its operations.
+func Run(e *jpleph.Ephemeris, w Workload, duration time.Duration) (Result, error) {
+	op, err := w.prepare(e)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", w.Name, err)
+	}
+	if err := op(0); err != nil { // Warm-up, and a check before timing
+		return Result{}, fmt.Errorf("%s: %w", w.Name, err)
+	}
+	n := 1
+	for {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		t0 := time.Now()
+		for i := 0; i < n; i++ {
+			if err := op(i); err != nil {
+				return Result{}, fmt.Errorf("%s: operation %d: %w", w.Name, i, err)
+			}
+		}
+		elapsed := time.Since(t0)
+		runtime.ReadMemStats(&after)
+		if elapsed >= duration || n >= 1e9 {
+			return Result{
+				Workload:    w.Name,
+				Ops:         n,
+				Elapsed:     elapsed,
+				NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+				OpsPerSec:   float64(n) / elapsed.Seconds(),
+				AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(n),
+				BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(n),
+			}, nil
+		}
+		// Aim 20% past the duration from the rate so far, growing by at most 100x per run
+		next := int(float64(n) * 1.2 * float64(duration) / float64(max(elapsed, 1)))
+		n = min(max(next, n+1), 100*n, 1e9)
+	}
+}
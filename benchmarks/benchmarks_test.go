@@ -0,0 +1,58 @@
+// ./benchmarks/benchmarks_test.go
+package benchmarks_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/benchmarks"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// TestStateWorkloadsDoNotAllocate checks the 0 allocs/op reported by "jpleph bench" for the
+// workloads of CalculatePV, record-churn among them, which reads a record at each operation.
+// Run counts the allocations of the whole process, so a few made by the runtime during the
+// run are tolerated; one in a hundred operations is not.
+func TestStateWorkloadsDoNotAllocate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jplephtest.Generate(&buf, jplephtest.DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	e, err := jpleph.NewEphemerisFromBytes(buf.Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	for _, name := range []string{"dense-scan", "random-multibody", "record-churn", "light-time"} {
+		w, ok := benchmarks.Lookup(name)
+		if !ok {
+			t.Fatalf("no workload %q", name)
+		}
+		res, err := benchmarks.Run(e, w, 20*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.AllocsPerOp > 0.01 {
+			t.Errorf("%s: %v allocs/op over %d operations, want 0", name, res.AllocsPerOp, res.Ops)
+		}
+	}
+}
+
+// BenchmarkWorkloads runs every workload on a synthetic DE440 file, e.g.
+// "go test -bench . ./benchmarks".
+func BenchmarkWorkloads(b *testing.B) {
+	var buf bytes.Buffer
+	if err := jplephtest.Generate(&buf, jplephtest.DefaultConfig()); err != nil {
+		b.Fatal(err)
+	}
+	e, err := jpleph.NewEphemerisFromBytes(buf.Bytes(), true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer e.Close()
+	for _, w := range benchmarks.Workloads() {
+		b.Run(w.Name, w.Benchmark(e))
+	}
+}
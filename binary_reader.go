@@ -29,6 +29,7 @@ import (
 	"encoding/binary"
 	"io"
 	"math"
+	"sync"
 )
 
 // defaultByteOrder specifies the default byte order for reading binary data.
@@ -45,6 +46,29 @@ func SetByteOrder(order binary.ByteOrder) {
 	byteOrder = order
 }
 
+// recordBuffers holds the byte buffers of readFloat64s between reads, so that loading a
+// record does not allocate. A buffer grows to the largest record read through it; the pool
+// drops idle buffers at garbage collections, which bounds the memory it keeps.
+var recordBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+// readFloat64s reads len(dst) little-endian float64 values, as binary.Read does, through a
+// pooled buffer instead of one allocated per call.
+func readFloat64s(r io.Reader, dst []float64) error {
+	bp := recordBuffers.Get().(*[]byte)
+	defer recordBuffers.Put(bp)
+	if cap(*bp) < 8*len(dst) {
+		*bp = make([]byte, 8*len(dst))
+	}
+	b := (*bp)[:8*len(dst)]
+	if _, err := io.ReadFull(r, b); err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float64frombits(defaultByteOrder.Uint64(b[8*i:]))
+	}
+	return nil
+}
+
 // getNumber reads a value of the specified type from the io.Reader using the configured byte order.
 // It takes an io.Reader and a pointer to the variable where the read value will be stored.
 // Returns an error if reading fails.
@@ -0,0 +1,212 @@
+// ./body_site.go
+package jpleph
+
+/*
+Package jpleph provides observer sites on the surfaces of other bodies.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// BodySite is an observer location on the surface of a body with a body-fixed frame in the
+// registry, such as a lander on Mars (areodetic coordinates) or the Moon (selenodetic
+// coordinates). On the Earth it matches Site, in the ITRF.
+type BodySite struct {
+	Body      Planet     // Body the site is on: a planet, the Moon, the Sun, or a planet center such as MarsCenter
+	Latitude  float64    // Planetodetic latitude in degrees, north positive
+	Longitude float64    // Longitude in degrees, east positive
+	Height    float64    // Height above the reference ellipsoid in meters
+	Frame     Frame      // Body-fixed frame of the coordinates; empty for the default frame of Body
+	Radii     [2]float64 // Equatorial and polar radii of the ellipsoid in km; zero for the values of Body
+}
+
+// bodyFrames are the default body-fixed frames of the bodies a BodySite can be on. The Moon
+// uses the mean-Earth frame of selenodetic coordinates; planet centers share the frame of
+// their system barycenter.
+var bodyFrames = map[Planet]Frame{
+	Sun: FrameIAUSun, Mercury: FrameIAUMercury, Venus: FrameIAUVenus, Earth: FrameITRF, Moon: FrameMoonME,
+	Mars: FrameIAUMars, Jupiter: FrameIAUJupiter, Saturn: FrameIAUSaturn, Uranus: FrameIAUUranus,
+	Neptune: FrameIAUNeptune, Pluto: FrameIAUPluto, MarsCenter: FrameIAUMars, JupiterCenter: FrameIAUJupiter,
+	SaturnCenter: FrameIAUSaturn, UranusCenter: FrameIAUUranus, NeptuneCenter: FrameIAUNeptune, PlutoCenter: FrameIAUPluto,
+}
+
+// bodyRadii are the equatorial and polar radii in km of the reference ellipsoids (IAU WGCCRE
+// 2015; WGS84 for the Earth). Planet centers share the radii of their system barycenter.
+var bodyRadii = map[Planet][2]float64{
+	Sun: {695700, 695700}, Mercury: {2440.53, 2438.26}, Venus: {6051.8, 6051.8},
+	Earth: {wgs84A, wgs84A * (1 - wgs84F)}, Moon: {1737.4, 1737.4}, Mars: {3396.19, 3376.20},
+	Jupiter: {71492, 66854}, Saturn: {60268, 54364}, Uranus: {25559, 24973}, Neptune: {24764, 24341},
+	Pluto: {1188.3, 1188.3},
+}
+
+// SkyPosition is the position of a target seen from a BodySite.
+type SkyPosition struct {
+	ET        float64    // Epoch of observation (TDB Julian Date)
+	ICRF      [3]float64 // Target relative to the site in the ICRF, in AU, with the corrections applied
+	BodyFixed [3]float64 // The same vector in the body-fixed frame of the site
+	Azimuth   float64    // Azimuth in degrees, from north through east
+	Elevation float64    // Elevation in degrees above the plane normal to the ellipsoid
+	Range     float64    // Distance in AU
+	LightTime float64    // One-way light time in days
+}
+
+// frame returns the body-fixed frame and the ellipsoid radii of the site.
+func (s BodySite) frame() (Frame, [2]float64, error) {
+	if !(s.Latitude >= -90 && s.Latitude <= 90) || math.IsNaN(s.Longitude) || math.IsInf(s.Longitude, 0) ||
+		math.IsNaN(s.Height) || math.IsInf(s.Height, 0) {
+		return "", [2]float64{}, fmt.Errorf("%w: coordinates of %+v", ErrSite, s)
+	}
+	frame := s.Frame
+	if frame == "" {
+		var ok bool
+		if frame, ok = bodyFrames[s.Body]; !ok {
+			return "", [2]float64{}, fmt.Errorf("%w: %v has no default body-fixed frame", ErrSite, s.Body)
+		}
+	}
+	radii := s.Radii
+	if radii[0] == 0 {
+		body := s.Body
+		if body > 100 && body%100 == 99 { // Planet center
+			body /= 100
+		}
+		var ok bool
+		if radii, ok = bodyRadii[body]; !ok {
+			return "", [2]float64{}, fmt.Errorf("%w: %v has no reference ellipsoid", ErrSite, s.Body)
+		}
+	}
+	if !(radii[0] > 0) || !(radii[1] > 0) || radii[1] > radii[0] {
+		return "", [2]float64{}, fmt.Errorf("%w: radii %v", ErrSite, radii)
+	}
+	return frame, radii, nil
+}
+
+// SiteState returns the state of a site relative to the Solar System Barycenter in the ICRF,
+// including the velocity of the rotation of its body.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - site: Observer location.
+//
+// Returns:
+//   - Position, Velocity: The state in AU and AU/day.
+//   - error: ErrSite for an invalid site, or an error from CalculatePV or the frames.
+func (r *FrameRegistry) SiteState(et float64, site BodySite) (Position, Velocity, error) {
+	frame, radii, err := site.frame()
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	au := r.eph.ephemData.au
+	x := geodeticPosition(site.Latitude, site.Longitude, site.Height/1000, radii[0], 1-radii[1]/radii[0])
+	offset, offsetVel, err := r.StateTransform(Position{X: x[0] / au, Y: x[1] / au, Z: x[2] / au}, Velocity{},
+		frame, FrameICRF, et)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	pos, vel, err := r.eph.CalculatePV(et, site.Body, CenterSolarSystemBarycenter, true)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	return Position{X: pos.X + offset.X, Y: pos.Y + offset.Y, Z: pos.Z + offset.Z},
+		Velocity{DX: vel.DX + offsetVel.DX, DY: vel.DY + offsetVel.DY, DZ: vel.DZ + offsetVel.DZ}, nil
+}
+
+// SkyPosition returns the position of a target, such as the Earth, the Sun, or Phobos, seen
+// from a site on another body, with its azimuth and elevation above the local horizon. The
+// light time is iterated to convergence, and the stellar aberration uses the velocity of the
+// site, including the rotation of its body.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date of observation.
+//   - target: Body observed (a kernel, SPK, custom, or source body).
+//   - site: Observer location.
+//   - corrections: CorrectionNone, CorrectionLT, or CorrectionLTS.
+//
+// Returns:
+//   - SkyPosition: The observed position.
+//   - error: ErrSite for an invalid site, ErrInvalidIndex if corrections is unknown or the
+//     target is the body of the site, or an error from CalculatePV or the frames.
+func (r *FrameRegistry) SkyPosition(et float64, target Planet, site BodySite, corrections Correction) (SkyPosition, error) {
+	if target == site.Body {
+		return SkyPosition{}, fmt.Errorf("target is the body of the site, %v: %w", target, ErrInvalidIndex)
+	}
+	if corrections < CorrectionNone || corrections > CorrectionLTS {
+		return SkyPosition{}, fmt.Errorf("%v: %w", corrections, ErrInvalidIndex)
+	}
+	frame, _, err := site.frame()
+	if err != nil {
+		return SkyPosition{}, err
+	}
+	obsPos, obsVel, err := r.SiteState(et, site)
+	if err != nil {
+		return SkyPosition{}, err
+	}
+
+	c := speedOfLightKMS * secondsPerDay / r.eph.ephemData.au // AU/day
+	var rel linalg.Vec3
+	lt := 0.0
+	for iter := 0; iter < 10; iter++ {
+		tgt, _, err := r.eph.CalculatePV(et-lt, target, CenterSolarSystemBarycenter, false)
+		if err != nil {
+			return SkyPosition{}, err
+		}
+		rel = linalg.Vec3{tgt.X - obsPos.X, tgt.Y - obsPos.Y, tgt.Z - obsPos.Z}
+		prev := lt
+		lt = rel.Norm() / c
+		if corrections == CorrectionNone || math.Abs(lt-prev) <= 1e-15*lt {
+			break
+		}
+	}
+	if corrections == CorrectionLTS {
+		kms := r.eph.ephemData.au / secondsPerDay
+		rel = ApplyAberration(rel, [3]float64{obsVel.DX * kms, obsVel.DY * kms, obsVel.DZ * kms})
+	}
+
+	m, err := r.rotation(FrameICRF, frame, et)
+	if err != nil {
+		return SkyPosition{}, err
+	}
+	x := m.Apply(rel)
+	sinLat, cosLat := math.Sincos(site.Latitude * math.Pi / 180) // Geodetic, as the ellipsoid normal
+	sinLon, cosLon := math.Sincos(site.Longitude * math.Pi / 180)
+	up := linalg.Vec3{cosLat * cosLon, cosLat * sinLon, sinLat}
+	east := linalg.Vec3{-sinLon, cosLon, 0}
+	north := up.Cross(east)
+	u := x.Unit()
+	az := math.Atan2(u.Dot(east), u.Dot(north)) * 180 / math.Pi
+	if az < 0 {
+		az += 360
+	}
+	return SkyPosition{
+		ET:        et,
+		ICRF:      [3]float64(rel),
+		BodyFixed: [3]float64(x),
+		Azimuth:   az,
+		Elevation: math.Asin(math.Max(-1, math.Min(1, u.Dot(up)))) * 180 / math.Pi,
+		Range:     rel.Norm(),
+		LightTime: lt,
+	}, nil
+}
@@ -0,0 +1,184 @@
+// ./centers.go
+package jpleph
+
+/*
+Package jpleph provides the chaining of states through the centers of the loaded bodies.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "fmt"
+
+// sourceBody is a StateSource registered with AddSource.
+type sourceBody struct {
+	center CenterBody  // Body the states of src are relative to
+	src    StateSource // Source of states in AU and AU/day
+}
+
+// AddSource registers any StateSource, such as a SmallBody or a Trajectory, as a body of the
+// ephemeris. It can then be used as a target or center of CalculatePV like a custom body,
+// and other bodies can be expressed relative to it. Clones of the ephemeris rebind the source
+// to themselves if they can (see Clone), and share it otherwise.
+//
+// Parameters:
+//   - id: Identifier of the body (FirstCustomBody or above, not already registered).
+//   - center: Body the states of src are relative to: a kernel, SPK, custom, or source body.
+//   - src: Source of states in AU and AU/day.
+//
+// Returns:
+//   - error: ErrCustomBody if id is below FirstCustomBody or already in use.
+func (e *Ephemeris) AddSource(id Planet, center CenterBody, src StateSource) error {
+	if id < FirstCustomBody {
+		return fmt.Errorf("%w: id %d is below FirstCustomBody (%d)", ErrCustomBody, id, FirstCustomBody)
+	}
+	if _, exists := e.sources[id]; exists || e.isCustom(id) {
+		return fmt.Errorf("%w: id %d is already registered", ErrCustomBody, id)
+	}
+	if e.sources == nil {
+		e.sources = make(map[Planet]sourceBody)
+	}
+	e.sources[id] = sourceBody{center: center, src: src}
+	return nil
+}
+
+// isChained reports whether id is a custom, source, or SPK body, whose states are found by
+// chaining through centers rather than from the JPL kernel alone.
+func (e *Ephemeris) isChained(id Planet) bool {
+	_, ok := e.sources[id]
+	return ok || e.isCustom(id) || isSPKBody(id)
+}
+
+// chainLink is one body on the path from a body to the Solar System Barycenter, with the
+// state of the starting body relative to it.
+type chainLink struct {
+	body Planet
+	pos  Position
+	vel  Velocity
+}
+
+// chainedPV computes the state of target relative to center when either is a custom, source,
+// or SPK body. Both are followed through their centers until the paths meet, and the states
+// are differenced at the first common body, usually a planetary barycenter. Titan relative to
+// Saturn, for example, never leaves the Saturn system, so it does not pick up the rounding of
+// the barycentric position of Saturn.
+func (e *Ephemeris) chainedPV(et float64, target Planet, center CenterBody, calcVelocity bool) (Position, Velocity, error) {
+	path, err := e.centerPath(et, target, calcVelocity, nil)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	cPath, err := e.centerPath(et, Planet(center), calcVelocity, path)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	c := cPath[len(cPath)-1] // The common body reached by the center
+	for _, t := range path {
+		if t.body == c.body {
+			pos := Position{X: t.pos.X - c.pos.X, Y: t.pos.Y - c.pos.Y, Z: t.pos.Z - c.pos.Z}
+			vel := Velocity{DX: t.vel.DX - c.vel.DX, DY: t.vel.DY - c.vel.DY, DZ: t.vel.DZ - c.vel.DZ}
+			return pos, vel, nil
+		}
+	}
+	return Position{}, Velocity{}, fmt.Errorf("%w: %v and %v have no common center", ErrBadBodyForCenter, target, center)
+}
+
+// centerPath follows body through its centers. It stops at the first body that is also on
+// stop, or at the Solar System Barycenter.
+func (e *Ephemeris) centerPath(et float64, body Planet, calcVelocity bool, stop []chainLink) ([]chainLink, error) {
+	path := []chainLink{{body: body}}
+	for hops := 0; ; hops++ {
+		link := path[len(path)-1]
+		if link.body == SolarSystemBarycenter {
+			return path, nil
+		}
+		for _, s := range stop {
+			if s.body == link.body {
+				return path, nil
+			}
+		}
+		if hops == maxSPKChain {
+			return nil, fmt.Errorf("%w: center chain of body %d is too long", ErrBadBodyForCenter, body)
+		}
+		pos, vel, parent, err := e.parentState(et, link.body, calcVelocity)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, chainLink{
+			body: parent,
+			pos:  Position{X: link.pos.X + pos.X, Y: link.pos.Y + pos.Y, Z: link.pos.Z + pos.Z},
+			vel:  Velocity{DX: link.vel.DX + vel.DX, DY: link.vel.DY + vel.DY, DZ: link.vel.DZ + vel.DZ},
+		})
+	}
+}
+
+// parentState returns the state of a body relative to its center, and that center. In the
+// JPL kernel the Moon is relative to the Earth, the Earth to the Earth-Moon barycenter, and
+// the other bodies to the Solar System Barycenter, following the quantities of the file.
+func (e *Ephemeris) parentState(et float64, body Planet, calcVelocity bool) (Position, Velocity, Planet, error) {
+	if isSPKBody(body) {
+		state, center, err := e.spkState(int(body), et)
+		if err != nil {
+			return Position{}, Velocity{}, 0, err
+		}
+		aufac := 1.0 / e.ephemData.au
+		pos := Position{X: state[0] * aufac, Y: state[1] * aufac, Z: state[2] * aufac}
+		var vel Velocity
+		if calcVelocity {
+			vfac := aufac * secondsPerDay // km/s to AU/day
+			vel = Velocity{DX: state[3] * vfac, DY: state[4] * vfac, DZ: state[5] * vfac}
+		}
+		return pos, vel, planetFromNAIF(center), nil
+	}
+	if custom, ok := e.customBodies[body]; ok {
+		pos, vel, err := custom.State(et, calcVelocity)
+		return pos, vel, Planet(custom.Center), err
+	}
+	if s, ok := e.sources[body]; ok {
+		pos, vel, err := s.src.StateAt(et)
+		if !calcVelocity {
+			vel = Velocity{}
+		}
+		return pos, vel, Planet(s.center), err
+	}
+
+	var parent CenterBody
+	switch {
+	case body == Moon:
+		parent = CenterEarth
+	case body == Earth:
+		parent = CenterEarthMoonBarycenter
+	case body >= Mercury && body <= EarthMoonBarycenter:
+		parent = CenterSolarSystemBarycenter
+	default:
+		return Position{}, Velocity{}, 0, fmt.Errorf("%v: %w", body, ErrInvalidIndex)
+	}
+	// plephInto rather than CalculatePV, which has clamped et already and would reset Clamped
+	velFlag := 0
+	if calcVelocity {
+		velFlag = 2
+	}
+	var rrd [6]float64
+	if err := plephInto(e.ephemData, et, 0, int(body), int(parent), velFlag, rrd[:], false); err != nil {
+		return Position{}, Velocity{}, 0, err
+	}
+	pos := Position{X: rrd[0], Y: rrd[1], Z: rrd[2]}
+	vel := Velocity{DX: rrd[3], DY: rrd[4], DZ: rrd[5]}
+	return pos, vel, Planet(parent), nil
+}
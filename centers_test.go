@@ -0,0 +1,67 @@
+// ./centers_test.go
+package jpleph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// fixedSource is a StateSource at a fixed offset from its center.
+type fixedSource struct {
+	pos jpleph.Position
+}
+
+func (s fixedSource) StateAt(et float64) (jpleph.Position, jpleph.Velocity, error) {
+	return s.pos, jpleph.Velocity{}, nil
+}
+
+// TestClampedChained checks that Clamped reports the clamping of the epoch of a query that is
+// chained through a source body, as target or as center.
+func TestClampedChained(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	e := openSynthetic(t, cfg, jpleph.WithClampToRange())
+	id := jpleph.FirstCustomBody
+	offset := jpleph.Position{X: 1e-3, Y: -2e-3, Z: 5e-4}
+	if err := e.AddSource(id, jpleph.CenterEarth, fixedSource{pos: offset}); err != nil {
+		t.Fatal(err)
+	}
+	earth, _, err := e.CalculatePV(cfg.Start, jpleph.Earth, jpleph.CenterSolarSystemBarycenter, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		et          float64
+		target      jpleph.Planet
+		center      jpleph.CenterBody
+		wantClamped bool
+	}{
+		{"source target, inside", cfg.Start + 1, id, jpleph.CenterSolarSystemBarycenter, false},
+		{"source target, clamped", cfg.Start - 30.0/86400, id, jpleph.CenterSolarSystemBarycenter, true},
+		{"source center, clamped", cfg.Start - 30.0/86400, jpleph.Sun, jpleph.CenterBody(id), true},
+		{"source center, inside", cfg.Start + 1, jpleph.Sun, jpleph.CenterBody(id), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := e.CalculatePV(tt.et, tt.target, tt.center, true); err != nil {
+				t.Fatal(err)
+			}
+			if e.Clamped() != tt.wantClamped {
+				t.Errorf("Clamped() = %v, want %v", e.Clamped(), tt.wantClamped)
+			}
+		})
+	}
+
+	pos, _, err := e.CalculatePV(cfg.Start-30.0/86400, id, jpleph.CenterSolarSystemBarycenter, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := jpleph.Position{X: earth.X + offset.X, Y: earth.Y + offset.Y, Z: earth.Z + offset.Z}
+	if d := math.Max(math.Abs(pos.X-want.X), math.Max(math.Abs(pos.Y-want.Y), math.Abs(pos.Z-want.Z))); d > 1e-15 {
+		t.Errorf("clamped source state %v, want %v at the start of the file", pos, want)
+	}
+}
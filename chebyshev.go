@@ -0,0 +1,172 @@
+// ./chebyshev.go
+package jpleph
+
+/*
+Package jpleph provides Chebyshev fitting utilities for user-supplied trajectories.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// FitChebyshev computes the Chebyshev expansion of fn over the interval [start, end].
+// The function is sampled at the ncoeff Chebyshev nodes of the interval, which gives a
+// near-minimax approximation without solving a least-squares system. The returned
+// coefficients use the same convention as the JPL kernels: the value at a normalized
+// time tc in [-1, 1] is sum(coeffs[i] * T_i(tc)), with no halving of coeffs[0].
+//
+// Parameters:
+//   - fn: Function to approximate, evaluated at Julian Ephemeris Dates within [start, end].
+//   - start: Start of the fitting interval.
+//   - end: End of the fitting interval (must be greater than start).
+//   - ncoeff: Number of coefficients to compute (1 to maxCheby-1).
+//
+// Returns:
+//   - []float64: Chebyshev coefficients, lowest order first.
+//   - error: ErrInvalidFit if the interval or coefficient count is invalid.
+func FitChebyshev(fn func(et float64) float64, start, end float64, ncoeff int) ([]float64, error) {
+	if ncoeff < 1 || ncoeff >= maxCheby {
+		return nil, fmt.Errorf("%w: ncoeff %d must be between 1 and %d", ErrInvalidFit, ncoeff, maxCheby-1)
+	}
+	if !(end > start) {
+		return nil, fmt.Errorf("%w: interval end %f must be after start %f", ErrInvalidFit, end, start)
+	}
+
+	samples := make([]float64, ncoeff)
+	for k, et := range chebyshevNodes(start, end, ncoeff) {
+		samples[k] = fn(et)
+	}
+	return chebyshevCoefficients(samples), nil
+}
+
+// fitChebyshevVector fits the three components returned by fn over [start, end],
+// sampling fn only once per node. The coefficients are laid out component by
+// component (x block, then y, then z), matching the layout interp() expects for a
+// single sub-interval.
+func fitChebyshevVector(fn func(et float64) ([3]float64, error), start, end float64, ncoeff int) ([]float64, error) {
+	if ncoeff < 1 || ncoeff >= maxCheby {
+		return nil, fmt.Errorf("%w: ncoeff %d must be between 1 and %d", ErrInvalidFit, ncoeff, maxCheby-1)
+	}
+	if !(end > start) {
+		return nil, fmt.Errorf("%w: interval end %f must be after start %f", ErrInvalidFit, end, start)
+	}
+
+	var samples [3][]float64
+	for i := range samples {
+		samples[i] = make([]float64, ncoeff)
+	}
+	for k, et := range chebyshevNodes(start, end, ncoeff) {
+		v, err := fn(et)
+		if err != nil {
+			return nil, fmt.Errorf("sampling at JD %f failed: %w", et, err)
+		}
+		for i := 0; i < 3; i++ {
+			samples[i][k] = v[i]
+		}
+	}
+
+	coeffs := make([]float64, 0, 3*ncoeff)
+	for i := 0; i < 3; i++ {
+		coeffs = append(coeffs, chebyshevCoefficients(samples[i])...)
+	}
+	return coeffs, nil
+}
+
+// chebyshevNodes returns the n Chebyshev nodes of the interval [start, end].
+func chebyshevNodes(start, end float64, n int) []float64 {
+	mid := 0.5 * (end + start)  // Interval midpoint
+	half := 0.5 * (end - start) // Interval half-width
+	nodes := make([]float64, n)
+	for k := 0; k < n; k++ {
+		nodes[k] = mid + half*math.Cos(math.Pi*(float64(k)+0.5)/float64(n)) // Node k mapped from [-1, 1]
+	}
+	return nodes
+}
+
+// chebyshevCoefficients converts samples taken at chebyshevNodes() into Chebyshev coefficients.
+func chebyshevCoefficients(samples []float64) []float64 {
+	n := float64(len(samples))
+	coeffs := make([]float64, len(samples))
+	for j := range coeffs {
+		sum := 0.0
+		for k, f := range samples {
+			sum += f * math.Cos(math.Pi*float64(j)*(float64(k)+0.5)/n)
+		}
+		coeffs[j] = 2.0 * sum / n
+	}
+	coeffs[0] *= 0.5 // The JPL convention folds the 1/2 factor into the constant term
+	return coeffs
+}
+
+// EvalChebyshev evaluates a Chebyshev expansion and its time derivative at et.
+// The coefficients must follow the convention used by FitChebyshev.
+//
+// Parameters:
+//   - coeffs: Chebyshev coefficients, lowest order first.
+//   - start: Start of the interval the coefficients were fitted over.
+//   - end: End of the interval the coefficients were fitted over.
+//   - et: Julian Ephemeris Date at which to evaluate.
+//
+// Returns:
+//   - float64: Value of the expansion at et.
+//   - float64: Derivative of the expansion with respect to et (per day).
+func EvalChebyshev(coeffs []float64, start, end, et float64) (float64, float64) {
+	if len(coeffs) == 0 {
+		return 0, 0
+	}
+	tc := (2.0*et - start - end) / (end - start) // Normalized time in [-1, 1]
+	twot := tc + tc
+
+	// Same recurrences for T_i and T'_i as used by interp().
+	tPrev, tCurr := 1.0, tc  // T_0, T_1
+	dPrev, dCurr := 0.0, 1.0 // T'_0, T'_1
+	value, deriv := coeffs[0], 0.0
+	for i := 1; i < len(coeffs); i++ {
+		value += coeffs[i] * tCurr
+		deriv += coeffs[i] * dCurr
+		tNext := twot*tCurr - tPrev
+		dNext := twot*dCurr + 2*tCurr - dPrev
+		tPrev, tCurr = tCurr, tNext
+		dPrev, dCurr = dCurr, dNext
+	}
+	return value, deriv * 2.0 / (end - start)
+}
+
+// compensatedDot returns the dot product of a and b computed with the compensated algorithm
+// Dot2 of Ogita, Rump, and Oishi (2005): the rounding errors of the products (recovered with
+// FMA) and of the running sum (recovered with TwoSum) are accumulated separately and added
+// at the end, giving a result as accurate as if computed in twice the working precision.
+func compensatedDot(a, b []float64) float64 {
+	sum, comp := 0.0, 0.0
+	for i := range a {
+		p := a[i] * b[i]
+		pErr := math.FMA(a[i], b[i], -p) // Exact rounding error of the product
+		t := sum + p
+		z := t - sum
+		sErr := (sum - (t - z)) + (p - z) // Exact rounding error of the sum (TwoSum)
+		sum = t
+		comp += pErr + sErr
+	}
+	return sum + comp
+}
@@ -0,0 +1,98 @@
+// ./close_approach.go
+package jpleph
+
+/*
+Package jpleph provides the search for close approaches between two bodies.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// CloseApproach is a local minimum of the distance between two bodies.
+type CloseApproach struct {
+	ET       float64  // Epoch of the minimum (TDB Julian Date)
+	Distance float64  // Miss distance in AU
+	Velocity Velocity // Velocity of the target relative to the center at ET, in AU/day
+	Speed    float64  // Relative speed in AU/day (the norm of Velocity)
+}
+
+// CloseApproaches finds the close approaches of two bodies over a time span: the epochs when
+// their geometric distance reaches a local minimum, where the range rate changes from
+// negative to positive, with the miss distance and the relative velocity there. Minima at
+// the ends of the span are not approaches and are left out, as are those farther than
+// maxDistance when it is positive.
+//
+// Parameters:
+//   - target: Body approaching (any body accepted by CalculatePV, such as a small body of an SPK kernel).
+//   - center: Body approached (e.g., CenterEarth).
+//   - start, end: Time span (TDB Julian Dates).
+//   - step: Sampling step in days; 0 means 1. It must be shorter than the time between two
+//     approaches, and a fast encounter needs a step well below its duration.
+//   - maxDistance: Largest miss distance reported in AU; 0 for every minimum.
+//
+// Returns:
+//   - []CloseApproach: The approaches in chronological order.
+//   - error: ErrEventSearch for an invalid span or step, or an error from CalculatePV.
+func (e *Ephemeris) CloseApproaches(target Planet, center CenterBody, start, end, step, maxDistance float64) ([]CloseApproach, error) {
+	if step == 0 {
+		step = 1
+	}
+	if !(end > start) || !(step > 0) || math.IsNaN(maxDistance) {
+		return nil, fmt.Errorf("%w: interval [%f, %f], step %g", ErrEventSearch, start, end, step)
+	}
+	rangeRate := func(et float64) (float64, error) {
+		pos, vel, err := e.CalculatePV(et, target, center, true)
+		if err != nil {
+			return 0, err
+		}
+		return pos.X*vel.DX + pos.Y*vel.DY + pos.Z*vel.DZ, nil // r·v, of the sign of the range rate
+	}
+	crossings, err := findCrossings(rangeRate, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	var approaches []CloseApproach
+	for _, c := range crossings {
+		if !c.rising {
+			continue // A maximum of the distance
+		}
+		pos, vel, err := e.CalculatePV(c.et, target, center, true)
+		if err != nil {
+			return nil, err
+		}
+		a := CloseApproach{
+			ET:       c.et,
+			Distance: norm3([3]float64{pos.X, pos.Y, pos.Z}),
+			Velocity: vel,
+			Speed:    norm3([3]float64{vel.DX, vel.DY, vel.DZ}),
+		}
+		if maxDistance > 0 && a.Distance > maxDistance {
+			continue
+		}
+		approaches = append(approaches, a)
+	}
+	return approaches, nil
+}
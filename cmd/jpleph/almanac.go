@@ -0,0 +1,57 @@
+// ./cmd/jpleph/almanac.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// runAlmanac implements "jpleph almanac file".
+func runAlmanac(args []string) error {
+	fs := flag.NewFlagSet("almanac", flag.ExitOnError)
+	body := fs.String("body", "mars", "observed body (name or number)")
+	start := fs.Float64("start", 0, "first epoch (TDB Julian Date; default: one day after the start of the file, leaving room for light time)")
+	end := fs.Float64("end", 0, "last epoch (TDB Julian Date; default: start + 30 days)")
+	step := fs.Float64("step", 1, "step in days")
+	lang := fs.String("lang", "", "language of the body names (e.g., de, es, fr; default: English)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph almanac [flags] file\n\n")
+		fmt.Fprintf(os.Stderr, "Writes a geocentric observer table (RA/Dec, magnitude, distances, elongation,\n")
+		fmt.Fprintf(os.Stderr, "phase angle) in the layout of JPL Horizons OBSERVER output.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	target, err := parseBody(*body)
+	if err != nil {
+		return err
+	}
+	names, ok := jpleph.LookupLocalization(*lang)
+	if !ok {
+		return fmt.Errorf("unknown language %q", *lang)
+	}
+	eph, err := jpleph.NewEphemeris(fs.Arg(0), false)
+	if err != nil {
+		return err
+	}
+	defer eph.Close()
+
+	if *start == 0 {
+		*start = eph.GetEphemerisDouble(jpleph.EphemerisStartJD) + 1
+	}
+	if *end == 0 {
+		*end = *start + 30
+	}
+	rows, err := eph.Almanac(target, *start, *end, *step)
+	if err != nil {
+		return err
+	}
+	return jpleph.WriteAlmanacLocalized(os.Stdout, target, rows, names)
+}
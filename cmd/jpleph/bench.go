@@ -0,0 +1,78 @@
+// ./cmd/jpleph/bench.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/benchmarks"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// runBench implements "jpleph bench [file]".
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	duration := fs.Duration("duration", time.Second, "minimum duration of each workload")
+	names := fs.String("workloads", "", "comma-separated workloads to run (default: all)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph bench [flags] [file]\n\n")
+		fmt.Fprintf(os.Stderr, "Measures the throughput of typical workloads on an ephemeris file, or on a\n")
+		fmt.Fprintf(os.Stderr, "small synthetic file in memory when none is given. Workloads:\n\n")
+		for _, w := range benchmarks.Workloads() {
+			fmt.Fprintf(os.Stderr, "  %-18s %s\n", w.Name, w.Description)
+		}
+		fmt.Fprintln(os.Stderr)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	selected := benchmarks.Workloads()
+	if *names != "" {
+		selected = selected[:0]
+		for _, name := range strings.Split(*names, ",") {
+			w, ok := benchmarks.Lookup(strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("unknown workload %q", name)
+			}
+			selected = append(selected, w)
+		}
+	}
+
+	var eph *jpleph.Ephemeris
+	var err error
+	source := "synthetic file (jplephtest.DefaultConfig)"
+	if fs.NArg() == 1 {
+		source = fs.Arg(0)
+		eph, err = jpleph.NewEphemeris(source, true)
+	} else {
+		var buf bytes.Buffer
+		if err = jplephtest.Generate(&buf, jplephtest.DefaultConfig()); err == nil {
+			eph, err = jpleph.NewEphemerisFromBytes(buf.Bytes(), true)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer eph.Close()
+
+	fmt.Printf("# %s, DE%d\n", source, eph.GetEphemerisLong(jpleph.EphemerisVersion))
+	fmt.Printf("# %s %s/%s, %d CPUs\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	for _, w := range selected {
+		res, err := benchmarks.Run(eph, w, *duration)
+		if err != nil {
+			return err
+		}
+		fmt.Println(res)
+	}
+	return nil
+}
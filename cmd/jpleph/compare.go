@@ -0,0 +1,135 @@
+// ./cmd/jpleph/compare.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// diffStats accumulates the maximum and RMS differences for one body.
+type diffStats struct {
+	maxPos, sumPos2 float64 // maxPos and sumPos2 track position differences in km.
+	maxVel, sumVel2 float64 // maxVel and sumVel2 track velocity differences in m/s.
+	maxPosJD        float64 // maxPosJD is the epoch of the largest position difference.
+	n               int     // n is the number of samples.
+}
+
+// add records one position (km) and velocity (m/s) difference sampled at et.
+func (s *diffStats) add(et, dPos, dVel float64) {
+	if dPos > s.maxPos {
+		s.maxPos, s.maxPosJD = dPos, et
+	}
+	s.maxVel = math.Max(s.maxVel, dVel)
+	s.sumPos2 += dPos * dPos
+	s.sumVel2 += dVel * dVel
+	s.n++
+}
+
+// runCompare implements "jpleph compare fileA fileB".
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	samples := fs.Int("samples", 2000, "number of epochs sampled across the overlapping time range")
+	step := fs.Float64("step", 0, "sampling step in days (overrides -samples when positive)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph compare [flags] fileA fileB\n\n")
+		fmt.Fprintf(os.Stderr, "Samples the states of all bodies across the overlapping time range of two\n")
+		fmt.Fprintf(os.Stderr, "ephemeris files and reports maximum and RMS differences. Bodies are compared\n")
+		fmt.Fprintf(os.Stderr, "relative to the Solar System Barycenter, except the Moon (geocentric).\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	a, err := jpleph.NewEphemeris(fs.Arg(0), false)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	b, err := jpleph.NewEphemeris(fs.Arg(1), false)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	start := math.Max(a.GetEphemerisDouble(jpleph.EphemerisStartJD), b.GetEphemerisDouble(jpleph.EphemerisStartJD))
+	end := math.Min(a.GetEphemerisDouble(jpleph.EphemerisEndJD), b.GetEphemerisDouble(jpleph.EphemerisEndJD))
+	if !(end > start) {
+		return errors.New("the files do not overlap in time")
+	}
+	n := *samples
+	if *step > 0 {
+		n = int((end - start) / *step)
+	}
+	if n < 1 {
+		return errors.New("at least one sample is required")
+	}
+	dt := (end - start) / float64(n)
+	auA, auB := a.GetEphemerisDouble(jpleph.AUinKM), b.GetEphemerisDouble(jpleph.AUinKM)
+
+	type bodySpec struct {
+		target jpleph.Planet
+		center jpleph.CenterBody
+	}
+	var bodies []bodySpec
+	for p := jpleph.Mercury; p <= jpleph.EarthMoonBarycenter; p++ {
+		switch p {
+		case jpleph.SolarSystemBarycenter:
+			continue
+		case jpleph.Moon:
+			bodies = append(bodies, bodySpec{p, jpleph.CenterEarth})
+		default:
+			bodies = append(bodies, bodySpec{p, jpleph.CenterSolarSystemBarycenter})
+		}
+	}
+
+	stats := make([]diffStats, len(bodies))
+	for i := 0; i <= n; i++ {
+		et := start + float64(i)*dt
+		for j, body := range bodies {
+			pa, va, err := a.CalculatePV(et, body.target, body.center, true)
+			if err != nil {
+				return fmt.Errorf("%s at JD %.5f in %s: %w", body.target, et, fs.Arg(0), err)
+			}
+			pb, vb, err := b.CalculatePV(et, body.target, body.center, true)
+			if err != nil {
+				return fmt.Errorf("%s at JD %.5f in %s: %w", body.target, et, fs.Arg(1), err)
+			}
+			dPos := math.Sqrt(sq(pa.X*auA-pb.X*auB) + sq(pa.Y*auA-pb.Y*auB) + sq(pa.Z*auA-pb.Z*auB))
+			dVel := math.Sqrt(sq(va.DX*auA-vb.DX*auB)+sq(va.DY*auA-vb.DY*auB)+sq(va.DZ*auA-vb.DZ*auB)) * 1000 / 86400
+			stats[j].add(et, dPos, dVel)
+		}
+	}
+
+	fmt.Printf("A: %s (%s)\nB: %s (%s)\n", fs.Arg(0), ephemName(a), fs.Arg(1), ephemName(b))
+	fmt.Printf("Overlap: JD %.1f to %.1f, %d samples every %.4f days\n\n", start, end, n+1, dt)
+	fmt.Printf("%-24s %14s %14s %14s %14s %14s\n", "Body", "max dr (km)", "RMS dr (km)", "at JD", "max dv (m/s)", "RMS dv (m/s)")
+	for j, body := range bodies {
+		s := stats[j]
+		label := body.target.String()
+		if body.center != jpleph.CenterSolarSystemBarycenter {
+			label += " (geocentric)"
+		}
+		fmt.Printf("%-24s %14.6e %14.6e %14.2f %14.6e %14.6e\n", label,
+			s.maxPos, math.Sqrt(s.sumPos2/float64(s.n)), s.maxPosJD, s.maxVel, math.Sqrt(s.sumVel2/float64(s.n)))
+	}
+	return nil
+}
+
+// ephemName returns the name of the ephemeris without the NUL padding of the kernel field.
+func ephemName(e *jpleph.Ephemeris) string {
+	return strings.TrimRight(e.GetEphemName(), "\x00")
+}
+
+// sq returns x squared.
+func sq(x float64) float64 {
+	return x * x
+}
@@ -0,0 +1,113 @@
+// ./cmd/jpleph/constants_diff.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// namedConstant is one header constant of a file.
+type namedConstant struct {
+	name  string  // name is the constant name without its blank padding.
+	value float64 // value is the constant value in the units of the file.
+}
+
+// readConstants returns the header constants of a file in file order, keeping the first of
+// repeated names.
+func readConstants(e *jpleph.Ephemeris) ([]namedConstant, map[string]float64, error) {
+	n := int(e.GetEphemerisLong(jpleph.NumberOfConstants))
+	list := make([]namedConstant, 0, n)
+	byName := make(map[string]float64, n)
+	for i := 0; i < n; i++ {
+		name, err := e.GetConstantName(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := e.GetConstantValue(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		name = strings.TrimRight(name, " \x00")
+		if _, ok := byName[name]; ok {
+			continue
+		}
+		byName[name] = value
+		list = append(list, namedConstant{name, value})
+	}
+	return list, byName, nil
+}
+
+// runConstantsDiff implements "jpleph constants-diff fileA fileB".
+func runConstantsDiff(args []string) error {
+	fs := flag.NewFlagSet("constants-diff", flag.ExitOnError)
+	tolerance := fs.Float64("tolerance", 0, "largest relative difference of a constant reported as unchanged")
+	all := fs.Bool("all", false, "also list the constants that are unchanged")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph constants-diff [flags] fileA fileB\n\n")
+		fmt.Fprintf(os.Stderr, "Compares the header constants of two ephemeris files and lists those added in\n")
+		fmt.Fprintf(os.Stderr, "B, removed from A, and changed, with the absolute and relative differences\n")
+		fmt.Fprintf(os.Stderr, "(e.g., the GM updates between DE430 and DE440), for migrating force models.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	a, err := jpleph.NewEphemeris(fs.Arg(0), true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	b, err := jpleph.NewEphemeris(fs.Arg(1), true)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	listA, byNameA, err := readConstants(a)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	listB, byNameB, err := readConstants(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(1), err)
+	}
+
+	fmt.Printf("A: %s (%s), %d constants\nB: %s (%s), %d constants\n\n",
+		fs.Arg(0), ephemName(a), len(listA), fs.Arg(1), ephemName(b), len(listB))
+	fmt.Printf("%-9s %-6s %24s %24s %15s %11s\n", "", "Name", "A", "B", "B - A", "relative")
+	var changed, added, removed int
+	for _, c := range listA {
+		vb, ok := byNameB[c.name]
+		if !ok {
+			removed++
+			fmt.Printf("%-9s %-6s %24.16e\n", "removed", c.name, c.value)
+			continue
+		}
+		diff := vb - c.value
+		rel := math.Abs(diff) / math.Max(math.Abs(c.value), math.Abs(vb))
+		if diff == 0 || rel <= *tolerance {
+			if *all {
+				fmt.Printf("%-9s %-6s %24.16e %24.16e\n", "unchanged", c.name, c.value, vb)
+			}
+			continue
+		}
+		changed++
+		fmt.Printf("%-9s %-6s %24.16e %24.16e %+15.6e %11.3e\n", "changed", c.name, c.value, vb, diff, rel)
+	}
+	for _, c := range listB {
+		if _, ok := byNameA[c.name]; !ok {
+			added++
+			fmt.Printf("%-9s %-6s %24s %24.16e\n", "added", c.name, "", c.value)
+		}
+	}
+	fmt.Printf("\n%d changed, %d added, %d removed, %d unchanged\n",
+		changed, added, removed, len(listA)-changed-removed)
+	return nil
+}
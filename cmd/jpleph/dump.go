@@ -0,0 +1,107 @@
+// ./cmd/jpleph/dump.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// dumpAliases are the short names accepted by -body in addition to the quantity names.
+var dumpAliases = map[string]jpleph.Quantity{
+	"emb": jpleph.QuantityEarthMoonBarycenter, "moon": jpleph.QuantityMoon, "nutation": jpleph.QuantityNutations,
+	"libration": jpleph.QuantityLibrations, "mantle": jpleph.QuantityLunarMantleOmega, "ttmtdb": jpleph.QuantityTTmTDB,
+}
+
+// runDump implements "jpleph dump file".
+func runDump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	body := fs.String("body", "moon", "quantity stored in the file (name or IPT index 0-14; e.g., moon, emb, sun, nutations)")
+	jd := fs.Float64("jd", 0, "epoch (TDB Julian Date; default: start of the file)")
+	asJSON := fs.Bool("json", false, "write the dump as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph dump [flags] file\n\n")
+		fmt.Fprintf(os.Stderr, "Prints the raw Chebyshev coefficients of one quantity at an epoch, with the\n")
+		fmt.Fprintf(os.Stderr, "record, sub-interval, and normalized time used to interpolate it.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	q, err := parseQuantity(*body)
+	if err != nil {
+		return err
+	}
+	eph, err := jpleph.NewEphemeris(fs.Arg(0), false)
+	if err != nil {
+		return err
+	}
+	defer eph.Close()
+	if *jd == 0 {
+		*jd = eph.GetEphemerisDouble(jpleph.EphemerisStartJD)
+	}
+	d, err := eph.Coefficients(*jd, q)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+
+	fmt.Printf("Quantity:        %v (IPT %d %d %d)\n", d.Quantity, d.IPT[0], d.IPT[1], d.IPT[2])
+	fmt.Printf("Epoch:           JD %.9f\n", d.ET)
+	fmt.Printf("Record:          %d (JD %.1f to %.1f), t = %.17g\n", d.Record, d.RecordStart, d.RecordEnd, d.RecordFraction)
+	fmt.Printf("Sub-interval:    %d of %d (JD %.6f to %.6f)\n", d.SubInterval, d.IPT[2], d.IntervalStart, d.IntervalEnd)
+	fmt.Printf("Normalized time: %.17g\n", d.NormalizedTime)
+	fmt.Printf("Units:           %s\n\n", d.Units)
+	fmt.Printf("%4s", "n")
+	for k := range d.Coefficients {
+		fmt.Printf(" %24s", fmt.Sprintf("c[%d]", k))
+	}
+	fmt.Println()
+	for j := 0; j < int(d.IPT[1]); j++ {
+		fmt.Printf("%4d", j)
+		for _, c := range d.Coefficients {
+			fmt.Printf(" %24.16e", c[j])
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%4s", "sum")
+	for _, v := range d.Values {
+		fmt.Printf(" %24.16e", v)
+	}
+	fmt.Println()
+	return nil
+}
+
+// parseQuantity parses a quantity given by IPT index or by name, ignoring case, spaces, and
+// hyphens (e.g., "moon", "emb", or "9", the IPT index of the Moon).
+func parseQuantity(s string) (jpleph.Quantity, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return jpleph.Quantity(n), nil
+	}
+	key := normalizeBodyName(s)
+	if q, ok := dumpAliases[key]; ok {
+		return q, nil
+	}
+	for q := jpleph.QuantityMercury; q <= jpleph.QuantityTTmTDB; q++ {
+		if normalizeBodyName(q.String()) == key {
+			return q, nil
+		}
+	}
+	if key == "earth" {
+		return 0, fmt.Errorf("the Earth is not stored in the file: dump emb and moon")
+	}
+	return 0, fmt.Errorf("unknown quantity %q", s)
+}
@@ -0,0 +1,78 @@
+// ./cmd/jpleph/main.go
+package main
+
+/*
+Command jpleph provides command-line tools built on the jpleph package.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"os"
+)
+
+// command describes a jpleph subcommand.
+type command struct {
+	name    string                    // name is the subcommand name given on the command line.
+	summary string                    // summary is a one-line description shown in the usage message.
+	run     func(args []string) error // run executes the subcommand with the remaining arguments.
+}
+
+// commands lists the available subcommands in the order shown by the usage message.
+var commands = []command{
+	{"compare", "compare the states of all bodies in two ephemeris files", runCompare},
+	{"table", "write tables of states as CSV, JSON lines, or Parquet", runTable},
+	{"almanac", "write Horizons-like observer tables of a body", runAlmanac},
+	{"dump", "print the raw Chebyshev coefficients of a quantity at an epoch", runDump},
+	{"bench", "measure the throughput of typical workloads", runBench},
+	{"testpo", "check an ephemeris file against a JPL testpo file or the embedded states", runTestpo},
+	{"constants-diff", "list the header constants added, removed, or changed between two files", runConstantsDiff},
+}
+
+// usage prints the list of subcommands to stderr.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for help on a command.\n", os.Args[0])
+}
+
+// main is the entry point of the jpleph command.
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "jpleph %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "jpleph: unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
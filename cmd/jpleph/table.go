@@ -0,0 +1,166 @@
+// ./cmd/jpleph/table.go
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// tableQuantities maps the -quantities names to table column groups.
+var tableQuantities = map[string]jpleph.TableQuantity{
+	"pos": jpleph.TablePosition, "vel": jpleph.TableVelocity, "r": jpleph.TableDistance,
+	"rdot": jpleph.TableRangeRate, "lonlat": jpleph.TableLonLat,
+}
+
+// tableFrames maps the -frame names to table frames.
+var tableFrames = map[string]jpleph.TableFrame{
+	"icrf": jpleph.TableICRF, "ecliptic": jpleph.TableEclipticJ2000, "ecliptic-of-date": jpleph.TableEclipticOfDate,
+	"galactic": jpleph.TableGalactic, "invariable": jpleph.TableInvariable,
+}
+
+// tableUnits maps the -units names to table units.
+var tableUnits = map[string]jpleph.TableUnits{
+	"au": jpleph.TableAU, "km/s": jpleph.TableKmPerSecond, "km/d": jpleph.TableKmPerDay,
+}
+
+// runTable implements "jpleph table file".
+func runTable(args []string) error {
+	fs := flag.NewFlagSet("table", flag.ExitOnError)
+	bodies := fs.String("bodies", "mercury,venus,earth,mars,jupiter,saturn,uranus,neptune,pluto",
+		"comma-separated target bodies (names or numbers)")
+	center := fs.String("center", "ssb", "center body (name or number)")
+	start := fs.Float64("start", 0, "first epoch (TDB Julian Date; default: start of the file)")
+	end := fs.Float64("end", 0, "last epoch (TDB Julian Date; default: start + 365 days)")
+	step := fs.Float64("step", 1, "step in days")
+	quantities := fs.String("quantities", "pos,vel", "comma-separated column groups: pos, vel, r, rdot, lonlat")
+	frame := fs.String("frame", "icrf", "frame: icrf, ecliptic, ecliptic-of-date, galactic, invariable")
+	units := fs.String("units", "au", "units: au (AU, AU/day), km/s, km/d")
+	format := fs.String("format", "csv", "output format: csv, jsonl, parquet")
+	output := fs.String("o", "", "output file (default: standard output)")
+	lang := fs.String("lang", "", "language of the body column (e.g., de, es, fr; default: English)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph table [flags] file\n\n")
+		fmt.Fprintf(os.Stderr, "Writes a table of states of the bodies at every step of a time span as CSV,\n")
+		fmt.Fprintf(os.Stderr, "JSON lines, or Parquet, for analysis pipelines.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	eph, err := jpleph.NewEphemeris(fs.Arg(0), false)
+	if err != nil {
+		return err
+	}
+	defer eph.Close()
+
+	spec := jpleph.TableSpec{Start: *start, End: *end, Step: *step}
+	if spec.Start == 0 {
+		spec.Start = eph.GetEphemerisDouble(jpleph.EphemerisStartJD)
+	}
+	if spec.End == 0 {
+		spec.End = spec.Start + 365
+	}
+	for _, name := range strings.Split(*bodies, ",") {
+		p, err := parseBody(name)
+		if err != nil {
+			return err
+		}
+		spec.Bodies = append(spec.Bodies, p)
+	}
+	c, err := parseBody(*center)
+	if err != nil {
+		return err
+	}
+	spec.Center = jpleph.CenterBody(c)
+	for _, name := range strings.Split(*quantities, ",") {
+		q, ok := tableQuantities[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown quantity %q", name)
+		}
+		spec.Quantities = append(spec.Quantities, q)
+	}
+	var ok bool
+	if spec.Frame, ok = tableFrames[*frame]; !ok {
+		return fmt.Errorf("unknown frame %q", *frame)
+	}
+	if spec.Units, ok = tableUnits[*units]; !ok {
+		return fmt.Errorf("unknown units %q", *units)
+	}
+
+	names, ok := jpleph.LookupLocalization(*lang)
+	if !ok {
+		return fmt.Errorf("unknown language %q", *lang)
+	}
+
+	var write func(*jpleph.Table, io.Writer) error
+	switch *format {
+	case "csv":
+		write = (*jpleph.Table).WriteCSV
+	case "jsonl":
+		write = (*jpleph.Table).WriteJSONLines
+	case "parquet":
+		write = (*jpleph.Table).WriteParquet
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	table, err := eph.GenerateTable(spec)
+	if err != nil {
+		return err
+	}
+	table.Names = names
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			return err
+		}
+	}
+	bw := bufio.NewWriter(out)
+	err = write(table, bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if out != os.Stdout {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// parseBody parses a body given by number or by name, ignoring case, spaces, and hyphens
+// (e.g., "mars", "4", "earth-moon barycenter"); "ssb" and "emb" are also accepted.
+func parseBody(s string) (jpleph.Planet, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return jpleph.Planet(n), nil
+	}
+	key := normalizeBodyName(s)
+	switch key {
+	case "ssb":
+		return jpleph.SolarSystemBarycenter, nil
+	case "emb":
+		return jpleph.EarthMoonBarycenter, nil
+	}
+	for p := jpleph.Mercury; p <= jpleph.EarthMoonBarycenter; p++ {
+		if normalizeBodyName(p.String()) == key {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown body %q", s)
+}
+
+// normalizeBodyName lowercases a body name and removes spaces and hyphens.
+func normalizeBodyName(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.ToLower(s))
+}
@@ -0,0 +1,97 @@
+// ./cmd/jpleph/testpo.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// runTestpo implements "jpleph testpo file [testpo]".
+func runTestpo(args []string) error {
+	fs := flag.NewFlagSet("testpo", flag.ExitOnError)
+	fortran := fs.Bool("fortran", true, "evaluate in the operation order of the Fortran reader (WithFortranOrder)")
+	maxULPs := fs.Uint64("ulps", 0, "largest distance from the expected values accepted, in units of the last place")
+	tolerance := fs.Float64("tolerance", 0, "largest absolute difference accepted instead of -ulps, when positive (testeph.f uses 1e-13)")
+	verbose := fs.Bool("v", false, "print every case that fails, not only the summary")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jpleph testpo [flags] file [testpo]\n\n")
+		fmt.Fprintf(os.Stderr, "Checks an ephemeris file against a JPL testpo file (e.g., testpo.440), as\n")
+		fmt.Fprintf(os.Stderr, "testeph.f does, and reports the largest differences. Cases outside the time\n")
+		fmt.Fprintf(os.Stderr, "span of the file are skipped. Without a testpo file, the reference states\n")
+		fmt.Fprintf(os.Stderr, "embedded for the DE version of the file are used. The exit status is 1 if a\n")
+		fmt.Fprintf(os.Stderr, "case fails.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var opts []jpleph.Option
+	if *fortran {
+		opts = append(opts, jpleph.WithFortranOrder())
+	}
+	eph, err := jpleph.NewEphemeris(fs.Arg(0), false, opts...)
+	if err != nil {
+		return err
+	}
+	defer eph.Close()
+	var cases []jpleph.TestpoCase
+	if fs.NArg() == 1 {
+		cases, err = jpleph.EmbeddedGoldenCases(int(eph.GetEphemerisLong(jpleph.EphemerisVersion)))
+	} else {
+		var f *os.File
+		if f, err = os.Open(fs.Arg(1)); err != nil {
+			return err
+		}
+		cases, err = jpleph.ParseTestpo(f)
+		f.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	var checked, skipped, failed int
+	var worstULPs uint64
+	var worstDiff float64
+	for _, r := range eph.CheckTestpo(cases) {
+		if errors.Is(r.Err, jpleph.ErrOutsideRange) {
+			skipped++
+			continue
+		}
+		checked++
+		bad := r.Err != nil || r.ULPs > *maxULPs
+		if *tolerance > 0 {
+			bad = r.Err != nil || math.Abs(r.Difference) > *tolerance
+		}
+		if r.Err == nil {
+			worstULPs = max(worstULPs, r.ULPs)
+			worstDiff = math.Max(worstDiff, math.Abs(r.Difference))
+		}
+		if !bad {
+			continue
+		}
+		failed++
+		if *verbose {
+			c := r.Case
+			if r.Err != nil {
+				fmt.Printf("line %d: %s %.1f %d %d %d: %v\n", c.Line, c.Date, c.JD, c.Target, c.Center, c.Coordinate, r.Err)
+			} else {
+				fmt.Printf("line %d: %s %.1f %d %d %d: got %.17g, want %.17g (%d ULPs, %.3g)\n",
+					c.Line, c.Date, c.JD, c.Target, c.Center, c.Coordinate, r.Computed, c.Value, r.ULPs, r.Difference)
+			}
+		}
+	}
+	fmt.Printf("%d cases checked, %d skipped outside the file, %d failed; largest difference %d ULPs, %.3g\n",
+		checked, skipped, failed, worstULPs, worstDiff)
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
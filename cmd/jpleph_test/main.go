@@ -83,20 +83,23 @@ func testSpecial(eph *jpleph.Ephemeris, et float64, spec jpleph.Planet, specName
 		return
 	}
 
+	// The components of the special quantities have their own units (see jpleph.StateUnits)
 	switch spec {
 	case jpleph.Nutations:
 		fmt.Printf("  Δψ: %.5e rad\n", pos.X)
 		fmt.Printf("  Δε: %.5e rad\n", pos.Y)
-		fmt.Printf("  dΔψ/dt: %.5e rad/day\n", vel.DX)
-		fmt.Printf("  dΔε/dt: %.5e rad/day\n", vel.DY)
+		fmt.Printf("  dΔψ/dt: %.5e rad/day\n", pos.Z)
+		fmt.Printf("  dΔε/dt: %.5e rad/day\n", vel.DX)
 
 	case jpleph.Librations:
 		printResult("Libration Angles (rad)", pos)
 		printVelocityResult("Angular Rates (rad/day)", vel)
 	case jpleph.LunarMantleOmega:
-		printVelocityResult("Angular Velocity (rad/day)", vel)
+		printResult("Angular Velocity (rad/day)", pos)
+		printVelocityResult("Angular Acceleration (rad/day^2)", vel)
 	case jpleph.TT_TDB:
-		fmt.Printf("  TT-TDB: %.5e seconds\n", pos.X*86400)
+		fmt.Printf("  TT-TDB: %.5e seconds\n", pos.X)
+		fmt.Printf("  d(TT-TDB)/dt: %.5e seconds/day\n", pos.Y)
 	}
 }
 
@@ -0,0 +1,219 @@
+// ./cmd/libjpleph/main.go
+
+/*
+Command libjpleph builds the jpleph package as a C library, so C, C++, Python (ctypes), and
+other programs can link against the Go implementation instead of the original C code:
+
+	go build -buildmode=c-shared -o libjpleph.so ./cmd/libjpleph
+	go build -buildmode=c-archive -o libjpleph.a ./cmd/libjpleph
+
+Both commands also write the header (libjpleph.h) declaring the functions below. Functions
+returning int return 0 on success or a negative JPL_EPH_* error code (see jpleph_strerror).
+Handles may be used from several threads; calls on the same handle are serialized.
+
+	int64_t eph;
+	double state[6];
+	if (jpleph_open("de440.bin", 1, &eph) == 0 &&
+	    jpleph_state(eph, 2451545.0, 4, 11, 1, state) == 0)
+	        printf("%f %f %f\n", state[0], state[1], state[2]);
+	jpleph_close(eph);
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+package main
+
+/*
+#include <stdint.h>
+
+#define JPL_EPH_OUTSIDE_RANGE             (-1)
+#define JPL_EPH_READ_ERROR                (-2)
+#define JPL_EPH_QUANTITY_NOT_IN_EPHEMERIS (-3)
+#define JPL_EPH_INVALID_INDEX             (-5)
+#define JPL_EPH_FSEEK_ERROR               (-6)
+#define JPLEPH_OPEN_FAILED                (-100)
+#define JPLEPH_INVALID_HANDLE             (-101)
+#define JPLEPH_CONSTANT_NOT_FOUND         (-102)
+#define JPLEPH_ERROR                      (-103)
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// Error codes of the C API beyond the JPL_EPH_* codes of the package.
+const (
+	codeOpenFailed       = -100 // The file could not be opened as an ephemeris
+	codeInvalidHandle    = -101 // The handle is not open
+	codeConstantNotFound = -102 // No constant has the requested name
+	codeError            = -103 // Any other error
+)
+
+// handle is an open ephemeris and the lock serializing calls on it.
+type handle struct {
+	mu  sync.Mutex        // Serializes calls, since an Ephemeris is not safe for concurrent use
+	eph *jpleph.Ephemeris // The open ephemeris
+}
+
+// handles maps the handles given to C callers to open ephemerides; Go pointers cannot be
+// kept by C code.
+var (
+	handlesMu  sync.Mutex
+	handles    = map[int64]*handle{}
+	nextHandle int64
+)
+
+// lookup returns the open ephemeris of a handle, or nil.
+func lookup(h C.int64_t) *handle {
+	handlesMu.Lock()
+	defer handlesMu.Unlock()
+	return handles[int64(h)]
+}
+
+// errorCode maps an error of the package to a C API error code: the JPL_EPH_* code of
+// jpleph.ErrorCode, or a code of the C API for the other errors.
+func errorCode(err error) C.int {
+	if errors.Is(err, jpleph.ErrConstantNotFound) {
+		return codeConstantNotFound
+	}
+	if code := jpleph.ErrorCode(err); code != jpleph.JPL_EPH_OTHER_ERROR {
+		return C.int(code)
+	}
+	return codeError
+}
+
+// jpleph_open opens an ephemeris file and stores its handle in *out.
+//
+//export jpleph_open
+func jpleph_open(filename *C.char, loadConstants C.int, out *C.int64_t) C.int {
+	eph, err := jpleph.NewEphemeris(C.GoString(filename), loadConstants != 0)
+	if err != nil {
+		return codeOpenFailed
+	}
+	handlesMu.Lock()
+	defer handlesMu.Unlock()
+	nextHandle++
+	handles[nextHandle] = &handle{eph: eph}
+	*out = C.int64_t(nextHandle)
+	return 0
+}
+
+// jpleph_state computes the state of target relative to center at et (TDB Julian Date) into
+// state[0..5]: the position in AU and, if calc_velocity is nonzero, the velocity in AU/day.
+// Bodies use the numbering of the original C code (1 = Mercury ... 13 = Earth-Moon
+// Barycenter, 14 = nutations, 15 = librations, 16 = lunar mantle, 17 = TT-TDB).
+//
+//export jpleph_state
+func jpleph_state(h C.int64_t, et C.double, target, center C.int, calcVelocity C.int, state *C.double) C.int {
+	eph := lookup(h)
+	if eph == nil {
+		return codeInvalidHandle
+	}
+	eph.mu.Lock()
+	defer eph.mu.Unlock()
+	pos, vel, err := eph.eph.CalculatePV(float64(et), jpleph.Planet(target), jpleph.CenterBody(center), calcVelocity != 0)
+	if err != nil {
+		return errorCode(err)
+	}
+	out := unsafe.Slice((*float64)(unsafe.Pointer(state)), 6)
+	out[0], out[1], out[2] = pos.X, pos.Y, pos.Z
+	out[3], out[4], out[5] = vel.DX, vel.DY, vel.DZ
+	return 0
+}
+
+// jpleph_constant looks up a constant by name (e.g., "AU", "EMRAT") into *value.
+//
+//export jpleph_constant
+func jpleph_constant(h C.int64_t, name *C.char, value *C.double) C.int {
+	eph := lookup(h)
+	if eph == nil {
+		return codeInvalidHandle
+	}
+	eph.mu.Lock()
+	defer eph.mu.Unlock()
+	v, err := eph.eph.ConstantByName(C.GoString(name))
+	if err != nil {
+		return errorCode(err)
+	}
+	*value = C.double(v)
+	return 0
+}
+
+// jpleph_get_double returns a header value of the ephemeris (the JPL_EPHEM_* value types, as
+// jpl_get_double in the original C code), or 0 for an invalid handle.
+//
+//export jpleph_get_double
+func jpleph_get_double(h C.int64_t, valueType C.int) C.double {
+	eph := lookup(h)
+	if eph == nil {
+		return 0
+	}
+	eph.mu.Lock()
+	defer eph.mu.Unlock()
+	return C.double(eph.eph.GetEphemerisDouble(jpleph.ValueType(valueType)))
+}
+
+// jpleph_close closes a handle.
+//
+//export jpleph_close
+func jpleph_close(h C.int64_t) C.int {
+	handlesMu.Lock()
+	eph := handles[int64(h)]
+	delete(handles, int64(h))
+	handlesMu.Unlock()
+	if eph == nil {
+		return codeInvalidHandle
+	}
+	eph.mu.Lock()
+	defer eph.mu.Unlock()
+	return errorCode(eph.eph.Close())
+}
+
+// errorMessages holds the messages of jpleph_strerror as C strings, allocated once.
+var errorMessages = map[int]*C.char{
+	0:                                        C.CString("no error"),
+	jpleph.JPL_EPH_OUTSIDE_RANGE:             C.CString(jpleph.ErrOutsideRange.Error()),
+	jpleph.JPL_EPH_READ_ERROR:                C.CString(jpleph.ErrFileRead.Error()),
+	jpleph.JPL_EPH_QUANTITY_NOT_IN_EPHEMERIS: C.CString(jpleph.ErrQuantityNotInEphemeris.Error()),
+	jpleph.JPL_EPH_INVALID_INDEX:             C.CString(jpleph.ErrInvalidIndex.Error()),
+	jpleph.JPL_EPH_FSEEK_ERROR:               C.CString(jpleph.ErrFileSeek.Error()),
+	codeOpenFailed:                           C.CString("cannot open ephemeris file"),
+	codeInvalidHandle:                        C.CString("invalid ephemeris handle"),
+	codeConstantNotFound:                     C.CString(jpleph.ErrConstantNotFound.Error()),
+	codeError:                                C.CString("ephemeris error"),
+}
+
+// jpleph_strerror returns a static message describing an error code.
+//
+//export jpleph_strerror
+func jpleph_strerror(code C.int) *C.char {
+	if msg, ok := errorMessages[int(code)]; ok {
+		return msg
+	}
+	return errorMessages[codeError]
+}
+
+// main is required by the c-shared and c-archive build modes and does nothing.
+func main() {}
@@ -0,0 +1,141 @@
+// ./coefficients.go
+package jpleph
+
+/*
+Package jpleph provides access to the raw Chebyshev coefficients of the ephemeris records.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+)
+
+// CoefficientDump is the Chebyshev series of one quantity interpolated at an epoch, exactly
+// as the reader uses it: the record and sub-interval chosen, the normalized time, and the
+// coefficients of each component. Comparing it with the output of another reader locates
+// a disagreement in the record selection, the time normalization, or the summation.
+type CoefficientDump struct {
+	Quantity       Quantity    // Quantity interpolated
+	ET             float64     // Epoch requested (TDB Julian Date)
+	Record         uint32      // Data record covering ET (0 for the first record of the file)
+	RecordStart    float64     // First epoch of the record (Julian Date)
+	RecordEnd      float64     // Last epoch of the record (Julian Date)
+	RecordFraction float64     // Fractional time within the record (0 <= t <= 1)
+	IPT            [3]uint32   // IPT entry: 1-based start in the record, coefficients per component, sub-intervals
+	SubInterval    int         // Sub-interval covering ET (0-based)
+	IntervalStart  float64     // First epoch of the sub-interval (Julian Date)
+	IntervalEnd    float64     // Last epoch of the sub-interval (Julian Date)
+	NormalizedTime float64     // Chebyshev argument within the sub-interval (-1 <= tc <= 1)
+	Coefficients   [][]float64 // Coefficients of each component, lowest order first, in the units of the file
+	Values         []float64   // Components evaluated at ET from Coefficients, in the units of the file
+	Units          string      // Units of Coefficients and Values (e.g., "km", "rad")
+}
+
+// quantityUnits gives the units in which a quantity is stored in the kernel.
+func quantityUnits(q Quantity) string {
+	switch q {
+	case QuantityNutations, QuantityLibrations:
+		return "rad"
+	case QuantityLunarMantleOmega:
+		return "rad/day"
+	case QuantityTTmTDB:
+		return "s"
+	}
+	return "km"
+}
+
+// Coefficients returns the raw Chebyshev coefficients of a quantity at an epoch, with the
+// record, sub-interval, and normalized time that CalculatePV uses for it. Bodies that are
+// not stored directly (the Earth, the Solar System Barycenter) have no series of their own:
+// dump the quantities they are formed from, such as QuantityEarthMoonBarycenter and
+// QuantityMoon.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - q: Quantity to dump (e.g., jpleph.QuantityMoon).
+//
+// Returns:
+//   - CoefficientDump: The series and the interpolation parameters.
+//   - error: ErrInvalidIndex for an unknown quantity, ErrQuantityNotInEphemeris,
+//     ErrOutsideRange, or a file access error.
+func (e *Ephemeris) Coefficients(et float64, q Quantity) (CoefficientDump, error) {
+	start, ncf, na, err := e.IPT(q)
+	if err != nil {
+		return CoefficientDump{}, err
+	}
+	e.applyReload()
+	ephem := e.ephemData
+	nr, frac, err := recordLocation(ephem, et)
+	if err != nil {
+		return CoefficientDump{}, err
+	}
+	if err := loadRecord(ephem, nr); err != nil {
+		return CoefficientDump{}, err
+	}
+
+	// Sub-interval and normalized time, as in interp()
+	intPart, fracPart := math.Modf(float64(na) * frac)
+	l := int(intPart)
+	tc := 2*fracPart - 1
+	if l == int(na) {
+		l--
+		tc = 1
+	}
+	recStart := ephem.ephemStart + float64(nr)*ephem.ephemStep
+	sub := ephem.ephemStep / float64(na)
+	d := CoefficientDump{
+		Quantity:       q,
+		ET:             et,
+		Record:         nr,
+		RecordStart:    recStart,
+		RecordEnd:      recStart + ephem.ephemStep,
+		RecordFraction: frac,
+		IPT:            [3]uint32{start, ncf, na},
+		SubInterval:    l,
+		IntervalStart:  recStart + float64(l)*sub,
+		IntervalEnd:    recStart + float64(l+1)*sub,
+		NormalizedTime: tc,
+		Units:          quantityUnits(q),
+	}
+
+	dim := quantityDimension(int(q))
+	n := int(ncf)
+	base := int(start) - 1 + n*dim*l
+	for k := 0; k < dim; k++ {
+		c := append([]float64(nil), ephem.cache[base+n*k:base+n*(k+1)]...)
+		d.Coefficients = append(d.Coefficients, c)
+		sum, tPrev, tCur := 0.0, 1.0, tc // T_0 and T_1
+		for j, cj := range c {
+			switch j {
+			case 0:
+				sum += cj
+			case 1:
+				sum += cj * tc
+			default:
+				tPrev, tCur = tCur, 2*tc*tCur-tPrev
+				sum += cj * tCur
+			}
+		}
+		d.Values = append(d.Values, sum)
+	}
+	return d, nil
+}
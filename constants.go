@@ -47,7 +47,8 @@ const (
 	JPL_EPHEM_KERNEL_SWAP_BYTES  = 240 // Flag indicating if byte swapping is needed (non-zero if yes)
 )
 
-// Error codes returned by State() and Pleph() functions.
+// Error codes returned by State() and Pleph() functions in the C version. ErrorCode maps the
+// errors of the Go functions to them.
 const (
 	JPL_EPH_OUTSIDE_RANGE             = -1 // Requested Julian Date is outside the ephemeris time range
 	JPL_EPH_READ_ERROR                = -2 // Error occurred during file read operation
@@ -56,7 +57,8 @@ const (
 	JPL_EPH_FSEEK_ERROR               = -6 // Error occurred during file seek operation
 )
 
-// Error codes returned by InitErrorCode() after calling InitEphemeris().
+// Error codes of the initialization in the C version, returned by InitErrorCode for the
+// errors of NewEphemeris and the other constructors.
 const (
 	JPL_INIT_NO_ERROR       = 0   // No error during initialization
 	JPL_INIT_FILE_NOT_FOUND = -1  // Ephemeris file not found at the specified path
@@ -0,0 +1,290 @@
+// ./cr3bp.go
+package jpleph
+
+/*
+Package jpleph provides the circular restricted three-body model of the Earth-Moon system, for seeding halo orbits.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// CR3BPSystem is the Earth-Moon system at an epoch in the normalized units and rotating
+// frame of the circular restricted three-body problem (CR3BP): the origin at the Earth-Moon
+// barycenter, x from the Earth to the Moon, z along the orbital angular momentum of the
+// Moon, the Earth at (-Mu, 0, 0) and the Moon at (1-Mu, 0, 0). The unit of length is the
+// Earth-Moon distance at the epoch and the unit of time makes G(M_Earth + M_Moon) = 1.
+type CR3BPSystem struct {
+	ET     float64 // Epoch (TDB Julian Date)
+	Mu     float64 // Mass ratio M_Moon / (M_Earth + M_Moon), from EMRAT
+	Length float64 // Unit of length: the Earth-Moon distance at ET, in km
+	Time   float64 // Unit of time in days, sqrt(Length^3 / G(M_Earth + M_Moon)); 2*pi units are about 27.3 days at the mean distance
+
+	rotation  linalg.Mat3 // From the ICRF to the rotating axes
+	rate      float64     // Angular velocity of the rotating axes at ET in rad/day
+	lengthDot float64     // Rate of change of Length in km/day
+	moon      linalg.Vec3 // Geocentric position of the Moon in km
+	moonVel   linalg.Vec3 // Geocentric velocity of the Moon in km/day
+	au        float64     // Kilometers per AU of the file
+}
+
+// EarthMoonCR3BP returns the CR3BP model of the Earth-Moon system at an epoch, for seeding
+// halo and other libration-point orbits that are then refined in the ephemeris model. The
+// rotating frame follows the geometric geocentric state of the Moon at the epoch: its axes
+// turn with the instantaneous angular velocity of the Moon and its length unit pulsates with
+// the Earth-Moon distance, so that the libration points are fixed in it.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - CR3BPSystem: The model at et.
+//   - error: An error from CalculatePV, or ErrConstantNotFound without the GMB constant.
+func (e *Ephemeris) EarthMoonCR3BP(et float64) (CR3BPSystem, error) {
+	pos, vel, err := e.CalculatePV(et, Moon, CenterEarth, true)
+	if err != nil {
+		return CR3BPSystem{}, err
+	}
+	gm, err := e.bodyGM(EarthMoonBarycenter)
+	if err != nil {
+		return CR3BPSystem{}, err
+	}
+	au := e.ephemData.au
+	r := linalg.Vec3{pos.X, pos.Y, pos.Z}.Scale(au)
+	v := linalg.Vec3{vel.DX, vel.DY, vel.DZ}.Scale(au)
+	h := r.Cross(v)
+	x, z := r.Unit(), h.Unit()
+	y := z.Cross(x)
+	d := r.Norm()
+	gmKM := gm * au * au * au // km^3/day^2
+	return CR3BPSystem{
+		ET:        et,
+		Mu:        1 / (1 + e.ephemData.emrat),
+		Length:    d,
+		Time:      math.Sqrt(d * d * d / gmKM),
+		rotation:  linalg.Mat3{x, y, z},
+		rate:      h.Norm() / (d * d),
+		lengthDot: r.Dot(v) / d,
+		moon:      r,
+		moonVel:   v,
+		au:        au,
+	}, nil
+}
+
+// FromICRF converts a geocentric ICRF state to the normalized rotating frame.
+//
+// Parameters:
+//   - s: State relative to the Earth in the ICRF, in AU and AU/day (as from CalculatePV
+//     with CenterEarth); its ET is not used.
+//
+// Returns:
+//   - [6]float64: Position and velocity in the units of the system.
+func (c CR3BPSystem) FromICRF(s StateVector) [6]float64 {
+	p, v := stateVectors(s)
+	rho := p.Scale(c.au).Sub(c.moon.Scale(c.Mu)) // Relative to the barycenter, km
+	rhoDot := v.Scale(c.au).Sub(c.moonVel.Scale(c.Mu))
+	q := c.rotation.Apply(rho)
+	qDot := c.rotation.Apply(rhoDot).Sub(linalg.Vec3{-c.rate * q[1], c.rate * q[0], 0}) // Seen in the rotating axes
+	var x [6]float64
+	for i := 0; i < 3; i++ {
+		x[i] = q[i] / c.Length
+		x[3+i] = (qDot[i] - c.lengthDot*x[i]) * c.Time / c.Length // The unit of length pulsates
+	}
+	return x
+}
+
+// ToICRF converts a state of the normalized rotating frame to a geocentric ICRF state.
+//
+// Parameters:
+//   - x: Position and velocity in the units of the system.
+//
+// Returns:
+//   - StateVector: The state relative to the Earth at the epoch of the system, in AU and AU/day.
+func (c CR3BPSystem) ToICRF(x [6]float64) StateVector {
+	var q, qDot linalg.Vec3
+	for i := 0; i < 3; i++ {
+		q[i] = x[i] * c.Length
+		qDot[i] = x[3+i]*c.Length/c.Time + c.lengthDot*x[i]
+	}
+	qDot = qDot.Add(linalg.Vec3{-c.rate * q[1], c.rate * q[0], 0})
+	back := c.rotation.Transpose()
+	p := back.Apply(q).Add(c.moon.Scale(c.Mu)).Scale(1 / c.au)
+	v := back.Apply(qDot).Add(c.moonVel.Scale(c.Mu)).Scale(1 / c.au)
+	return StateVector{ET: c.ET, Position: Position{X: p[0], Y: p[1], Z: p[2]}, Velocity: Velocity{DX: v[0], DY: v[1], DZ: v[2]}}
+}
+
+// collinearDistance returns the distance gamma from the smaller primary to the collinear
+// libration point L1, L2, or L3 (from the larger primary for L3), in units of the distance
+// of the primaries, by Newton's method on the quintic of Szebehely.
+func collinearDistance(mu float64, point int) float64 {
+	var f func(g float64) (float64, float64)
+	g := math.Cbrt(mu / 3) // Hill's approximation for L1 and L2
+	switch point {
+	case 1:
+		f = func(g float64) (float64, float64) {
+			return ((((g-(3-mu))*g+(3-2*mu))*g-mu)*g+2*mu)*g - mu,
+				(((5*g-4*(3-mu))*g+3*(3-2*mu))*g-2*mu)*g + 2*mu
+		}
+	case 2:
+		f = func(g float64) (float64, float64) {
+			return ((((g+(3-mu))*g+(3-2*mu))*g-mu)*g-2*mu)*g - mu,
+				(((5*g+4*(3-mu))*g+3*(3-2*mu))*g-2*mu)*g - 2*mu
+		}
+	default:
+		g = 1 - 7*mu/12
+		f = func(g float64) (float64, float64) {
+			return ((((g+(2+mu))*g+(1+2*mu))*g-(1-mu))*g-2*(1-mu))*g - (1 - mu),
+				(((5*g+4*(2+mu))*g+3*(1+2*mu))*g-2*(1-mu))*g - 2*(1-mu)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		v, d := f(g)
+		step := v / d
+		g -= step
+		if math.Abs(step) < 1e-15 {
+			break
+		}
+	}
+	return g
+}
+
+// CR3BPLagrangePoints returns the positions of the five libration points L1 to L5 in the
+// normalized rotating frame of a CR3BP with mass ratio mu (see CR3BPSystem).
+//
+// Parameters:
+//   - mu: Mass ratio of the smaller primary, 0 < mu <= 0.5.
+//
+// Returns:
+//   - [5][3]float64: L1 to L5; the collinear points are on the x axis, L4 leads the smaller
+//     primary and L5 trails it.
+func CR3BPLagrangePoints(mu float64) [5][3]float64 {
+	return [5][3]float64{
+		{1 - mu - collinearDistance(mu, 1), 0, 0},
+		{1 - mu + collinearDistance(mu, 2), 0, 0},
+		{-mu - collinearDistance(mu, 3), 0, 0},
+		{0.5 - mu, math.Sqrt(3) / 2, 0},
+		{0.5 - mu, -math.Sqrt(3) / 2, 0},
+	}
+}
+
+// HaloSeed returns an initial state of a halo orbit about L1 or L2 from the third-order
+// approximation of Richardson (1980), in the normalized rotating frame of a CR3BP. The state
+// is only a seed: it should be refined, for example by differential correction, to a
+// periodic orbit of the CR3BP and then of the ephemeris model (through ToICRF). The
+// approximation degrades for vertical amplitudes above about a third of the distance from
+// the point to the Moon.
+//
+// Parameters:
+//   - mu: Mass ratio of the smaller primary (CR3BPSystem.Mu).
+//   - point: 1 for L1 or 2 for L2.
+//   - az: Vertical amplitude in units of the system (e.g., km / CR3BPSystem.Length).
+//   - northern: Whether the orbit is of the northern family (maximum excursion toward +z
+//     at the far side of the point from the Moon), else of the southern one.
+//   - phase: Phase along the orbit in radians; 0 is the crossing of the xz plane on the side
+//     of the point farthest from the Moon.
+//
+// Returns:
+//   - [6]float64: Position and velocity in the units of the system.
+//   - float64: Approximate period in the units of time of the system.
+//   - error: ErrCR3BP for an invalid mass ratio, point, or amplitude.
+func HaloSeed(mu float64, point int, az float64, northern bool, phase float64) ([6]float64, float64, error) {
+	if !(mu > 0 && mu <= 0.5) || (point != 1 && point != 2) || !(az >= 0) || math.IsInf(az, 0) || math.IsNaN(phase) {
+		return [6]float64{}, 0, fmt.Errorf("%w: mu %g, L%d, Az %g, phase %g", ErrCR3BP, mu, point, az, phase)
+	}
+	gamma := collinearDistance(mu, point)
+	c := func(n int) float64 { // Legendre coefficients of the potential about the point
+		sign := 1.0
+		if n%2 == 1 {
+			sign = -1
+		}
+		if point == 1 {
+			return (mu + sign*(1-mu)*math.Pow(gamma/(1-gamma), float64(n+1))) / (gamma * gamma * gamma)
+		}
+		return (sign*mu + sign*(1-mu)*math.Pow(gamma/(1+gamma), float64(n+1))) / (gamma * gamma * gamma)
+	}
+	c2, c3, c4 := c(2), c(3), c(4)
+	lambda := math.Sqrt((2 - c2 + math.Sqrt((c2-2)*(c2-2)+4*(c2-1)*(1+2*c2))) / 2) // In-plane frequency
+	l2 := lambda * lambda
+	k := (l2 + 1 + 2*c2) / (2 * lambda)
+	k2 := k * k
+
+	d1 := 3 * l2 / k * (k*(6*l2-1) - 2*lambda)
+	d2 := 8 * l2 / k * (k*(11*l2-1) - 2*lambda)
+	a21 := 3 * c3 * (k2 - 2) / (4 * (1 + 2*c2))
+	a22 := 3 * c3 / (4 * (1 + 2*c2))
+	a23 := -3 * c3 * lambda / (4 * k * d1) * (3*k2*k*lambda - 6*k*(k-lambda) + 4)
+	a24 := -3 * c3 * lambda / (4 * k * d1) * (2 + 3*k*lambda)
+	b21 := -3 * c3 * lambda / (2 * d1) * (3*k*lambda - 4)
+	b22 := 3 * c3 * lambda / d1
+	d21 := -c3 / (2 * l2)
+	a31 := -9*lambda/(4*d2)*(4*c3*(k*a23-b21)+k*c4*(4+k2)) +
+		(9*l2+1-c2)/(2*d2)*(3*c3*(2*a23-k*b21)+c4*(2+3*k2))
+	a32 := -1 / d2 * (9*lambda/4*(4*c3*(k*a24-b22)+k*c4) + 1.5*(9*l2+1-c2)*(c3*(k*b22+d21-2*a24)-c4))
+	b31 := 3 / (8 * d2) * (8*lambda*(3*c3*(k*b21-2*a23)-c4*(2+3*k2)) + (9*l2+1+2*c2)*(4*c3*(k*a23-b21)+k*c4*(4+k2)))
+	b32 := 1 / d2 * (9*lambda*(c3*(k*b22+d21-2*a24)-c4) + 3.0/8*(9*l2+1+2*c2)*(4*c3*(k*a24-b22)+k*c4))
+	d31 := 3 / (64 * l2) * (4*c3*a24 + c4)
+	d32 := 3 / (64 * l2) * (4*c3*(a23-d21) + c4*(4+k2))
+	s := 1 / (2 * lambda * (lambda*(1+k2) - 2*k))
+	s1 := s * (1.5*c3*(2*a21*(k2-2)-a23*(k2+2)-2*k*b21) - 3.0/8*c4*(3*k2*k2-8*k2+8))
+	s2 := s * (1.5*c3*(2*a22*(k2-2)+a24*(k2+2)+2*k*b22+5*d21) + 3.0/8*c4*(12-k2))
+	a1 := -1.5*c3*(2*a21+a23+5*d21) - 3.0/8*c4*(12-k2)
+	a2 := 1.5*c3*(a24-2*a22) + 9.0/8*c4
+	l1c, l2c := a1+2*l2*s1, a2+2*l2*s2
+	delta := l2 - c2
+
+	Az := az / gamma // In units of the distance from the point to the Moon
+	ax2 := -(l2c*Az*Az + delta) / l1c
+	if !(ax2 >= 0) {
+		return [6]float64{}, 0, fmt.Errorf("%w: no halo orbit of Az %g about L%d", ErrCR3BP, az, point)
+	}
+	Ax := math.Sqrt(ax2)
+	omega := 1 + s1*ax2 + s2*Az*Az // Frequency correction
+	dm := -1.0                     // Richardson's 2 - m, with m = 3 for the southern family
+	if northern {
+		dm = 1
+	}
+
+	t := phase
+	c1, sn1 := math.Cos(t), math.Sin(t)
+	cs2, sn2 := math.Cos(2*t), math.Sin(2*t)
+	cs3, sn3 := math.Cos(3*t), math.Sin(3*t)
+	x := a21*ax2 + a22*Az*Az - Ax*c1 + (a23*ax2-a24*Az*Az)*cs2 + (a31*ax2*Ax-a32*Ax*Az*Az)*cs3
+	y := k*Ax*sn1 + (b21*ax2-b22*Az*Az)*sn2 + (b31*ax2*Ax-b32*Ax*Az*Az)*sn3
+	z := dm*Az*c1 + dm*d21*Ax*Az*(cs2-3) + dm*(d32*Az*ax2-d31*Az*Az*Az)*cs3
+	f := lambda * omega // d(phase)/dt
+	vx := f * (Ax*sn1 - 2*(a23*ax2-a24*Az*Az)*sn2 - 3*(a31*ax2*Ax-a32*Ax*Az*Az)*sn3)
+	vy := f * (k*Ax*c1 + 2*(b21*ax2-b22*Az*Az)*cs2 + 3*(b31*ax2*Ax-b32*Ax*Az*Az)*cs3)
+	vz := f * (-dm*Az*sn1 - 2*dm*d21*Ax*Az*sn2 - 3*dm*(d32*Az*ax2-d31*Az*Az*Az)*sn3)
+
+	// From Richardson's frame, centered on the point with gamma as the unit of length, to the
+	// barycentric one
+	center := 1 - mu - gamma
+	if point == 2 {
+		center = 1 - mu + gamma
+	}
+	state := [6]float64{center + gamma*x, gamma * y, gamma * z, gamma * vx, gamma * vy, gamma * vz}
+	return state, 2 * math.Pi / f, nil
+}
@@ -0,0 +1,349 @@
+// ./custom_bodies.go
+package jpleph
+
+/*
+Package jpleph provides support for user-defined bodies stored as Chebyshev records.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// Custom body file structure notes:
+//
+// A custom body file is a small auxiliary kernel holding user trajectories in the
+// same Chebyshev form as the JPL data records. All values are little-endian.
+//
+// Bytes 0-7:   Magic string "JPLCUST1" (8 bytes)
+// Bytes 8-11:  Number of bodies in the file (uint32)
+//
+// Each body then follows:
+//
+//	id (int32), center (int32), name (32 bytes, NUL padded),
+//	ncoeff (uint32), number of segments (uint32),
+//	and for each segment: start JD (float64), end JD (float64),
+//	then 3*ncoeff coefficients (x block, y block, z block) in AU.
+
+// customBodyMagic identifies a custom body file.
+const customBodyMagic = "JPLCUST1"
+
+// FirstCustomBody is the lowest Planet identifier available for user-defined bodies.
+// Identifiers below this value are reserved for the bodies and quantities of the JPL kernel.
+const FirstCustomBody Planet = 1000
+
+// customSegment holds the Chebyshev coefficients of a user trajectory over one time span.
+type customSegment struct {
+	start  float64   // start is the first Julian Ephemeris Date covered by the segment.
+	end    float64   // end is the last Julian Ephemeris Date covered by the segment.
+	coeffs []float64 // coeffs holds ncoeff coefficients for x, then y, then z, in AU.
+}
+
+// CustomBody is a user-defined trajectory stored as piecewise Chebyshev polynomials.
+// Positions are stored in AU relative to Center, so the body can be addressed through
+// CalculatePV like any body of the JPL kernel once added to an Ephemeris.
+type CustomBody struct {
+	ID       Planet            // ID is the identifier used in CalculatePV (FirstCustomBody or above).
+	Name     string            // Name is a human-readable label (at most 32 bytes are stored).
+	Center   CenterBody        // Center is the kernel body the trajectory is relative to.
+	NCoeff   int               // NCoeff is the number of Chebyshev coefficients per component.
+	segments []customSegment   // segments are sorted by start time and do not overlap.
+	iinfo    interpolationInfo // iinfo caches Chebyshev polynomial values between calls.
+}
+
+// FitCustomBody builds a CustomBody by fitting a user trajectory with Chebyshev polynomials.
+// The span [start, end] is split into segments of segmentDays, and each segment is fitted
+// with ncoeff coefficients per component. Velocities are obtained from the derivative of
+// the fitted position, exactly as for the bodies of the JPL kernel.
+//
+// Parameters:
+//   - id: Identifier for the body (FirstCustomBody or above).
+//   - name: Human-readable name of the body.
+//   - center: Kernel body the positions returned by fn are relative to.
+//   - start: First Julian Ephemeris Date to cover.
+//   - end: Last Julian Ephemeris Date to cover.
+//   - segmentDays: Length of each fitted segment in days.
+//   - ncoeff: Number of Chebyshev coefficients per component (1 to 17).
+//   - fn: Function returning the position of the body relative to center, in AU.
+//
+// Returns:
+//   - *CustomBody: The fitted body on success.
+//   - error: ErrCustomBody or ErrInvalidFit if the parameters are invalid, or the error returned by fn.
+func FitCustomBody(id Planet, name string, center CenterBody, start, end, segmentDays float64, ncoeff int,
+	fn func(et float64) (Position, error)) (*CustomBody, error) {
+	body := &CustomBody{ID: id, Name: name, Center: center, NCoeff: ncoeff}
+	if err := body.validateHeader(); err != nil {
+		return nil, err
+	}
+	if !(end > start) || !(segmentDays > 0) {
+		return nil, fmt.Errorf("%w: invalid span %f to %f with segment length %f", ErrInvalidFit, start, end, segmentDays)
+	}
+
+	sample := func(et float64) ([3]float64, error) {
+		pos, err := fn(et)
+		return [3]float64{pos.X, pos.Y, pos.Z}, err
+	}
+	nSegments := int(math.Ceil((end - start) / segmentDays))
+	for i := 0; i < nSegments; i++ {
+		segStart := start + float64(i)*segmentDays
+		segEnd := math.Min(segStart+segmentDays, end)
+		coeffs, err := fitChebyshevVector(sample, segStart, segEnd, ncoeff)
+		if err != nil {
+			return nil, fmt.Errorf("fitting custom body %d segment %d failed: %w", id, i, err)
+		}
+		body.segments = append(body.segments, customSegment{start: segStart, end: segEnd, coeffs: coeffs})
+	}
+	body.iinfo.reset()
+	return body, nil
+}
+
+// Span returns the first and last Julian Ephemeris Dates covered by the body.
+func (b *CustomBody) Span() (float64, float64) {
+	if len(b.segments) == 0 {
+		return 0, 0
+	}
+	return b.segments[0].start, b.segments[len(b.segments)-1].end
+}
+
+// State interpolates the position and optionally velocity of the body relative to its Center.
+//
+// Parameters:
+//   - et: Julian Ephemeris Date at which to interpolate.
+//   - calcVelocity: Flag to indicate whether to calculate velocities.
+//
+// Returns:
+//   - Position: Position relative to Center, in AU.
+//   - Velocity: Velocity relative to Center, in AU/day (zero if calcVelocity is false).
+//   - error: ErrOutsideRange if et is not covered by any segment.
+func (b *CustomBody) State(et float64, calcVelocity bool) (Position, Velocity, error) {
+	seg, err := b.segmentAt(et)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+
+	velocityFlag := 1
+	if calcVelocity {
+		velocityFlag = 2
+	}
+	var posvel [6]float64
+	t := [2]float64{(et - seg.start) / (seg.end - seg.start), seg.end - seg.start} // Fraction of segment, segment length
+	interp(&b.iinfo, seg.coeffs, t, uint(b.NCoeff), 3, 1, velocityFlag, posvel[:])
+
+	pos := Position{X: posvel[0], Y: posvel[1], Z: posvel[2]}
+	vel := Velocity{}
+	if calcVelocity {
+		vel = Velocity{DX: posvel[3], DY: posvel[4], DZ: posvel[5]}
+	}
+	return pos, vel, nil
+}
+
+// segmentAt returns the segment covering et.
+func (b *CustomBody) segmentAt(et float64) (*customSegment, error) {
+	idx := sort.Search(len(b.segments), func(i int) bool { return b.segments[i].end >= et })
+	if idx == len(b.segments) || et < b.segments[idx].start {
+		return nil, fmt.Errorf("custom body %d at JD %f: %w", b.ID, et, ErrOutsideRange)
+	}
+	return &b.segments[idx], nil
+}
+
+// validateHeader checks the identifier, center, and coefficient count of the body.
+func (b *CustomBody) validateHeader() error {
+	if b.ID < FirstCustomBody {
+		return fmt.Errorf("%w: id %d is below FirstCustomBody (%d)", ErrCustomBody, b.ID, FirstCustomBody)
+	}
+	if b.Center < CenterMercury || b.Center > CenterEarthMoonBarycenter {
+		return fmt.Errorf("%w: center %d is not a kernel body", ErrCustomBody, b.Center)
+	}
+	if b.NCoeff < 1 || b.NCoeff >= maxCheby {
+		return fmt.Errorf("%w: ncoeff %d must be between 1 and %d", ErrCustomBody, b.NCoeff, maxCheby-1)
+	}
+	return nil
+}
+
+// AddCustomBody registers a user-defined body so it can be used as a target or center in CalculatePV.
+//
+// Parameters:
+//   - body: The body to register. Its ID must not already be registered.
+//
+// Returns:
+//   - error: ErrCustomBody if the body is invalid or its ID is already in use.
+func (e *Ephemeris) AddCustomBody(body *CustomBody) error {
+	if err := body.validateHeader(); err != nil {
+		return err
+	}
+	if _, exists := e.customBodies[body.ID]; exists {
+		return fmt.Errorf("%w: id %d is already registered", ErrCustomBody, body.ID)
+	}
+	if e.customBodies == nil {
+		e.customBodies = make(map[Planet]*CustomBody)
+	}
+	e.customBodies[body.ID] = body
+	return nil
+}
+
+// LoadCustomBodies reads a custom body file and registers every body it contains. Every body
+// is checked before any is registered, so a failed load leaves the Ephemeris unchanged.
+//
+// Parameters:
+//   - filename: Path to a file written by WriteCustomBodies.
+//
+// Returns:
+//   - error: An error if the file cannot be read, decoded, or a body cannot be registered.
+func (e *Ephemeris) LoadCustomBodies(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open custom body file: %w", err)
+	}
+	defer f.Close()
+
+	bodies, err := ReadCustomBodies(f)
+	if err != nil {
+		return err
+	}
+	seen := make(map[Planet]bool, len(bodies))
+	for _, body := range bodies {
+		if err := body.validateHeader(); err != nil {
+			return err
+		}
+		if _, exists := e.customBodies[body.ID]; exists || seen[body.ID] {
+			return fmt.Errorf("%w: id %d is already registered", ErrCustomBody, body.ID)
+		}
+		seen[body.ID] = true
+	}
+	for _, body := range bodies {
+		if err := e.AddCustomBody(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCustom reports whether id refers to a registered custom body.
+func (e *Ephemeris) isCustom(id Planet) bool {
+	_, ok := e.customBodies[id]
+	return ok
+}
+
+// WriteCustomBodies encodes bodies in the custom body file format.
+//
+// Parameters:
+//   - w: Destination writer.
+//   - bodies: Bodies to encode.
+//
+// Returns:
+//   - error: ErrCustomBody if a body is invalid, or the error returned by w.
+func WriteCustomBodies(w io.Writer, bodies []*CustomBody) error {
+	var buf bytes.Buffer
+	buf.WriteString(customBodyMagic)
+	binary.Write(&buf, defaultByteOrder, uint32(len(bodies)))
+	for _, body := range bodies {
+		if err := body.validateHeader(); err != nil {
+			return err
+		}
+		var name [32]byte
+		copy(name[:], body.Name)
+		binary.Write(&buf, defaultByteOrder, int32(body.ID))
+		binary.Write(&buf, defaultByteOrder, int32(body.Center))
+		buf.Write(name[:])
+		binary.Write(&buf, defaultByteOrder, uint32(body.NCoeff))
+		binary.Write(&buf, defaultByteOrder, uint32(len(body.segments)))
+		for _, seg := range body.segments {
+			binary.Write(&buf, defaultByteOrder, seg.start)
+			binary.Write(&buf, defaultByteOrder, seg.end)
+			binary.Write(&buf, defaultByteOrder, seg.coeffs)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadCustomBodies decodes bodies written by WriteCustomBodies.
+//
+// Parameters:
+//   - r: Source reader.
+//
+// Returns:
+//   - []*CustomBody: The decoded bodies.
+//   - error: ErrCustomBody if the data is not a valid custom body file.
+func ReadCustomBodies(r io.Reader) ([]*CustomBody, error) {
+	magic := make([]byte, len(customBodyMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != customBodyMagic {
+		return nil, fmt.Errorf("%w: missing %q header", ErrCustomBody, customBodyMagic)
+	}
+	var count uint32
+	if err := binary.Read(r, defaultByteOrder, &count); err != nil {
+		return nil, fmt.Errorf("%w: reading body count: %v", ErrCustomBody, err)
+	}
+
+	var bodies []*CustomBody // Grown as the bodies are read: count comes from the file and may be anything
+	for i := uint32(0); i < count; i++ {
+		var header struct {
+			ID        int32
+			Center    int32
+			Name      [32]byte
+			NCoeff    uint32
+			NSegments uint32
+		}
+		if err := binary.Read(r, defaultByteOrder, &header); err != nil {
+			return nil, fmt.Errorf("%w: reading body %d header: %v", ErrCustomBody, i, err)
+		}
+		body := &CustomBody{
+			ID:     Planet(header.ID),
+			Name:   string(bytes.TrimRight(header.Name[:], "\x00")),
+			Center: CenterBody(header.Center),
+			NCoeff: int(header.NCoeff),
+		}
+		if err := body.validateHeader(); err != nil {
+			return nil, err
+		}
+		for s := uint32(0); s < header.NSegments; s++ {
+			seg := customSegment{coeffs: make([]float64, 3*body.NCoeff)}
+			err := binary.Read(r, defaultByteOrder, &seg.start)
+			if err == nil {
+				err = binary.Read(r, defaultByteOrder, &seg.end)
+			}
+			if err == nil {
+				err = binary.Read(r, defaultByteOrder, seg.coeffs)
+			}
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, fmt.Errorf("%w: reading body %d segment %d: %v", ErrCustomBody, body.ID, s, err)
+			}
+			if !(seg.end > seg.start) || (s > 0 && seg.start < body.segments[s-1].end) {
+				return nil, fmt.Errorf("%w: body %d segment %d has an invalid time span", ErrCustomBody, body.ID, s)
+			}
+			body.segments = append(body.segments, seg)
+		}
+		body.iinfo.reset()
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
@@ -0,0 +1,127 @@
+// ./custom_bodies_test.go
+package jpleph_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// TestReadCustomBodiesRoundTrip checks that WriteCustomBodies and ReadCustomBodies preserve the
+// states of a body.
+func TestReadCustomBodiesRoundTrip(t *testing.T) {
+	fn := func(et float64) (jpleph.Position, error) {
+		return jpleph.Position{X: 1e-3 * et, Y: 2e-3, Z: -1e-3}, nil
+	}
+	body, err := jpleph.FitCustomBody(jpleph.FirstCustomBody, "test", jpleph.CenterEarth, 2451545, 2451565, 5, 8, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := jpleph.WriteCustomBodies(&buf, []*jpleph.CustomBody{body}); err != nil {
+		t.Fatal(err)
+	}
+	bodies, err := jpleph.ReadCustomBodies(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 1 || bodies[0].ID != body.ID || bodies[0].Name != body.Name {
+		t.Fatalf("read %+v, want one body like %+v", bodies, body)
+	}
+	for et := 2451545.0; et <= 2451565; et += 0.7 {
+		p, _, err1 := body.State(et, false)
+		q, _, err2 := bodies[0].State(et, false)
+		if err1 != nil || err2 != nil || p != q {
+			t.Fatalf("state at %v: read %v (%v), want %v (%v)", et, q, err2, p, err1)
+		}
+	}
+}
+
+// TestReadCustomBodiesMalformed checks that malformed files fail with ErrCustomBody, including
+// a body count far beyond the data, which must not be allocated up front.
+func TestReadCustomBodiesMalformed(t *testing.T) {
+	var valid bytes.Buffer
+	if err := jpleph.WriteCustomBodies(&valid, nil); err != nil {
+		t.Fatal(err)
+	}
+	magic := valid.Bytes()[:valid.Len()-4]
+	withCount := func(count uint32) []byte {
+		b := append([]byte(nil), magic...)
+		return binary.LittleEndian.AppendUint32(b, count)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", []byte("not a custom body file")},
+		{"no count", magic},
+		{"huge count", withCount(0xFFFFFFFF)},
+		{"truncated body", append(withCount(1), 1, 2, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := jpleph.ReadCustomBodies(bytes.NewReader(tt.data)); !errors.Is(err, jpleph.ErrCustomBody) {
+				t.Errorf("ReadCustomBodies: %v, want ErrCustomBody", err)
+			}
+		})
+	}
+}
+
+// TestLoadCustomBodiesAtomic checks that a load that fails on a later body registers none of
+// the bodies before it.
+func TestLoadCustomBodiesAtomic(t *testing.T) {
+	fn := func(et float64) (jpleph.Position, error) {
+		return jpleph.Position{X: 1e-3, Y: 2e-3, Z: -1e-3}, nil
+	}
+	fit := func(id jpleph.Planet) *jpleph.CustomBody {
+		body, err := jpleph.FitCustomBody(id, "test", jpleph.CenterEarth, 2451545, 2451565, 5, 8, fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	first, second := jpleph.FirstCustomBody, jpleph.FirstCustomBody+1
+	tests := []struct {
+		name       string
+		registered []jpleph.Planet
+		file       []jpleph.Planet
+	}{
+		{"clash with a registered body", []jpleph.Planet{second}, []jpleph.Planet{first, second}},
+		{"duplicate in the file", nil, []jpleph.Planet{first, first}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := openSynthetic(t, jplephtest.DefaultConfig())
+			for _, id := range tt.registered {
+				if err := e.AddCustomBody(fit(id)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var bodies []*jpleph.CustomBody
+			for _, id := range tt.file {
+				bodies = append(bodies, fit(id))
+			}
+			var buf bytes.Buffer
+			if err := jpleph.WriteCustomBodies(&buf, bodies); err != nil {
+				t.Fatal(err)
+			}
+			name := filepath.Join(t.TempDir(), "bodies.bin")
+			if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.LoadCustomBodies(name); !errors.Is(err, jpleph.ErrCustomBody) {
+				t.Fatalf("LoadCustomBodies: %v, want ErrCustomBody", err)
+			}
+			if _, _, err := e.CalculatePV(2451550, first, jpleph.CenterEarth, false); err == nil {
+				t.Errorf("body %d is registered after the failed load", first)
+			}
+		})
+	}
+}
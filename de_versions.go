@@ -0,0 +1,233 @@
+// ./de_versions.go
+package jpleph
+
+/*
+Package jpleph provides the published properties of the JPL DE releases.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DEVersion is the published description of one JPL DE release, as distributed by JPL in its
+// binary and ASCII forms. Zero fields are not recorded in the table and are not checked.
+type DEVersion struct {
+	Name       string     // Release name (e.g., "DE440" or "DE440t")
+	Number     int        // DE number, as stored in the header (e.g., 440)
+	NCoeff     uint32     // Doubles per data record
+	StartJD    float64    // First epoch of the published file (Julian Date), 0 if not recorded
+	EndJD      float64    // Last epoch of the published file (Julian Date), 0 if not recorded
+	Quantities []Quantity // Quantities of the published file in IPT order, nil if not recorded
+	Constants  int        // Number of header constants (NCON), 0 if not recorded
+}
+
+// Quantities of the releases, by layout
+var (
+	deBodies  = []Quantity{QuantityMercury, QuantityVenus, QuantityEarthMoonBarycenter, QuantityMars, QuantityJupiter, QuantitySaturn, QuantityUranus, QuantityNeptune, QuantityPluto, QuantityMoon, QuantitySun}
+	deOrients = append(append([]Quantity(nil), deBodies...), QuantityNutations, QuantityLibrations)
+	deTimes   = append(append([]Quantity(nil), deOrients...), QuantityLunarMantleOmega, QuantityTTmTDB)
+)
+
+// deVersions is the table of the JPL releases, the "t" variants following their base
+// release. The record sizes are those of the DE headers; the spans are those of the files
+// distributed by JPL, of which locally generated files may cover only a part.
+var deVersions = []DEVersion{
+	{Name: "DE102", Number: 102, NCoeff: 773},
+	{Name: "DE200", Number: 200, NCoeff: 826},
+	{Name: "DE202", Number: 202, NCoeff: 826},
+	{Name: "DE403", Number: 403, NCoeff: 1018, Quantities: deOrients},
+	{Name: "DE404", Number: 404, NCoeff: 728, Quantities: deBodies},
+	{Name: "DE405", Number: 405, NCoeff: 1018, StartJD: 2305424.5, EndJD: 2525008.5, Quantities: deOrients, Constants: 156},
+	{Name: "DE406", Number: 406, NCoeff: 728, StartJD: 625360.5, EndJD: 2816848.5, Quantities: deBodies},
+	{Name: "DE410", Number: 410, NCoeff: 1018, StartJD: 2415056.5, EndJD: 2458832.5, Quantities: deOrients},
+	{Name: "DE413", Number: 413, NCoeff: 1018, Quantities: deOrients},
+	{Name: "DE414", Number: 414, NCoeff: 1018, Quantities: deOrients},
+	{Name: "DE418", Number: 418, NCoeff: 1018, Quantities: deOrients},
+	{Name: "DE421", Number: 421, NCoeff: 1018, StartJD: 2414864.5, EndJD: 2471184.5, Quantities: deOrients},
+	{Name: "DE422", Number: 422, NCoeff: 1018, StartJD: 625648.5, EndJD: 2816816.5, Quantities: deOrients},
+	{Name: "DE423", Number: 423, NCoeff: 1018, Quantities: deOrients},
+	{Name: "DE424", Number: 424, NCoeff: 1018, Quantities: deOrients},
+	{Name: "DE430", Number: 430, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients, Constants: 572},
+	{Name: "DE430t", Number: 430, NCoeff: 982, StartJD: 2287184.5, EndJD: 2688976.5},
+	{Name: "DE431", Number: 431, NCoeff: 1018, StartJD: -3027215.5, EndJD: 7930192.5, Quantities: deOrients, Constants: 572},
+	{Name: "DE432", Number: 432, NCoeff: 938, StartJD: 2287184.5, EndJD: 2688976.5},
+	{Name: "DE432t", Number: 432, NCoeff: 982, StartJD: 2287184.5, EndJD: 2688976.5},
+	{Name: "DE433", Number: 433, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients},
+	{Name: "DE434", Number: 434, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients},
+	{Name: "DE435", Number: 435, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients},
+	{Name: "DE436", Number: 436, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients},
+	{Name: "DE436t", Number: 436, NCoeff: 1122, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deTimes},
+	{Name: "DE438", Number: 438, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients},
+	{Name: "DE438t", Number: 438, NCoeff: 1042, StartJD: 2287184.5, EndJD: 2688976.5},
+	{Name: "DE440", Number: 440, NCoeff: 1018, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deOrients},
+	{Name: "DE440t", Number: 440, NCoeff: 1122, StartJD: 2287184.5, EndJD: 2688976.5, Quantities: deTimes},
+	{Name: "DE441", Number: 441, NCoeff: 1018, StartJD: -3100015.5, EndJD: 8000016.5, Quantities: deOrients},
+}
+
+// LookupDEVersion returns the published descriptions of a DE number: the base release, and
+// the "t" variant with the lunar mantle and TT-TDB where JPL published one.
+//
+// Parameters:
+//   - number: DE number (e.g., 440), as returned by GetEphemerisLong(EphemerisVersion).
+//
+// Returns:
+//   - []DEVersion: The releases of the number, the base release first.
+//   - bool: Whether the number is in the table.
+func LookupDEVersion(number int) ([]DEVersion, bool) {
+	var res []DEVersion
+	for _, v := range deVersions {
+		if v.Number == number {
+			v.Quantities = append([]Quantity(nil), v.Quantities...) // The table is not shared
+			res = append(res, v)
+		}
+	}
+	return res, len(res) > 0
+}
+
+// knownRecordSizes returns the record sizes of the releases of a DE number, from deVersions.
+func knownRecordSizes(number uint64) []uint32 {
+	var n []uint32
+	for _, v := range deVersions {
+		if uint64(v.Number) == number {
+			n = append(n, v.NCoeff)
+		}
+	}
+	return n
+}
+
+// DEVersionMismatch is a property of a loaded file that disagrees with the published values
+// of its DE number. A mismatch is not an error: files regenerated for a shorter span, files
+// of other producers that reuse a DE number, and edited headers all load and interpolate
+// normally. It is a sign that the file may not be the release its header names.
+type DEVersionMismatch struct {
+	Version   string // Release compared against (e.g., "DE440"), or "DE<n>" when no variant matches the record size
+	Property  string // Property that disagrees: "record size", "time span", "quantities", or "constants"
+	File      string // Value in the file
+	Published string // Published value, or values
+}
+
+// String formats the mismatch as a one-line warning.
+func (m DEVersionMismatch) String() string {
+	return fmt.Sprintf("%s: %s is %s in the file, %s published", m.Version, m.Property, m.File, m.Published)
+}
+
+// DEVersionWarnings compares the loaded file with the published values of its DE number:
+// the record size against those of the releases of the number, then the time span, the
+// quantities, and the number of constants against the release of that record size. A span
+// within the published one is not a mismatch, since files are often generated for part of
+// it. Call it after opening a file, or use WithDEVersionWarnings.
+//
+// Returns:
+//   - []DEVersionMismatch: The disagreements, nil if none or if the DE number is not in the
+//     table (such as for INPOP or synthetic files).
+func (e *Ephemeris) DEVersionWarnings() []DEVersionMismatch {
+	ephem := e.ephemData
+	versions, ok := LookupDEVersion(int(ephem.ephemerisVersion))
+	if !ok {
+		return nil
+	}
+	var v *DEVersion
+	var sizes []string
+	for i := range versions {
+		if versions[i].NCoeff == ephem.ncoeff {
+			v = &versions[i]
+			break
+		}
+		sizes = append(sizes, fmt.Sprint(versions[i].NCoeff))
+	}
+	if v == nil {
+		return []DEVersionMismatch{{
+			Version:   fmt.Sprintf("DE%d", ephem.ephemerisVersion),
+			Property:  "record size",
+			File:      fmt.Sprintf("%d doubles", ephem.ncoeff),
+			Published: strings.Join(sizes, " or ") + " doubles",
+		}}
+	}
+
+	var res []DEVersionMismatch
+	add := func(property, file, published string) {
+		res = append(res, DEVersionMismatch{Version: v.Name, Property: property, File: file, Published: published})
+	}
+	if v.StartJD != 0 || v.EndJD != 0 {
+		if ephem.ephemStart < v.StartJD-recordEpochTolerance || ephem.ephemEnd > v.EndJD+recordEpochTolerance {
+			add("time span", fmt.Sprintf("JD %.1f to %.1f", ephem.ephemStart, ephem.ephemEnd),
+				fmt.Sprintf("JD %.1f to %.1f", v.StartJD, v.EndJD))
+		}
+	}
+	if v.Quantities != nil {
+		var have []Quantity
+		for q := QuantityMercury; q <= QuantityTTmTDB; q++ {
+			if _, _, _, err := e.IPT(q); err == nil {
+				have = append(have, q)
+			}
+		}
+		if !sameQuantities(have, v.Quantities) {
+			add("quantities", quantityList(have), quantityList(v.Quantities))
+		}
+	}
+	if v.Constants != 0 && int(ephem.ncon) != v.Constants {
+		add("constants", fmt.Sprint(ephem.ncon), fmt.Sprint(v.Constants))
+	}
+	return res
+}
+
+// WithDEVersionWarnings reports, when the file is opened, the properties that disagree with
+// the published values of its DE number, as listed by DEVersionWarnings. The file is opened
+// regardless of the warnings.
+//
+// Parameters:
+//   - warn: Function called with each mismatch, such as a logger.
+func WithDEVersionWarnings(warn func(DEVersionMismatch)) Option {
+	return func(e *Ephemeris) {
+		for _, m := range e.DEVersionWarnings() {
+			warn(m)
+		}
+	}
+}
+
+// sameQuantities reports whether two lists hold the same quantities, in any order.
+func sameQuantities(a, b []Quantity) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]Quantity(nil), a...), append([]Quantity(nil), b...)
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// quantityList formats quantities as a comma-separated list of their names.
+func quantityList(qs []Quantity) string {
+	names := make([]string, len(qs))
+	for i, q := range qs {
+		names[i] = q.String()
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
@@ -0,0 +1,272 @@
+// ./deadline.go
+package jpleph
+
+/*
+Package jpleph provides states within a deadline for soft real-time loops.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultMaxExtrapolation is the longest extrapolation of CalculatePVBefore when none is
+// given, in days (one hour).
+const defaultMaxExtrapolation = 1.0 / 24
+
+// DeadlineOptions configures one call of CalculatePVBefore.
+type DeadlineOptions struct {
+	Deadline         time.Time // Latest time to wait for a record read; zero waits as CalculatePV does
+	MaxExtrapolation float64   // Longest span in days over which an earlier state is extrapolated (0: one hour)
+}
+
+// TimedState is a state returned by CalculatePVBefore, exact or extrapolated.
+type TimedState struct {
+	Position Position // Position in AU (or the special quantity, as for CalculatePV)
+	Velocity Velocity // Velocity in AU/day, zero unless requested
+	Stale    bool     // Whether the state was extrapolated because its record was not read before the deadline
+	Age      float64  // Days from the epoch of the exact state extrapolated to the requested epoch (0 unless Stale)
+}
+
+// exactState is the last state computed exactly for a target and center, with the
+// acceleration estimated from the one before it.
+type exactState struct {
+	et       float64    // Epoch of the state (TDB Julian Date)
+	pos, vel [3]float64 // Position and velocity in AU and AU/day
+	acc      [3]float64 // Acceleration in AU/day^2, zero until two states are known
+}
+
+// recordRead is a data record read in the background.
+type recordRead struct {
+	source *jplEphData // Ephemeris data the record belongs to
+	nr     uint32      // Record number
+	rec    []float64   // Coefficients, byte-swapped
+	err    error       // Failure of the read
+}
+
+// deadlineReader reads data records in the background for CalculatePVBefore, through its
+// own handle, so that the caller never blocks on the file past its deadline.
+type deadlineReader struct {
+	source *jplEphData            // Ephemeris data the handle was cloned from
+	clone  *jplEphData            // Own handle for the reads, opened at the first miss
+	busy   bool                   // Whether a read is in flight
+	done   chan recordRead        // Delivers the read in flight; capacity 1
+	ready  *recordRead            // A record delivered but not yet wanted
+	last   map[[2]int]*exactState // Last exact states by target and center
+}
+
+// CalculatePVBefore returns the state of a target relative to a center, as CalculatePV does,
+// without blocking on the file past a deadline, for soft real-time loops such as telescope
+// control. When the record covering et is in memory (the record last used, one preloaded
+// with PreloadRange, or one read in the background), the state is exact. Otherwise the
+// record is read in the background through a second file handle while the call waits until
+// the deadline; if it has not arrived by then, the last exact state for the same target and
+// center is extrapolated to et with its velocity and acceleration, and marked Stale. The
+// read continues, so a later call finds the record in memory.
+//
+// After an hour of extrapolation, the error is of the order of 10 m for the planets seen
+// from the Earth and 100 m for the geocentric Moon; it grows as the cube of the span. The
+// deadline bounds
+// the reads of the DE file only: SPK kernels, custom bodies, and sources added with
+// AddSource are queried as by CalculatePV. Like CalculatePV, CalculatePVBefore must not be
+// called concurrently on the same Ephemeris.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target: Target body, as for CalculatePV.
+//   - center: Center body, as for CalculatePV.
+//   - velocity: Whether to return the velocity.
+//   - opts: The deadline and the longest extrapolation.
+//
+// Returns:
+//   - TimedState: The state, with whether it was extrapolated.
+//   - error: An error from CalculatePV or from the background read, or ErrDeadline if the
+//     record was not read before the deadline and no exact state within MaxExtrapolation
+//     of et is known.
+func (e *Ephemeris) CalculatePVBefore(et float64, target Planet, center CenterBody, velocity bool, opts DeadlineOptions) (TimedState, error) {
+	e.applyReload()
+	ephem := e.ephemData
+	if e.deadline == nil {
+		e.deadline = &deadlineReader{done: make(chan recordRead, 1), last: make(map[[2]int]*exactState)}
+	}
+	dr := e.deadline
+	if !opts.Deadline.IsZero() {
+		if nr, _, err := recordLocation(ephem, e.clampEpoch(et)); err == nil && !dr.resident(ephem, nr) {
+			if err := dr.wait(ephem, nr, opts.Deadline); errors.Is(err, ErrDeadline) {
+				return dr.extrapolate(et, target, center, velocity, opts.MaxExtrapolation, err)
+			} else if err != nil {
+				return TimedState{}, err
+			}
+		}
+	}
+
+	pos, vel, err := e.CalculatePV(et, target, center, true)
+	if err != nil {
+		return TimedState{}, err
+	}
+	dr.remember(et, target, center, pos, vel)
+	if !velocity {
+		vel = Velocity{}
+	}
+	return TimedState{Position: pos, Velocity: vel}, nil
+}
+
+// resident reports whether record nr is in memory, installing it in the cache when it was
+// read in the background.
+func (dr *deadlineReader) resident(ephem *jplEphData, nr uint32) bool {
+	if nr == ephem.currCacheLoc {
+		return true
+	}
+	if _, ok := ephem.preloaded[nr]; ok {
+		return true
+	}
+	if dr.busy {
+		select {
+		case r := <-dr.done:
+			dr.busy = false
+			dr.ready = &r
+		default:
+		}
+	}
+	if r := dr.ready; r != nil && r.source == ephem && r.nr == nr && r.err == nil {
+		copy(ephem.cache, r.rec)
+		ephem.currCacheLoc = nr
+		dr.ready = nil
+		return true
+	}
+	return false
+}
+
+// wait reads record nr in the background and waits for it until the deadline.
+//
+// Returns:
+//   - error: ErrDeadline if the record did not arrive in time, or the error of the read.
+func (dr *deadlineReader) wait(ephem *jplEphData, nr uint32, deadline time.Time) error {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		if !dr.busy {
+			if err := dr.start(ephem, nr); err != nil {
+				return err
+			}
+		}
+		select {
+		case r := <-dr.done:
+			dr.busy = false
+			dr.ready = &r
+			if r.source == ephem && r.nr == nr {
+				if r.err != nil {
+					dr.ready = nil
+					return r.err
+				}
+				dr.resident(ephem, nr)
+				return nil
+			}
+		case <-timer.C:
+			return fmt.Errorf("%w: record %d", ErrDeadline, nr)
+		}
+	}
+}
+
+// start begins the background read of record nr, opening the handle of the reader if needed.
+func (dr *deadlineReader) start(ephem *jplEphData, nr uint32) error {
+	if dr.source != ephem { // First miss, or the file was reloaded
+		if dr.clone != nil {
+			closeEphemeris(dr.clone)
+		}
+		dr.source, dr.clone = nil, nil
+		clone, err := cloneEphemeris(ephem)
+		if err != nil {
+			return err
+		}
+		dr.source, dr.clone = ephem, clone
+	}
+	dr.busy = true
+	go func(clone *jplEphData) {
+		r := recordRead{source: ephem, nr: nr, rec: make([]float64, clone.ncoeff)}
+		if r.err = readRecord(clone, nr, r.rec); r.err == nil && clone.swapBytes != 0 {
+			swapBytes64Slice(r.rec)
+		}
+		dr.done <- r
+	}(dr.clone)
+	return nil
+}
+
+// remember keeps an exact state for extrapolation, estimating the acceleration from the
+// previous state of the same target and center when it is recent.
+func (dr *deadlineReader) remember(et float64, target Planet, center CenterBody, pos Position, vel Velocity) {
+	key := [2]int{int(target), int(center)}
+	s := dr.last[key]
+	if s == nil {
+		s = &exactState{}
+		dr.last[key] = s
+	}
+	p, v := [3]float64{pos.X, pos.Y, pos.Z}, [3]float64{vel.DX, vel.DY, vel.DZ}
+	if dt := et - s.et; s.et != 0 && dt != 0 && dt*dt <= defaultMaxExtrapolation*defaultMaxExtrapolation {
+		for k := range s.acc {
+			s.acc[k] = (v[k] - s.vel[k]) / dt
+		}
+	} else if dt != 0 {
+		s.acc = [3]float64{}
+	}
+	s.et, s.pos, s.vel = et, p, v
+}
+
+// extrapolate returns the last exact state of a target and center carried to et, or cause
+// when there is none close enough.
+func (dr *deadlineReader) extrapolate(et float64, target Planet, center CenterBody, velocity bool, maxSpan float64, cause error) (TimedState, error) {
+	if maxSpan <= 0 {
+		maxSpan = defaultMaxExtrapolation
+	}
+	s := dr.last[[2]int{int(target), int(center)}]
+	if s == nil || !(et-s.et <= maxSpan && s.et-et <= maxSpan) {
+		return TimedState{}, cause
+	}
+	dt := et - s.et
+	var p, v [3]float64
+	for k := range p {
+		p[k] = s.pos[k] + (s.vel[k]+0.5*s.acc[k]*dt)*dt
+		v[k] = s.vel[k] + s.acc[k]*dt
+	}
+	res := TimedState{Position: Position{X: p[0], Y: p[1], Z: p[2]}, Stale: true, Age: dt}
+	if velocity {
+		res.Velocity = Velocity{DX: v[0], DY: v[1], DZ: v[2]}
+	}
+	return res, nil
+}
+
+// stopDeadlineReads waits for a background read in flight and closes the handle of the reader.
+func (e *Ephemeris) stopDeadlineReads() {
+	dr := e.deadline
+	if dr == nil {
+		return
+	}
+	if dr.busy {
+		<-dr.done
+	}
+	if dr.clone != nil {
+		closeEphemeris(dr.clone)
+	}
+	e.deadline = nil
+}
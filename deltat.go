@@ -0,0 +1,231 @@
+// ./deltat.go
+package jpleph
+
+/*
+Package jpleph provides the difference TT - UT1 (Delta T) from polynomial fits and IERS data.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// daysPerJulianYear is the length of the Julian year in days.
+const daysPerJulianYear = 365.25
+
+// deltaTBlendYears is the time over which a DeltaTTable fades from its first or last entry
+// into the polynomial model outside its span.
+const deltaTBlendYears = 100.0
+
+// DeltaT returns TT - UT1 in seconds at a Julian Date, from the polynomial fits of Espenak
+// and Meeus (Five Millennium Canon of Solar Eclipses, NASA/TP-2006-214141), valid from
+// -1999 to +3000. Outside that span it follows their long-term parabola. The uncertainty
+// grows from under a second in the 20th century to hours at the extremes of DE431/DE441.
+// The fits assume a lunar secular acceleration of -25.858 arcsec/century², close to that of
+// DE430 and later. For 1973 onwards, a DeltaTTable read from IERS data is more accurate.
+//
+// Parameters:
+//   - jd: Julian Date (UT1 or TT; Delta T varies too slowly for the difference to matter).
+//
+// Returns:
+//   - float64: TT - UT1 in seconds.
+func DeltaT(jd float64) float64 {
+	y := 2000 + (jd-J2000)/daysPerJulianYear // Decimal year
+	poly := func(t float64, c ...float64) float64 {
+		v := 0.0
+		for i := len(c) - 1; i >= 0; i-- {
+			v = v*t + c[i]
+		}
+		return v
+	}
+	longTerm := func(y float64) float64 {
+		u := (y - 1820) / 100
+		return -20 + 32*u*u
+	}
+
+	switch {
+	case y < -500:
+		return longTerm(y)
+	case y < 500:
+		return poly(y/100, 10583.6, -1014.41, 33.78311, -5.952053, -0.1798452, 0.022174192, 0.0090316521)
+	case y < 1600:
+		return poly((y-1000)/100, 1574.2, -556.01, 71.23472, 0.319781, -0.8503463, -0.005050998, 0.0083572073)
+	case y < 1700:
+		return poly(y-1600, 120, -0.9808, -0.01532, 1.0/7129)
+	case y < 1800:
+		return poly(y-1700, 8.83, 0.1603, -0.0059285, 0.00013336, -1.0/1174000)
+	case y < 1860:
+		return poly(y-1800, 13.72, -0.332447, 0.0068612, 0.0041116, -0.00037436, 0.0000121272, -0.0000001699, 0.000000000875)
+	case y < 1900:
+		return poly(y-1860, 7.62, 0.5737, -0.251754, 0.01680668, -0.0004473624, 1.0/233174)
+	case y < 1920:
+		return poly(y-1900, -2.79, 1.494119, -0.0598939, 0.0061966, -0.000197)
+	case y < 1941:
+		return poly(y-1920, 21.20, 0.84493, -0.076100, 0.0020936)
+	case y < 1961:
+		return poly(y-1950, 29.07, 0.407, -1.0/233, 1.0/2547)
+	case y < 1986:
+		return poly(y-1975, 45.45, 1.067, -1.0/260, -1.0/718)
+	case y < 2005:
+		return poly(y-2000, 63.86, 0.3345, -0.060374, 0.0017275, 0.000651814, 0.00002373599)
+	case y < 2050:
+		return poly(y-2000, 62.92, 0.32217, 0.005589)
+	case y < 2150:
+		return longTerm(y) - 0.5628*(2150-y)
+	}
+	return longTerm(y)
+}
+
+// DeltaTTable holds observed values of TT - UT1, such as the IERS/USNO file deltat.data.
+// Between entries it interpolates linearly; outside its span it fades into the DeltaT
+// polynomial model over a century, so that it stays continuous at both ends.
+type DeltaTTable struct {
+	jd     []float64 // Julian Dates (UT1) of the entries, ascending
+	deltaT []float64 // TT - UT1 in seconds at jd
+}
+
+// NewDeltaTTable builds a table from Julian Dates and TT - UT1 values in seconds.
+//
+// Parameters:
+//   - jd: Julian Dates of the entries, strictly ascending.
+//   - deltaT: TT - UT1 in seconds at each date.
+//
+// Returns:
+//   - *DeltaTTable: The table.
+//   - error: ErrTimeData if the slices differ in length, are empty, or the dates are not ascending.
+func NewDeltaTTable(jd, deltaT []float64) (*DeltaTTable, error) {
+	if len(jd) != len(deltaT) || len(jd) == 0 {
+		return nil, fmt.Errorf("%w: %d dates and %d Delta T values", ErrTimeData, len(jd), len(deltaT))
+	}
+	for i := 1; i < len(jd); i++ {
+		if !(jd[i] > jd[i-1]) {
+			return nil, fmt.Errorf("%w: Delta T dates not ascending at entry %d", ErrTimeData, i)
+		}
+	}
+	return &DeltaTTable{jd: append([]float64(nil), jd...), deltaT: append([]float64(nil), deltaT...)}, nil
+}
+
+// ReadDeltaTTable parses a table of TT - UT1 values in the format of the IERS/USNO file
+// deltat.data, one entry per line:
+//
+//	1973  2  1  43.4724
+//
+// giving the year, month, day (0h UT1), and TT - UT1 in seconds. Blank lines and lines
+// starting with '#' are skipped.
+//
+// Parameters:
+//   - r: Source of the table.
+//
+// Returns:
+//   - *DeltaTTable: The table.
+//   - error: ErrTimeData for a malformed line, or a read error.
+func ReadDeltaTTable(r io.Reader) (*DeltaTTable, error) {
+	var jd, deltaT []float64
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("%w: deltat line %d has %d fields", ErrTimeData, n, len(fields))
+		}
+		var ymd [3]int
+		for i := range ymd {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w: deltat line %d: %q", ErrTimeData, n, fields[i])
+			}
+			ymd[i] = v
+		}
+		v, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: deltat line %d: %q", ErrTimeData, n, fields[3])
+		}
+		jd = append(jd, julianDateFromTime(time.Date(ymd[0], time.Month(ymd[1]), ymd[2], 0, 0, 0, 0, time.UTC)))
+		deltaT = append(deltaT, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewDeltaTTable(jd, deltaT)
+}
+
+// Span returns the Julian Dates of the first and last entries.
+func (t *DeltaTTable) Span() (float64, float64) {
+	return t.jd[0], t.jd[len(t.jd)-1]
+}
+
+// DeltaT returns TT - UT1 in seconds at a Julian Date, interpolated in the table within its
+// span and blended into the polynomial model (see DeltaT) outside.
+func (t *DeltaTTable) DeltaT(jd float64) float64 {
+	first, last := 0, len(t.jd)-1
+	switch {
+	case jd <= t.jd[first]:
+		return t.blend(jd, first)
+	case jd >= t.jd[last]:
+		return t.blend(jd, last)
+	}
+	i := sort.SearchFloat64s(t.jd, jd) // t.jd[i-1] < jd <= t.jd[i]
+	f := (jd - t.jd[i-1]) / (t.jd[i] - t.jd[i-1])
+	return t.deltaT[i-1] + f*(t.deltaT[i]-t.deltaT[i-1])
+}
+
+// blend returns the polynomial model at jd, offset to match the table entry i and with the
+// offset fading out linearly over deltaTBlendYears.
+func (t *DeltaTTable) blend(jd float64, i int) float64 {
+	offset := t.deltaT[i] - DeltaT(t.jd[i])
+	w := math.Max(0, 1-math.Abs(jd-t.jd[i])/(deltaTBlendYears*daysPerJulianYear))
+	return DeltaT(jd) + w*offset
+}
+
+// UT1ToTDB converts a UT1 Julian Date into a TDB Julian Date, the time argument of CalculatePV.
+//
+// Parameters:
+//   - jdUT1: Julian Date in UT1.
+//   - deltaT: TT - UT1 in seconds, e.g. DeltaT(jdUT1).
+//
+// Returns:
+//   - float64: Julian Date in TDB.
+func UT1ToTDB(jdUT1, deltaT float64) float64 {
+	tt := jdUT1 + deltaT/secondsPerDay
+	return tt + tdbMinusTT(tt)/secondsPerDay
+}
+
+// TDBToUT1 converts a TDB Julian Date, such as the time of an event, into a UT1 Julian Date.
+//
+// Parameters:
+//   - et: Julian Date in TDB.
+//   - deltaT: TT - UT1 in seconds, e.g. DeltaT(et).
+//
+// Returns:
+//   - float64: Julian Date in UT1.
+func TDBToUT1(et, deltaT float64) float64 {
+	return et - tdbMinusTT(et)/secondsPerDay - deltaT/secondsPerDay
+}
@@ -0,0 +1,91 @@
+// ./direction.go
+package jpleph
+
+/*
+Package jpleph provides aberration-corrected pointing directions.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "fmt"
+
+// Correction selects the corrections applied to the direction of a target by Direction.
+type Correction int
+
+const (
+	// CorrectionNone gives the geometric direction at et.
+	CorrectionNone Correction = iota
+	// CorrectionLT corrects for light time: the target is taken where it was when the light
+	// now arriving at the observer left it.
+	CorrectionLT
+	// CorrectionLTS corrects for light time and stellar aberration, giving the apparent
+	// direction, as SPICE does for "CN+S".
+	CorrectionLTS
+)
+
+// correctionNames holds the names of the corrections, indexed by Correction value.
+var correctionNames = [...]string{CorrectionNone: "NONE", CorrectionLT: "LT", CorrectionLTS: "LT+S"}
+
+// String returns the name of the correction (e.g., "LT+S").
+func (c Correction) String() string {
+	if c >= 0 && int(c) < len(correctionNames) {
+		return correctionNames[c]
+	}
+	return fmt.Sprintf("Correction(%d)", int(c))
+}
+
+// Direction returns the unit vector from an observer toward a target in the ICRF, with the
+// selected corrections, for attitude and pointing work that does not need the full state.
+// The light-time equation is iterated to convergence. Dividing the position by its length
+// loses nothing, so the components are as accurate as those of CalculatePV.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date of observation.
+//   - target: Body to point at.
+//   - observer: Body the direction is seen from (e.g., Earth, or an SPK or custom body).
+//   - corrections: CorrectionNone, CorrectionLT, or CorrectionLTS.
+//
+// Returns:
+//   - [3]float64: Unit vector toward the target.
+//   - error: ErrInvalidIndex if the target and observer coincide or corrections is unknown,
+//     or an error from CalculatePV.
+func (e *Ephemeris) Direction(et float64, target, observer Planet, corrections Correction) ([3]float64, error) {
+	if target == observer {
+		return [3]float64{}, fmt.Errorf("target and observer are both %v: %w", target, ErrInvalidIndex)
+	}
+	corr := spiceCorrection{transmission: -1}
+	switch corrections {
+	case CorrectionNone:
+	case CorrectionLT:
+		corr.lightTime, corr.converged = true, true
+	case CorrectionLTS:
+		corr.lightTime, corr.converged, corr.stellar = true, true, true
+	default:
+		return [3]float64{}, fmt.Errorf("%v: %w", corrections, ErrInvalidIndex)
+	}
+
+	pos, _, _, err := e.correctedState((et-J2000)*secondsPerDay, target, observer, corr, false)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	r := norm3(pos)
+	return [3]float64{pos[0] / r, pos[1] / r, pos[2] / r}, nil
+}
@@ -0,0 +1,88 @@
+// ./earth_moon.go
+package jpleph
+
+/*
+Package jpleph provides the split of the Earth-Moon barycenter into the Earth and the Moon.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+// EarthMoonStates holds the states of the Earth-Moon system at one epoch, as computed by
+// EarthMoonSplit. The file stores the Earth-Moon barycenter and the geocentric Moon; the
+// Earth and the Moon follow from the Earth-Moon mass ratio EMRAT:
+//
+//	Earth = EMB - GeocentricMoon/(1+EMRAT)
+//	Moon  = Earth + GeocentricMoon
+type EarthMoonStates struct {
+	ET                  float64     // Epoch (TDB Julian Date)
+	EMRAT               float64     // Earth-Moon mass ratio of the file
+	GeocentricMoon      StateVector // Moon relative to the Earth, as stored in the file
+	EarthMoonBarycenter StateVector // Barycentric Earth-Moon barycenter, as stored in the file
+	Earth               StateVector // Barycentric Earth
+	Moon                StateVector // Barycentric Moon
+
+	Provenance []ConstantProvenance // Header constants of the split: the AU of the states, then EMRAT
+}
+
+// EarthMoonSplit returns the geocentric Moon and the barycentric Earth-Moon barycenter, Earth,
+// and Moon at an epoch, all from one evaluation of the record. It gives the same values as
+// CalculatePV for the corresponding pairs, without repeating the interpolation for each.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - EarthMoonStates: The states in AU and AU/day, relative to the solar system barycenter
+//     except for the geocentric Moon.
+//   - error: ErrOutsideRange if et is outside the time span of the file, or a file read error.
+func (e *Ephemeris) EarthMoonSplit(et float64) (EarthMoonStates, error) {
+	e.applyReload()
+	et = e.clampEpoch(et)
+	var list [14]int
+	list[2], list[9] = 2, 2 // Earth-Moon barycenter and geocentric Moon, with velocities
+	var pv [13][6]float64
+	if err := State(e.ephemData, et, list, &pv, make([]float64, 6), 1); err != nil {
+		return EarthMoonStates{}, err
+	}
+
+	emrat := e.ephemData.emrat
+	var earth, moon [6]float64
+	for i := range earth {
+		earth[i] = pv[2][i] - pv[9][i]/(1.0+emrat)
+		moon[i] = earth[i] + pv[9][i]
+	}
+	state := func(s [6]float64) StateVector {
+		return StateVector{
+			ET:       et,
+			Position: Position{X: s[0], Y: s[1], Z: s[2]},
+			Velocity: Velocity{DX: s[3], DY: s[4], DZ: s[5]},
+		}
+	}
+	return EarthMoonStates{
+		ET:                  et,
+		EMRAT:               emrat,
+		GeocentricMoon:      state(pv[9]),
+		EarthMoonBarycenter: state(pv[2]),
+		Earth:               state(earth),
+		Moon:                state(moon),
+		Provenance:          e.ScalingProvenance(),
+	}, nil
+}
@@ -0,0 +1,255 @@
+// ./eclipse_path.go
+package jpleph
+
+/*
+Package jpleph provides the paths of central solar eclipses on the Earth and their export to GeoJSON.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// eclipseOutlinePoints is the number of generators of the umbral cone traced to the ground
+// to find the limits of the path.
+const eclipseOutlinePoints = 180
+
+// EclipsePathPoint is a point on the ground of the path of a central eclipse.
+type EclipsePathPoint struct {
+	ET        float64 // Epoch (TDB Julian Date)
+	Latitude  float64 // Geodetic latitude in degrees, north positive
+	Longitude float64 // Longitude in degrees, east positive, in (-180, 180]
+}
+
+// EclipsePath is the track of the umbra or antumbra of the Moon over the Earth.
+type EclipsePath struct {
+	Kind       EclipseKind        // EclipseTotal, EclipseAnnular, or EclipseHybrid; EclipseNone when the axis misses the Earth
+	Central    []EclipsePathPoint // Central line, where the shadow axis meets the ground
+	NorthLimit []EclipsePathPoint // Northern limit of the shadow, the one of higher mean latitude
+	SouthLimit []EclipsePathPoint // Southern limit of the shadow
+}
+
+// ellipsoidHit returns the intersection of the line p + s d with the ellipsoid, in km, for
+// the largest s: the point facing the direction d.
+func ellipsoidHit(el Ellipsoid, p, d linalg.Vec3) (linalg.Vec3, bool) {
+	k := el.A / el.B() // Stretch of z mapping the ellipsoid to a sphere of radius A
+	q, v := linalg.Vec3{p[0], p[1], p[2] * k}, linalg.Vec3{d[0], d[1], d[2] * k}
+	a, b, c := v.Dot(v), q.Dot(v), q.Dot(q)-el.A*el.A
+	disc := b*b - a*c
+	if disc < 0 {
+		return linalg.Vec3{}, false
+	}
+	s := (-b + math.Sqrt(disc)) / a
+	return p.Add(d.Scale(s)), true
+}
+
+// shadowAxis returns the geocentric Moon in the ITRF (km), the direction of the shadow axis
+// away from the Sun, and the sine of the half-angle of the umbral cone.
+func (r *FrameRegistry) shadowAxis(et float64) (moon, axis linalg.Vec3, sinF float64, err error) {
+	earth, _, err := r.eph.CalculatePV(et, Earth, CenterSolarSystemBarycenter, false)
+	if err != nil {
+		return
+	}
+	obs := [3]float64{earth.X, earth.Y, earth.Z}
+	s, err := r.eph.lightTimePosition(et, Sun, obs)
+	if err != nil {
+		return
+	}
+	m, err := r.eph.lightTimePosition(et, Moon, obs)
+	if err != nil {
+		return
+	}
+	rot, err := r.rotation(FrameICRF, FrameITRF, et)
+	if err != nil {
+		return
+	}
+	au := r.eph.ephemData.au
+	sun := linalg.Vec3(rot.Apply(s)).Scale(au)
+	moon = linalg.Vec3(rot.Apply(m)).Scale(au)
+	d := moon.Sub(sun)
+	return moon, d.Unit(), (bodyRadii[Sun][0] - moonRadiusCentral*wgs84A) / d.Norm(), nil
+}
+
+// EclipsePath traces the central line and the limits of the umbra (or of the antumbra of an
+// annular eclipse) of the Moon on the WGS84 ellipsoid, at each step from start to end. Like
+// LocalCircumstances, it works on the geocentric Sun and Moon corrected for light time, with
+// the Earth orientation of the registry, rather than on Besselian elements. The limits are
+// the extreme points across the track of the outline of the shadow cone on the ground, which
+// places them to a few km for steps of a minute; lunar limb profiles are not included.
+// Epochs when the shadow axis misses the Earth are left out, so the path starts and ends
+// where the central line meets the sunrise and sunset terminators.
+//
+// Parameters:
+//   - start, end: Time span as ephemeris time (TDB) Julian Dates, such as the four hours
+//     around the geocentric conjunction; end is included when it falls on a step.
+//   - step: Sampling step in days (e.g., 1.0/1440 for one minute).
+//
+// Returns:
+//   - *EclipsePath: The path, with Kind EclipseNone and no points when the eclipse is not
+//     central in the span.
+//   - error: ErrEventSearch for an empty span or a non-positive step, or an error from
+//     CalculatePV or the frames.
+func (r *FrameRegistry) EclipsePath(start, end, step float64) (*EclipsePath, error) {
+	if !(step > 0) || !(end >= start) {
+		return nil, fmt.Errorf("%w: eclipse path span %v to %v with step %v", ErrEventSearch, start, end, step)
+	}
+	el := WGS84
+	hit := func(et float64) (c linalg.Vec3, ok bool, err error) {
+		moon, axis, _, err := r.shadowAxis(et)
+		if err != nil {
+			return linalg.Vec3{}, false, err
+		}
+		c, ok = ellipsoidHit(el, moon, axis.Scale(-1))
+		return c, ok, nil
+	}
+	point := func(et float64, x linalg.Vec3) EclipsePathPoint {
+		lat, lon, _ := el.Geodetic(x)
+		return EclipsePathPoint{ET: et, Latitude: lat, Longitude: lon}
+	}
+
+	path := &EclipsePath{}
+	total, annular := false, false
+	n := int(math.Floor((end-start)/step+1e-9)) + 1
+	for i := 0; i < n; i++ {
+		et := start + float64(i)*step
+		moon, axis, sinF, err := r.shadowAxis(et)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := ellipsoidHit(el, moon, axis.Scale(-1))
+		if !ok {
+			continue
+		}
+		path.Central = append(path.Central, point(et, c))
+
+		// The vertex of the umbral cone, beyond the Earth for a total eclipse
+		vertexDistance := moonRadiusCentral * wgs84A / sinF
+		if c.Sub(moon).Norm() < vertexDistance {
+			total = true
+		} else {
+			annular = true
+		}
+		vertex := moon.Add(axis.Scale(vertexDistance))
+
+		// Direction across the track, from the motion of the central point over a few seconds
+		const dt = 1e-4
+		next, ok, err := hit(et + dt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if next, ok, err = hit(et - dt); err != nil {
+				return nil, err
+			}
+			next = c.Scale(2).Sub(next) // Reflected, to point forward
+		}
+		if !ok {
+			continue // A single point: no direction of motion
+		}
+		across := c.Unit().Cross(next.Sub(c)).Unit() // Left of the motion
+
+		// Extreme points across the track of the outline of the cone on the ground
+		e1 := axis.Cross(linalg.Vec3{0, 0, 1}).Unit()
+		e2 := axis.Cross(e1)
+		cosF := math.Sqrt(1 - sinF*sinF)
+		var left, right linalg.Vec3
+		maxOff, minOff := math.Inf(-1), math.Inf(1)
+		for k := 0; k < eclipseOutlinePoints; k++ {
+			sinT, cosT := math.Sincos(2 * math.Pi * float64(k) / eclipseOutlinePoints)
+			w := axis.Scale(-cosF).Add(e1.Scale(sinF * cosT)).Add(e2.Scale(sinF * sinT)) // Toward the Moon
+			x, ok := ellipsoidHit(el, vertex, w)
+			if !ok {
+				continue
+			}
+			off := x.Sub(c).Dot(across)
+			if off > maxOff {
+				maxOff, left = off, x
+			}
+			if off < minOff {
+				minOff, right = off, x
+			}
+		}
+		if maxOff > 0 && minOff < 0 {
+			path.NorthLimit = append(path.NorthLimit, point(et, left))
+			path.SouthLimit = append(path.SouthLimit, point(et, right))
+		}
+	}
+	north, south := 0.0, 0.0
+	for i := range path.NorthLimit {
+		north += path.NorthLimit[i].Latitude
+		south += path.SouthLimit[i].Latitude
+	}
+	if north < south { // Left of a westward motion
+		path.NorthLimit, path.SouthLimit = path.SouthLimit, path.NorthLimit
+	}
+	switch {
+	case total && annular:
+		path.Kind = EclipseHybrid
+	case total:
+		path.Kind = EclipseTotal
+	case annular:
+		path.Kind = EclipseAnnular
+	}
+	return path, nil
+}
+
+// WriteGeoJSON writes the path as a GeoJSON feature collection for web maps: a Polygon (or
+// a MultiPolygon, when cut at the antimeridian) of the area between the limits, and
+// MultiLineString features of the central line and of each limit, cut at the antimeridian.
+// The "feature" property of each names it ("path", "central", "north_limit", or
+// "south_limit"), and "kind" gives the kind of the eclipse.
+func (p *EclipsePath) WriteGeoJSON(w io.Writer) error {
+	line := func(points []EclipsePathPoint) [][2]float64 {
+		coords := make([][2]float64, len(points))
+		for i, q := range points {
+			coords[i] = [2]float64{q.Longitude, q.Latitude}
+		}
+		return coords
+	}
+	properties := func(feature string, points []EclipsePathPoint) map[string]any {
+		props := map[string]any{"feature": feature, "kind": p.Kind.String()}
+		if len(points) > 0 {
+			props["jd_tdb_start"] = points[0].ET
+			props["jd_tdb_end"] = points[len(points)-1].ET
+		}
+		return props
+	}
+
+	var features []geoJSONFeature
+	if len(p.NorthLimit) > 1 {
+		ring := line(p.NorthLimit)
+		south := line(p.SouthLimit)
+		for i := len(south) - 1; i >= 0; i-- {
+			ring = append(ring, south[i])
+		}
+		features = append(features, polygonFeature(ring, properties("path", p.NorthLimit)))
+	}
+	features = append(features,
+		lineFeature(line(p.Central), properties("central", p.Central)),
+		lineFeature(line(p.NorthLimit), properties("north_limit", p.NorthLimit)),
+		lineFeature(line(p.SouthLimit), properties("south_limit", p.SouthLimit)))
+	return writeGeoJSON(w, features)
+}
@@ -0,0 +1,175 @@
+// ./ecliptic.go
+package jpleph
+
+/*
+Package jpleph provides ecliptic spherical coordinates (longitude, latitude, distance).
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// EclipticFrame selects the ecliptic and equinox that ecliptic coordinates refer to.
+type EclipticFrame int
+
+const (
+	// EclipticJ2000 is the mean ecliptic and equinox of J2000.0, with the IAU 1976 obliquity of
+	// SPICE ECLIPJ2000 and of MPC and JPL SBDB elements.
+	EclipticJ2000 EclipticFrame = iota
+	// EclipticMeanOfDate is the mean ecliptic and equinox of date (IAU 2006 precession).
+	EclipticMeanOfDate
+	// EclipticTrueOfDate is the ecliptic of date referred to the true equinox of date, adding the
+	// nutation in longitude from the ephemeris file.
+	EclipticTrueOfDate
+)
+
+// arcsecToRad converts arcseconds to radians.
+const arcsecToRad = math.Pi / (180 * 3600)
+
+// daysPerJulianCentury is the length of a Julian century in days.
+const daysPerJulianCentury = 36525.0
+
+// EclipticCoordinates holds ecliptic spherical coordinates.
+type EclipticCoordinates struct {
+	Longitude float64 // Ecliptic longitude (lambda) in degrees, in [0, 360)
+	Latitude  float64 // Ecliptic latitude (beta) in degrees, in [-90, 90]
+	Distance  float64 // Distance from the center in AU
+}
+
+// Ecliptic returns the ecliptic longitude, latitude, and distance of a body as seen from a
+// center, e.g. heliocentric (center Sun) or geocentric (center Earth) coordinates. The
+// states are geometric; no light-time or aberration corrections are applied, and the ICRF
+// frame bias of a few milliarcseconds is ignored.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target: Target body (any target accepted by CalculatePV except the special quantities).
+//   - center: Center body.
+//   - frame: Ecliptic and equinox of the output coordinates.
+//
+// Returns:
+//   - EclipticCoordinates: Longitude and latitude in degrees, distance in AU.
+//   - error: An error from CalculatePV, ErrQuantityNotInEphemeris if EclipticTrueOfDate is
+//     requested from a file without nutations, or ErrInvalidIndex for an unknown frame.
+func (e *Ephemeris) Ecliptic(et float64, target Planet, center CenterBody, frame EclipticFrame) (EclipticCoordinates, error) {
+	if frame < EclipticJ2000 || frame > EclipticTrueOfDate {
+		return EclipticCoordinates{}, fmt.Errorf("ecliptic frame %d: %w", frame, ErrInvalidIndex)
+	}
+	if target >= Nutations && target <= TT_TDB {
+		return EclipticCoordinates{}, fmt.Errorf("%v has no ecliptic coordinates: %w", target, ErrInvalidIndex)
+	}
+	pos, _, err := e.CalculatePV(et, target, center, false)
+	if err != nil {
+		return EclipticCoordinates{}, err
+	}
+
+	m := linalg.RotX(obliquityJ2000)
+	if frame != EclipticJ2000 {
+		m = eclipticOfDate(et)
+	}
+	lon, lat, r := sphericalDegrees(m.Apply([3]float64{pos.X, pos.Y, pos.Z}))
+	if frame == EclipticTrueOfDate {
+		dpsi, _, err := e.nutationAngles(et)
+		if err != nil {
+			return EclipticCoordinates{}, err
+		}
+		lon = math.Mod(lon+dpsi*180/math.Pi+360, 360) // Nutation in longitude moves the equinox along the ecliptic
+	}
+	return EclipticCoordinates{Longitude: lon, Latitude: lat, Distance: r}, nil
+}
+
+// eclipticOfDate returns the rotation from the ICRF to the mean ecliptic and equinox of date.
+// The J2000 ecliptic it starts from uses the IAU 2006 obliquity that the P03 precession angles
+// and meanObliquity refer to, not the IAU 1976 value of EclipticJ2000.
+func eclipticOfDate(et float64) linalg.Mat3 {
+	return eclipticPrecession(et).Mul(linalg.RotX(obliquityIAU2006))
+}
+
+// eclipticPrecession returns the rotation from the mean ecliptic and equinox of J2000 to the
+// mean ecliptic and equinox of date, from the IAU 2006 (P03) ecliptic precession angles
+// (IERS Conventions 2010, eq. 5.39).
+func eclipticPrecession(et float64) linalg.Mat3 {
+	t := (et - J2000) / daysPerJulianCentury
+	piA := (46.998973 + (-0.0334926+(-0.00012559+(0.000000113-0.0000000022*t)*t)*t)*t) * t * arcsecToRad
+	bigPiA := (629546.7936 + (-867.95758+(0.157992+(-0.0005371+(-0.00004797+0.000000072*t)*t)*t)*t)*t) * arcsecToRad
+	pA := (5028.796195 + (1.1054348+(0.00007964+(-0.000023857-0.0000000383*t)*t)*t)*t) * t * arcsecToRad
+	// Rotate to the node of the ecliptic of date, tilt by piA, then measure from the moved equinox.
+	return linalg.RotZ(-(bigPiA + pA)).Mul(linalg.RotX(piA)).Mul(linalg.RotZ(bigPiA))
+}
+
+// longitudeCrossingStep is the sampling step of LongitudeCrossings in days. Mercury, the
+// fastest planet, moves less than 30 degrees in a step, well short of the 180 degrees that
+// would make a crossing ambiguous.
+const longitudeCrossingStep = 4.0
+
+// LongitudeCrossings finds the times between start and end when the heliocentric ecliptic
+// longitude of a body crosses a given value: for example Mars at 0 degrees (0 Aries), the
+// longitude of a seasonal point of a planet, or the ingresses of a planet into a zodiacal
+// sign. Heliocentric longitudes increase monotonically, so each orbit gives one crossing.
+//
+// Parameters:
+//   - target: Body whose longitude is followed (a planet, the Earth, the Earth-Moon
+//     barycenter, or a custom or SPK body orbiting the Sun).
+//   - longitude: Longitude to cross in degrees, in the frame given.
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//   - frame: Ecliptic and equinox of the longitude (e.g., EclipticMeanOfDate).
+//
+// Returns:
+//   - []float64: The epochs (TDB Julian Dates) of the crossings in chronological order.
+//   - error: ErrEventSearch for an invalid interval, or an error from Ecliptic.
+func (e *Ephemeris) LongitudeCrossings(target Planet, longitude, start, end float64, frame EclipticFrame) ([]float64, error) {
+	if !(end > start) || math.IsNaN(longitude) || math.IsInf(longitude, 0) {
+		return nil, fmt.Errorf("%w: interval [%f, %f], longitude %g", ErrEventSearch, start, end, longitude)
+	}
+	offset := func(et float64) (float64, error) { // Longitude past the given one, in (-180, 180]
+		c, err := e.Ecliptic(et, target, CenterSun, frame)
+		if err != nil {
+			return 0, err
+		}
+		d := math.Mod(c.Longitude-longitude, 360)
+		if d > 180 {
+			d -= 360
+		} else if d <= -180 {
+			d += 360
+		}
+		return d, nil
+	}
+	crossings, err := findCrossings(offset, start, end, longitudeCrossingStep)
+	if err != nil {
+		return nil, err
+	}
+	var epochs []float64
+	for _, c := range crossings {
+		d, err := offset(c.et)
+		if err != nil {
+			return nil, err
+		}
+		if math.Abs(d) < 90 { // Not the wrap of the offset at the opposite longitude
+			epochs = append(epochs, c.et)
+		}
+	}
+	return epochs, nil
+}
@@ -0,0 +1,39 @@
+// ./ecliptic_test.go
+package jpleph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// TestEclipticObliquity checks that the J2000 frame uses the IAU 1976 obliquity of SPICE
+// ECLIPJ2000 and that the of-date frame, at J2000.0 where precession vanishes, uses the
+// IAU 2006 obliquity of the P03 precession.
+func TestEclipticObliquity(t *testing.T) {
+	e := openSynthetic(t, jplephtest.DefaultConfig())
+	pos, _, err := e.CalculatePV(jpleph.J2000, jpleph.Mars, jpleph.CenterSun, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		frame     jpleph.EclipticFrame
+		obliquity float64 // Arcseconds
+	}{
+		{jpleph.EclipticJ2000, 84381.448},
+		{jpleph.EclipticMeanOfDate, 84381.406},
+	} {
+		s, c := math.Sincos(tc.obliquity * math.Pi / (180 * 3600))
+		y, z := c*pos.Y+s*pos.Z, -s*pos.Y+c*pos.Z
+		wantLat := math.Asin(z/math.Sqrt(pos.X*pos.X+y*y+z*z)) * 180 / math.Pi
+		got, err := e.Ecliptic(jpleph.J2000, jpleph.Mars, jpleph.CenterSun, tc.frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := math.Abs(got.Latitude - wantLat); d > 1e-9 {
+			t.Errorf("frame %d: latitude %.12f, want %.12f for obliquity %.3f\"", tc.frame, got.Latitude, wantLat, tc.obliquity)
+		}
+	}
+}
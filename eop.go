@@ -0,0 +1,283 @@
+// ./eop.go
+package jpleph
+
+/*
+Package jpleph provides Earth orientation parameters and the accuracy tiers of the Earth-fixed frame.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// EOP are the Earth orientation parameters at one epoch: the coordinates of the celestial
+// intermediate pole in the ITRF (polar motion) and UT1 - UTC.
+type EOP struct {
+	Xp          float64 // Polar motion x in arcseconds
+	Yp          float64 // Polar motion y in arcseconds
+	UT1MinusUTC float64 // UT1 - UTC in seconds
+}
+
+// EOPTable holds daily Earth orientation parameters, such as those of the IERS files
+// finals2000A.all (Bulletin A, with predictions) or finals2000A.data. UT1 is interpolated as
+// UT1 - TAI, which stays continuous across leap seconds.
+type EOPTable struct {
+	mjd         []float64 // Modified Julian Dates (UTC) of the entries, ascending
+	xp, yp      []float64 // Polar motion in arcseconds
+	ut1MinusTAI []float64 // UT1 - TAI in seconds
+}
+
+// NewEOPTable builds a table from daily values.
+//
+// Parameters:
+//   - mjd: Modified Julian Dates (UTC) of the entries, strictly ascending.
+//   - xp, yp: Polar motion in arcseconds at each date.
+//   - ut1MinusUTC: UT1 - UTC in seconds at each date.
+//
+// Returns:
+//   - *EOPTable: The table.
+//   - error: ErrTimeData if the slices differ in length, are empty, or the dates are not ascending.
+func NewEOPTable(mjd, xp, yp, ut1MinusUTC []float64) (*EOPTable, error) {
+	n := len(mjd)
+	if n == 0 || len(xp) != n || len(yp) != n || len(ut1MinusUTC) != n {
+		return nil, fmt.Errorf("%w: %d dates, %d x, %d y, and %d UT1 - UTC values", ErrTimeData,
+			n, len(xp), len(yp), len(ut1MinusUTC))
+	}
+	t := &EOPTable{mjd: append([]float64(nil), mjd...), xp: append([]float64(nil), xp...),
+		yp: append([]float64(nil), yp...), ut1MinusTAI: make([]float64, n)}
+	leaps := CurrentLeapSeconds()
+	for i := range mjd {
+		if i > 0 && !(mjd[i] > mjd[i-1]) {
+			return nil, fmt.Errorf("%w: EOP dates not ascending at entry %d", ErrTimeData, i)
+		}
+		t.ut1MinusTAI[i] = ut1MinusUTC[i] - leaps.TAIMinusUTC(mjd[i]+mjdOffset)
+	}
+	return t, nil
+}
+
+// ReadEOPTable parses Earth orientation parameters in the fixed columns of the IERS files
+// finals2000A.all and finals2000A.data (Bulletin B values where present, else Bulletin A),
+// or in four columns separated by blanks:
+//
+//	60310.00  0.061270  0.300630 -0.0141424
+//
+// giving the MJD (UTC), x and y in arcseconds, and UT1 - UTC in seconds. Blank lines, lines
+// starting with '#', and finals lines without values (beyond the predictions) are skipped.
+//
+// Parameters:
+//   - r: Source of the table.
+//
+// Returns:
+//   - *EOPTable: The table.
+//   - error: ErrTimeData for a malformed line, or a read error.
+func ReadEOPTable(r io.Reader) (*EOPTable, error) {
+	var mjd, xp, yp, dut1 []float64
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var v [4]float64
+		if len(line) >= 15 && line[6] == ' ' && line[12] == '.' { // finals2000A, MJD in columns 8-15
+			cols := [4][2]int{{7, 15}, {18, 27}, {37, 46}, {58, 68}}
+			if len(line) >= 165 && strings.TrimSpace(line[134:165]) != "" {
+				cols[1], cols[2], cols[3] = [2]int{134, 144}, [2]int{144, 154}, [2]int{154, 165}
+			}
+			if len(line) < 68 || strings.TrimSpace(line[cols[1][0]:cols[1][1]]) == "" ||
+				strings.TrimSpace(line[cols[3][0]:cols[3][1]]) == "" {
+				continue // No values for this date
+			}
+			for i, c := range cols {
+				f, err := strconv.ParseFloat(strings.TrimSpace(line[c[0]:c[1]]), 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: EOP line %d: %q", ErrTimeData, n, line[c[0]:c[1]])
+				}
+				v[i] = f
+			}
+		} else {
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("%w: EOP line %d has %d fields", ErrTimeData, n, len(fields))
+			}
+			for i := range v {
+				f, err := strconv.ParseFloat(fields[i], 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: EOP line %d: %q", ErrTimeData, n, fields[i])
+				}
+				v[i] = f
+			}
+		}
+		mjd, xp, yp, dut1 = append(mjd, v[0]), append(xp, v[1]), append(yp, v[2]), append(dut1, v[3])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewEOPTable(mjd, xp, yp, dut1)
+}
+
+// Span returns the Modified Julian Dates (UTC) of the first and last entries.
+func (t *EOPTable) Span() (float64, float64) {
+	return t.mjd[0], t.mjd[len(t.mjd)-1]
+}
+
+// At returns the parameters at a Modified Julian Date (UTC), interpolated linearly between
+// the daily values.
+//
+// Parameters:
+//   - mjd: Modified Julian Date in UTC.
+//
+// Returns:
+//   - EOP: The parameters.
+//   - bool: Whether mjd is within the span of the table; otherwise EOP is zero.
+func (t *EOPTable) At(mjd float64) (EOP, bool) {
+	xp, yp, ut1tai, ok := t.interpolate(mjd, false)
+	if !ok {
+		return EOP{}, false
+	}
+	return EOP{Xp: xp, Yp: yp, UT1MinusUTC: ut1tai + CurrentLeapSeconds().TAIMinusUTC(mjd+mjdOffset)}, true
+}
+
+// interpolate returns x, y, and UT1 - TAI at mjd, linearly or, with lagrange, by the
+// four-point Lagrange interpolation of the IERS routine INTERP where four entries surround
+// mjd.
+func (t *EOPTable) interpolate(mjd float64, lagrange bool) (xp, yp, ut1tai float64, ok bool) {
+	n := len(t.mjd)
+	if !(mjd >= t.mjd[0] && mjd <= t.mjd[n-1]) {
+		return 0, 0, 0, false
+	}
+	i := sort.SearchFloat64s(t.mjd, mjd) // t.mjd[i-1] < mjd <= t.mjd[i]
+	if i == 0 {
+		return t.xp[0], t.yp[0], t.ut1MinusTAI[0], true
+	}
+	if lagrange && i >= 2 && i+1 < n {
+		var w [4]float64
+		for k := range w {
+			w[k] = 1
+			for j := 0; j < 4; j++ {
+				if j != k {
+					w[k] *= (mjd - t.mjd[i-2+j]) / (t.mjd[i-2+k] - t.mjd[i-2+j])
+				}
+			}
+			xp += w[k] * t.xp[i-2+k]
+			yp += w[k] * t.yp[i-2+k]
+			ut1tai += w[k] * t.ut1MinusTAI[i-2+k]
+		}
+		return xp, yp, ut1tai, true
+	}
+	f := (mjd - t.mjd[i-1]) / (t.mjd[i] - t.mjd[i-1])
+	lerp := func(v []float64) float64 { return v[i-1] + f*(v[i]-v[i-1]) }
+	return lerp(t.xp), lerp(t.yp), lerp(t.ut1MinusTAI), true
+}
+
+// ObserverAccuracy is an accuracy tier of the Earth orientation of FrameITRF, and so of the
+// sites, sky positions, and site vectors of a FrameRegistry. The errors given are those of
+// the orientation of the Earth; the precession-nutation of the registry (IAU 2006
+// precession without the frame bias, and the IAU 1980 nutations of the file) adds up to a
+// few tens of milliarcseconds in every tier.
+type ObserverAccuracy int
+
+const (
+	// AccuracyFast uses no Earth orientation parameters: UT1 comes from the TT - UT1 of
+	// SetDeltaT (the DeltaT model by default), and polar motion is ignored. Neglecting polar
+	// motion costs 0.3 to 0.5 arcsecond (about 15 m on the ground); each second of error in
+	// TT - UT1 costs 15 arcseconds of Earth rotation. The DeltaT polynomial is off by up to
+	// about a second near the present, a DeltaTTable from IERS data by a few milliseconds.
+	AccuracyFast ObserverAccuracy = iota
+	// AccuracyStandard takes UT1 and polar motion from an EOPTable of IERS Bulletin A or B
+	// values, interpolated linearly. The error is about a milliarcsecond (3 cm on the
+	// ground) for final values, from the sub-daily tidal terms and the interpolation, and
+	// grows to tens of milliarcseconds for Bulletin A predictions months ahead.
+	AccuracyStandard
+	// AccuracyPrecise interpolates the table with four-point Lagrange polynomials, applies
+	// the TIO locator s', and adds the diurnal and semidiurnal variations of polar motion and
+	// UT1 from EarthOrientation.SubDaily (such as a port of the IERS routines PMUT1_OCEANS
+	// and PM_GRAVI, which are not embedded). The error of the orientation is then about 0.1
+	// milliarcsecond; without SubDaily it stays near that of AccuracyStandard.
+	AccuracyPrecise
+)
+
+// EarthOrientation configures the Earth orientation of FrameITRF in a FrameRegistry.
+type EarthOrientation struct {
+	Accuracy          ObserverAccuracy      // Accuracy tier
+	Table             *EOPTable             // Daily parameters, required by AccuracyStandard and AccuracyPrecise
+	IgnorePolarMotion bool                  // Whether to take only UT1 from Table, leaving out polar motion
+	SubDaily          func(mjd float64) EOP // Sub-daily corrections at an MJD (UTC) added by AccuracyPrecise (optional)
+}
+
+// SetEarthOrientation selects the accuracy tier of FrameITRF. Outside the span of the table,
+// the registry falls back to AccuracyFast: the TT - UT1 of SetDeltaT and no polar motion.
+//
+// Parameters:
+//   - eo: Accuracy tier and Earth orientation parameters.
+//
+// Returns:
+//   - error: ErrTimeData if a tier other than AccuracyFast has no table, or for an unknown tier.
+func (r *FrameRegistry) SetEarthOrientation(eo EarthOrientation) error {
+	switch {
+	case eo.Accuracy < AccuracyFast || eo.Accuracy > AccuracyPrecise:
+		return fmt.Errorf("%w: accuracy tier %d", ErrTimeData, eo.Accuracy)
+	case eo.Accuracy != AccuracyFast && eo.Table == nil:
+		return fmt.Errorf("%w: accuracy tier %d needs an EOP table", ErrTimeData, eo.Accuracy)
+	}
+	r.eop = eo
+	return nil
+}
+
+// tioLocatorRate is the rate of the TIO locator s' in radians per Julian century (IERS
+// Conventions 2010, eq. 5.13).
+const tioLocatorRate = -47e-6 * arcsecToRad
+
+// earthOrientation returns TT - UT1 in seconds at et and the polar motion matrix from the
+// terrestrial intermediate frame to the ITRF, following the tier of the registry.
+func (r *FrameRegistry) earthOrientation(et float64) (float64, linalg.Mat3) {
+	eo := r.eop
+	if eo.Accuracy == AccuracyFast {
+		return r.deltaT(et), linalg.Identity()
+	}
+	mjd := TDBToUTC(et) - mjdOffset
+	precise := eo.Accuracy == AccuracyPrecise
+	xp, yp, ut1tai, ok := eo.Table.interpolate(mjd, precise)
+	if !ok {
+		return r.deltaT(et), linalg.Identity()
+	}
+	sp := 0.0
+	if precise {
+		if eo.SubDaily != nil {
+			d := eo.SubDaily(mjd)
+			xp, yp, ut1tai = xp+d.Xp, yp+d.Yp, ut1tai+d.UT1MinusUTC
+		}
+		sp = tioLocatorRate * (et - J2000) / daysPerJulianCentury
+	}
+	deltaT := ttMinusTAI - ut1tai
+	if eo.IgnorePolarMotion {
+		return deltaT, linalg.Identity()
+	}
+	return deltaT, linalg.RotX(-yp * arcsecToRad).Mul(linalg.RotY(-xp * arcsecToRad)).Mul(linalg.RotZ(sp))
+}
@@ -27,7 +27,6 @@ Piotr A. Dybczynski and later revised by Bill J Gray.
 
 import (
 	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -89,10 +88,7 @@ func GetLong(ephem *jplEphData, value int) int64 {
 		if tval >= 0 && tval < 45 { // IPT array indices range 0-44 (15x3)
 			rval = int64(ephem.ipt[tval/3][tval%3]) // Access IPT array: ipt[row][column]
 		} else {
-			rval = -1 // Invalid IPT array index
-			if rval == -1 {
-				panic("Assertion failed: rval == -1 - Invalid JPL_EPHEM_IPT_ARRAY index") // Panic for assertion failure in Go
-			}
+			rval = -1 // Invalid value code or IPT array index
 		}
 	}
 	return rval
@@ -120,6 +116,22 @@ func GetLong(ephem *jplEphData, value int) int64 {
 //   - JPL_EPH_QUANTITY_NOT_IN_EPHEMERIS if requested quantity (nutations, librations, TT-TDB) is not in the ephemeris file.
 //   - JPL_EPH_INVALID_INDEX if target or center body index is invalid.
 func Pleph(ephem *jplEphData, et float64, ntarg int, ncent int, calcVelocity int) ([]float64, error) {
+	return plephSplit(ephem, et, 0, ntarg, ncent, calcVelocity)
+}
+
+// plephSplit is Pleph for a two-part date et = jd1 + jd2 (see recordLocationSplit).
+func plephSplit(ephem *jplEphData, jd1, jd2 float64, ntarg int, ncent int, calcVelocity int) ([]float64, error) {
+	rrd := make([]float64, 6)
+	if err := plephInto(ephem, jd1, jd2, ntarg, ncent, calcVelocity, rrd, false); err != nil {
+		return nil, err
+	}
+	return rrd, nil
+}
+
+// plephInto is plephSplit writing into rrd (6 values), which CalculatePV keeps on the stack,
+// in km and km/day instead of AU and AU/day when km is set. On failure rrd may hold partial
+// results.
+func plephInto(ephem *jplEphData, jd1, jd2 float64, ntarg int, ncent int, calcVelocity int, rrd []float64, km bool) error {
 
 	var pv [13][6]float64 // Position/velocity array for 13 bodies (0-12).
 	// 0=Mercury, 1=Venus,..., 8=Pluto, 9=Moon, 10=Sun, 11=SSBary, 12=EMBary
@@ -132,13 +144,13 @@ func Pleph(ephem *jplEphData, et float64, ntarg int, ncent int, calcVelocity int
 	var i uint
 	var list [14]int // List of bodies for which to calculate ephemeris values in State().
 	// 0=Mercury, 1=Venus, 2=EMBary,..., 8=Pluto, 9=geocentric Moon, 10=nutations in
-	// long. & obliq., 11= lunar librations, 12 = TT-TDB, 13=lunar mantle omegas
+	// long. & obliq., 11= lunar librations, 12=lunar mantle omegas, 13 = TT-TDB
 
 	// Initialize output array
-	rrd := make([]float64, 6)
+	clear(rrd[:6])
 
 	if ntarg == ncent { // Relative position/velocity is zero if target and center are the same
-		return rrd, nil
+		return nil
 	}
 	for i = 0; i < uint(len(list)); i++ {
 		list[i] = 0
@@ -147,18 +159,18 @@ func Pleph(ephem *jplEphData, et float64, ntarg int, ncent int, calcVelocity int
 		if ntarg == int(i)+14 {
 			if ephem.ipt[i+11][1] > 0 {
 				list[i+10] = listVal
-				err := State(ephem, et, list, &pv, rrd, 0)
+				err := stateSplit(ephem, jd1, jd2, list, &pv, rrd, 0, 0, km)
 				if err != nil {
-					return nil, err
+					return err
 				}
 			} else {
-				return nil, ErrQuantityNotInEphemeris
+				return ErrQuantityNotInEphemeris
 			}
-			return rrd, nil
+			return nil
 		}
 	}
 	if ntarg > 13 || ncent > 13 || ntarg < 1 || ncent < 1 {
-		return nil, ErrInvalidIndex
+		return ErrInvalidIndex
 	}
 
 	// Prepare list for State call to get barycentric positions
@@ -181,9 +193,13 @@ func Pleph(ephem *jplEphData, et float64, ntarg int, ncent int, calcVelocity int
 
 	// Call State to get barycentric positions and velocities
 	// Handle Sun, Solar System Barycenter, and Earth-Moon Barycenter cases
-	err := State(ephem, et, list, &pv, rrd, 1)
+	sun := 0
+	if ntarg == 11 || ncent == 11 {
+		sun = listVal
+	}
+	err := stateSplit(ephem, jd1, jd2, list, &pv, rrd, 1, sun, km)
 	if err != nil {
-		return rrd, err
+		return err
 	}
 	if ntarg == 11 || ncent == 11 {
 		for i = 0; i < 6; i++ {
@@ -225,7 +241,7 @@ func Pleph(ephem *jplEphData, et float64, ntarg int, ncent int, calcVelocity int
 	for i = 0; i < uint(listVal*3); i++ {
 		rrd[i] = pv[ntarg-1][i] - pv[ncent-1][i]
 	}
-	return rrd, nil
+	return nil
 }
 
 // interp interpolates Chebyshev coefficients to compute position, velocity, and optionally acceleration.
@@ -265,24 +281,20 @@ func interp(iinfo *interpolationInfo, coef []float64, t [2]float64, ncf uint, nc
 		panic("tc must be between -1 and 1") // Panic if normalized time is out of bounds
 	}
 
-	// Recurrence relation for Chebyshev polynomials T_i(tc)
-	if tc != iinfo.posnCoeff[1] { // Recompute Chebyshev polynomials if tc has changed
-		iinfo.nPosnAvail = 2
-		iinfo.nVelAvail = 2
-		iinfo.posnCoeff[1] = tc
-		iinfo.twot = tc + tc // 2*tc for efficiency in recurrence
-		if debugFlag {
-			fmt.Printf("interp: tc changed, iinfo.nPosnAvail = %d, iinfo.nVelAvail = %d, iinfo.posnCoeff[1] = %f, iinfo.twot = %f\n", iinfo.nPosnAvail, iinfo.nVelAvail, iinfo.posnCoeff[1], iinfo.twot)
-		}
-	}
+	// Recurrence relation for Chebyshev polynomials T_i(tc), reusing those already computed at tc
+	cv := iinfo.values(tc)
 
-	if iinfo.nPosnAvail < ncf { // Compute Chebyshev polynomials up to ncf if needed
+	if cv.nPosnAvail < ncf { // Compute Chebyshev polynomials up to ncf if needed
 		for i = 2; i < ncf; i++ {
-			iinfo.posnCoeff[i] = iinfo.twot*iinfo.posnCoeff[i-1] - iinfo.posnCoeff[i-2] // T_{n+1} = 2tc*T_n - T_{n-1}
+			if iinfo.fortranOrder {
+				cv.posnCoeff[i] = float64(cv.twot*cv.posnCoeff[i-1]) - cv.posnCoeff[i-2]
+				continue
+			}
+			cv.posnCoeff[i] = cv.twot*cv.posnCoeff[i-1] - cv.posnCoeff[i-2] // T_{n+1} = 2tc*T_n - T_{n-1}
 		}
-		iinfo.nPosnAvail = ncf
+		cv.nPosnAvail = ncf
 		if debugFlag {
-			fmt.Printf("interp: Updated iinfo.posnCoeff, iinfo.nPosnAvail = %d\n", iinfo.nPosnAvail)
+			fmt.Printf("interp: Updated cv.posnCoeff, cv.nPosnAvail = %d\n", cv.nPosnAvail)
 		}
 	}
 
@@ -290,8 +302,15 @@ func interp(iinfo *interpolationInfo, coef []float64, t [2]float64, ncf uint, nc
 	for i = 0; i < ncm; i++ { // Interpolate position components
 		coeffPtr := coef[ncf*(i+l*ncm):] // Pointer to coefficients for current component and sub-interval
 		posvel[posvelIndex] = 0.0
-		for j = 0; j < ncf; j++ {
-			posvel[posvelIndex] += iinfo.posnCoeff[j] * coeffPtr[j] // Sum of coefficients * Chebyshev polynomials
+		switch {
+		case iinfo.fortranOrder:
+			posvel[posvelIndex] = fortranDot(cv.posnCoeff[:ncf], coeffPtr[:ncf])
+		case iinfo.compensated:
+			posvel[posvelIndex] = compensatedDot(cv.posnCoeff[:ncf], coeffPtr[:ncf])
+		default:
+			for j = 0; j < ncf; j++ {
+				posvel[posvelIndex] += cv.posnCoeff[j] * coeffPtr[j] // Sum of coefficients * Chebyshev polynomials
+			}
 		}
 		posvelIndex++
 		if debugFlag {
@@ -307,13 +326,20 @@ func interp(iinfo *interpolationInfo, coef []float64, t [2]float64, ncf uint, nc
 	}
 
 	// Recurrence relation for derivatives of Chebyshev polynomials T'_i(tc)
-	if iinfo.nVelAvail < ncf { // Compute derivative Chebyshev polynomials up to ncf if needed
+	if cv.nVelAvail < ncf { // Compute derivative Chebyshev polynomials up to ncf if needed
 		for i = 2; i < ncf; i++ {
-			iinfo.velCoeff[i] = iinfo.twot*iinfo.velCoeff[i-1] + 2*iinfo.posnCoeff[i-1] - iinfo.velCoeff[i-2] // T'_{n+1} = 2tc*T'_n + 2T_n - T'_{n-1}
+			if iinfo.fortranOrder { // VC(3) = TWOT+TWOT, then VC(I) = TWOT*VC(I-1) + PC(I-1) + PC(I-1) - VC(I-2)
+				cv.velCoeff[i] = cv.twot + cv.twot
+				if i > 2 {
+					cv.velCoeff[i] = ((float64(cv.twot*cv.velCoeff[i-1]) + cv.posnCoeff[i-1]) + cv.posnCoeff[i-1]) - cv.velCoeff[i-2]
+				}
+				continue
+			}
+			cv.velCoeff[i] = cv.twot*cv.velCoeff[i-1] + 2*cv.posnCoeff[i-1] - cv.velCoeff[i-2] // T'_{n+1} = 2tc*T'_n + 2T_n - T'_{n-1}
 		}
-		iinfo.nVelAvail = ncf
+		cv.nVelAvail = ncf
 		if debugFlag {
-			fmt.Printf("interp: Updated iinfo.velCoeff, iinfo.nVelAvail = %d\n", iinfo.nVelAvail)
+			fmt.Printf("interp: Updated cv.velCoeff, cv.nVelAvail = %d\n", cv.nVelAvail)
 		}
 	}
 
@@ -321,8 +347,15 @@ func interp(iinfo *interpolationInfo, coef []float64, t [2]float64, ncf uint, nc
 	for i = 0; i < ncm; i++ { // Interpolate velocity components
 		tval := 0.0
 		coeffPtr := coef[ncf*(i+l*ncm):] // Pointer to coefficients for current component and sub-interval
-		for j = 1; j < ncf; j++ {        // Sum of coefficients (starting from j=1) * derivative Chebyshev polynomials
-			tval += iinfo.velCoeff[j] * coeffPtr[j]
+		switch {
+		case iinfo.fortranOrder:
+			tval = fortranDot(cv.velCoeff[1:ncf], coeffPtr[1:ncf])
+		case iinfo.compensated:
+			tval = compensatedDot(cv.velCoeff[1:ncf], coeffPtr[1:ncf])
+		default:
+			for j = 1; j < ncf; j++ { // Sum of coefficients (starting from j=1) * derivative Chebyshev polynomials
+				tval += cv.velCoeff[j] * coeffPtr[j]
+			}
 		}
 		posvel[posvelIndex] = tval * vfac // Scale velocity by vfac
 		posvelIndex++
@@ -332,17 +365,21 @@ func interp(iinfo *interpolationInfo, coef []float64, t [2]float64, ncf uint, nc
 	}
 
 	if velocityFlag == 3 { // Calculate acceleration if velocityFlag is 3 (for pvsun)
-		accelCoeffs := make([]float64, maxCheby) // Array to store second derivatives of Chebyshev polynomials
+		var accelCoeffs [maxCheby]float64 // Second derivatives of the Chebyshev polynomials, on the stack
 		accelCoeffs[0] = 0.0
 		accelCoeffs[1] = 0.0
 		for i = 2; i < ncf; i++ {
-			accelCoeffs[i] = 4.0*iinfo.velCoeff[i-1] + iinfo.twot*accelCoeffs[i-1] - accelCoeffs[i-2] // T''_{n+1} = 2tc*T''_n + 4T'_n - T''_{n-1}
+			accelCoeffs[i] = 4.0*cv.velCoeff[i-1] + cv.twot*accelCoeffs[i-1] - accelCoeffs[i-2] // T''_{n+1} = 2tc*T''_n + 4T'_n - T''_{n-1}
 		}
 		for i = 0; i < ncm; i++ { // Interpolate acceleration components
 			tval := 0.0
 			coeffPtr := coef[ncf*(i+l*ncm):] // Pointer to coefficients for current component and sub-interval
-			for j = 2; j < ncf; j++ {        // Sum of coefficients (starting from j=2) * second derivative Chebyshev polynomials
-				tval += accelCoeffs[j] * coeffPtr[j]
+			if iinfo.compensated {
+				tval = compensatedDot(accelCoeffs[2:ncf], coeffPtr[2:ncf])
+			} else {
+				for j = 2; j < ncf; j++ { // Sum of coefficients (starting from j=2) * second derivative Chebyshev polynomials
+					tval += accelCoeffs[j] * coeffPtr[j]
+				}
 			}
 			posvel[posvelIndex] = tval * vfac * vfac // Scale acceleration by vfac^2
 			posvelIndex++
@@ -369,6 +406,34 @@ func quantityDimension(idx int) int {
 	}
 }
 
+// validateExtendedIPT clears the lunar mantle (ipt[13]) and TT-TDB (ipt[14]) entries that do
+// not fit the record layout. The quantities of a record are stored contiguously in IPT order,
+// so each entry present must start where the coefficients of the preceding quantities end.
+// The entries are checked one by one: a file with TT-TDB but no mantle angular velocities, or
+// without librations, keeps what it has. Entries read from the header bytes of older files,
+// which are not offsets at all, fail the check.
+func validateExtendedIPT(ipt *[15][3]uint32) {
+	end := uint32(3) // Offsets count doubles from 1; the first two hold the record time span
+	for i := 0; i < 13; i++ {
+		if n := ipt[i][1] * ipt[i][2]; n > 0 && ipt[i][0] > 0 {
+			if e := ipt[i][0] + n*uint32(quantityDimension(i)); e > end {
+				end = e
+			}
+		}
+	}
+	for i := 13; i < 15; i++ {
+		n := ipt[i][1] * ipt[i][2]
+		if n == 0 || ipt[i][0] != end || ipt[i][1] > maxCheby {
+			if ipt[i] != [3]uint32{} && debugFlag {
+				fmt.Printf("InitEphemeris: Ignoring ipt[%d] = %v, coefficients were expected at %d\n", i, ipt[i], end)
+			}
+			ipt[i] = [3]uint32{}
+			continue
+		}
+		end += n * uint32(quantityDimension(i))
+	}
+}
+
 // State calculates and interpolates ephemeris data for specified bodies at a given time.
 //
 // Parameters:
@@ -378,15 +443,17 @@ func quantityDimension(idx int) int {
 //     list[i]=0: no interpolation for body i, 1: position only, 2: position and velocity.
 //   - pv: Pointer to a [13][6] double array to store interpolated position and velocity vectors.
 //     pv[i][0]=x, pv[i][1]=y, pv[i][2]=z, pv[i][3]=dx, pv[i][4]=dy, pv[i][5]=dz for body i.
-//   - nut: Slice of 4 doubles to store nutations and rates if list[10] is set.
+//   - nut: Slice of up to 6 doubles receiving the special quantity selected by list[10..13],
+//     its components followed by their rates. For nutations (list[10]):
 //     nut[0]=d psi (nutation in longitude), nut[1]=d epsilon (nutation in obliquity),
-//     nut[2]=d psi dot, nut[3]=d epsilon dot.
-//   - bary: Flag (non-zero to output heliocentric positions, 0 for solar-system barycentric).
+//     nut[2]=d psi dot, nut[3]=d epsilon dot. All special quantities selected are written to
+//     nut, so select one per call; Ephemeris.RawState returns each in its own field.
+//   - bary: Flag (non-zero for solar-system barycentric positions, 0 for heliocentric planets and Earth-moon barycenter).
 //
 // Body Indices for 'list' array:
 //
 //	0: Mercury, 1: Venus, 2: Earth-moon barycenter, 3: Mars, 4: Jupiter, 5: Saturn, 6: Uranus,
-//	7: Neptune, 8: Pluto, 9: geocentric moon, 10: nutations, 11: lunar librations, 12: TT-TDB, 13: lunar mantle omegas.
+//	7: Neptune, 8: Pluto, 9: geocentric moon, 10: nutations, 11: lunar librations, 12: lunar mantle omegas, 13: TT-TDB.
 //
 // Returns:
 //   - 0 on success.
@@ -394,75 +461,54 @@ func quantityDimension(idx int) int {
 //   - JPL_EPH_FSEEK_ERROR if file seek operation fails.
 //   - JPL_EPH_READ_ERROR if file read operation fails.
 func State(ephem *jplEphData, et float64, list [14]int, pv *[13][6]float64, nut []float64, bary int) error {
+	return stateSplit(ephem, et, 0, list, pv, nut, bary, 0, false)
+}
+
+// stateSplit is State for a two-part date et = jd1 + jd2 (see recordLocationSplit), which
+// also leaves the barycentric state of the Sun in ephem.pvsun to the order sun (0 for none,
+// 1 for positions, 2 with velocities, 3 with accelerations). The Sun is interpolated only
+// when sun or heliocentric output asks for it, and reused when one of the last sunSlots
+// states was computed to that order at the same record and time within it. With km, the
+// states of the bodies are left in the km and km/day of the file instead of being divided
+// by the AU.
+func stateSplit(ephem *jplEphData, jd1, jd2 float64, list [14]int, pv *[13][6]float64, nut []float64, bary, sun int, km bool) error {
 	if debugFlag {
 		fmt.Println("State: Entered")
-		fmt.Printf("State: et = %f, list = %v, bary = %d\n", et, list, bary)
+		fmt.Printf("State: jd1 = %f, jd2 = %g, list = %v, bary = %d, km = %t\n", jd1, jd2, list, bary, km)
 	}
 	var i, j uint
 	var nIntervals uint
-	buf := ephem.cache                                    // Cache buffer for ephemeris data
-	var t [2]float64                                      // Time parameters for interpolation
-	blockLoc := (et - ephem.ephemStart) / ephem.ephemStep // Time block location in ephemeris file
-	recomputePvsun := false                               // Flag to control recomputation of Sun's state
-	aufac := 1.0 / ephem.au                               // Conversion factor from km to AU
-
-	// Error return for epoch out of range
-	if et < ephem.ephemStart || et > ephem.ephemEnd {
+	buf := ephem.cache      // Cache buffer for ephemeris data
+	var t [2]float64        // Time parameters for interpolation
+	aufac := 1.0 / ephem.au // Conversion factor from km to AU
+
+	// Locate the record covering et and make sure it is in the cache
+	nr, frac, err := recordLocationSplit(ephem, jd1, jd2)
+	if err != nil {
 		if debugFlag {
 			fmt.Println("State: Error - Epoch out of range")
 		}
-		return ErrOutsideRange
-	}
-
-	// Calculate record number and relative time within the interval
-	nr := uint32(blockLoc)        // Record number (integer part of blockLoc)
-	t[0] = blockLoc - float64(nr) // Fractional time within the interval (0 <= t[0] < 1)
-	if t[0] == 0 && nr != 0 {     // Handle case when t[0] is exactly 0, except for the very first interval
-		t[0] = 1.0
-		nr--
+		return err
 	}
-	if nr != ephem.currCacheLoc {
-		ephem.currCacheLoc = nr
-		_, err := ephem.ifile.Seek(int64((nr+2)*ephem.recsize), io.SeekStart)
-		if err != nil {
-			if debugFlag {
-				fmt.Printf("State: Error - Seek error: %v\n", err)
-			}
-			return ErrFileSeek
-		}
-		err = binary.Read(ephem.ifile, defaultByteOrder, buf) // Read record into cache buffer
-		if err != nil {
-			if debugFlag {
-				fmt.Printf("State: Error - Read error: %v\n", err)
-			}
-			return ErrFileRead
-		}
-		if ephem.swapBytes != 0 {
-			swapBytes64Slice(buf) // Byte-swap if needed
-		}
-		if debugFlag {
-			fmt.Println("State: Read block from file, first 10 values of buf:")
-			for k := 0; k < 10 && k < len(buf); k++ {
-				fmt.Printf("State: buf[%d] = %e\n", k, buf[k])
-			}
-		}
+	t[0] = frac
+	if err := loadRecord(ephem, nr); err != nil {
+		return err
 	}
 	t[1] = ephem.ephemStep // Set interval length
 
-	if ephem.pvsunT != et { // Check if Sun's state needs recomputation for the current time
-		recomputePvsun = true // Recompute Sun's state if time has changed
-		ephem.pvsunT = et     // Update last computed time for Sun's state
-	} else {
-		recomputePvsun = false // No need to recompute if time is the same
+	if bary == 0 { // Heliocentric planets need the Sun to the order of the planets
+		for i = 0; i < 9; i++ {
+			sun = max(sun, list[i])
+		}
 	}
+	recomputePvsun := sun > 0 && !ephem.reuseSun(nr, frac, sun, km)
 
 	// Here, i loops through the "traditional" 14 listed items -- 10
 	// solar system objects,  nutations,  librations,  lunar mantle angles,
 	// and TT-TDT -- plus a fifteenth:  the solar system barycenter.  That
 	// last is quite different:  it's computed 'as needed',  rather than
 	// from list[];  the output goes to pvsun rather than the pv array;
-	// and three quantities (position,  velocity,  acceleration) are
-	// computed (nobody else gets accelerations at present.)
+	// and it can include accelerations (nobody else gets them.)
 	for nIntervals = 1; nIntervals <= 8; nIntervals *= 2 {
 		for i = 0; i < 15; i++ { // Loop through bodies and special quantities (15 total items)
 			var quantities int
@@ -470,7 +516,7 @@ func State(ephem *jplEphData, et float64, list [14]int, pv *[13][6]float64, nut
 
 			if i == 14 { // Special case for Solar System Barycenter (index 14 is SSB in this loop)
 				if recomputePvsun { // Only compute if needed
-					quantities = 3 // Position, velocity, acceleration for Sun
+					quantities = sun // Order requested for the Sun
 				}
 				iptr = &ephem.ipt[10] // IPT entry for Sun
 			} else {
@@ -499,14 +545,21 @@ func State(ephem *jplEphData, et float64, list [14]int, pv *[13][6]float64, nut
 				// Call Chebyshev interpolation function
 				interp(&ephem.iinfo, buf[(*iptr)[0]-1:], t, uint((*iptr)[1]), uint(quantityDimension(int(i)+1)), nIntervals, quantities, dest)
 
-				if i < 10 || i == 14 { // Convert km to AU for planets, moon, and sun
+				if (i < 10 || i == 14) && !km { // Convert km to AU for planets, moon, and sun
 					for j = 0; j < uint(quantities*3); j++ {
-						dest[j] *= aufac // Apply AU conversion factor
+						if ephem.iinfo.compensated {
+							dest[j] /= ephem.au // One rounding instead of two through aufac
+						} else {
+							dest[j] *= aufac // Apply AU conversion factor
+						}
 					}
 				}
 			}
 		}
 	}
+	if recomputePvsun {
+		ephem.keepSun(nr, frac, sun, km)
+	}
 	if bary == 0 { // Correct for solar system barycenter if barycentric output is requested (bary == 0)
 		for i = 0; i < 9; i++ { // Loop through planets (Mercury to Pluto)
 			for j = 0; j < uint(list[i]*3); j++ {
@@ -520,12 +573,153 @@ func State(ephem *jplEphData, et float64, list [14]int, pv *[13][6]float64, nut
 	return nil
 }
 
+// reuseSun copies into pvsun a kept state of the Sun interpolated at time frac of record nr,
+// to at least the given order and in the given units, and reports whether there was one.
+func (ephem *jplEphData) reuseSun(nr uint32, frac float64, order int, km bool) bool {
+	for k := range ephem.suns {
+		s := &ephem.suns[k]
+		if s.order >= order && s.record == nr && s.frac == frac && s.km == km {
+			ephem.pvsun = s.pv
+			return true
+		}
+	}
+	return false
+}
+
+// keepSun keeps the state of the Sun just interpolated into pvsun. It replaces a state of
+// lower order at the same time, or else the oldest slot.
+func (ephem *jplEphData) keepSun(nr uint32, frac float64, order int, km bool) {
+	slot := -1
+	for k := range ephem.suns {
+		s := &ephem.suns[k]
+		if s.order > 0 && s.record == nr && s.frac == frac && s.km == km {
+			slot = k
+			break
+		}
+	}
+	if slot < 0 {
+		slot = ephem.nextSun
+		ephem.nextSun = (ephem.nextSun + 1) % sunSlots
+	}
+	ephem.suns[slot] = sunState{record: nr, frac: frac, order: order, km: km, pv: ephem.pvsun}
+}
+
+// recordLocation returns the number of the data record covering et and the fractional
+// time within that record (0 <= t <= 1). Epochs falling exactly on a record boundary are
+// assigned to the end of the preceding record, except for the very first record.
+//
+// Returns:
+//   - uint32: Record number (0 for the first data record).
+//   - float64: Fractional time within the record.
+//   - error: ErrOutsideRange if et is outside the ephemeris time range.
+func recordLocation(ephem *jplEphData, et float64) (uint32, float64, error) {
+	return recordLocationSplit(ephem, et, 0)
+}
+
+// recordLocationSplit is recordLocation for a two-part date et = jd1 + jd2, such as a whole
+// or half-integer day number and a fraction. The offset from the start of the file is kept
+// as an exact two-part sum and the record number as an integer, and only the time within
+// the record is reduced to one float64. Dividing the rounded offset instead loses up to
+// 1e-9 day (about 80 microseconds) at the epochs of DE431/DE441 (|et| ~ 8e6), and can put
+// epochs next to a record boundary into the wrong record.
+func recordLocationSplit(ephem *jplEphData, jd1, jd2 float64) (uint32, float64, error) {
+	if ephem.iinfo.fortranOrder {
+		return fortranRecordLocation(ephem, jd1, jd2)
+	}
+	step := ephem.ephemStep
+	hi := jd1 - ephem.ephemStart
+	b := hi - jd1
+	lo := (jd1 - (hi - b)) + (-ephem.ephemStart - b) + jd2 // Exact rounding error of hi (TwoSum), plus the low part
+	if hi+lo < 0 || hi+lo > ephem.ephemEnd-ephem.ephemStart {
+		return 0, 0, ErrOutsideRange
+	}
+	nr := math.Floor(hi / step)
+	rem := math.FMA(-nr, step, hi) + lo // Days into record nr; exact for the power-of-two steps of the DE files
+	for rem < 0 && nr > 0 {
+		nr--
+		rem += step
+	}
+	for rem >= step {
+		nr++
+		rem -= step
+	}
+	frac := math.Max(rem/step, 0) // Fractional time within the interval (0 <= frac < 1)
+	last := math.Round((ephem.ephemEnd - ephem.ephemStart) / step)
+	if nr >= last { // The end of the file belongs to the last record
+		nr, frac = last, 0
+	}
+	if frac == 0 && nr != 0 { // Handle case when frac is exactly 0, except for the very first interval
+		frac = 1.0
+		nr--
+	}
+	return uint32(nr), frac, nil
+}
+
+// loadRecord reads data record nr into the coefficient cache, unless it is already cached.
+//
+// Returns:
+//   - error: ErrFileSeek or ErrFileRead if the record cannot be read (see WithReadRetry).
+func loadRecord(ephem *jplEphData, nr uint32) error {
+	if nr == ephem.currCacheLoc {
+		return nil
+	}
+	buf := ephem.cache
+	if rec, ok := ephem.preloaded[nr]; ok { // Read ahead by PreloadRange, already byte-swapped
+		copy(buf, rec)
+		ephem.currCacheLoc = nr
+		return nil
+	}
+	if err := readRecord(ephem, nr, buf); err != nil {
+		ephem.currCacheLoc = uint32(4294967295) // The cache holds no valid record, so a later call reads again
+		return err
+	}
+	ephem.currCacheLoc = nr
+	if ephem.swapBytes != 0 {
+		swapBytes64Slice(buf) // Byte-swap if needed
+	}
+	if debugFlag {
+		fmt.Println("State: Read block from file, first 10 values of buf:")
+		for k := 0; k < 10 && k < len(buf); k++ {
+			fmt.Printf("State: buf[%d] = %e\n", k, buf[k])
+		}
+	}
+	return nil
+}
+
 // start400ThConstantName is the file offset to the names of constants beyond the first 400.
 const start400ThConstantName = (84*3 + 400*6 + 5*8 + 41*4) // START_400TH_CONSTANT_NAME
 
 // jplHeaderSize is the size of the JPL ephemeris header in bytes.
 const jplHeaderSize = (5*8 + 41*4) // JPL_HEADER_SIZE
 
+// maxConstants is the largest number of header constants accepted; the files of the JPL have
+// under a thousand, and larger counts are read from corrupt or foreign files.
+const maxConstants = 65536
+
+// kernelSizeOf returns the size of a data record in 4-byte words for an IPT.
+func kernelSizeOf(ipt *[15][3]uint32) uint32 {
+	size := uint32(4) // The two epochs of the record
+	for i := range ipt {
+		size += 2 * ipt[i][1] * ipt[i][2] * uint32(quantityDimension(i)) // Sum of coefficients for each quantity
+	}
+	return size
+}
+
+// truncated marks the end of the data with ErrTruncatedFile, and returns other read errors
+// as they are.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w (%w)", ErrTruncatedFile, err)
+	}
+	return err
+}
+
+// isConstantName reports whether a 6-byte field holds a constant name: it must not contain
+// a NUL byte (as in the C version) nor be blank.
+func isConstantName(b []byte) bool {
+	return bytes.IndexByte(b, 0) < 0 && len(bytes.TrimSpace(b)) > 0
+}
+
 // initEphemeris initializes the JPL ephemeris data from a binary ephemeris file.
 //
 // Parameters:
@@ -540,69 +734,92 @@ func initEphemeris(ephemerisFilename string, nam [][6]byte, val []float64) (*jpl
 	if debugFlag {
 		fmt.Println("InitEphemeris: Entered, filename:", ephemerisFilename)
 	}
-	var i, j uint
-	var deVersion int64
-	title := make([]byte, 84)                // Buffer for ephemeris title
 	ifile, err := os.Open(ephemerisFilename) // Open ephemeris file
 	if err != nil {
 		if debugFlag {
 			fmt.Printf("InitEphemeris: Error opening file: %v\n", err)
 		}
-		return nil, fmt.Errorf("failed to open ephemeris file: %w", err)
+		return nil, initFailed(JPL_INIT_FILE_NOT_FOUND, fmt.Errorf("failed to open ephemeris file: %w", err))
 	}
+	rval, err := initEphemerisFile(ifile, ephemerisFilename, nil, nam, val)
+	if err != nil {
+		ifile.Close()
+		return nil, err
+	}
+	return rval, nil
+}
 
-	rval := &jplEphData{ifile: ifile, pvsunT: -1e+80} // Allocate and initialize jplEphData structure
-	tempData := rval                                  // Temporary pointer for easier access to struct fields
+// initEphemerisFile initializes the JPL ephemeris data from an opened ephemeris file, which
+// may be any seekable source of the file contents.
+//
+// Parameters:
+//   - ifile: The opened ephemeris file; it is owned by the returned data.
+//   - ephemerisFilename: Path of the file, or a descriptive name for other sources.
+//   - open: Function opening another handle of the same contents for clones, or nil to reopen the path.
+//   - nam: Optional [][6]byte array to store constant names (pass nil if not needed).
+//   - val: Optional []float64 slice to store constant values (pass nil if not needed).
+//
+// Returns:
+//   - Interface to the initialized ephemeris data (jplEphData) on success, nil on failure.
+//   - Error if initialization fails; the caller still owns ifile in that case.
+func initEphemerisFile(ifile io.ReadSeekCloser, ephemerisFilename string, open func() (io.ReadSeekCloser, error),
+	nam [][6]byte, val []float64) (*jplEphData, error) {
+	var deVersion int64
 
-	// Read ephemeris title (first 84 bytes)
-	n, err := ifile.Read(title)
-	if n != 84 || (err != nil && !errors.Is(err, io.EOF)) {
-		if debugFlag {
-			fmt.Printf("InitEphemeris: Error reading title: %v\n", err)
-		}
-		return nil, fmt.Errorf("fread title failed: %w", err)
-	}
-	// Seek to header data location (byte 2652)
-	_, err = ifile.Seek(2652, io.SeekStart)
+	rval := &jplEphData{ifile: ifile, filename: ephemerisFilename, open: open} // Allocate and initialize jplEphData structure
+	tempData := rval                                                           // Temporary pointer for easier access to struct fields
+
+	// Read the title lines, the names of the first 400 constants, and the header in one pass
+	_, err := ifile.Seek(0, io.SeekStart)
 	if err != nil {
 		if debugFlag {
 			fmt.Printf("InitEphemeris: Error seeking to header: %v\n", err)
 		}
-		return nil, fmt.Errorf("fseek failed: %w", err)
+		return nil, initFailed(JPL_INIT_FSEEK_FAILED, fmt.Errorf("fseek failed: %w", err))
 	}
-
-	header := make([]byte, jplHeaderSize) // Buffer for header data
-	// Read header data (jplHeaderSize bytes)
-	n, err = ifile.Read(header)
-	if n != len(header) || (err != nil && !errors.Is(err, io.EOF)) {
+	head := make([]byte, start400ThConstantName) // Title (3*84), names (400*6), and header data
+	if _, err = io.ReadFull(ifile, head); err != nil {
 		if debugFlag {
 			fmt.Printf("InitEphemeris: Error reading header: %v\n", err)
 		}
-		return nil, fmt.Errorf("fread header failed: %w", err)
+		return nil, initFailed(JPL_INIT_FREAD_FAILED, fmt.Errorf("fread header failed: %w", truncated(err)))
+	}
+	title := head[:84]                        // Ephemeris title
+	header := head[2652 : 2652+jplHeaderSize] // Header data (byte 2652)
+
+	// Check if byte swapping is needed based on ncon value
+	tempData.swapBytes = 0
+	if uInt32FromBytes(header[24:28]) > 65536 { // Heuristic to detect wrong byte order
+		tempData.swapBytes = 1 // Set swap flag
+	}
+	decodeUint32 := func(b []byte) uint32 { // uint32 in the byte order of the file
+		v := uInt32FromBytes(b)
+		if tempData.swapBytes != 0 {
+			swapBytes32(&v)
+		}
+		return v
+	}
+	decodeFloat64 := func(b []byte) float64 { // float64 in the byte order of the file
+		v := float64FromBytes(b)
+		if tempData.swapBytes != 0 {
+			swapBytes64(&v)
+		}
+		return v
 	}
+
 	// Parse header data
-	tempData.ephemStart = float64FromBytes(header[0:8])  // Ephemeris start time (JD)
-	tempData.ephemEnd = float64FromBytes(header[8:16])   // Ephemeris end time (JD)
-	tempData.ephemStep = float64FromBytes(header[16:24]) // Ephemeris step size (days)
-	tempData.ncon = uInt32FromBytes(header[24:28])       // Number of constants
-	tempData.au = float64FromBytes(header[28:36])        // Astronomical Unit (km)
-	tempData.emrat = float64FromBytes(header[36:44])     // Earth-Moon mass ratio
+	tempData.ephemStart = decodeFloat64(header[0:8])  // Ephemeris start time (JD)
+	tempData.ephemEnd = decodeFloat64(header[8:16])   // Ephemeris end time (JD)
+	tempData.ephemStep = decodeFloat64(header[16:24]) // Ephemeris step size (days)
+	tempData.ncon = decodeUint32(header[24:28])       // Number of constants
+	tempData.au = decodeFloat64(header[28:36])        // Astronomical Unit (km)
+	tempData.emrat = decodeFloat64(header[36:44])     // Earth-Moon mass ratio
+	tempData.headerAU = tempData.au                   // Kept when WithAU sets au
 
 	// Parse IPT array (interpolation parameters table)
 	for i := 0; i < 40; i++ {
 		offset := 44 + i*4
-		tempData.ipt[i/3][i%3] = uInt32FromBytes(header[offset : offset+4]) // IPT[row][column]
-	}
-	// Check if byte swapping is needed based on ncon value
-	tempData.swapBytes = 0
-	if tempData.ncon > 65536 { // Heuristic to detect wrong byte order
-		tempData.swapBytes = 1 // Set swap flag
-		swapBytes32(&tempData.ncon)
-		swapBytes64(&tempData.ephemStart)
-		swapBytes64(&tempData.ephemEnd)
-		swapBytes64(&tempData.ephemStep)
-		swapBytes64(&tempData.au)
-		swapBytes64(&tempData.emrat)
+		tempData.ipt[i/3][i%3] = decodeUint32(header[offset : offset+4]) // IPT[row][column]
 	}
 	// Parse DE version and ephemeris name from title string
 	if bytes.HasPrefix(title, []byte("INPOP")) { // INPOP ephemeris format
@@ -619,7 +836,7 @@ func initEphemeris(ephemerisFilename string, nam [][6]byte, val []float64) (*jpl
 			if debugFlag {
 				fmt.Printf("InitEphemeris: Error parsing INPOP DE version: %v\n", err)
 			}
-			return nil, fmt.Errorf("atoi de_version (INPOP) failed for '%s': %w", deVersionStr[:i], err)
+			return nil, initFailed(JPL_INIT_FILE_CORRUPT, fmt.Errorf("atoi de_version (INPOP) failed for '%s': %w", deVersionStr[:i], err))
 		}
 		nameBytes := title[:30]                                      // Ephemeris name bytes
 		if nullIdx := bytes.IndexByte(nameBytes, 0); nullIdx != -1 { // Remove null terminator if present
@@ -643,7 +860,7 @@ func initEphemeris(ephemerisFilename string, nam [][6]byte, val []float64) (*jpl
 			if debugFlag {
 				fmt.Printf("InitEphemeris: Error parsing non-INPOP DE version: %v\n", err)
 			}
-			return nil, fmt.Errorf("atoi de_version failed for '%s': %w", deVersionStr[:i], err)
+			return nil, initFailed(JPL_INIT_FILE_CORRUPT, fmt.Errorf("atoi de_version failed for '%s': %w", deVersionStr[:i], err))
 		}
 		nameBytes := title[24:54]                                    // Ephemeris name bytes
 		if nullIdx := bytes.IndexByte(nameBytes, 0); nullIdx != -1 { // Remove null terminator if present
@@ -661,76 +878,60 @@ func initEphemeris(ephemerisFilename string, nam [][6]byte, val []float64) (*jpl
 	tempData.ipt[12][2] = tempData.ipt[13][0]
 	tempData.ephemerisVersion = uint64(deVersion) // Store DE version
 
-	// Handle TT-TDB data (present in DE430 and later, but not always reliable)
-	if deVersion >= 430 && tempData.ncon != 400 {
-		// Seek past constants if more than 400
-		if tempData.ncon > 400 {
-			_, err = ifile.Seek(int64(tempData.ncon-400)*6, io.SeekCurrent)
-			if err != nil {
-				if debugFlag {
-					fmt.Printf("InitEphemeris: Error seeking past 400 constants: %v\n", err)
-				}
-				return nil, fmt.Errorf("fseek failed after 400 constants: %w", err)
-			}
-		}
-		ipt1314Header := make([]byte, 6*4) // Buffer for IPT[13] and IPT[14] data
-		_, err = ifile.Read(ipt1314Header)
-		if err != nil && !errors.Is(err, io.EOF) {
-			if debugFlag {
-				fmt.Printf("InitEphemeris: Error reading ipt[13][0]: %v\n", err)
-			}
-			return nil, fmt.Errorf("fread ipt[13][0] failed: %w", err)
-		}
-		ipt1314Reader := strings.NewReader(string(ipt1314Header))
-		for i := 0; i < 6; i++ { // Read 6 integers for IPT[13] and IPT[14]
-			val32, err := getUint32(ipt1314Reader) // Helper function to read uint32 from string reader
-			if err != nil {
-				if debugFlag {
-					fmt.Printf("InitEphemeris: Error getting uint32 for ipt[%d][%d]: %v\n", (13+i)/3, (13+i)%3, err)
-				}
-				return nil, fmt.Errorf("getUint32 ipt[%d][%d] (13/14) failed: %w", (13+i)/3, (13+i)%3, err)
-			}
-			if i < 3 {
-				tempData.ipt[13][i] = val32 // IPT[13]
-			} else {
-				tempData.ipt[14][i-3] = val32 // IPT[14]
-			}
+	// Read the rest of the header region from the first record: the names of the constants
+	// beyond the 400th, then the lunar mantle angular velocity (ipt[13]) and TT-TDB (ipt[14])
+	// entries of the *t kernels. The entries are read for every file, INPOP included, and kept
+	// when they fit the record layout.
+	tempData.ipt[13] = [3]uint32{}
+	if tempData.ncon > maxConstants {
+		return nil, initFailed(JPL_INIT_FILE_CORRUPT, fmt.Errorf("ephemeris file corrupt: number of constants out of range: %d", tempData.ncon))
+	}
+	nExtra := int(max(tempData.ncon, 400) - 400) // Names stored after the header
+	tail := make([]byte, 6*nExtra+6*4)
+	if tempData.ncon == 400 { // Further names may follow the header: read up to the end of the first record
+		tail = make([]byte, max(int(4*kernelSizeOf(&tempData.ipt))-start400ThConstantName, 6*4))
+	}
+	n, err := io.ReadFull(ifile, tail)
+	switch {
+	case err == nil:
+	case (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) && n >= 6*nExtra: // Files too short to hold ipt[13] and ipt[14] have neither
+		tail = tail[:n]
+	default:
+		if debugFlag {
+			fmt.Printf("InitEphemeris: Error reading constant names (400+): %v\n", err)
 		}
-
-	} else { // Mark IPT[13] and IPT[14] as invalid if DE version < 430 or ncon == 400
-		tempData.ipt[13][0] = uint32(0) // Set to 0 as invalid
+		return nil, initFailed(JPL_INIT_FREAD4_FAILED, fmt.Errorf("fread constant names (400+) failed: %w", truncated(err)))
 	}
-
-	if tempData.swapBytes != 0 { // Byte swapping for IPT array (currently disabled)
-		for j = 0; j < 3; j++ {
-			for i = 0; i < 15; i++ {
-				swapBytes32(&tempData.ipt[i][j])
-			}
+	if tempData.ncon == 400 { // Count the extra names, up to the first one with a NUL byte or only blanks
+		for len(tail) >= 6*(nExtra+1) && isConstantName(tail[6*nExtra:6*nExtra+6]) {
+			nExtra++
 		}
+		tempData.ncon += uint32(nExtra)
 	}
-	// Sanity check for TT-TDB IPT data (cross-check indices)
-	if tempData.ipt[13][0] != (tempData.ipt[12][0]+tempData.ipt[12][1]*tempData.ipt[12][2]*3) ||
-		tempData.ipt[14][0] != (tempData.ipt[13][0]+tempData.ipt[13][1]*tempData.ipt[13][2]*3) {
-		// Zero out IPT[13] and IPT[14] if sanity check fails (likely garbage data)
-		for i = 13; i < 15; i++ {
-			for j = 0; j < 3; j++ {
-				tempData.ipt[i][j] = 0
-			}
+	if extended := tail[6*nExtra:]; len(extended) >= 6*4 {
+		for k := 0; k < 6; k++ {
+			tempData.ipt[13+k/3][k%3] = decodeUint32(extended[4*k : 4*k+4])
 		}
+	} else if debugFlag {
+		fmt.Printf("InitEphemeris: Only %d bytes for ipt[13] and ipt[14]\n", len(extended))
 	}
+	validateExtendedIPT(&tempData.ipt)
+
 	// Sanity check for Earth-Moon mass ratio
 	if tempData.emrat > 81.3008 || tempData.emrat < 81.30055 {
 		if debugFlag {
 			fmt.Printf("InitEphemeris: Error - Earth-Moon ratio out of range: %f\n", tempData.emrat)
 		}
-		return nil, fmt.Errorf("ephemeris file corrupt: Earth-Moon ratio out of range: %f", tempData.emrat)
+		swapped := tempData.emrat
+		swapBytes64(&swapped)
+		if swapped >= 81.30055 && swapped <= 81.3008 {
+			return nil, initFailed(JPL_INIT_FILE_CORRUPT, fmt.Errorf("%w: the Earth-Moon ratio reads correctly only with the bytes swapped", ErrByteOrder))
+		}
+		return nil, initFailed(JPL_INIT_FILE_CORRUPT, fmt.Errorf("ephemeris file corrupt: Earth-Moon ratio out of range: %f", tempData.emrat))
 	}
 
 	// Calculate kernel size, record size, and number of coefficients
-	tempData.kernelSize = 4 // Initial kernel size
-	for i = 0; i < 15; i++ {
-		tempData.kernelSize += 2 * tempData.ipt[i][1] * tempData.ipt[i][2] * uint32(quantityDimension(int(i))) // Sum of coefficients for each quantity
-	}
+	tempData.kernelSize = kernelSizeOf(&tempData.ipt)
 	tempData.recsize = tempData.kernelSize * 4 // Record size in bytes (kernel size * 4 bytes/double)
 	tempData.ncoeff = tempData.kernelSize / 2  // Number of coefficients (kernel size / 2 doubles/coefficient)
 
@@ -738,93 +939,82 @@ func initEphemeris(ephemerisFilename string, nam [][6]byte, val []float64) (*jpl
 	rval.cache = make([]float64, tempData.ncoeff)
 
 	// Initialize interpolation info structure
-	rval.iinfo.posnCoeff[0] = 1.0  // Initial Chebyshev polynomial values
-	rval.iinfo.posnCoeff[1] = -2.0 // Bogus initial value, corrected in interp()
-	rval.iinfo.velCoeff[0] = 0.0
-	rval.iinfo.velCoeff[1] = 1.0
+	rval.iinfo.reset()
 	rval.currCacheLoc = uint32(4294967295) // Initialize cache location to invalid value
 
-	// Handle constant names beyond 400 (if present)
-	if rval.ncon == 400 {
-		buff := make([]byte, 6)                                   // Buffer for constant name
-		_, err = ifile.Seek(start400ThConstantName, io.SeekStart) // Seek to start of extra constant names
-		if err != nil {
-			if debugFlag {
-				fmt.Printf("InitEphemeris: Error seeking to 400th constant name: %v\n", err)
-			}
-			return nil, fmt.Errorf("fseek to 400th constant name failed: %w", err)
-		}
-		for { // Read constant names until EOF or read error
-			n, err := ifile.Read(buff)
-			if err != nil && errors.Is(err, io.EOF) {
-				break // End of file
-			}
-			if err != nil {
-				if debugFlag {
-					fmt.Printf("InitEphemeris: Error reading constant name (400+): %v\n", err)
-				}
-				return nil, fmt.Errorf("fread constant name (400+) failed: %w", err)
-			}
-			if n != 6 { // Should read exactly 6 bytes for constant name
-				break // Assume end of constant names if less than 6 bytes read
-			}
-			rval.ncon++ // Increment constant count for each extra name found
+	// Keep the names of the constants, from the first record
+	rval.constNames = make([]byte, 6*rval.ncon)
+	copy(rval.constNames, head[84*3:84*3+6*min(rval.ncon, 400)])
+	copy(rval.constNames[min(6*rval.ncon, 6*400):], tail[:6*nExtra])
+	if err := verifyRecordLayout(rval, ifile); err != nil {
+		if debugFlag {
+			fmt.Printf("InitEphemeris: Error - %v\n", err)
 		}
+		return nil, initFailed(JPL_INIT_FILE_CORRUPT, err)
 	}
 
-	if val != nil { // Read constant values if 'val' slice is provided
-		_, err = ifile.Seek(int64(rval.recsize), io.SeekStart) // Seek to start of constant values
-		if err != nil {
-			if debugFlag {
-				fmt.Printf("InitEphemeris: Error seeking to constant values: %v\n", err)
-			}
-			return nil, fmt.Errorf("fseek to constants values failed: %w", err)
-		}
-		err = binary.Read(ifile, defaultByteOrder, val[:rval.ncon]) // Read constant values into 'val' slice
-		if err != nil && !errors.Is(err, io.EOF) {
-			if debugFlag {
-				fmt.Printf("InitEphemeris: Error reading constant values: %v\n", err)
-			}
-			return nil, fmt.Errorf("fread constant values failed: %w", err)
+	// Read the values of the constants, from the second record
+	_, err = ifile.Seek(int64(rval.recsize), io.SeekStart)
+	if err != nil {
+		if debugFlag {
+			fmt.Printf("InitEphemeris: Error seeking to constant values: %v\n", err)
 		}
-		if rval.swapBytes != 0 { // Byte swap constant values if needed (currently disabled)
-			swapBytes64Slice(val[:rval.ncon])
+		return nil, initFailed(JPL_INIT_FSEEK_FAILED, fmt.Errorf("fseek to constants values failed: %w", err))
+	}
+	values := make([]byte, 8*rval.ncon)
+	if _, err = io.ReadFull(ifile, values); err != nil {
+		if debugFlag {
+			fmt.Printf("InitEphemeris: Error reading constant values: %v\n", err)
 		}
+		return nil, initFailed(JPL_INIT_FREAD3_FAILED, fmt.Errorf("fread constant values failed: %w", truncated(err)))
+	}
+	rval.constValues = make([]float64, rval.ncon)
+	for i := range rval.constValues {
+		rval.constValues[i] = decodeFloat64(values[8*i : 8*i+8])
 	}
 
-	if nam != nil { // Read constant names if 'nam' array is provided
-		_, err = ifile.Seek(84*3, io.SeekStart) // Seek to start of constant names (after title lines)
-		if err != nil {
-			if debugFlag {
-				fmt.Printf("InitEphemeris: Error seeking to constant names: %v\n", err)
-			}
-			return nil, fmt.Errorf("fseek to constant names failed: %w", err)
-		}
-		for i := uint(0); i < uint(rval.ncon); i++ { // Read constant names up to ncon
-			if i == 400 { // Seek to start of extra constant names if index is 400
-				_, err = ifile.Seek(start400ThConstantName, io.SeekStart)
-				if err != nil {
-					if debugFlag {
-						fmt.Printf("InitEphemeris: Error seeking to 400+ constant names: %v\n", err)
-					}
-					return nil, fmt.Errorf("fseek to 400+ constant names failed: %w", err)
-				}
-			}
-			_, err = ifile.Read(nam[i][:]) // Read constant name into 'nam' array
-			if err != nil && !errors.Is(err, io.EOF) {
-				if debugFlag {
-					fmt.Printf("InitEphemeris: Error reading constant name [%d]: %v\n", i, err)
-				}
-				return nil, fmt.Errorf("fread constant name [%d] failed: %w", i, err)
-			}
-		}
+	for i := 0; i < int(rval.ncon) && i < len(nam); i++ { // Copy constant names if 'nam' array is provided
+		copy(nam[i][:], rval.constNames[6*i:6*i+6])
 	}
+	copy(val, rval.constValues) // Copy constant values if 'val' slice is provided
 	if debugFlag {
 		fmt.Println("InitEphemeris: Finished, ephemeris initialized successfully.")
 	}
 	return rval, nil
 }
 
+// cloneEphemeris returns a copy of the ephemeris data with its own file handle, record
+// cache, and interpolation state, so that it can be used concurrently with the original.
+// The header values are copied, not re-read from the file.
+func cloneEphemeris(ephem *jplEphData) (*jplEphData, error) {
+	var ifile io.ReadSeekCloser
+	var err error
+	if ephem.open != nil {
+		ifile, err = ephem.open()
+	} else {
+		ifile, err = os.Open(ephem.filename)
+	}
+	if err != nil {
+		if debugFlag {
+			fmt.Printf("CloneEphemeris: Error opening file: %v\n", err)
+		}
+		return nil, fmt.Errorf("failed to open ephemeris file: %w", err)
+	}
+	rval := *ephem // Copy header values
+	rval.ifile = ifile
+	rval.cache = make([]float64, ephem.ncoeff)
+	rval.currCacheLoc = uint32(4294967295) // Invalid cache location, as in initEphemeris
+	if ephem.preloaded != nil {
+		rval.preloaded = make(map[uint32][]float64, len(ephem.preloaded))
+		for nr, rec := range ephem.preloaded {
+			rval.preloaded[nr] = rec // Records are read-only and shared
+		}
+	}
+	rval.suns, rval.nextSun = [sunSlots]sunState{}, 0
+	rval.iinfo.reset()
+	return &rval, nil
+}
+
 // closeEphemeris closes the ephemeris file associated with the given ephemeris data interface.
 // It's important to call this function to release file resources when finished using the ephemeris.
 func closeEphemeris(ephem *jplEphData) error {
@@ -848,7 +1038,8 @@ func closeEphemeris(ephem *jplEphData) error {
 	return nil // Return nil if no file was open
 }
 
-// getConstant retrieves a specific JPL constant value by its index.
+// getConstant retrieves a specific JPL constant value by its index, from the names and
+// values read at initialization.
 //
 // Parameters:
 //   - idx: Index of the constant to retrieve (0-based).
@@ -856,57 +1047,16 @@ func closeEphemeris(ephem *jplEphData) error {
 //   - constantName: Byte slice of size 7 to store the constant name (optional, can be nil if name is not needed).
 //
 // Returns:
-//   - The constant value as a float64. Returns 0 if index is invalid or read error occurs (check debug log for warnings).
+//   - The constant value as a float64. Returns 0 if index is invalid.
 func getConstant(idx int, ephem *jplEphData, constantName []byte) float64 {
-	rval := 0.0
-
-	if idx >= 0 && idx < int(ephem.ncon) { // Validate constant index
-		var seekLoc int64
-		if idx < 400 { // Calculate file offset for constant name based on index
-			seekLoc = 84*3 + int64(idx)*6
-		} else {
-			seekLoc = start400ThConstantName + int64(idx-400)*6
-		}
-
-		_, err := ephem.ifile.Seek(seekLoc, io.SeekStart) // Seek to constant name location
-		if err != nil {
-			if debugFlag {
-				fmt.Printf("GetConstant: Warning: fseek to constant name failed: %v\n", err) // Non-critical error, name might be unavailable
-			}
-			return 0 // Return 0 on seek error (constant name unavailable)
-		}
-
-		n, err := ephem.ifile.Read(constantName[:6]) // Read constant name (6 bytes)
-		if err != nil && !errors.Is(err, io.EOF) {
-			if debugFlag {
-				fmt.Printf("GetConstant: Warning: fread constant name failed: %v\n", err) // Non-critical error, name might be unavailable
-			}
-			return 0 // Return 0 on read error (constant name unavailable)
-		}
-		if n == 6 { // If constant name was read successfully
-			constantName[6] = 0                                                        // Null terminate the name (for C-style string compatibility, though Go doesn't need it)
-			_, err = ephem.ifile.Seek(int64(ephem.recsize)+int64(idx)*8, io.SeekStart) // Seek to constant value location
-			if err != nil {
-				if debugFlag {
-					fmt.Printf("GetConstant: Warning: fseek to constant value failed: %v\n", err) // Non-critical error, value might be unavailable
-				}
-				return 0 // Return 0 on seek error (constant value unavailable)
-			}
-			var val float64
-			err = binary.Read(ephem.ifile, defaultByteOrder, &val) // Read constant value (double-precision)
-			if err != nil && !errors.Is(err, io.EOF) {
-				if debugFlag {
-					fmt.Printf("GetConstant: Warning: fread constant value failed: %v\n", err) // Non-critical error, value might be unavailable
-				}
-				return 0 // Return 0 on read error (constant value unavailable)
-			}
-			rval = val                // Assign read constant value to return value
-			if ephem.swapBytes != 0 { // Byte swap constant value if needed (currently disabled)
-				swapBytes64(&rval)
-			}
-		}
+	if idx < 0 || idx >= int(ephem.ncon) || idx >= len(ephem.constValues) { // Validate constant index
+		return 0
+	}
+	if constantName != nil {
+		copy(constantName[:6], ephem.constNames[6*idx:6*idx+6])
+		constantName[6] = 0 // Null terminate the name (for C-style string compatibility, though Go doesn't need it)
 	}
-	return rval // Return retrieved constant value (or 0 if error)
+	return ephem.constValues[idx]
 }
 
 // getEphemName returns the name of the ephemeris (e.g., "DE405").
@@ -0,0 +1,164 @@
+// ./ephemeris_test.go
+package jpleph_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// extendedIPTOffset is the file offset of the lunar mantle and TT-TDB IPT entries in a file
+// with fewer than 400 constants.
+const extendedIPTOffset = 2856
+
+// TestExtendedIPT checks which of the lunar mantle (ipt[13]) and TT-TDB (ipt[14]) entries are
+// kept for the layouts of the DE releases and of other producers, and that the quantities
+// kept evaluate to the values the file was generated from.
+func TestExtendedIPT(t *testing.T) {
+	withLayouts := func(de int, drop ...int) jplephtest.Config {
+		cfg := jplephtest.DefaultConfig()
+		cfg.DENumber = de
+		for _, q := range drop {
+			cfg.Layouts[q] = jplephtest.Layout{}
+		}
+		return cfg
+	}
+	inpop := func(b []byte) {
+		copy(b, bytes.Repeat([]byte(" "), 84))
+		copy(b, "INPOP19a  TDB  DE-like synthetic file")
+	}
+	garbage := func(b []byte) {
+		copy(b[extendedIPTOffset:], "GMBGMSGM1GM2GM4GM5GM6GM7") // Names, as found after the constants of old files
+	}
+	plausible := func(b []byte) { // Entries of the right shape that do not follow the librations
+		for k, v := range []uint32{5, 10, 4, 9, 13, 8} {
+			binary.LittleEndian.PutUint32(b[extendedIPTOffset+4*k:], v)
+		}
+	}
+
+	tests := []struct {
+		name               string
+		cfg                jplephtest.Config
+		patch              func([]byte)
+		wantDE             int64
+		wantMantle, wantTT bool
+	}{
+		{"DE440t", withLayouts(440), nil, 440, true, true},
+		{"DE430t", withLayouts(430), nil, 430, true, true},
+		{"DE440t big-endian", func() jplephtest.Config { c := withLayouts(440); c.ByteOrder = binary.BigEndian; return c }(), nil, 440, true, true},
+		{"DE440 without mantle and TT-TDB", withLayouts(440, 13, 14), nil, 440, false, false},
+		{"TT-TDB without mantle", withLayouts(440, 13), nil, 440, false, true},
+		{"mantle without TT-TDB", withLayouts(440, 14), nil, 440, true, false},
+		{"no librations", withLayouts(440, 12), nil, 440, true, true},
+		{"no nutations or librations", withLayouts(440, 11, 12), nil, 440, true, true},
+		{"DE405 numbering with TT-TDB", withLayouts(405, 13), nil, 405, false, true},
+		{"INPOP with TT-TDB", withLayouts(440, 13), inpop, 19, false, true},
+		{"INPOP without TT-TDB", withLayouts(440, 13, 14), inpop, 19, false, false},
+		{"garbage header bytes", withLayouts(405, 13, 14), garbage, 405, false, false},
+		{"misplaced entries", withLayouts(440, 13, 14), plausible, 440, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := jplephtest.Generate(&buf, tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+			data := buf.Bytes()
+			if tt.patch != nil {
+				tt.patch(data)
+			}
+			e, err := jpleph.NewEphemerisFromBytes(data, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer e.Close()
+			if de := e.GetEphemerisLong(jpleph.EphemerisVersion); de != tt.wantDE {
+				t.Errorf("DE number %d, want %d", de, tt.wantDE)
+			}
+
+			for _, q := range []struct {
+				quantity jpleph.Quantity
+				target   jpleph.Planet
+				want     bool
+			}{
+				{jpleph.QuantityLunarMantleOmega, jpleph.LunarMantleOmega, tt.wantMantle},
+				{jpleph.QuantityTTmTDB, jpleph.TT_TDB, tt.wantTT},
+			} {
+				start, ncf, nsub, err := e.IPT(q.quantity)
+				if got := err == nil; got != q.want {
+					t.Errorf("%v: kept %v (ipt %d %d %d, %v), want %v", q.quantity, got, start, ncf, nsub, err, q.want)
+					continue
+				}
+				et := tt.cfg.Start + 10.3
+				pos, _, err := e.CalculatePV(et, q.target, 0, true)
+				if !q.want {
+					if !errors.Is(err, jpleph.ErrQuantityNotInEphemeris) {
+						t.Errorf("%v: CalculatePV error %v, want ErrQuantityNotInEphemeris", q.quantity, err)
+					}
+					continue
+				}
+				want, _, _ := tt.cfg.Expected(q.target, 0, et)
+				if err != nil || math.Abs(pos.X-want.X) > 1e-9*math.Max(1, math.Abs(want.X)) {
+					t.Errorf("%v: CalculatePV = %v (%v), want %v", q.quantity, pos, err, want)
+				}
+			}
+		})
+	}
+}
+
+// TestSunReuse checks that states involving the Sun are the same whether or not the state of
+// the Sun is reused from an earlier query: queries alternate between epochs, orders, and
+// units, and are compared with those of a handle that answers each query first.
+func TestSunReuse(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	name := jplephtest.TempFile(t, cfg)
+	e, err := jpleph.NewEphemeris(name, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	type query struct {
+		et       float64
+		target   jpleph.Planet
+		center   jpleph.CenterBody
+		velocity bool
+		km       bool
+	}
+	var queries []query
+	for k := 0; k < 40; k++ {
+		et := cfg.Start + 1 + 15.3*float64(k)
+		queries = append(queries,
+			query{et, jpleph.Earth, jpleph.CenterSun, false, false},
+			query{et - 0.01, jpleph.Mars, jpleph.CenterSun, false, false},
+			query{et, jpleph.Sun, jpleph.CenterSolarSystemBarycenter, true, false},
+			query{et - 0.01, jpleph.Mars, jpleph.CenterSun, true, true},
+			query{et - 0.5, jpleph.Sun, jpleph.CenterMoon, true, false},
+			query{et, jpleph.Earth, jpleph.CenterSun, true, false})
+	}
+	get := func(e *jpleph.Ephemeris, q query) (jpleph.Position, jpleph.Velocity, error) {
+		if q.km {
+			return e.CalculatePVKm(q.et, q.target, q.center, q.velocity)
+		}
+		return e.CalculatePV(q.et, q.target, q.center, q.velocity)
+	}
+	for i, q := range queries {
+		fresh, err := jpleph.NewEphemeris(name, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, wantVel, err := get(fresh, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fresh.Close()
+		got, gotVel, err := get(e, q)
+		if err != nil || got != want || gotVel != wantVel {
+			t.Fatalf("query %d %+v: %v %v (%v), want %v %v", i, q, got, gotVel, err, want, wantVel)
+		}
+	}
+}
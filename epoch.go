@@ -0,0 +1,270 @@
+// ./epoch.go
+package jpleph
+
+/*
+Package jpleph provides epochs tagged with their time scale.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeScale identifies the time scale of an Epoch.
+type TimeScale int
+
+const (
+	// TDB is Barycentric Dynamical Time, the time argument of the ephemerides.
+	TDB TimeScale = iota
+	// TT is Terrestrial Time.
+	TT
+	// TAI is International Atomic Time.
+	TAI
+	// UTC is Coordinated Universal Time, converted with the current leap-second table.
+	UTC
+	// UT1 is the time scale of the Earth's rotation, converted with the DeltaT model.
+	UT1
+)
+
+// timeScaleNames holds the names of the time scales, indexed by TimeScale value.
+var timeScaleNames = [...]string{TDB: "TDB", TT: "TT", TAI: "TAI", UTC: "UTC", UT1: "UT1"}
+
+// String returns the name of the time scale (e.g., "TDB").
+func (s TimeScale) String() string {
+	if s >= 0 && int(s) < len(timeScaleNames) {
+		return timeScaleNames[s]
+	}
+	return fmt.Sprintf("TimeScale(%d)", int(s))
+}
+
+// parseTimeScale returns the time scale of a name such as "tdb" or "UTC".
+func parseTimeScale(name string) (TimeScale, bool) {
+	for s, n := range timeScaleNames {
+		if strings.EqualFold(name, n) {
+			return TimeScale(s), true
+		}
+	}
+	return 0, false
+}
+
+// mjdUnixEpoch is the Modified Julian Date of 1970 January 1.0.
+const mjdUnixEpoch = 40587
+
+// Epoch is an instant given as a date and time in an explicit time scale. Its TDB method
+// gives the TDB Julian Date expected by CalculatePV and the other methods of Ephemeris. The
+// day number and the seconds of day are kept apart, so calendar labels and Unix times keep
+// nanosecond resolution instead of the ~40 microseconds of a float64 Julian Date.
+type Epoch struct {
+	mjd   int64     // Modified Julian Day number in scale
+	sec   float64   // Seconds since 0h of the day in scale
+	scale TimeScale // Time scale of the date
+}
+
+// newEpoch returns the normalized epoch of a day number and seconds, which may exceed a day.
+func newEpoch(mjd int64, sec float64, scale TimeScale) Epoch {
+	days := math.Floor(sec / secondsPerDay)
+	return Epoch{mjd: mjd + int64(days), sec: sec - days*secondsPerDay, scale: scale}
+}
+
+// EpochFromJD returns the epoch of a Julian Date in a time scale.
+func EpochFromJD(jd float64, scale TimeScale) Epoch {
+	return EpochFromMJD(jd-mjdOffset, scale)
+}
+
+// EpochFromMJD returns the epoch of a Modified Julian Date (JD - 2400000.5) in a time scale.
+func EpochFromMJD(mjd float64, scale TimeScale) Epoch {
+	day := math.Floor(mjd)
+	return newEpoch(int64(day), (mjd-day)*secondsPerDay, scale)
+}
+
+// EpochFromUnix returns the UTC epoch of a Unix time in seconds and nanoseconds since
+// 1970-01-01T00:00:00Z. As in POSIX, Unix time does not count leap seconds.
+func EpochFromUnix(sec, nsec int64) Epoch {
+	days := sec / 86400
+	if sec%86400 < 0 {
+		days--
+	}
+	return newEpoch(mjdUnixEpoch+days, float64(sec-days*86400)+float64(nsec)*1e-9, UTC)
+}
+
+// EpochFromUnixNano returns the UTC epoch of a Unix time in nanoseconds.
+func EpochFromUnixNano(nsec int64) Epoch {
+	return EpochFromUnix(0, nsec)
+}
+
+// EpochFromTime returns the UTC epoch of a time.Time, taking its location into account.
+func EpochFromTime(t time.Time) Epoch {
+	return EpochFromUnix(t.Unix(), int64(t.Nanosecond()))
+}
+
+// epochFromLabel returns the epoch of calendar fields read in a time scale.
+func epochFromLabel(t time.Time, scale TimeScale) Epoch {
+	ep := EpochFromTime(t)
+	ep.scale = scale
+	return ep
+}
+
+// epochLayouts lists the ISO 8601 layouts accepted without a time zone.
+var epochLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseEpoch parses an epoch with an explicit time scale, in one of the forms:
+//
+//	2024-03-01T12:00:00Z           ISO 8601 with "Z" or a UTC offset: UTC
+//	2024-03-01T12:00:00.5 TDB      ISO 8601 without a zone, followed by the time scale
+//	JD 2460371.0 TT                Julian Date and time scale
+//	MJD 60370.5 UTC                Modified Julian Date and time scale
+//
+// Time scales are TDB, TT, TAI, UTC, and UT1, in any case. There is no default scale, so that
+// a calendar date cannot silently be taken in the wrong one.
+//
+// Parameters:
+//   - s: Text of the epoch.
+//
+// Returns:
+//   - Epoch: The epoch.
+//   - error: ErrEpochFormat if the text is not in one of the forms above.
+func ParseEpoch(s string) (Epoch, error) {
+	fields := strings.Fields(s)
+	scale, tagged := TimeScale(0), false
+	if n := len(fields); n >= 2 {
+		scale, tagged = parseTimeScale(fields[n-1])
+		if tagged {
+			fields = fields[:n-1]
+		}
+	}
+	if len(fields) == 0 {
+		return Epoch{}, fmt.Errorf("%w: %q", ErrEpochFormat, s)
+	}
+
+	if kind := strings.ToUpper(fields[0]); (kind == "JD" || kind == "MJD") && len(fields) == 2 {
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || !tagged {
+			return Epoch{}, fmt.Errorf("%w: %q needs a number and a time scale", ErrEpochFormat, s)
+		}
+		if kind == "MJD" {
+			return EpochFromMJD(v, scale), nil
+		}
+		return EpochFromJD(v, scale), nil
+	}
+
+	text := strings.Join(fields, " ")
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		if tagged && scale != UTC {
+			return Epoch{}, fmt.Errorf("%w: %q has a UTC offset but the %v time scale", ErrEpochFormat, s, scale)
+		}
+		return EpochFromTime(t), nil
+	}
+	for _, layout := range epochLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			if !tagged {
+				return Epoch{}, fmt.Errorf("%w: %q needs a time scale or a UTC offset", ErrEpochFormat, s)
+			}
+			return epochFromLabel(t, scale), nil
+		}
+	}
+	return Epoch{}, fmt.Errorf("%w: %q", ErrEpochFormat, s)
+}
+
+// JD returns the Julian Date of the epoch in its own time scale.
+func (ep Epoch) JD() float64 {
+	return float64(ep.mjd) + mjdOffset + ep.sec/secondsPerDay
+}
+
+// MJD returns the Modified Julian Date of the epoch in its own time scale.
+func (ep Epoch) MJD() float64 {
+	return float64(ep.mjd) + ep.sec/secondsPerDay
+}
+
+// Scale returns the time scale of the epoch.
+func (ep Epoch) Scale() TimeScale {
+	return ep.scale
+}
+
+// TDB returns the TDB Julian Date of the epoch, the time argument of CalculatePV.
+func (ep Epoch) TDB() float64 {
+	return ep.In(TDB).JD()
+}
+
+// toTT returns the seconds to add to a time in scale, near the Julian Date jd, to get TT.
+func toTT(scale TimeScale, jd float64) float64 {
+	switch scale {
+	case TDB:
+		return -tdbMinusTT(jd)
+	case TAI:
+		return ttMinusTAI
+	case UTC:
+		return CurrentLeapSeconds().TAIMinusUTC(jd) + ttMinusTAI
+	case UT1:
+		return DeltaT(jd)
+	}
+	return 0
+}
+
+// In returns the same instant in another time scale.
+func (ep Epoch) In(scale TimeScale) Epoch {
+	if scale == ep.scale {
+		return ep
+	}
+	tt := newEpoch(ep.mjd, ep.sec+toTT(ep.scale, ep.JD()), TT)
+	out := newEpoch(tt.mjd, tt.sec-toTT(scale, tt.JD()), scale)
+	// Once more at the converted date, in case a leap second lies in between.
+	return newEpoch(tt.mjd, tt.sec-toTT(scale, out.JD()), scale)
+}
+
+// Time returns the calendar date and time of the epoch in its own time scale, rounded to the
+// nanosecond. The UTC location of the result only holds the calendar fields; for UTC
+// epochs it is the actual UTC time.
+func (ep Epoch) Time() time.Time {
+	ns := int64(math.Round(ep.sec * 1e9))
+	return time.Unix((ep.mjd-mjdUnixEpoch)*86400, ns).UTC()
+}
+
+// String formats the epoch in ISO 8601 followed by its time scale, e.g.
+// "2000-01-01T12:00:00.000000 TDB", a form accepted by ParseEpoch.
+func (ep Epoch) String() string {
+	return ep.Time().Format("2006-01-02T15:04:05.000000") + " " + ep.scale.String()
+}
+
+// MarshalText encodes the epoch as String does.
+func (ep Epoch) MarshalText() ([]byte, error) {
+	return []byte(ep.String()), nil
+}
+
+// UnmarshalText decodes an epoch in any of the forms of ParseEpoch.
+func (ep *Epoch) UnmarshalText(text []byte) error {
+	v, err := ParseEpoch(string(text))
+	if err != nil {
+		return err
+	}
+	*ep = v
+	return nil
+}
@@ -0,0 +1,108 @@
+// ./error_codes.go
+package jpleph
+
+/*
+Package jpleph provides the mapping of errors to the numeric codes of the C version.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// JPL_EPH_OTHER_ERROR is returned by ErrorCode for errors without a code in the C version,
+// such as an invalid option or a frame error. It follows the JPL_EPH_* codes.
+const JPL_EPH_OTHER_ERROR = -7
+
+// initError is an initialization error carrying the JPL_INIT_* code the C version returns
+// for the same failure.
+type initError struct {
+	code int   // JPL_INIT_* code
+	err  error // The failure
+}
+
+// Error returns the message of the failure.
+func (e *initError) Error() string { return e.err.Error() }
+
+// Unwrap returns the failure, so that errors.Is sees its sentinel errors.
+func (e *initError) Unwrap() error { return e.err }
+
+// initFailed tags an initialization failure with its JPL_INIT_* code.
+func initFailed(code int, err error) error {
+	return &initError{code: code, err: err}
+}
+
+// ErrorCode returns the JPL_EPH_* code that State and Pleph return in the C version for an
+// error of a query (CalculatePV, State, Pleph, and the functions built on them), for callers
+// porting C code or logging with the numeric codes. The mapping looks through wrapped
+// errors and does not depend on the messages.
+//
+// Parameters:
+//   - err: Error returned by a query; use InitErrorCode for errors of NewEphemeris.
+//
+// Returns:
+//   - int: 0 for nil; JPL_EPH_OUTSIDE_RANGE, JPL_EPH_READ_ERROR,
+//     JPL_EPH_QUANTITY_NOT_IN_EPHEMERIS, JPL_EPH_INVALID_INDEX, or JPL_EPH_FSEEK_ERROR; or
+//     JPL_EPH_OTHER_ERROR for errors without a code in the C version.
+func ErrorCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrOutsideRange):
+		return JPL_EPH_OUTSIDE_RANGE
+	case errors.Is(err, ErrFileRead), errors.Is(err, ErrTruncatedFile):
+		return JPL_EPH_READ_ERROR
+	case errors.Is(err, ErrQuantityNotInEphemeris):
+		return JPL_EPH_QUANTITY_NOT_IN_EPHEMERIS
+	case errors.Is(err, ErrInvalidIndex), errors.Is(err, ErrBadBodyForCenter), errors.Is(err, ErrCenterIgnored):
+		return JPL_EPH_INVALID_INDEX
+	case errors.Is(err, ErrFileSeek):
+		return JPL_EPH_FSEEK_ERROR
+	}
+	return JPL_EPH_OTHER_ERROR
+}
+
+// InitErrorCode returns the JPL_INIT_* code that the C version reports for a failure to open
+// an ephemeris file (NewEphemeris, NewEphemerisFromBytes, and the other constructors).
+//
+// Parameters:
+//   - err: Error returned by a constructor.
+//
+// Returns:
+//   - int: JPL_INIT_NO_ERROR for nil; the code of the step that failed, such as
+//     JPL_INIT_FILE_NOT_FOUND, JPL_INIT_FREAD_FAILED, or JPL_INIT_FILE_CORRUPT; or
+//     JPL_INIT_NOT_CALLED for errors that are not initialization failures.
+func InitErrorCode(err error) int {
+	var ie *initError
+	switch {
+	case err == nil:
+		return JPL_INIT_NO_ERROR
+	case errors.As(err, &ie):
+		return ie.code
+	case errors.Is(err, fs.ErrNotExist):
+		return JPL_INIT_FILE_NOT_FOUND
+	case errors.Is(err, ErrTruncatedFile), errors.Is(err, ErrByteOrder), errors.Is(err, ErrUnknownLayout):
+		return JPL_INIT_FILE_CORRUPT
+	}
+	return JPL_INIT_NOT_CALLED
+}
@@ -0,0 +1,215 @@
+// ./error_estimate.go
+package jpleph
+
+/*
+Package jpleph provides estimates of the Chebyshev truncation error of interpolated states.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// InterpolationError is an estimate of the truncation error of an interpolated state.
+//
+// The estimate is the magnitude of the highest-order Chebyshev coefficient of the
+// sub-interval covering the epoch. For the smooth, rapidly converging series of the JPL
+// kernels this is a good proxy for the first neglected term, but it is an estimate of
+// the representation error of the kernel against its own integration, not of the
+// physical accuracy of the ephemeris.
+type InterpolationError struct {
+	Position float64 // Position is the estimated position error (AU, or the quantity's native unit).
+	Velocity float64 // Velocity is the estimated velocity error (AU/day, or the quantity's native rate).
+}
+
+// EstimateInterpolationError estimates the truncation error of the state that CalculatePV
+// returns for the same arguments. The contributions of every kernel quantity involved in
+// the relative state (for example the Earth-Moon barycenter and the geocentric Moon for the
+// Earth) are weighted as in Pleph and combined as a root sum of squares. Custom bodies
+// contribute the estimate of their own fitted segment. SPK and source bodies (see AddSpk
+// and AddSource) are followed through their centers to the kernel body they are relative
+// to, whose terms are used; their own states add nothing to the estimate, which is then a
+// lower bound.
+//
+// Parameters:
+//   - et: Julian Ephemeris Date.
+//   - target: Target body or quantity, as for CalculatePV.
+//   - center: Center body, as for CalculatePV.
+//
+// Returns:
+//   - InterpolationError: The estimated position and velocity errors.
+//   - error: ErrOutsideRange, ErrInvalidIndex, ErrQuantityNotInEphemeris, ErrCenterIgnored,
+//     ErrBadBodyForCenter, or a file access error.
+func (e *Ephemeris) EstimateInterpolationError(et float64, target Planet, center CenterBody) (InterpolationError, error) {
+	if err := e.validateCenter(target, center); err != nil {
+		return InterpolationError{}, err
+	}
+	e.applyReload()
+	ephem := e.ephemData
+	nr, frac, err := recordLocation(ephem, et)
+	if err != nil {
+		return InterpolationError{}, err
+	}
+	if err := loadRecord(ephem, nr); err != nil {
+		return InterpolationError{}, err
+	}
+
+	if target >= Nutations && target <= TT_TDB { // Special quantities: a single IPT entry, native units
+		idx := int(target) - 3
+		if ephem.ipt[idx][1] == 0 {
+			return InterpolationError{}, ErrQuantityNotInEphemeris
+		}
+		pos, vel := iptTruncationError(ephem, idx, frac)
+		return InterpolationError{Position: pos, Velocity: vel}, nil
+	}
+
+	weights := make(map[int]float64) // Net weight of each IPT entry in target - center
+	var posSq, velSq float64
+	for _, side := range []struct {
+		body Planet
+		sign float64
+	}{{target, 1}, {Planet(center), -1}} {
+		body, pos, vel, err := e.kernelAncestor(et, side.body)
+		if err != nil {
+			return InterpolationError{}, err
+		}
+		posSq += pos
+		velSq += vel
+		terms, err := barycentricTerms(body, ephem.emrat)
+		if err != nil {
+			return InterpolationError{}, err
+		}
+		for idx, w := range terms {
+			weights[idx] += side.sign * w
+		}
+	}
+
+	indices := make([]int, 0, len(weights))
+	for idx := range weights {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices) // Deterministic summation order
+	aufac := 1.0 / ephem.au
+	for _, idx := range indices {
+		w := weights[idx]
+		if math.Abs(w) < 1e-12 { // Contribution cancels, e.g. the barycenter in Earth - Moon
+			continue
+		}
+		pos, vel := iptTruncationError(ephem, idx, frac)
+		posSq += (w * pos * aufac) * (w * pos * aufac)
+		velSq += (w * vel * aufac) * (w * vel * aufac)
+	}
+	return InterpolationError{Position: math.Sqrt(posSq), Velocity: math.Sqrt(velSq)}, nil
+}
+
+// kernelAncestor follows a custom, source, or SPK body through its centers to the first
+// body of the JPL kernel, and returns that body with the sums of the squared position and
+// velocity estimates of the custom bodies on the way. Kernel bodies are returned unchanged.
+func (e *Ephemeris) kernelAncestor(et float64, body Planet) (Planet, float64, float64, error) {
+	var posSq, velSq float64
+	for hops := 0; e.isChained(body); hops++ {
+		if hops == maxSPKChain {
+			return 0, 0, 0, fmt.Errorf("%w: center chain of body %d is too long", ErrBadBodyForCenter, body)
+		}
+		if custom, ok := e.customBodies[body]; ok {
+			pos, vel, err := custom.truncationError(et)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			posSq += pos * pos
+			velSq += vel * vel
+			body = Planet(custom.Center)
+		} else if s, ok := e.sources[body]; ok {
+			body = Planet(s.center)
+		} else {
+			_, center, err := e.spkState(int(body), et)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			body = planetFromNAIF(center)
+		}
+	}
+	return body, posSq, velSq, nil
+}
+
+// barycentricTerms returns the IPT entries (and their weights) combined by Pleph to form
+// the barycentric state of a kernel body.
+func barycentricTerms(body Planet, emrat float64) (map[int]float64, error) {
+	switch {
+	case body == SolarSystemBarycenter:
+		return nil, nil
+	case body == Earth:
+		return map[int]float64{2: 1, 9: -1 / (1 + emrat)}, nil
+	case body == Moon:
+		return map[int]float64{2: 1, 9: emrat / (1 + emrat)}, nil
+	case body == EarthMoonBarycenter:
+		return map[int]float64{2: 1}, nil
+	case body == Sun:
+		return map[int]float64{10: 1}, nil
+	case body >= Mercury && body <= Pluto:
+		return map[int]float64{int(body) - 1: 1}, nil
+	}
+	return nil, ErrInvalidIndex
+}
+
+// iptTruncationError estimates the position and velocity truncation errors, in the raw
+// units of the kernel, of the IPT entry idx at fractional record time frac. The cached
+// record must cover the epoch.
+func iptTruncationError(ephem *jplEphData, idx int, frac float64) (float64, float64) {
+	entry := ephem.ipt[idx]
+	ncf, na := int(entry[1]), int(entry[2])
+	if ncf == 0 || na == 0 {
+		return 0, 0
+	}
+	dim := quantityDimension(idx)
+	l := int(float64(na) * frac) // Sub-interval index, as in interp()
+	if l == na {
+		l--
+	}
+	sum := 0.0
+	for k := 0; k < dim; k++ {
+		c := ephem.cache[int(entry[0])-1+ncf*(k+l*dim)+ncf-1] // Highest-order coefficient of component k
+		sum += c * c
+	}
+	pos := math.Sqrt(sum)
+	vfac := 2 * float64(na) / ephem.ephemStep // Same velocity scaling as interp()
+	n := float64(ncf - 1)                     // max |T'_n| on [-1, 1] is n^2
+	return pos, pos * n * n * vfac
+}
+
+// truncationError estimates the truncation errors of a custom body at et, in AU and AU/day.
+func (b *CustomBody) truncationError(et float64) (float64, float64, error) {
+	seg, err := b.segmentAt(et)
+	if err != nil {
+		return 0, 0, err
+	}
+	sum := 0.0
+	for k := 0; k < 3; k++ {
+		c := seg.coeffs[b.NCoeff*k+b.NCoeff-1]
+		sum += c * c
+	}
+	pos := math.Sqrt(sum)
+	n := float64(b.NCoeff - 1)
+	return pos, pos * n * n * 2 / (seg.end - seg.start), nil
+}
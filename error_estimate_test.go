@@ -0,0 +1,50 @@
+// ./error_estimate_test.go
+package jpleph_test
+
+import (
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// TestEstimateInterpolationErrorChained checks that source bodies are followed through their
+// centers to the kernel body they are relative to, whose estimate they take.
+func TestEstimateInterpolationErrorChained(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	e := openSynthetic(t, cfg)
+	mars, earth := jpleph.FirstCustomBody, jpleph.FirstCustomBody+1
+	if err := e.AddSource(mars, jpleph.CenterMars, fixedSource{pos: jpleph.Position{X: 1e-4}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddSource(earth, jpleph.CenterEarth, fixedSource{pos: jpleph.Position{Y: 1e-5}}); err != nil {
+		t.Fatal(err)
+	}
+	satellite := earth + 1 // Two hops from the kernel
+	if err := e.AddSource(satellite, jpleph.CenterBody(earth), fixedSource{pos: jpleph.Position{Z: 1e-6}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		target, kernelTarget jpleph.Planet     // kernelTarget is the kernel body target hangs from
+		center, kernelCenter jpleph.CenterBody // kernelCenter is the kernel body center hangs from
+	}{
+		{mars, jpleph.Mars, jpleph.CenterSun, jpleph.CenterSun},
+		{earth, jpleph.Earth, jpleph.CenterMars, jpleph.CenterMars},
+		{satellite, jpleph.Earth, jpleph.CenterSolarSystemBarycenter, jpleph.CenterSolarSystemBarycenter},
+		{jpleph.Venus, jpleph.Venus, jpleph.CenterBody(satellite), jpleph.CenterEarth},
+	}
+	et := cfg.Start + 23.4
+	for _, tt := range tests {
+		got, err := e.EstimateInterpolationError(et, tt.target, tt.center)
+		if err != nil {
+			t.Fatalf("%v from %v: %v", tt.target, tt.center, err)
+		}
+		want, err := e.EstimateInterpolationError(et, tt.kernelTarget, tt.kernelCenter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want || got.Position == 0 {
+			t.Errorf("%v from %v: %+v, want %+v", tt.target, tt.center, got, want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// ./export_test.go
+package jpleph
+
+import "io/fs"
+
+// SetGoldenFS makes VerifyAgainstEmbedded and the other embedded reference functions read
+// the golden directory of fsys instead of the embedded one, until restore is called.
+func SetGoldenFS(fsys fs.FS) (restore func()) {
+	saved := goldenFS
+	goldenFS = fsys
+	return func() { goldenFS = saved }
+}
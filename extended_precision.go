@@ -0,0 +1,203 @@
+// ./extended_precision.go
+package jpleph
+
+/*
+Package jpleph provides an arbitrary-precision evaluation path for validation.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DefaultBigPrecision is the mantissa precision in bits used by CalculatePVBig when none is given.
+const DefaultBigPrecision = 128
+
+// bigEvaluator evaluates the records of an ephemeris with big.Float arithmetic.
+type bigEvaluator struct {
+	ephem *jplEphData
+	prec  uint
+	frac  *big.Float // Fractional time within the record, 0 <= frac <= 1
+}
+
+// float returns x promoted exactly to a big.Float of the evaluator precision.
+func (b *bigEvaluator) float(x float64) *big.Float {
+	return new(big.Float).SetPrec(b.prec).SetFloat64(x)
+}
+
+// CalculatePVBig computes the state of target relative to center like CalculatePV, but with
+// the epoch, the record location, the Chebyshev series, and the Earth-Moon split evaluated
+// in big.Float arithmetic from the coefficients of the file promoted exactly. It is meant for
+// validating the float64 path, and for the extreme epochs of DE431/DE441 (|JD| ~ 8e6), where
+// a float64 epoch resolves only about 80 microseconds. Only the kernel bodies (Mercury to the
+// Earth-Moon barycenter) are supported; it is much slower than CalculatePV.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date, e.g. big.NewFloat(2451545).SetPrec(128) plus a fraction.
+//   - target: Target body (Mercury to EarthMoonBarycenter).
+//   - center: Center body.
+//   - prec: Mantissa precision in bits; 0 means DefaultBigPrecision.
+//
+// Returns:
+//   - [6]*big.Float: Position in AU and velocity in AU/day.
+//   - error: ErrInvalidIndex for bodies outside the JPL kernel, ErrOutsideRange if et is
+//     outside the time span of the file, or a file read error.
+func (e *Ephemeris) CalculatePVBig(et *big.Float, target Planet, center CenterBody, prec uint) ([6]*big.Float, error) {
+	var out [6]*big.Float
+	if prec == 0 {
+		prec = DefaultBigPrecision
+	}
+	for i := range out {
+		out[i] = new(big.Float).SetPrec(prec)
+	}
+	inKernel := func(p Planet) bool { return p >= Mercury && p <= EarthMoonBarycenter }
+	if !inKernel(target) || !inKernel(Planet(center)) {
+		return out, fmt.Errorf("%v relative to %v: %w", target, center, ErrInvalidIndex)
+	}
+	if target == Planet(center) {
+		return out, nil
+	}
+	e.applyReload()
+	ephem := e.ephemData
+
+	// Record location: nr = floor((et - start) / step), as in recordLocation.
+	b := &bigEvaluator{ephem: ephem, prec: prec}
+	if et.Cmp(b.float(ephem.ephemStart)) < 0 || et.Cmp(b.float(ephem.ephemEnd)) > 0 {
+		return out, ErrOutsideRange
+	}
+	blockLoc := new(big.Float).SetPrec(prec).Sub(et, b.float(ephem.ephemStart))
+	blockLoc.Quo(blockLoc, b.float(ephem.ephemStep))
+	whole, _ := blockLoc.Int(nil)
+	nr := whole.Uint64()
+	b.frac = new(big.Float).SetPrec(prec).Sub(blockLoc, new(big.Float).SetPrec(prec).SetInt(whole))
+	if b.frac.Sign() == 0 && nr != 0 { // Epochs on a boundary belong to the preceding record
+		b.frac.SetInt64(1)
+		nr--
+	}
+	if err := loadRecord(ephem, uint32(nr)); err != nil {
+		return out, err
+	}
+
+	tState := b.barycentric(target, center)
+	cState := b.barycentric(Planet(center), CenterBody(target))
+	for i := range out {
+		out[i].Sub(tState[i], cState[i])
+	}
+	return out, nil
+}
+
+// barycentric returns the barycentric state of a kernel body in AU and AU/day. For the Earth
+// relative to the Moon (or the reverse), other is used to return the geocentric Moon state
+// directly, as Pleph does, so that the pair is free of the Earth-Moon split rounding.
+func (b *bigEvaluator) barycentric(body Planet, other CenterBody) [6]*big.Float {
+	var zero [6]*big.Float
+	for i := range zero {
+		zero[i] = new(big.Float).SetPrec(b.prec)
+	}
+	switch body {
+	case SolarSystemBarycenter:
+		return zero
+	case Sun:
+		return b.quantity(10)
+	case EarthMoonBarycenter:
+		return b.quantity(2)
+	case Earth, Moon:
+		moon := b.quantity(9) // Geocentric Moon
+		if Planet(other) == Earth || Planet(other) == Moon {
+			if body == Moon {
+				return moon
+			}
+			return zero // The Moon relative to the Earth is the geocentric Moon
+		}
+		emb := b.quantity(2)
+		onePlusEMRAT := new(big.Float).SetPrec(b.prec).Add(b.float(1), b.float(b.ephem.emrat))
+		var earth [6]*big.Float
+		for i := range earth {
+			earth[i] = new(big.Float).SetPrec(b.prec).Quo(moon[i], onePlusEMRAT)
+			earth[i].Sub(emb[i], earth[i]) // Earth = EMB - Moon/(1+EMRAT)
+		}
+		if body == Earth {
+			return earth
+		}
+		for i := range moon {
+			moon[i].Add(moon[i], earth[i])
+		}
+		return moon
+	}
+	return b.quantity(int(body) - 1) // Planets: ipt[0..8], with Earth handled above
+}
+
+// quantity evaluates the three components of ipt entry q of the cached record, returning
+// the position in AU and the velocity in AU/day.
+func (b *bigEvaluator) quantity(q int) [6]*big.Float {
+	var state [6]*big.Float
+	entry := b.ephem.ipt[q]
+	ncf, na := int(entry[1]), int(entry[2])
+	prec := b.prec
+
+	// Sub-interval and normalized time, as in interp.
+	temp := new(big.Float).SetPrec(prec).Mul(b.frac, b.float(float64(na)))
+	whole, _ := temp.Int(nil)
+	l := int(whole.Int64())
+	tc := new(big.Float).SetPrec(prec).Sub(temp, new(big.Float).SetPrec(prec).SetInt(whole))
+	tc.Mul(tc, b.float(2)).Sub(tc, b.float(1))
+	if l == na {
+		l--
+		tc.SetInt64(1)
+	}
+
+	// Chebyshev polynomials T_i(tc) and their derivatives T'_i(tc).
+	twot := new(big.Float).SetPrec(prec).Add(tc, tc)
+	t := make([]*big.Float, ncf)
+	d := make([]*big.Float, ncf)
+	for i := range t {
+		t[i], d[i] = new(big.Float).SetPrec(prec), new(big.Float).SetPrec(prec)
+	}
+	t[0].SetInt64(1)
+	if ncf > 1 {
+		t[1].Set(tc)
+		d[1].SetInt64(1)
+	}
+	tmp := new(big.Float).SetPrec(prec)
+	for i := 2; i < ncf; i++ {
+		t[i].Mul(twot, t[i-1]).Sub(t[i], t[i-2])
+		d[i].Mul(twot, d[i-1]).Add(d[i], tmp.Add(t[i-1], t[i-1])).Sub(d[i], d[i-2])
+	}
+
+	au := b.float(b.ephem.au)
+	vfac := new(big.Float).SetPrec(prec).Quo(b.float(float64(2*na)), b.float(b.ephem.ephemStep))
+	vfac.Quo(vfac, au)
+	base := int(entry[0]) - 1
+	for c := 0; c < 3; c++ {
+		coef := b.ephem.cache[base+ncf*(c+l*3):]
+		pos, vel := new(big.Float).SetPrec(prec), new(big.Float).SetPrec(prec)
+		for j := 0; j < ncf; j++ {
+			cj := b.float(coef[j])
+			pos.Add(pos, tmp.Mul(t[j], cj))
+			vel.Add(vel, tmp.Mul(d[j], cj))
+		}
+		state[c] = pos.Quo(pos, au)
+		state[c+3] = vel.Mul(vel, vfac)
+	}
+	return state
+}
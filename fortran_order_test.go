@@ -0,0 +1,216 @@
+// ./fortran_order_test.go
+package jpleph_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// testeph computes states as PLEPH, STATE, and INTERP of testeph.f do, transcribed
+// statement by statement, from the coefficients of a file opened in the default mode. It
+// is the reference against which WithFortranOrder is checked.
+type testeph struct {
+	tb               testing.TB
+	e                *jpleph.Ephemeris
+	start, end, step float64
+	aufac, emrat     float64
+}
+
+func newTesteph(tb testing.TB, e *jpleph.Ephemeris) *testeph {
+	return &testeph{
+		tb:    tb,
+		e:     e,
+		start: e.GetEphemerisDouble(jpleph.EphemerisStartJD),
+		end:   e.GetEphemerisDouble(jpleph.EphemerisEndJD),
+		step:  e.GetEphemerisDouble(jpleph.EphemerisStep),
+		aufac: 1 / e.GetEphemerisDouble(jpleph.AUinKM),
+		emrat: e.GetEphemerisDouble(jpleph.EarthMoonMassRatio),
+	}
+}
+
+// split is SPLIT of testeph.f.
+func split(tt float64) (float64, float64) {
+	whole := math.Trunc(tt)
+	frac := tt - whole
+	if tt < 0 && frac != 0 {
+		whole--
+		frac++
+	}
+	return whole, frac
+}
+
+// interp is INTERP for quantity q at the Julian Date et: the position components followed
+// by their rates, unscaled.
+func (r *testeph) interp(q jpleph.Quantity, et float64) []float64 {
+	// STATE: the record and the time within it
+	pjd1, pjd2 := split(et - 0.5)
+	pjd3, pjd4 := split(0)
+	pjd1 = pjd1 + pjd3 + 0.5
+	pjd2 = pjd2 + pjd4
+	pjd3, pjd4 = split(pjd2)
+	pjd1 = pjd1 + pjd3
+	nr := math.Trunc((pjd1-r.start)/r.step) + 1
+	if pjd1 == r.end {
+		nr--
+	}
+	t1 := ((pjd1 - ((nr-1)*r.step + r.start)) + pjd4) / r.step
+
+	d, err := r.e.Coefficients(et, q)
+	if err != nil {
+		r.tb.Fatalf("Coefficients(%v, %v): %v", et, q, err)
+	}
+	ncf, na := len(d.Coefficients[0]), float64(d.IPT[2])
+	dt1 := math.Trunc(t1)
+	temp := na * t1
+	l := int(temp - dt1)
+	tc := 2*(math.Mod(temp, 1)+dt1) - 1
+	if d.Record != uint32(nr-1) || d.SubInterval != l {
+		r.tb.Fatalf("%v at %v: record %d sub-interval %d, testeph.f uses %v and %d", q, et, d.Record, d.SubInterval, nr-1, l)
+	}
+
+	pc := make([]float64, ncf)
+	vc := make([]float64, ncf)
+	pc[0], pc[1], vc[1] = 1, tc, 1
+	twot := tc + tc
+	for i := 2; i < ncf; i++ {
+		pc[i] = float64(twot*pc[i-1]) - pc[i-2]
+	}
+	vc[2] = twot + twot
+	for i := 3; i < ncf; i++ {
+		vc[i] = float64(twot*vc[i-1]) + pc[i-1] + pc[i-1] - vc[i-2]
+	}
+	vfac := (na + na) / r.step
+	ncm := len(d.Coefficients)
+	pv := make([]float64, 2*ncm)
+	for i, c := range d.Coefficients {
+		for j := ncf - 1; j >= 0; j-- {
+			pv[i] += float64(pc[j] * c[j])
+		}
+		for j := ncf - 1; j >= 1; j-- {
+			pv[ncm+i] += float64(vc[j] * c[j])
+		}
+		pv[ncm+i] *= vfac
+	}
+	return pv
+}
+
+// pleph is PLEPH with the velocity flag set, for targets 1-13 and 14-17 (with center 0).
+func (r *testeph) pleph(et float64, ntarg, ncent int) [6]float64 {
+	var rrd [6]float64
+	if ntarg >= 14 {
+		copy(rrd[:], r.interp(jpleph.Quantity(ntarg-3), et))
+		return rrd
+	}
+	if ntarg == ncent {
+		return rrd
+	}
+	var pv, pvst [13][6]float64
+	for b := 0; b <= 10; b++ { // STATE with BARY: every body in AU from the SSB
+		for j, v := range r.interp(jpleph.Quantity(b), et) {
+			pvst[b][j] = v * r.aufac
+		}
+	}
+	copy(pv[:10], pvst[:10])
+	pv[10] = pvst[10]
+	pv[12] = pvst[2]
+	if ntarg*ncent == 30 && ntarg+ncent == 13 {
+		pv[2] = [6]float64{}
+	} else {
+		for i := 0; i < 6; i++ {
+			pv[2][i] = pvst[2][i] - pvst[9][i]/(1+r.emrat)
+			pv[9][i] = pv[2][i] + pvst[9][i]
+		}
+	}
+	for i := 0; i < 6; i++ {
+		rrd[i] = pv[ntarg-1][i] - pv[ncent-1][i]
+	}
+	return rrd
+}
+
+// syntheticTestpo returns testpo lines, without the header, of the states computed by testeph
+// for every target and center at n random epochs off the record boundaries of cfg.
+func syntheticTestpo(tb testing.TB, cfg jplephtest.Config, n int) string {
+	ref := newTesteph(tb, openSynthetic(tb, cfg))
+	var b strings.Builder
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		et := cfg.Start + (float64(rng.Intn(cfg.Records))+0.01+0.98*rng.Float64())*cfg.RecordDays
+		for ntarg := 1; ntarg <= 17; ntarg++ {
+			for ncent := 0; ncent <= 13; ncent++ {
+				if (ntarg >= 14) != (ncent == 0) {
+					continue
+				}
+				ncoord := 6
+				switch ntarg {
+				case 14:
+					ncoord = 4
+				case 17:
+					ncoord = 2
+				}
+				rrd := ref.pleph(et, ntarg, ncent)
+				for k := 0; k < ncoord; k++ {
+					fmt.Fprintf(&b, "%d 2000.01.01 %.17g %d %d %d %.20e\n", cfg.DENumber, et, ntarg, ncent, k+1, rrd[k])
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// TestCheckTestpoFortranOrder checks that WithFortranOrder reproduces testeph.f bit for bit,
+// through a testpo file of the states of every target and center at epochs off the record
+// boundaries.
+func TestCheckTestpoFortranOrder(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	e := openSynthetic(t, cfg, jpleph.WithFortranOrder())
+
+	testpo := "Synthetic testpo for WithFortranOrder\nEOT\n" + syntheticTestpo(t, cfg, 24)
+	cases, err := jpleph.ParseTestpo(strings.NewReader(testpo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range e.CheckTestpo(cases) {
+		c := r.Case
+		if r.Err != nil || r.ULPs != 0 {
+			t.Errorf("JD %.17g target %d center %d coordinate %d: got %.17g, want %.17g (%d ULPs, err %v)",
+				c.JD, c.Target, c.Center, c.Coordinate, r.Computed, c.Value, r.ULPs, r.Err)
+		}
+	}
+}
+
+// TestFortranOrderLaterOptionWins checks that WithFortranOrder and WithCompensatedSummation
+// replace each other, whatever their order.
+func TestFortranOrderLaterOptionWins(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	fortran := openSynthetic(t, cfg, jpleph.WithFortranOrder())
+	compensated := openSynthetic(t, cfg, jpleph.WithCompensatedSummation())
+	tests := []struct {
+		name string
+		opts []jpleph.Option
+		want *jpleph.Ephemeris
+	}{
+		{"compensated then Fortran", []jpleph.Option{jpleph.WithCompensatedSummation(), jpleph.WithFortranOrder()}, fortran},
+		{"Fortran then compensated", []jpleph.Option{jpleph.WithFortranOrder(), jpleph.WithCompensatedSummation()}, compensated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := openSynthetic(t, cfg, tt.opts...)
+			for k := 0; k < 200; k++ {
+				et := cfg.Start + 0.37 + float64(k)*3.1
+				for target := jpleph.Mercury; target <= jpleph.Sun; target++ {
+					p, v, err := e.CalculatePV(et, target, jpleph.CenterSolarSystemBarycenter, true)
+					wp, wv, werr := tt.want.CalculatePV(et, target, jpleph.CenterSolarSystemBarycenter, true)
+					if err != nil || werr != nil || p != wp || v != wv {
+						t.Fatalf("%v at %v: got %v %v (%v), want %v %v (%v)", target, et, p, v, err, wp, wv, werr)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,506 @@
+// ./frame_registry.go
+package jpleph
+
+/*
+Package jpleph provides a registry of named reference frames and the rotations between them.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// Frame names a reference frame of a FrameRegistry.
+type Frame string
+
+// Builtin frames of a FrameRegistry.
+const (
+	// FrameICRF is the International Celestial Reference Frame, the frame of the DE files and
+	// the root of every registry.
+	FrameICRF Frame = "ICRF"
+	// FrameEclipticJ2000 is the mean ecliptic and equinox of J2000.0 (as in Ecliptic).
+	FrameEclipticJ2000 Frame = "ECLIPJ2000"
+	// FrameEclipticOfDate is the mean ecliptic and equinox of date (IAU 2006 precession).
+	FrameEclipticOfDate Frame = "ECLIPDATE"
+	// FrameMeanOfDate is the mean equator and equinox of date (IAU 2006 precession).
+	FrameMeanOfDate Frame = "MOD"
+	// FrameTrueOfDate is the true equator and equinox of date, with the nutations of the file.
+	FrameTrueOfDate Frame = "TOD"
+	// FrameITRF is the Earth-fixed frame, rotated from FrameTrueOfDate by the Greenwich apparent
+	// sidereal time. Polar motion is ignored unless set with SetEarthOrientation.
+	FrameITRF Frame = "ITRF"
+	// FrameMoonPA is the lunar principal-axis frame, from the libration angles of the file.
+	FrameMoonPA Frame = "MOON_PA"
+	// FrameMoonME is the lunar mean-Earth/polar-axis frame, a fixed rotation of FrameMoonPA
+	// that depends on the DE version.
+	FrameMoonME Frame = "MOON_ME"
+	// FrameGalactic is the galactic frame (as in ToGalactic).
+	FrameGalactic Frame = "GALACTIC"
+	// FrameInvariable is the frame of the invariable plane (as in ToInvariablePlane).
+	FrameInvariable Frame = "INVARIABLE"
+	// FrameIAUSun to FrameIAUPluto are body-fixed frames from the IAU rotation models.
+	FrameIAUSun     Frame = "IAU_SUN"
+	FrameIAUMercury Frame = "IAU_MERCURY"
+	FrameIAUVenus   Frame = "IAU_VENUS"
+	FrameIAUEarth   Frame = "IAU_EARTH"
+	FrameIAUMars    Frame = "IAU_MARS"
+	FrameIAUJupiter Frame = "IAU_JUPITER"
+	FrameIAUSaturn  Frame = "IAU_SATURN"
+	FrameIAUUranus  Frame = "IAU_URANUS"
+	FrameIAUNeptune Frame = "IAU_NEPTUNE"
+	FrameIAUPluto   Frame = "IAU_PLUTO"
+)
+
+// maxFrameDepth bounds the number of rotations between a frame and the ICRF.
+const maxFrameDepth = 64
+
+// frameRateStep is the step in days of the central difference giving the rotation rate of
+// frames registered without one; the rounding of Julian Dates limits how short it can be.
+const frameRateStep = 1e-3
+
+// frameNode is a frame of a registry: the edge from its parent frame.
+type frameNode struct {
+	parent   Frame                                 // Frame the rotation starts from
+	rotation func(et float64) (linalg.Mat3, error) // Rotation from the parent to the frame at et
+	rate     func(et float64) (linalg.Mat3, error) // Time derivative of rotation per day (nil: by central difference)
+}
+
+// FrameRegistry holds named reference frames as the nodes of a tree rooted at the ICRF, each
+// with the rotation from its parent frame. Transform finds the path between two frames and
+// composes the rotations along it, so users can add their own frames (a spacecraft or
+// instrument frame, say) and convert between any pair. A FrameRegistry is not safe for
+// concurrent use, since it evaluates the ephemeris it was created from.
+type FrameRegistry struct {
+	eph    *Ephemeris
+	frames map[Frame]frameNode
+	deltaT func(jd float64) float64 // TT - UT1 in seconds for FrameITRF
+	eop    EarthOrientation         // Earth orientation of FrameITRF (SetEarthOrientation)
+}
+
+// iauRotation is an IAU rotation model: the pole (alpha0, delta0) and the prime meridian W in
+// degrees, linear in Julian centuries T and days d since J2000.
+type iauRotation struct {
+	alpha0, alphaT float64
+	delta0, deltaT float64
+	w0, wd         float64
+}
+
+// iauRotations holds the rotation models of the IAU Working Group on Cartographic Coordinates
+// and Rotational Elements (Archinal et al. 2011, 2009 report), without their small periodic
+// terms; the Neptune model is completed in iauFrame.
+var iauRotations = map[Frame]iauRotation{
+	FrameIAUSun:     {286.13, 0, 63.87, 0, 84.176, 14.1844000},
+	FrameIAUMercury: {281.0097, -0.0328, 61.4143, -0.0049, 329.5469, 6.1385025},
+	FrameIAUVenus:   {272.76, 0, 67.16, 0, 160.20, -1.4813688},
+	FrameIAUEarth:   {0.00, -0.641, 90.00, -0.557, 190.147, 360.9856235},
+	FrameIAUMars:    {317.68143, -0.1061, 52.88650, -0.0609, 176.630, 350.89198226},
+	FrameIAUJupiter: {268.056595, -0.006499, 64.495303, 0.002413, 284.95, 870.5360000},
+	FrameIAUSaturn:  {40.589, -0.036, 83.537, -0.004, 38.90, 810.7939024},
+	FrameIAUUranus:  {257.311, 0, -15.175, 0, 203.81, -501.1600928},
+	FrameIAUNeptune: {299.36, 0, 43.46, 0, 249.978, 541.1397757},
+	FrameIAUPluto:   {132.993, 0, -6.163, 0, 302.695, 56.3625225},
+}
+
+// iauFrame returns the rotation from the ICRF to a body-fixed frame, z along the pole
+// (alpha0, delta0) and x along the prime meridian, and its time derivative per day.
+func iauFrame(frame Frame, et float64) (linalg.Mat3, linalg.Mat3) {
+	m := iauRotations[frame]
+	d := et - J2000
+	t := d / daysPerJulianCentury
+	alpha, delta, w := m.alpha0+m.alphaT*t, m.delta0+m.deltaT*t, m.w0+m.wd*d
+	alphaDot, deltaDot, wDot := m.alphaT/daysPerJulianCentury, m.deltaT/daysPerJulianCentury, m.wd // Degrees per day
+	if frame == FrameIAUNeptune {
+		sinN, cosN := math.Sincos((357.85 + 52.316*t) * math.Pi / 180)
+		nDot := 52.316 / daysPerJulianCentury * math.Pi / 180
+		alpha, alphaDot = alpha+0.70*sinN, alphaDot+0.70*cosN*nDot
+		delta, deltaDot = delta-0.51*cosN, deltaDot+0.51*sinN*nDot
+		w, wDot = w-0.48*sinN, wDot-0.48*cosN*nDot
+	}
+	deg := math.Pi / 180
+	a, b, c := math.Mod(w, 360)*deg, (90-delta)*deg, (90+alpha)*deg
+	rot := linalg.RotZ(a).Mul(linalg.RotX(b)).Mul(linalg.RotZ(c))
+	rate := rotZRate(a).Mul(linalg.RotX(b)).Mul(linalg.RotZ(c)).Scale(wDot * deg).
+		Add(linalg.RotZ(a).Mul(rotXRate(b)).Mul(linalg.RotZ(c)).Scale(-deltaDot * deg)).
+		Add(linalg.RotZ(a).Mul(linalg.RotX(b)).Mul(rotZRate(c)).Scale(alphaDot * deg))
+	return rot, rate
+}
+
+// rotXRate returns the derivative of linalg.RotX with respect to the angle.
+func rotXRate(angle float64) linalg.Mat3 {
+	s, c := math.Sincos(angle)
+	return linalg.Mat3{{0, 0, 0}, {0, -s, c}, {0, -c, -s}}
+}
+
+// rotZRate returns the derivative of linalg.RotZ with respect to the angle.
+func rotZRate(angle float64) linalg.Mat3 {
+	s, c := math.Sincos(angle)
+	return linalg.Mat3{{-s, c, 0}, {-c, -s, 0}, {0, 0, 0}}
+}
+
+// moonMEAngles returns the rotation angles (arcseconds, about z, y, and x) from the lunar
+// principal-axis frame to the mean-Earth frame of a DE version, as given with the lunar
+// orientation of DE421 (Williams et al. 2008), DE430 (Folkner et al. 2014), and DE440
+// (Park et al. 2021).
+func moonMEAngles(denum int64) [3]float64 {
+	switch {
+	case denum >= 440:
+		return [3]float64{67.8526, 78.6944, 0.2785}
+	case denum >= 430:
+		return [3]float64{67.573, 78.580, 0.285}
+	}
+	return [3]float64{67.92, 78.56, 0.30}
+}
+
+// NewFrameRegistry returns a registry of the builtin frames (the Frame constants), evaluated
+// with the nutations and librations of the ephemeris where needed. FrameITRF uses the DeltaT
+// model unless SetDeltaT is called.
+func (e *Ephemeris) NewFrameRegistry() *FrameRegistry {
+	r := &FrameRegistry{eph: e, frames: make(map[Frame]frameNode), deltaT: DeltaT}
+	fixed := func(parent Frame, m linalg.Mat3) frameNode {
+		return frameNode{parent: parent,
+			rotation: func(float64) (linalg.Mat3, error) { return m, nil },
+			rate:     func(float64) (linalg.Mat3, error) { return linalg.Mat3{}, nil }}
+	}
+	r.frames[FrameEclipticJ2000] = fixed(FrameICRF, linalg.RotX(obliquityJ2000))
+	r.frames[FrameEclipticOfDate] = frameNode{parent: FrameEclipticJ2000, rotation: func(et float64) (linalg.Mat3, error) {
+		return eclipticPrecession(et), nil
+	}}
+	r.frames[FrameMeanOfDate] = frameNode{parent: FrameICRF, rotation: func(et float64) (linalg.Mat3, error) {
+		return equatorialPrecession(et), nil
+	}}
+	r.frames[FrameTrueOfDate] = frameNode{parent: FrameMeanOfDate, rotation: r.nutation}
+	r.frames[FrameITRF] = frameNode{parent: FrameTrueOfDate, rotation: r.earthRotation, rate: r.earthRotationRate}
+	r.frames[FrameMoonPA] = frameNode{parent: FrameICRF, rotation: r.moonPA, rate: r.moonPARate}
+	a := moonMEAngles(GetLong(e.ephemData, JPL_EPHEM_EPHEMERIS_VERSION))
+	r.frames[FrameMoonME] = fixed(FrameMoonPA,
+		linalg.RotX(-a[2]*arcsecToRad).Mul(linalg.RotY(-a[1]*arcsecToRad)).Mul(linalg.RotZ(-a[0]*arcsecToRad)))
+	r.frames[FrameGalactic] = fixed(FrameICRF, icrfToGalactic)
+	r.frames[FrameInvariable] = fixed(FrameEclipticJ2000, linalg.RotX(invariableInclination).Mul(linalg.RotZ(invariableNode)))
+	for frame := range iauRotations {
+		frame := frame
+		r.frames[frame] = frameNode{parent: FrameICRF,
+			rotation: func(et float64) (linalg.Mat3, error) { m, _ := iauFrame(frame, et); return m, nil },
+			rate:     func(et float64) (linalg.Mat3, error) { _, dm := iauFrame(frame, et); return dm, nil }}
+	}
+	return r
+}
+
+// SetDeltaT sets the source of TT - UT1 in seconds used for FrameITRF, e.g. the DeltaT method
+// of a DeltaTTable.
+func (r *FrameRegistry) SetDeltaT(deltaT func(jd float64) float64) {
+	r.deltaT = deltaT
+}
+
+// Register adds a frame defined by its rotation from a frame already in the registry. For
+// StateTransform, the rotation rate is taken by central differences over 86.4 seconds; use
+// RegisterRotating for frames spinning faster than about a turn per hour.
+//
+// Parameters:
+//   - name: Name of the new frame.
+//   - parent: Frame the rotation starts from.
+//   - rotation: Function returning, at et, the matrix whose rows are the axes of the new frame
+//     expressed in the parent frame, so that it maps parent coordinates into the new frame.
+//
+// Returns:
+//   - error: ErrFrame if name is already registered or parent is unknown.
+func (r *FrameRegistry) Register(name, parent Frame, rotation func(et float64) ([3][3]float64, error)) error {
+	if r.has(name) {
+		return fmt.Errorf("%w: %s is already registered", ErrFrame, name)
+	}
+	if !r.has(parent) {
+		return fmt.Errorf("%w: parent %s of %s", ErrFrame, parent, name)
+	}
+	r.frames[name] = frameNode{parent: parent, rotation: func(et float64) (linalg.Mat3, error) {
+		m, err := rotation(et)
+		return linalg.Mat3(m), err
+	}}
+	return nil
+}
+
+// RegisterRotating is Register for a frame whose rotation comes with its time derivative,
+// used by StateTransform for the velocity of the rotating frame.
+//
+// Parameters:
+//   - name, parent: As for Register.
+//   - rotation: Function returning, at et, the rotation (as for Register) and its time
+//     derivative per day.
+//
+// Returns:
+//   - error: As for Register.
+func (r *FrameRegistry) RegisterRotating(name, parent Frame, rotation func(et float64) ([3][3]float64, [3][3]float64, error)) error {
+	if err := r.Register(name, parent, func(et float64) ([3][3]float64, error) {
+		m, _, err := rotation(et)
+		return m, err
+	}); err != nil {
+		return err
+	}
+	node := r.frames[name]
+	node.rate = func(et float64) (linalg.Mat3, error) {
+		_, dm, err := rotation(et)
+		return linalg.Mat3(dm), err
+	}
+	r.frames[name] = node
+	return nil
+}
+
+// Frames returns the names of the registered frames, sorted.
+func (r *FrameRegistry) Frames() []Frame {
+	names := []Frame{FrameICRF}
+	for name := range r.frames {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Rotation returns the matrix mapping coordinates in one frame into another at et.
+//
+// Parameters:
+//   - from, to: Names of registered frames.
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - [3][3]float64: The rotation; its rows are the axes of to expressed in from.
+//   - error: ErrFrame for an unknown frame, or an error from the ephemeris (e.g.,
+//     ErrQuantityNotInEphemeris for FrameTrueOfDate from a file without nutations).
+func (r *FrameRegistry) Rotation(from, to Frame, et float64) ([3][3]float64, error) {
+	m, err := r.rotation(from, to, et)
+	return [3][3]float64(m), err
+}
+
+// Transform converts a position and velocity from one frame into another at et. Both are
+// rotated; the velocity does not include the motion of rotating frames (see StateTransform).
+//
+// Parameters:
+//   - pos, vel: State in frame from (any units).
+//   - from, to: Names of registered frames.
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - Position, Velocity: The state in frame to, in the same units.
+//   - error: As for Rotation.
+func (r *FrameRegistry) Transform(pos Position, vel Velocity, from, to Frame, et float64) (Position, Velocity, error) {
+	m, err := r.rotation(from, to, et)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	pos, vel = applyState(m, pos, vel)
+	return pos, vel, nil
+}
+
+// StateTransform converts a state from one frame into another at et, including the motion of
+// the frames: the velocity becomes R v + (dR/dt) x, where the second term is the omega x r
+// of a rotating frame. Use it between inertial and rotating frames, such as the ICRF and
+// FrameITRF or FrameMoonPA, where Transform would leave out the rotation velocity.
+//
+// Parameters:
+//   - pos: Position in frame from (any length unit).
+//   - vel: Velocity in frame from, in the length unit of pos per day (e.g., AU/day).
+//   - from, to: Names of registered frames.
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - Position, Velocity: The state in frame to, in the same units.
+//   - error: As for Rotation.
+func (r *FrameRegistry) StateTransform(pos Position, vel Velocity, from, to Frame, et float64) (Position, Velocity, error) {
+	m, dm, err := r.rotationRate(from, to, et)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	x := linalg.Vec3{pos.X, pos.Y, pos.Z}
+	p := m.Apply(x)
+	v := m.Apply(linalg.Vec3{vel.DX, vel.DY, vel.DZ}).Add(dm.Apply(x))
+	return Position{X: p[0], Y: p[1], Z: p[2]}, Velocity{DX: v[0], DY: v[1], DZ: v[2]}, nil
+}
+
+// RotationRate returns the rotation of Rotation and its time derivative per day.
+func (r *FrameRegistry) RotationRate(from, to Frame, et float64) ([3][3]float64, [3][3]float64, error) {
+	m, dm, err := r.rotationRate(from, to, et)
+	return [3][3]float64(m), [3][3]float64(dm), err
+}
+
+// has reports whether a frame is registered.
+func (r *FrameRegistry) has(name Frame) bool {
+	_, ok := r.frames[name]
+	return ok || name == FrameICRF
+}
+
+// path returns the frames from name up to the ICRF.
+func (r *FrameRegistry) path(name Frame) ([]Frame, error) {
+	path := []Frame{name}
+	for name != FrameICRF {
+		node, ok := r.frames[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrFrame, name)
+		}
+		if len(path) == maxFrameDepth {
+			return nil, fmt.Errorf("%w: %s is more than %d rotations from the ICRF", ErrFrame, path[0], maxFrameDepth)
+		}
+		name = node.parent
+		path = append(path, name)
+	}
+	return path, nil
+}
+
+// rotation composes the rotations from frame from up to the first frame it shares with to,
+// and from there down to to.
+func (r *FrameRegistry) rotation(from, to Frame, et float64) (linalg.Mat3, error) {
+	m, _, err := r.compose(from, to, et, false)
+	return m, err
+}
+
+// rotationRate is rotation with the time derivative of the rotation per day.
+func (r *FrameRegistry) rotationRate(from, to Frame, et float64) (linalg.Mat3, linalg.Mat3, error) {
+	return r.compose(from, to, et, true)
+}
+
+// compose returns the rotation from frame from to frame to, and its derivative if withRate.
+func (r *FrameRegistry) compose(from, to Frame, et float64, withRate bool) (linalg.Mat3, linalg.Mat3, error) {
+	fromPath, err := r.path(from)
+	if err != nil {
+		return linalg.Mat3{}, linalg.Mat3{}, err
+	}
+	toPath, err := r.path(to)
+	if err != nil {
+		return linalg.Mat3{}, linalg.Mat3{}, err
+	}
+	i, j := len(fromPath)-1, len(toPath)-1 // Both paths end at the ICRF
+	for i > 0 && j > 0 && fromPath[i-1] == toPath[j-1] {
+		i, j = i-1, j-1
+	}
+	up, dUp, err := r.down(fromPath[:i+1], et, withRate)
+	if err != nil {
+		return linalg.Mat3{}, linalg.Mat3{}, err
+	}
+	down, dDown, err := r.down(toPath[:j+1], et, withRate)
+	if err != nil {
+		return linalg.Mat3{}, linalg.Mat3{}, err
+	}
+	m := down.Mul(up.Transpose())
+	if !withRate {
+		return m, linalg.Mat3{}, nil
+	}
+	return m, dDown.Mul(up.Transpose()).Add(down.Mul(dUp.Transpose())), nil
+}
+
+// down returns the rotation from the last frame of path to the first, each frame being the
+// child of the next, and its derivative if withRate.
+func (r *FrameRegistry) down(path []Frame, et float64, withRate bool) (linalg.Mat3, linalg.Mat3, error) {
+	m, dm := linalg.Identity(), linalg.Mat3{}
+	for k := len(path) - 2; k >= 0; k-- {
+		node := r.frames[path[k]]
+		step, err := node.rotation(et)
+		if err != nil {
+			return linalg.Mat3{}, linalg.Mat3{}, fmt.Errorf("frame %s: %w", path[k], err)
+		}
+		if withRate {
+			dStep, err := node.stepRate(et)
+			if err != nil {
+				return linalg.Mat3{}, linalg.Mat3{}, fmt.Errorf("frame %s: %w", path[k], err)
+			}
+			dm = dStep.Mul(m).Add(step.Mul(dm)) // d(step m) = d(step) m + step dm
+		}
+		m = step.Mul(m)
+	}
+	return m, dm, nil
+}
+
+// stepRate returns the time derivative per day of the rotation of a frame, by central
+// differences when the frame has no rate function.
+func (n frameNode) stepRate(et float64) (linalg.Mat3, error) {
+	if n.rate != nil {
+		return n.rate(et)
+	}
+	before, err := n.rotation(et - frameRateStep)
+	if err != nil {
+		return linalg.Mat3{}, err
+	}
+	after, err := n.rotation(et + frameRateStep)
+	if err != nil {
+		return linalg.Mat3{}, err
+	}
+	return after.Add(before.Scale(-1)).Scale(1 / (2 * frameRateStep)), nil
+}
+
+// nutation returns the rotation from the mean to the true equator and equinox of date.
+func (r *FrameRegistry) nutation(et float64) (linalg.Mat3, error) {
+	return r.eph.nutationMatrix(et)
+}
+
+// earthRotation returns the rotation from the true equator and equinox of date to the
+// Earth-fixed frame, by the Greenwich apparent sidereal time and, with Earth orientation
+// parameters, polar motion.
+func (r *FrameRegistry) earthRotation(et float64) (linalg.Mat3, error) {
+	deltaT, pm := r.earthOrientation(et)
+	gast, err := r.apparentSiderealTime(et, deltaT)
+	return pm.Mul(linalg.RotZ(gast)), err
+}
+
+// earthRotationRate returns the derivative of earthRotation, from the rotation rate of the
+// Earth; the slow variations of the equation of the equinoxes and of polar motion are
+// neglected.
+func (r *FrameRegistry) earthRotationRate(et float64) (linalg.Mat3, error) {
+	deltaT, pm := r.earthOrientation(et)
+	gast, err := r.apparentSiderealTime(et, deltaT)
+	return pm.Mul(rotZRate(gast)).Scale(earthRotationRate), err
+}
+
+// earthRotationRate is the rate of the Earth rotation angle in radians per day (IERS
+// Conventions 2010, eq. 5.15), taking UT1 days for TDB days.
+const earthRotationRate = 2 * math.Pi * 1.00273781191135448
+
+// apparentSiderealTime returns the Greenwich apparent sidereal time in radians, for TT - UT1
+// in seconds.
+func (r *FrameRegistry) apparentSiderealTime(et, deltaT float64) (float64, error) {
+	dpsi, _, err := r.eph.nutationAngles(et)
+	if err != nil {
+		return 0, err
+	}
+	eqeq := dpsi * math.Cos(meanObliquity(et)) // Equation of the equinoxes
+	return greenwichMeanSiderealTime(et, deltaT) + eqeq, nil
+}
+
+// moonPA returns the rotation from the ICRF to the lunar principal axes, from the Euler
+// angles phi, theta, psi (z-x-z) of the file.
+func (r *FrameRegistry) moonPA(et float64) (linalg.Mat3, error) {
+	lib, _, err := r.eph.CalculatePV(et, Librations, 0, false)
+	if err != nil {
+		return linalg.Mat3{}, err
+	}
+	return linalg.RotZ(lib.Z).Mul(linalg.RotX(lib.Y)).Mul(linalg.RotZ(lib.X)), nil
+}
+
+// moonPARate returns the derivative of moonPA, from the libration rates of the file.
+func (r *FrameRegistry) moonPARate(et float64) (linalg.Mat3, error) {
+	lib, rate, err := r.eph.CalculatePV(et, Librations, 0, true)
+	if err != nil {
+		return linalg.Mat3{}, err
+	}
+	rz1, rx, rz3 := linalg.RotZ(lib.Z), linalg.RotX(lib.Y), linalg.RotZ(lib.X)
+	return rotZRate(lib.Z).Mul(rx).Mul(rz3).Scale(rate.DZ).
+		Add(rz1.Mul(rotXRate(lib.Y)).Mul(rz3).Scale(rate.DY)).
+		Add(rz1.Mul(rx).Mul(rotZRate(lib.X)).Scale(rate.DX)), nil
+}
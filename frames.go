@@ -0,0 +1,124 @@
+// ./frames.go
+package jpleph
+
+/*
+Package jpleph provides fixed rotations between the ICRF and other reference frames.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// applyState rotates a position and velocity with a fixed (time-independent) rotation.
+func applyState(m linalg.Mat3, pos Position, vel Velocity) (Position, Velocity) {
+	p := m.Apply([3]float64{pos.X, pos.Y, pos.Z})
+	v := m.Apply([3]float64{vel.DX, vel.DY, vel.DZ})
+	return Position{X: p[0], Y: p[1], Z: p[2]}, Velocity{DX: v[0], DY: v[1], DZ: v[2]}
+}
+
+// Invariable plane of the solar system (Souami and Souchay, A&A 543, A133, 2012), referred to
+// the J2000 ecliptic and equinox.
+const (
+	invariableInclination = 1.5786944 * math.Pi / 180   // 1 deg 34' 43.3"
+	invariableNode        = 107.5822222 * math.Pi / 180 // 107 deg 34' 56"
+)
+
+// icrfToInvariable rotates ICRF vectors into the invariable plane frame: first to the J2000
+// ecliptic, then to the node of the invariable plane, then onto the plane.
+var icrfToInvariable = linalg.RotX(invariableInclination).Mul(linalg.RotZ(invariableNode)).Mul(linalg.RotX(obliquityJ2000))
+
+// icrfToGalactic rotates ICRF vectors into galactic coordinates (Hipparcos Catalogue, ESA SP-1200,
+// Vol. 1, Sect. 1.5.3): x towards the galactic center, z towards the north galactic pole.
+var icrfToGalactic = linalg.Mat3{
+	{-0.0548755604162154, -0.8734370902348850, -0.4838350155487132},
+	{+0.4941094278755837, -0.4448296299600112, +0.7469822444972189},
+	{-0.8676661490190047, -0.1980763734312015, +0.4559837761750669},
+}
+
+// ToInvariablePlane expresses an ICRF state in the frame of the invariable plane of the solar
+// system: z along the total angular momentum pole, x towards the ascending node of the
+// invariable plane on the J2000 ecliptic.
+//
+// Parameters:
+//   - pos: Position in the ICRF (any length unit).
+//   - vel: Velocity in the ICRF (any rate unit).
+//
+// Returns:
+//   - Position, Velocity: The state in the invariable plane frame, in the same units.
+func ToInvariablePlane(pos Position, vel Velocity) (Position, Velocity) {
+	return applyState(icrfToInvariable, pos, vel)
+}
+
+// FromInvariablePlane converts a state from the invariable plane frame back to the ICRF.
+// It is the inverse of ToInvariablePlane.
+func FromInvariablePlane(pos Position, vel Velocity) (Position, Velocity) {
+	return applyState(icrfToInvariable.Transpose(), pos, vel)
+}
+
+// ToGalactic expresses an ICRF state in galactic Cartesian coordinates.
+//
+// Parameters:
+//   - pos: Position in the ICRF (any length unit).
+//   - vel: Velocity in the ICRF (any rate unit).
+//
+// Returns:
+//   - Position, Velocity: The state in galactic coordinates, in the same units.
+func ToGalactic(pos Position, vel Velocity) (Position, Velocity) {
+	return applyState(icrfToGalactic, pos, vel)
+}
+
+// FromGalactic converts a state from galactic Cartesian coordinates back to the ICRF.
+// It is the inverse of ToGalactic.
+func FromGalactic(pos Position, vel Velocity) (Position, Velocity) {
+	return applyState(icrfToGalactic.Transpose(), pos, vel)
+}
+
+// GalacticLonLat returns the galactic longitude, latitude, and distance of an ICRF position,
+// for example the apex of the Sun's motion or an interstellar inflow direction.
+//
+// Parameters:
+//   - pos: Position in the ICRF.
+//
+// Returns:
+//   - float64: Galactic longitude l in degrees, in [0, 360).
+//   - float64: Galactic latitude b in degrees, in [-90, 90].
+//   - float64: Distance, in the unit of pos.
+func GalacticLonLat(pos Position) (float64, float64, float64) {
+	g := icrfToGalactic.Apply([3]float64{pos.X, pos.Y, pos.Z})
+	return sphericalDegrees(g)
+}
+
+// sphericalDegrees converts a Cartesian vector into longitude and latitude (degrees) and length.
+func sphericalDegrees(x [3]float64) (float64, float64, float64) {
+	r := math.Sqrt(x[0]*x[0] + x[1]*x[1] + x[2]*x[2])
+	if r == 0 {
+		return 0, 0, 0
+	}
+	lon := math.Atan2(x[1], x[0]) * 180 / math.Pi
+	if lon < 0 {
+		lon += 360
+	}
+	return lon, math.Asin(x[2]/r) * 180 / math.Pi, r
+}
@@ -0,0 +1,184 @@
+// ./galilean.go
+package jpleph
+
+/*
+Package jpleph provides the mutual phenomena of Jupiter and its Galilean satellites.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// galileanStep is the sampling step of GalileanPhenomena in days (15 minutes), well below
+// the duration of the phenomena of Io, the shortest except for grazing ones.
+const galileanStep = 1.0 / 96
+
+// GalileanPhenomenonKind is the kind of a phenomenon of a Galilean satellite.
+type GalileanPhenomenonKind int
+
+const (
+	// GalileanTransit is the passage of the satellite in front of the disk of Jupiter.
+	GalileanTransit GalileanPhenomenonKind = iota
+	// GalileanShadowTransit is the passage of the shadow of the satellite across the disk of Jupiter.
+	GalileanShadowTransit
+	// GalileanOccultation is the passage of the satellite behind the disk of Jupiter.
+	GalileanOccultation
+	// GalileanEclipse is the passage of the satellite through the shadow of Jupiter.
+	GalileanEclipse
+)
+
+// galileanPhenomenonNames holds the names of the kinds, indexed by GalileanPhenomenonKind value.
+var galileanPhenomenonNames = [...]string{GalileanTransit: "transit", GalileanShadowTransit: "shadow transit",
+	GalileanOccultation: "occultation", GalileanEclipse: "eclipse"}
+
+// String returns the name of the kind (e.g., "shadow transit").
+func (k GalileanPhenomenonKind) String() string {
+	if k >= 0 && int(k) < len(galileanPhenomenonNames) {
+		return galileanPhenomenonNames[k]
+	}
+	return fmt.Sprintf("GalileanPhenomenonKind(%d)", int(k))
+}
+
+// GalileanPhenomenon is a transit, shadow transit, occultation, or eclipse of a Galilean
+// satellite, as observed from the center of the Earth.
+type GalileanPhenomenon struct {
+	Satellite Planet                 // Io, Europa, Ganymede, or Callisto
+	Kind      GalileanPhenomenonKind // Kind of phenomenon
+	Start     float64                // Ingress or disappearance, observed at the Earth (TDB Julian Date)
+	End       float64                // Egress or reappearance, observed at the Earth (TDB Julian Date)
+}
+
+// limbGap returns by how much the line through p (km, relative to the center of Jupiter) with
+// direction u misses the ellipsoid of Jupiter, in km of equatorial radius: negative when the
+// line crosses the planet. The ellipsoid is mapped to a sphere by stretching the body-fixed z
+// axis, m being the rotation from the ICRF to the body-fixed frame.
+func limbGap(m linalg.Mat3, p, u [3]float64) float64 {
+	radii := bodyRadii[Jupiter]
+	q, v := m.Apply(p), m.Apply(u)
+	q[2] *= radii[0] / radii[1]
+	v[2] *= radii[0] / radii[1]
+	qv, vv := linalg.Vec3(q).Dot(v), linalg.Vec3(v).Dot(v)
+	closest := linalg.Vec3(q).Sub(linalg.Vec3(v).Scale(qv / vv))
+	return closest.Norm() - radii[0]
+}
+
+// galileanGeometry returns a function of the observation epoch giving, for a satellite, the
+// limb gap (see limbGap) of each kind of phenomenon: negative while it is in progress.
+func (e *Ephemeris) galileanGeometry(satellite Planet, kind GalileanPhenomenonKind) func(et float64) (float64, error) {
+	au := e.ephemData.au
+	c := speedOfLightKMS * secondsPerDay / au // AU/day
+	return func(et float64) (float64, error) {
+		earth, _, err := e.CalculatePV(et, Earth, CenterSolarSystemBarycenter, false)
+		if err != nil {
+			return 0, err
+		}
+		obs := [3]float64{earth.X, earth.Y, earth.Z}
+		xJ, err := e.lightTimePosition(et, JupiterCenter, obs)
+		if err != nil {
+			return 0, err
+		}
+		xS, err := e.lightTimePosition(et, satellite, obs)
+		if err != nil {
+			return 0, err
+		}
+		tJ, tS := et-norm3(xJ)/c, et-norm3(xS)/c
+
+		var p, u [3]float64 // A line relative to Jupiter, and whether the phenomenon is on its near side
+		var near bool
+		switch kind {
+		case GalileanTransit, GalileanOccultation: // The line of sight through the satellite
+			p = linalg.Vec3(xS).Sub(xJ)
+			u = xJ
+			near = kind == GalileanTransit
+		default: // The sunlight through the satellite, when it leaves the satellite
+			t := tS // The satellite entering the shadow, seen when its light reaches the Earth
+			if kind == GalileanShadowTransit {
+				t = tJ // The shadow on Jupiter, seen when the light of Jupiter reaches the Earth
+			}
+			s, _, err := e.CalculatePV(t, satellite, CenterSolarSystemBarycenter, false)
+			if err != nil {
+				return 0, err
+			}
+			j, _, err := e.CalculatePV(t, JupiterCenter, CenterSolarSystemBarycenter, false)
+			if err != nil {
+				return 0, err
+			}
+			sat := [3]float64{s.X, s.Y, s.Z}
+			sun, err := e.lightTimePosition(t, Sun, sat)
+			if err != nil {
+				return 0, err
+			}
+			p = [3]float64{s.X - j.X, s.Y - j.Y, s.Z - j.Z}
+			u = linalg.Vec3(sun).Scale(-1) // From the Sun
+			near = kind == GalileanShadowTransit
+		}
+		for i := range p {
+			p[i] *= au
+		}
+		rot, _ := iauFrame(FrameIAUJupiter, tJ)
+		gap := limbGap(rot, p, u)
+		if (linalg.Vec3(p).Dot(u) < 0) != near {
+			// Beyond the planet: the line never crosses it on the side of the phenomenon, and
+			// the gap is positive where the satellite changes sides, so the sign is continuous
+			gap = math.Abs(gap)
+		}
+		return gap, nil
+	}
+}
+
+// GalileanPhenomena predicts the transits, shadow transits, occultations, and eclipses of
+// the Galilean satellites between start and end, as observed from the center of the Earth:
+// the times of the classic almanac tables. The states of the satellites and of the center of
+// Jupiter come from a satellite kernel loaded with LoadSPK (e.g., jup365.bsp), corrected for
+// light time. The contacts are those of the center of the satellite with the limb of the
+// oblate disk of Jupiter, or with the geometric edge of its shadow (between the umbra and
+// the penumbra), so the partial phases around them last from a few minutes (Io) to about
+// half an hour (Callisto). Phenomena in progress at start or end are clipped to them.
+//
+// Parameters:
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//
+// Returns:
+//   - []GalileanPhenomenon: The phenomena by start time.
+//   - error: ErrEventSearch for an invalid interval, or an error from CalculatePV, such as
+//     when no loaded kernel gives the satellites or the center of Jupiter over the interval.
+func (e *Ephemeris) GalileanPhenomena(start, end float64) ([]GalileanPhenomenon, error) {
+	var res []GalileanPhenomenon
+	for _, satellite := range []Planet{Io, Europa, Ganymede, Callisto} {
+		for kind := GalileanTransit; kind <= GalileanEclipse; kind++ {
+			windows, err := findWindows(e.galileanGeometry(satellite, kind), WindowSearch{Start: start, End: end, Step: galileanStep})
+			if err != nil {
+				return nil, fmt.Errorf("%v %v: %w", satellite, kind, err)
+			}
+			for _, w := range windows {
+				res = append(res, GalileanPhenomenon{Satellite: satellite, Kind: kind, Start: w.Start, End: w.End})
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Start < res[j].Start })
+	return res, nil
+}
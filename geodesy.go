@@ -0,0 +1,176 @@
+// ./geodesy.go
+package jpleph
+
+/*
+Package jpleph provides reference ellipsoids and conversions between geodetic and geocentric coordinates.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// Ellipsoid is a reference ellipsoid of revolution of the Earth, to which the geodetic
+// coordinates of a Site refer.
+type Ellipsoid struct {
+	A float64 // Equatorial radius (semi-major axis) in km
+	F float64 // Flattening, (A - B) / A for the polar radius B
+}
+
+// Reference ellipsoids of the Earth.
+var (
+	// WGS84 is the ellipsoid of the World Geodetic System 1984, the default of Site (GPS).
+	WGS84 = Ellipsoid{A: wgs84A, F: wgs84F}
+	// GRS80 is the Geodetic Reference System 1980 ellipsoid of the ITRF realizations and of
+	// ETRS89 and NAD83; its polar radius differs from that of WGS84 by 0.1 mm.
+	GRS80 = Ellipsoid{A: 6378.137, F: 1 / 298.257222101}
+)
+
+// NewEllipsoid returns the ellipsoid of an equatorial radius and an inverse flattening, as
+// geodetic datums publish them.
+//
+// Parameters:
+//   - a: Equatorial radius in km.
+//   - inverseFlattening: 1/f (e.g., 298.257223563 for WGS84); 0 or +Inf for a sphere.
+//
+// Returns:
+//   - Ellipsoid: The ellipsoid.
+//   - error: ErrSite if the radius is not positive or the flattening is not in [0, 1).
+func NewEllipsoid(a, inverseFlattening float64) (Ellipsoid, error) {
+	el := Ellipsoid{A: a}
+	if inverseFlattening != 0 {
+		el.F = 1 / inverseFlattening
+	}
+	if !el.valid() {
+		return Ellipsoid{}, fmt.Errorf("%w: ellipsoid with radius %g km and inverse flattening %g", ErrSite, a, inverseFlattening)
+	}
+	return el, nil
+}
+
+// valid reports whether the radius is positive and finite and the flattening in [0, 1).
+func (el Ellipsoid) valid() bool {
+	return el.A > 0 && !math.IsInf(el.A, 1) && el.F >= 0 && el.F < 1
+}
+
+// B returns the polar radius (semi-minor axis) in km.
+func (el Ellipsoid) B() float64 {
+	return el.A * (1 - el.F)
+}
+
+// Geocentric converts geodetic coordinates on the ellipsoid to the Earth-fixed (ITRF)
+// Cartesian position.
+//
+// Parameters:
+//   - lat: Geodetic latitude in degrees, north positive.
+//   - lon: Longitude in degrees, east positive.
+//   - height: Height above the ellipsoid in meters, as in Site.
+//
+// Returns:
+//   - [3]float64: The position in km.
+func (el Ellipsoid) Geocentric(lat, lon, height float64) [3]float64 {
+	return geodeticPosition(lat, lon, height/1000, el.A, el.F)
+}
+
+// Geodetic converts an Earth-fixed (ITRF) Cartesian position to geodetic coordinates on the
+// ellipsoid, the inverse of Geocentric. The latitude is iterated to convergence, which takes
+// a few steps from the surface to beyond the Moon.
+//
+// Parameters:
+//   - x: Position in km.
+//
+// Returns:
+//   - lat: Geodetic latitude in degrees, north positive; ±90 on the polar axis.
+//   - lon: Longitude in degrees, east positive, in (-180, 180]; 0 on the polar axis.
+//   - height: Height above the ellipsoid in meters.
+func (el Ellipsoid) Geodetic(x [3]float64) (lat, lon, height float64) {
+	e2 := el.F * (2 - el.F)
+	p := math.Hypot(x[0], x[1])
+	if p == 0 {
+		return math.Copysign(90, x[2]), 0, (math.Abs(x[2]) - el.B()) * 1000
+	}
+	lon = math.Atan2(x[1], x[0]) * 180 / math.Pi
+	phi := math.Atan2(x[2], p*(1-e2))
+	var h float64
+	for i := 0; i < 10; i++ {
+		sinPhi, cosPhi := math.Sincos(phi)
+		n := el.A / math.Sqrt(1-e2*sinPhi*sinPhi) // Prime vertical radius of curvature
+		if math.Abs(cosPhi) > math.Abs(sinPhi) {
+			h = p/cosPhi - n
+		} else {
+			h = x[2]/sinPhi - n*(1-e2) // Near the poles, where p/cos(phi) loses precision
+		}
+		next := math.Atan2(x[2], p*(1-e2*n/(n+h)))
+		if math.Abs(next-phi) < 1e-15 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+	return phi * 180 / math.Pi, lon, h * 1000
+}
+
+// ParallaxConstants returns the components of the geocentric radius vector of a site in the
+// meridian plane, rho cos(phi') and rho sin(phi'), in units of the equatorial radius, where
+// phi' is the geocentric latitude: the site coordinates of diurnal parallax reductions (as
+// tabulated in the Astronomical Almanac).
+//
+// Parameters:
+//   - lat: Geodetic latitude in degrees.
+//   - height: Height above the ellipsoid in meters.
+//
+// Returns:
+//   - rhoCosPhi: Distance from the polar axis over the equatorial radius.
+//   - rhoSinPhi: Distance from the equatorial plane over the equatorial radius, north positive.
+func (el Ellipsoid) ParallaxConstants(lat, height float64) (rhoCosPhi, rhoSinPhi float64) {
+	x := el.Geocentric(lat, 0, height)
+	return x[0] / el.A, x[2] / el.A
+}
+
+// ellipsoid returns the reference ellipsoid of the site, WGS84 unless set.
+func (s Site) ellipsoid() Ellipsoid {
+	if s.Ellipsoid == (Ellipsoid{}) {
+		return WGS84
+	}
+	return s.Ellipsoid
+}
+
+// SiteVector returns the geocentric state of a site on the Earth in the ICRF: its radius vector
+// rotated from FrameITRF, and the velocity of the rotation of the Earth at the site. Adding it
+// to the geocentric state of the Earth gives that of the site (see SiteState).
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - site: Observer location, on the ellipsoid of the site.
+//
+// Returns:
+//   - Position, Velocity: The state in AU and AU/day.
+//   - error: ErrSite for an invalid site, or an error from the frames (e.g.,
+//     ErrQuantityNotInEphemeris for a file without nutations).
+func (r *FrameRegistry) SiteVector(et float64, site Site) (Position, Velocity, error) {
+	if !site.valid() {
+		return Position{}, Velocity{}, fmt.Errorf("%w: coordinates of %+v", ErrSite, site)
+	}
+	au := r.eph.ephemData.au
+	x := site.ecef()
+	return r.StateTransform(Position{X: x[0] / au, Y: x[1] / au, Z: x[2] / au}, Velocity{}, FrameITRF, FrameICRF, et)
+}
@@ -0,0 +1,172 @@
+// ./geojson.go
+package jpleph
+
+/*
+Package jpleph provides the GeoJSON encoding of tracks on the Earth.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// geoJSONGeometry is a GeoJSON geometry object (RFC 7946).
+type geoJSONGeometry struct {
+	Type        string `json:"type"`        // Geometry type (e.g., "MultiLineString")
+	Coordinates any    `json:"coordinates"` // Positions as [longitude, latitude] in degrees, nested as the type requires
+}
+
+// geoJSONFeature is a GeoJSON feature object.
+type geoJSONFeature struct {
+	Type       string          `json:"type"` // Always "Feature"
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+// geoJSONFeatureCollection is a GeoJSON feature collection, the top-level object written.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"` // Always "FeatureCollection"
+	Features []geoJSONFeature `json:"features"`
+}
+
+// lineFeature returns a MultiLineString feature through points given as [longitude,
+// latitude] in degrees, cut where it crosses the antimeridian.
+func lineFeature(points [][2]float64, properties map[string]any) geoJSONFeature {
+	lines := splitAntimeridian(points)
+	if lines == nil {
+		lines = [][][2]float64{} // An empty geometry rather than null
+	}
+	return geoJSONFeature{Type: "Feature", Geometry: geoJSONGeometry{Type: "MultiLineString", Coordinates: lines},
+		Properties: properties}
+}
+
+// splitAntimeridian cuts a line of [longitude, latitude] points into pieces that do not
+// cross the antimeridian, as RFC 7946 recommends, so that web maps do not draw the line the
+// long way round. A step of more than 180 degrees in longitude is taken as a crossing, and
+// both pieces end at ±180 degrees with the latitude interpolated there.
+func splitAntimeridian(points [][2]float64) [][][2]float64 {
+	var lines [][][2]float64
+	var line [][2]float64
+	for i, p := range points {
+		if i > 0 {
+			q := points[i-1]
+			if dl := p[0] - q[0]; math.Abs(dl) > 180 {
+				edge := math.Copysign(180, q[0]) // Side of the antimeridian q is on
+				unwrapped := p[0] + 2*edge
+				lat := q[1] + (edge-q[0])/(unwrapped-q[0])*(p[1]-q[1])
+				line = append(line, [2]float64{edge, lat})
+				lines = append(lines, line)
+				line = [][2]float64{{-edge, lat}}
+			}
+		}
+		line = append(line, p)
+	}
+	if len(line) > 1 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// polygonFeature returns a Polygon feature bounded by a ring of [longitude, latitude]
+// points in degrees, or a MultiPolygon of its pieces when it crosses the antimeridian. The
+// ring is closed and made counterclockwise, as RFC 7946 recommends.
+func polygonFeature(ring [][2]float64, properties map[string]any) geoJSONFeature {
+	// Longitudes made continuous along the ring, so that it can be cut at ±180 + 360 k
+	unwrapped := make([][2]float64, len(ring))
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, p := range ring {
+		if i > 0 {
+			prev := unwrapped[i-1][0]
+			p[0] = prev + math.Remainder(p[0]-prev, 360)
+		}
+		unwrapped[i] = p
+		lo, hi = math.Min(lo, p[0]), math.Max(hi, p[0])
+	}
+
+	var polygons [][][][2]float64
+	for k := math.Floor((lo + 180) / 360); k <= math.Floor((hi+180)/360); k++ {
+		west, east := 360*k-180, 360*k+180
+		piece := clipLongitude(clipLongitude(unwrapped, west, true), east, false)
+		if len(piece) < 3 {
+			continue
+		}
+		for i := range piece {
+			piece[i][0] -= 360 * k
+		}
+		if ringArea(piece) < 0 {
+			for i, j := 0, len(piece)-1; i < j; i, j = i+1, j-1 {
+				piece[i], piece[j] = piece[j], piece[i]
+			}
+		}
+		piece = append(piece, piece[0])
+		polygons = append(polygons, [][][2]float64{piece})
+	}
+	switch len(polygons) {
+	case 0:
+		return geoJSONFeature{Type: "Feature", Geometry: geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]float64{}},
+			Properties: properties}
+	case 1:
+		return geoJSONFeature{Type: "Feature", Geometry: geoJSONGeometry{Type: "Polygon", Coordinates: polygons[0]},
+			Properties: properties}
+	}
+	return geoJSONFeature{Type: "Feature", Geometry: geoJSONGeometry{Type: "MultiPolygon", Coordinates: polygons},
+		Properties: properties}
+}
+
+// clipLongitude clips an open ring to the side of a meridian, east of it with east set and
+// west of it otherwise (Sutherland-Hodgman).
+func clipLongitude(ring [][2]float64, meridian float64, east bool) [][2]float64 {
+	inside := func(p [2]float64) bool { return (p[0] >= meridian) == east || p[0] == meridian }
+	var out [][2]float64
+	for i, p := range ring {
+		q := ring[(i+len(ring)-1)%len(ring)] // Previous point, closing the ring
+		if inside(p) != inside(q) {
+			t := (meridian - q[0]) / (p[0] - q[0])
+			out = append(out, [2]float64{meridian, q[1] + t*(p[1]-q[1])})
+		}
+		if inside(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ringArea returns the signed area of an open ring in square degrees, positive when it is
+// counterclockwise.
+func ringArea(ring [][2]float64) float64 {
+	area := 0.0
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
+
+// writeGeoJSON writes features as a GeoJSON feature collection on one line.
+func writeGeoJSON(w io.Writer, features []geoJSONFeature) error {
+	if features == nil {
+		features = []geoJSONFeature{}
+	}
+	return json.NewEncoder(w).Encode(geoJSONFeatureCollection{Type: "FeatureCollection", Features: features})
+}
@@ -0,0 +1,84 @@
+// ./golden_test.go
+package jpleph_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// TestEmbeddedGoldenFiles checks that every embedded reference file parses.
+func TestEmbeddedGoldenFiles(t *testing.T) {
+	for _, de := range jpleph.EmbeddedGoldenVersions() {
+		cases, err := jpleph.EmbeddedGoldenCases(de)
+		if err != nil || len(cases) == 0 {
+			t.Errorf("DE%d: %d cases (%v)", de, len(cases), err)
+		}
+		for _, c := range cases {
+			if c.DE != de {
+				t.Errorf("DE%d: line %d is for DE%d", de, c.Line, c.DE)
+				break
+			}
+		}
+	}
+}
+
+// TestVerifyAgainstEmbedded checks VerifyAgainstEmbedded with reference states computed by
+// testeph for a synthetic DE440 file: they pass, in the default mode and with
+// WithFortranOrder, cases outside the span are skipped, a perturbed value fails, and files of
+// other DE numbers have no reference states.
+func TestVerifyAgainstEmbedded(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	lines := syntheticTestpo(t, cfg, 4)
+	outside := fmt.Sprintf("%d 2100.01.01 %.17g 3 12 1 1.0\n", cfg.DENumber, cfg.End()+1000)
+	golden := func(text string) fstest.MapFS {
+		return fstest.MapFS{fmt.Sprintf("golden/testpo.%d", cfg.DENumber): {Data: []byte(text)}}
+	}
+	defer jpleph.SetGoldenFS(golden(lines + outside))()
+
+	if got := jpleph.EmbeddedGoldenVersions(); len(got) != 1 || got[0] != cfg.DENumber {
+		t.Fatalf("EmbeddedGoldenVersions: %v, want [%d]", got, cfg.DENumber)
+	}
+	want := strings.Count(lines, "\n")
+	for _, opts := range [][]jpleph.Option{nil, {jpleph.WithFortranOrder()}} {
+		rep, err := jpleph.VerifyAgainstEmbedded(openSynthetic(t, cfg, opts...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rep.Passed() || rep.Checked != want || rep.Skipped != 1 || rep.DE != cfg.DENumber {
+			t.Errorf("options %d: report %+v, want %d cases passed and 1 skipped", len(opts), summary(rep), want)
+		}
+	}
+
+	// Perturb the last digits of the first value by 1e-12
+	first, rest, _ := strings.Cut(lines, "\n")
+	f := strings.Fields(first)
+	var v float64
+	fmt.Sscan(f[6], &v)
+	f[6] = fmt.Sprintf("%.20e", v+1e-12)
+	jpleph.SetGoldenFS(golden(strings.Join(f, " ") + "\n" + rest))
+	rep, err := jpleph.VerifyAgainstEmbedded(openSynthetic(t, cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Passed() || rep.Failed != 1 {
+		t.Errorf("perturbed value: report %+v, want 1 failure", summary(rep))
+	}
+
+	other := cfg
+	other.DENumber = 421
+	if _, err := jpleph.VerifyAgainstEmbedded(openSynthetic(t, other)); !errors.Is(err, jpleph.ErrNoGoldenVectors) {
+		t.Errorf("DE421: %v, want ErrNoGoldenVectors", err)
+	}
+}
+
+// summary returns a report without its results, for messages.
+func summary(rep jpleph.GoldenReport) jpleph.GoldenReport {
+	rep.Results = nil
+	return rep
+}
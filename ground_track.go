@@ -0,0 +1,132 @@
+// ./ground_track.go
+package jpleph
+
+/*
+Package jpleph provides the ground tracks of spacecraft and other bodies over the Earth.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// GroundTrackPoint is the sub-satellite point of a body at one epoch.
+type GroundTrackPoint struct {
+	ET        float64 // Epoch (TDB Julian Date)
+	Latitude  float64 // Geodetic latitude in degrees, north positive
+	Longitude float64 // Longitude in degrees, east positive, in (-180, 180]
+	Height    float64 // Height of the body above the ellipsoid in meters
+}
+
+// GroundTrack is the path of the sub-satellite point of a body over the Earth.
+type GroundTrack struct {
+	Body      Planet             // Body followed
+	Name      string             // Name of the body: that of a custom body, or Body.String()
+	Ellipsoid Ellipsoid          // Reference ellipsoid of the coordinates
+	Points    []GroundTrackPoint // Points in chronological order
+}
+
+// GroundTrack samples the ground track of a body: the point of the ellipsoid below it along
+// the normal, at the geodetic latitude and longitude of its geocentric position in the ITRF.
+// The Earth orientation is that of the registry, so an EOP table set with
+// SetEarthOrientation places the track to the centimeter level. Positions are geometric,
+// uncorrected for light time.
+//
+// Parameters:
+//   - body: Body to follow, typically a spacecraft registered with AddCustomBody or AddSource.
+//   - start, end: Time span as ephemeris time (TDB) Julian Dates; end is included when it
+//     falls on a step.
+//   - step: Sampling step in days (e.g., 1.0/1440 for one minute).
+//   - el: Reference ellipsoid; zero for WGS84.
+//
+// Returns:
+//   - *GroundTrack: The sampled track.
+//   - error: ErrEventSearch for an empty span or a non-positive step, ErrSite for an
+//     invalid ellipsoid, or an error from CalculatePV or the frames.
+func (r *FrameRegistry) GroundTrack(body Planet, start, end, step float64, el Ellipsoid) (*GroundTrack, error) {
+	if !(step > 0) || !(end >= start) {
+		return nil, fmt.Errorf("%w: ground track span %v to %v with step %v", ErrEventSearch, start, end, step)
+	}
+	if el == (Ellipsoid{}) {
+		el = WGS84
+	}
+	if !el.valid() {
+		return nil, fmt.Errorf("%w: ellipsoid %+v", ErrSite, el)
+	}
+	au := r.eph.ephemData.au
+	n := int(math.Floor((end-start)/step+1e-9)) + 1
+	track := &GroundTrack{Body: body, Name: body.String(), Ellipsoid: el, Points: make([]GroundTrackPoint, 0, n)}
+	if cb, ok := r.eph.customBodies[body]; ok && cb.Name != "" {
+		track.Name = cb.Name
+	}
+	for i := 0; i < n; i++ {
+		et := start + float64(i)*step
+		pos, vel, err := r.eph.CalculatePV(et, body, CenterEarth, false)
+		if err != nil {
+			return nil, fmt.Errorf("%v at JD %.5f: %w", body, et, err)
+		}
+		pos, _, err = r.Transform(pos, vel, FrameICRF, FrameITRF, et)
+		if err != nil {
+			return nil, err
+		}
+		lat, lon, h := el.Geodetic([3]float64{pos.X * au, pos.Y * au, pos.Z * au})
+		track.Points = append(track.Points, GroundTrackPoint{ET: et, Latitude: lat, Longitude: lon, Height: h})
+	}
+	return track, nil
+}
+
+// WriteCSV writes the track as CSV with the header jd_tdb,lat,lon,height_m.
+func (t *GroundTrack) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"jd_tdb", "lat", "lon", "height_m"}); err != nil {
+		return err
+	}
+	for _, p := range t.Points {
+		record := []string{strconv.FormatFloat(p.ET, 'f', -1, 64), strconv.FormatFloat(p.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(p.Longitude, 'f', -1, 64), strconv.FormatFloat(p.Height, 'f', -1, 64)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteGeoJSON writes the track as a GeoJSON feature collection holding one MultiLineString
+// feature, cut at the antimeridian, with the name of the body and the time span (jd_tdb_start,
+// jd_tdb_end) as properties. Heights are left out of the coordinates.
+func (t *GroundTrack) WriteGeoJSON(w io.Writer) error {
+	points := make([][2]float64, len(t.Points))
+	for i, p := range t.Points {
+		points[i] = [2]float64{p.Longitude, p.Latitude}
+	}
+	properties := map[string]any{"body": t.Name}
+	if len(t.Points) > 0 {
+		properties["jd_tdb_start"] = t.Points[0].ET
+		properties["jd_tdb_end"] = t.Points[len(t.Points)-1].ET
+	}
+	return writeGeoJSON(w, []geoJSONFeature{lineFeature(points, properties)})
+}
@@ -0,0 +1,21 @@
+// ./helpers_test.go
+package jpleph_test
+
+import (
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// openSynthetic generates a synthetic file from cfg and opens it with the given options,
+// closing it when the test ends.
+func openSynthetic(tb testing.TB, cfg jplephtest.Config, opts ...jpleph.Option) *jpleph.Ephemeris {
+	tb.Helper()
+	e, err := jpleph.NewEphemeris(jplephtest.TempFile(tb, cfg), true, opts...)
+	if err != nil {
+		tb.Fatalf("NewEphemeris: %v", err)
+	}
+	tb.Cleanup(func() { e.Close() })
+	return e
+}
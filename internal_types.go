@@ -67,7 +67,10 @@ Piotr A. Dybczynski and later revised by Bill J Gray.
 //   - rpt[0..2]:  ipt[13][0..2] - Lunar Euler angle rate offsets (new in DE-430t and later)
 //   - tpt[0..2]:  ipt[14][0..2] - TT-TDB offsets (new in DE-430t and later)
 //
-// For DE versions prior to DE-430t, ipt[13] and ipt[14] are typically zero or invalid.
+// For DE versions prior to DE-430t, ipt[13] and ipt[14] are typically zero or invalid. The
+// reader keeps each of them only when it starts where the preceding quantities of the record
+// end (see validateExtendedIPT), whatever the DE number, so INPOP files and files with TT-TDB
+// but no mantle angular velocities keep what they have.
 //
 // Constant Names (Beyond 400, if present):
 //
@@ -76,6 +79,10 @@ Piotr A. Dybczynski and later revised by Bill J Gray.
 //   - Bytes 2862-2867: Name of the 401st (402nd) constant (6 bytes)
 //   - ... and so on, until all constant names are listed.
 //
+// Some files store ncon as exactly 400 and list further names: the reader counts them up to
+// the first 6-byte field holding a NUL byte or only blanks, as the C version does, within
+// the first record.
+//
 // IPT[13] and IPT[14] Data Location:
 //
 // After the last constant name (or immediately after byte 2855 if ncon <= 400), the data for ipt[13][0..2] and ipt[14][0..2] is stored in 24 bytes:
@@ -119,9 +126,14 @@ Piotr A. Dybczynski and later revised by Bill J Gray.
 // An assertion in the code will trigger if this value needs to be increased for future ephemerides.
 const maxCheby = 18
 
-// interpolationInfo struct holds data required for Chebyshev interpolation.
-// Used to optimize interpolation by storing and reusing Chebyshev polynomial values.
-type interpolationInfo struct {
+// chebyshevSlots is the number of normalized times whose Chebyshev polynomial values are
+// kept by interpolationInfo: one per sub-interval length of the JPL kernels (1, 2, 4, and 8
+// sub-intervals per record), so that querying several bodies at one epoch, the Sun included,
+// evaluates the polynomials once for each length.
+const chebyshevSlots = 4
+
+// chebyshevValues holds the Chebyshev polynomials and their derivatives at one normalized time.
+type chebyshevValues struct {
 	posnCoeff  [maxCheby]float64 // posnCoeff stores Chebyshev polynomial values T_i(tc).
 	velCoeff   [maxCheby]float64 // velCoeff stores derivatives of Chebyshev polynomials T'_i(tc).
 	nPosnAvail uint              // nPosnAvail indicates the number of position Chebyshev polynomials already computed and available in posnCoeff.
@@ -129,6 +141,69 @@ type interpolationInfo struct {
 	twot       float64           // twot stores 2 * tc, used as an optimization in Chebyshev recurrence relations.
 }
 
+// reset sets the values to those of no normalized time, so that any tc misses them.
+func (cv *chebyshevValues) reset() {
+	cv.posnCoeff[0] = 1.0  // Initial Chebyshev polynomial values
+	cv.posnCoeff[1] = -2.0 // Bogus initial value, corrected in interp()
+	cv.velCoeff[0] = 0.0
+	cv.velCoeff[1] = 1.0
+	cv.nPosnAvail = 0
+	cv.nVelAvail = 0
+}
+
+// interpolationInfo struct holds data required for Chebyshev interpolation.
+// Used to optimize interpolation by storing and reusing Chebyshev polynomial values.
+type interpolationInfo struct {
+	slots [chebyshevSlots]chebyshevValues // slots hold the polynomial values at the last normalized times used.
+	next  int                             // next is the slot replaced by the next normalized time not in slots.
+
+	compensated  bool // compensated selects compensated summation of the Chebyshev series (see WithCompensatedSummation); kept by reset.
+	fortranOrder bool // fortranOrder selects the operation order of the Fortran reader (see WithFortranOrder); kept by reset.
+}
+
+// reset restores the interpolation state to its initial values, forcing interp()
+// to recompute the Chebyshev polynomials on its next call.
+func (iinfo *interpolationInfo) reset() {
+	for k := range iinfo.slots {
+		iinfo.slots[k].reset()
+	}
+	iinfo.next = 0
+}
+
+// values returns the slot holding the polynomial values at tc, replacing the oldest slot
+// (at least T_0, T_1, T'_0, and T'_1) when tc is not among them.
+func (iinfo *interpolationInfo) values(tc float64) *chebyshevValues {
+	for k := range iinfo.slots {
+		if iinfo.slots[k].posnCoeff[1] == tc && iinfo.slots[k].nPosnAvail > 0 {
+			return &iinfo.slots[k]
+		}
+	}
+	cv := &iinfo.slots[iinfo.next]
+	iinfo.next = (iinfo.next + 1) % chebyshevSlots
+	cv.reset()
+	cv.nPosnAvail = 2
+	cv.nVelAvail = 2
+	cv.posnCoeff[1] = tc
+	cv.twot = tc + tc // 2*tc for efficiency in recurrence
+	return cv
+}
+
+// sunSlots is the number of barycentric states of the Sun kept by stateSplit, so that queries
+// alternating between a few epochs, such as the observer and retarded epochs of a light-time
+// iteration, interpolate the Sun once for each epoch.
+const sunSlots = 4
+
+// sunState is a barycentric state of the Sun kept by stateSplit, identified by the arguments
+// it was interpolated from rather than by the date, so that any date reducing to the same
+// record and time within it reuses it.
+type sunState struct {
+	record uint32     // record is the data record the state was interpolated from.
+	frac   float64    // frac is the fractional time within the record.
+	order  int        // order is 1 for positions, 2 with velocities, 3 with accelerations; 0 for an unused slot.
+	km     bool       // km is true when pv is in km and km/day, as stored in the file, rather than in AU.
+	pv     [9]float64 // pv is the position, velocity, and acceleration.
+}
+
 // jplEphData struct encapsulates data to access and interpolate a JPL ephemeris file.
 // Instances are returned by InitEphemeris() and passed to other jpleph functions.
 type jplEphData struct {
@@ -136,21 +211,34 @@ type jplEphData struct {
 	ephemEnd         float64       // ephemEnd is the ending Julian Ephemeris Date of the ephemeris data.
 	ephemStep        float64       // ephemStep is the time step (in days) between data records in the ephemeris.
 	ncon             uint32        // ncon is the number of constants in the ephemeris file.
-	au               float64       // au is the value of the Astronomical Unit in kilometers, as defined in the ephemeris.
+	au               float64       // au is the value of the Astronomical Unit in kilometers that scales the outputs: headerAU unless set by WithAU.
+	headerAU         float64       // headerAU is the value of the Astronomical Unit in kilometers, as defined in the ephemeris.
 	emrat            float64       // emrat is the Earth-Moon mass ratio used in the ephemeris.
 	ipt              [15][3]uint32 // ipt is the Interpolation Parameters Table, a 15x3 array of integers controlling interpolation.
 	ephemerisVersion uint64        // ephemerisVersion indicates the JPL ephemeris version (e.g., 405, 406, 430).
 
 	// Internal data computed and used by the jpleph package.
-	kernelSize   uint32            // kernelSize is the size of the ephemeris kernel in doubles (number of doubles per record).
-	recsize      uint32            // recsize is the size of a single ephemeris data record in bytes.
-	ncoeff       uint32            // ncoeff is the number of Chebyshev coefficients per data record (kernelSize / 2).
-	swapBytes    uint32            // swapBytes is a flag indicating if byte swapping is needed when reading the ephemeris file (non-zero if yes).
-	currCacheLoc uint32            // currCacheLoc stores the record number of the currently cached data block.
-	pvsun        [9]float64        // pvsun stores the position, velocity, and acceleration of the Sun (Solar System Barycentric).
-	pvsunT       float64           // pvsunT stores the Julian Ephemeris Date for which pvsun was last computed, for caching purposes.
-	cache        []float64         // cache is a buffer to store a single ephemeris data record, read from the file.
-	iinfo        interpolationInfo // iinfo is an instance of interpolationInfo, used to store Chebyshev interpolation data for optimization.
-	ifile        io.ReadSeekCloser // ifile is an interface representing the opened ephemeris file.
-	name         [32]byte          // name stores the name of the ephemeris (e.g., "DE405", "INPOP-19a").
+	kernelSize   uint32             // kernelSize is the size of the ephemeris kernel in doubles (number of doubles per record).
+	recsize      uint32             // recsize is the size of a single ephemeris data record in bytes.
+	ncoeff       uint32             // ncoeff is the number of Chebyshev coefficients per data record (kernelSize / 2).
+	swapBytes    uint32             // swapBytes is a flag indicating if byte swapping is needed when reading the ephemeris file (non-zero if yes).
+	currCacheLoc uint32             // currCacheLoc stores the record number of the currently cached data block.
+	pvsun        [9]float64         // pvsun stores the position, velocity, and acceleration of the Sun (Solar System Barycentric).
+	suns         [sunSlots]sunState // suns are the last states of the Sun interpolated, reused by stateSplit.
+	nextSun      int                // nextSun is the slot of suns replaced by the next state interpolated.
+	cache        []float64          // cache is a buffer to store a single ephemeris data record, read from the file.
+	iinfo        interpolationInfo  // iinfo is an instance of interpolationInfo, used to store Chebyshev interpolation data for optimization.
+	ifile        io.ReadSeekCloser  // ifile is an interface representing the opened ephemeris file.
+	filename     string             // filename is the path the ephemeris file was opened from, used to open clones.
+	name         [32]byte           // name stores the name of the ephemeris (e.g., "DE405", "INPOP-19a").
+	constNames   []byte             // constNames holds the 6-byte names of the header constants, read at initialization.
+	constValues  []float64          // constValues holds the values of the header constants, read at initialization.
+
+	// open opens another handle of a file not read from filename (e.g., an in-memory or HTTP
+	// source), for clones; nil reopens filename.
+	open func() (io.ReadSeekCloser, error)
+
+	retry *RetryPolicy // retry is the policy for failed record reads (see WithReadRetry); nil fails at once.
+
+	preloaded map[uint32][]float64 // preloaded holds records read ahead by PreloadRange, byte-swapped, by number; read-only once stored.
 }
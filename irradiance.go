@@ -0,0 +1,92 @@
+// ./irradiance.go
+package jpleph
+
+/*
+Package jpleph provides solar distance and irradiance helpers for thermal and power models.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// SolarConstant is the nominal total solar irradiance at 1 AU in W/m^2 (IAU 2015 Resolution B3).
+const SolarConstant = 1361.0
+
+// SolarDistance returns the distance of a body from the Sun's center.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - body: Any body accepted by CalculatePV except the Sun and the special quantities.
+//
+// Returns:
+//   - float64: Heliocentric distance in AU.
+//   - error: ErrInvalidIndex for the Sun or a special quantity, or an error from CalculatePV.
+func (e *Ephemeris) SolarDistance(et float64, body Planet) (float64, error) {
+	if body == Sun || (body >= Nutations && body <= TT_TDB) {
+		return 0, fmt.Errorf("solar distance of %v: %w", body, ErrInvalidIndex)
+	}
+	pos, _, err := e.CalculatePV(et, body, CenterSun, false)
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(pos.X*pos.X + pos.Y*pos.Y + pos.Z*pos.Z), nil
+}
+
+// SolarDistanceFactor returns the inverse-square scaling (1 AU / r)^2 of solar flux at a body,
+// which is 1 at one astronomical unit from the Sun.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - body: Any body accepted by SolarDistance.
+//
+// Returns:
+//   - float64: The flux scaling factor relative to 1 AU.
+//   - error: As for SolarDistance.
+func (e *Ephemeris) SolarDistanceFactor(et float64, body Planet) (float64, error) {
+	r, err := e.SolarDistance(et, body)
+	if err != nil {
+		return 0, err
+	}
+	return 1 / (r * r), nil
+}
+
+// Irradiance returns the solar irradiance at a body, normal to the Sun direction, by scaling
+// the irradiance at 1 AU with SolarDistanceFactor. Eclipses and the finite size of the Sun
+// are not taken into account; scale by 1 - Fraction of ShadowAt for the shadows of bodies.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - body: Any body accepted by SolarDistance.
+//   - solarConstant: Irradiance at 1 AU, e.g. SolarConstant (W/m^2) or a measured value.
+//
+// Returns:
+//   - float64: Irradiance at the body in the unit of solarConstant.
+//   - error: As for SolarDistance.
+func (e *Ephemeris) Irradiance(et float64, body Planet, solarConstant float64) (float64, error) {
+	f, err := e.SolarDistanceFactor(et, body)
+	if err != nil {
+		return 0, err
+	}
+	return solarConstant * f, nil
+}
@@ -0,0 +1,438 @@
+// ./jplephpb/jplephpb.go
+
+/*
+Package jplephpb provides Go types of the protocol buffer messages in jpleph.proto, for
+exchanging states, constants, and events with services and programs in other languages.
+
+The types encode and decode the standard protocol buffer wire format with Marshal and
+Unmarshal, without depending on the protobuf runtime, so messages interoperate with code
+generated by protoc from jpleph.proto in any language. Programs that use the protobuf
+runtime or gRPC can instead generate their own types from jpleph.proto, whose service
+definition describes the ephemeris RPCs.
+
+	msg := jplephpb.FromStateVector(state, jpleph.Mars, jpleph.CenterSun)
+	b, err := msg.Marshal()
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+// Package jplephpb provides Go types of the protocol buffer messages in jpleph.proto.
+package jplephpb
+
+import (
+	"strings"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// Vector3 is a Cartesian vector (message jpleph.v1.Vector3).
+type Vector3 struct {
+	X, Y, Z float64
+}
+
+// StateVector is the state of a target relative to a center (message jpleph.v1.StateVector).
+type StateVector struct {
+	JdTdb    float64  // Epoch (TDB Julian Date)
+	Target   int32    // Target body
+	Center   int32    // Center body
+	Position *Vector3 // Position in AU
+	Velocity *Vector3 // Velocity in AU/day
+}
+
+// Constant is a named constant (message jpleph.v1.Constant).
+type Constant struct {
+	Name  string
+	Value float64
+}
+
+// Constants lists constants of an ephemeris file (message jpleph.v1.Constants).
+type Constants struct {
+	DeVersion int32       // DE version number
+	Constants []*Constant // Constants in file order
+}
+
+// EventKind is the kind of an event (enum jpleph.v1.EventKind).
+type EventKind int32
+
+const (
+	// EventKindUnspecified is the default kind.
+	EventKindUnspecified EventKind = 0
+	// EventKindRise is a rising of a body.
+	EventKindRise EventKind = 1
+	// EventKindSet is a setting of a body.
+	EventKindSet EventKind = 2
+)
+
+// Event is a timed event of a body (message jpleph.v1.Event).
+type Event struct {
+	JdTdb float64   // Epoch of the event (TDB Julian Date)
+	Kind  EventKind // Kind of the event
+	Body  int32     // Body of the event
+}
+
+// Events lists events in time order (message jpleph.v1.Events).
+type Events struct {
+	Events []*Event
+}
+
+// StateRequest asks for a state (message jpleph.v1.StateRequest).
+type StateRequest struct {
+	JdTdb  float64
+	Target int32
+	Center int32
+}
+
+// ConstantsRequest asks for constants by name (message jpleph.v1.ConstantsRequest).
+type ConstantsRequest struct {
+	Names []string // Names of the constants; none asks for all
+}
+
+// EventsRequest asks for rise and set events (message jpleph.v1.EventsRequest).
+type EventsRequest struct {
+	Body       int32
+	StartJdTdb float64
+	EndJdTdb   float64
+	Latitude   float64 // Geodetic latitude in degrees
+	Longitude  float64 // Longitude in degrees, east positive
+	Height     float64 // Height above the WGS84 ellipsoid in meters
+}
+
+// FromStateVector converts a state of target relative to center.
+func FromStateVector(s jpleph.StateVector, target jpleph.Planet, center jpleph.CenterBody) *StateVector {
+	return &StateVector{
+		JdTdb:    s.ET,
+		Target:   int32(target),
+		Center:   int32(center),
+		Position: &Vector3{s.Position.X, s.Position.Y, s.Position.Z},
+		Velocity: &Vector3{s.Velocity.DX, s.Velocity.DY, s.Velocity.DZ},
+	}
+}
+
+// StateVector converts the message to a jpleph.StateVector; missing vectors are zero.
+func (m *StateVector) StateVector() jpleph.StateVector {
+	s := jpleph.StateVector{ET: m.JdTdb}
+	if m.Position != nil {
+		s.Position = jpleph.Position{X: m.Position.X, Y: m.Position.Y, Z: m.Position.Z}
+	}
+	if m.Velocity != nil {
+		s.Velocity = jpleph.Velocity{DX: m.Velocity.X, DY: m.Velocity.Y, DZ: m.Velocity.Z}
+	}
+	return s
+}
+
+// FromRiseSetEvent converts a rise or set event of body.
+func FromRiseSetEvent(ev jpleph.RiseSetEvent, body jpleph.Planet) *Event {
+	kind := EventKindSet
+	if ev.Rising {
+		kind = EventKindRise
+	}
+	return &Event{JdTdb: ev.ET, Kind: kind, Body: int32(body)}
+}
+
+// ConstantsOf lists the constants of an ephemeris opened with loadConstants.
+func ConstantsOf(e *jpleph.Ephemeris) *Constants {
+	m := &Constants{DeVersion: int32(e.GetEphemerisLong(jpleph.EphemerisVersion))}
+	for i := 0; ; i++ {
+		name, err := e.GetConstantName(i)
+		if err != nil {
+			break
+		}
+		value, err := e.GetConstantValue(i)
+		if err != nil {
+			break
+		}
+		m.Constants = append(m.Constants, &Constant{Name: strings.TrimSpace(name), Value: value})
+	}
+	return m
+}
+
+// Marshal encodes the message.
+func (m *Vector3) Marshal() ([]byte, error) { return m.appendTo(nil), nil }
+
+// appendTo appends the encoded message to b.
+func (m *Vector3) appendTo(b []byte) []byte {
+	b = appendDouble(b, 1, m.X)
+	b = appendDouble(b, 2, m.Y)
+	return appendDouble(b, 3, m.Z)
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *Vector3) Unmarshal(b []byte) error {
+	*m = Vector3{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			m.X, err = d.double(wire)
+		case 2:
+			m.Y, err = d.double(wire)
+		case 3:
+			m.Z, err = d.double(wire)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *StateVector) Marshal() ([]byte, error) {
+	b := appendDouble(nil, 1, m.JdTdb)
+	b = appendInt32(b, 2, m.Target)
+	b = appendInt32(b, 3, m.Center)
+	if m.Position != nil {
+		b = appendMessage(b, 4, m.Position.appendTo)
+	}
+	if m.Velocity != nil {
+		b = appendMessage(b, 5, m.Velocity.appendTo)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *StateVector) Unmarshal(b []byte) error {
+	*m = StateVector{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			m.JdTdb, err = d.double(wire)
+		case 2:
+			m.Target, err = d.int32(wire)
+		case 3:
+			m.Center, err = d.int32(wire)
+		case 4, 5:
+			var sub []byte
+			if sub, err = d.bytes(wire); err == nil {
+				v := &Vector3{}
+				err = v.Unmarshal(sub)
+				if num == 4 {
+					m.Position = v
+				} else {
+					m.Velocity = v
+				}
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *Constant) Marshal() ([]byte, error) { return m.appendTo(nil), nil }
+
+// appendTo appends the encoded message to b.
+func (m *Constant) appendTo(b []byte) []byte {
+	b = appendString(b, 1, m.Name)
+	return appendDouble(b, 2, m.Value)
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *Constant) Unmarshal(b []byte) error {
+	*m = Constant{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			var s []byte
+			s, err = d.bytes(wire)
+			m.Name = string(s)
+		case 2:
+			m.Value, err = d.double(wire)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *Constants) Marshal() ([]byte, error) {
+	b := appendInt32(nil, 1, m.DeVersion)
+	for _, c := range m.Constants {
+		b = appendMessage(b, 2, c.appendTo)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *Constants) Unmarshal(b []byte) error {
+	*m = Constants{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			m.DeVersion, err = d.int32(wire)
+		case 2:
+			var sub []byte
+			if sub, err = d.bytes(wire); err == nil {
+				c := &Constant{}
+				err = c.Unmarshal(sub)
+				m.Constants = append(m.Constants, c)
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *Event) Marshal() ([]byte, error) { return m.appendTo(nil), nil }
+
+// appendTo appends the encoded message to b.
+func (m *Event) appendTo(b []byte) []byte {
+	b = appendDouble(b, 1, m.JdTdb)
+	b = appendInt32(b, 2, int32(m.Kind))
+	return appendInt32(b, 3, m.Body)
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *Event) Unmarshal(b []byte) error {
+	*m = Event{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			m.JdTdb, err = d.double(wire)
+		case 2:
+			var k int32
+			k, err = d.int32(wire)
+			m.Kind = EventKind(k)
+		case 3:
+			m.Body, err = d.int32(wire)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *Events) Marshal() ([]byte, error) {
+	var b []byte
+	for _, ev := range m.Events {
+		b = appendMessage(b, 1, ev.appendTo)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *Events) Unmarshal(b []byte) error {
+	*m = Events{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		if num != 1 {
+			return false, nil
+		}
+		sub, err := d.bytes(wire)
+		if err != nil {
+			return true, err
+		}
+		ev := &Event{}
+		err = ev.Unmarshal(sub)
+		m.Events = append(m.Events, ev)
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *StateRequest) Marshal() ([]byte, error) {
+	b := appendDouble(nil, 1, m.JdTdb)
+	b = appendInt32(b, 2, m.Target)
+	return appendInt32(b, 3, m.Center), nil
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *StateRequest) Unmarshal(b []byte) error {
+	*m = StateRequest{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			m.JdTdb, err = d.double(wire)
+		case 2:
+			m.Target, err = d.int32(wire)
+		case 3:
+			m.Center, err = d.int32(wire)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *ConstantsRequest) Marshal() ([]byte, error) {
+	var b []byte
+	for _, name := range m.Names {
+		b = appendRepeatedString(b, 1, name) // Written even when empty, to keep positions
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *ConstantsRequest) Unmarshal(b []byte) error {
+	*m = ConstantsRequest{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		if num != 1 {
+			return false, nil
+		}
+		s, err := d.bytes(wire)
+		m.Names = append(m.Names, string(s))
+		return true, err
+	})
+}
+
+// Marshal encodes the message.
+func (m *EventsRequest) Marshal() ([]byte, error) {
+	b := appendInt32(nil, 1, m.Body)
+	b = appendDouble(b, 2, m.StartJdTdb)
+	b = appendDouble(b, 3, m.EndJdTdb)
+	b = appendDouble(b, 4, m.Latitude)
+	b = appendDouble(b, 5, m.Longitude)
+	return appendDouble(b, 6, m.Height), nil
+}
+
+// Unmarshal decodes the message, replacing its contents.
+func (m *EventsRequest) Unmarshal(b []byte) error {
+	*m = EventsRequest{}
+	return decodeFields(b, func(d *decoder, num, wire int) (bool, error) {
+		var err error
+		switch num {
+		case 1:
+			m.Body, err = d.int32(wire)
+		case 2:
+			m.StartJdTdb, err = d.double(wire)
+		case 3:
+			m.EndJdTdb, err = d.double(wire)
+		case 4:
+			m.Latitude, err = d.double(wire)
+		case 5:
+			m.Longitude, err = d.double(wire)
+		case 6:
+			m.Height, err = d.double(wire)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
@@ -0,0 +1,203 @@
+// ./jplephpb/wire.go
+package jplephpb
+
+/*
+Package jplephpb provides the protocol buffer wire encoding of its messages.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrWire is returned when a message cannot be decoded.
+var ErrWire = errors.New("invalid protocol buffer encoding")
+
+// Wire types of the protocol buffer encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// appendTag appends the key of a field.
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+// appendDouble appends a double field, omitted when zero as proto3 does.
+func appendDouble(b []byte, field int, v float64) []byte {
+	if v == 0 && !math.Signbit(v) {
+		return b
+	}
+	b = appendTag(b, field, wireFixed64)
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+// appendInt32 appends an int32 or enum field, omitted when zero. Negative values take ten
+// bytes, as in the standard encoding.
+func appendInt32(b []byte, field int, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, uint64(int64(v)))
+}
+
+// appendString appends a string field, omitted when empty.
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendRepeatedString(b, field, s)
+}
+
+// appendRepeatedString appends an element of a repeated string field, even if it is empty.
+func appendRepeatedString(b []byte, field int, s string) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendMessage appends an embedded message field encoded by encode, even if it is empty.
+func appendMessage(b []byte, field int, encode func([]byte) []byte) []byte {
+	sub := encode(nil)
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(sub)))
+	return append(b, sub...)
+}
+
+// decoder reads the fields of one message.
+type decoder struct {
+	b []byte // Remaining encoded bytes
+}
+
+// next reads the key of the next field. It returns ok=false at the end of the message.
+func (d *decoder) next() (field, wire int, ok bool, err error) {
+	if len(d.b) == 0 {
+		return 0, 0, false, nil
+	}
+	key, err := d.varint()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if key>>3 == 0 || key>>3 > math.MaxInt32 {
+		return 0, 0, false, fmt.Errorf("%w: field number %d", ErrWire, key>>3)
+	}
+	return int(key >> 3), int(key & 7), true, nil
+}
+
+// varint reads a varint.
+func (d *decoder) varint() (uint64, error) {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad varint", ErrWire)
+	}
+	d.b = d.b[n:]
+	return v, nil
+}
+
+// double reads a fixed64 double, checking the wire type.
+func (d *decoder) double(wire int) (float64, error) {
+	if wire != wireFixed64 || len(d.b) < 8 {
+		return 0, fmt.Errorf("%w: bad double", ErrWire)
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.b))
+	d.b = d.b[8:]
+	return v, nil
+}
+
+// int32 reads a varint int32 or enum, checking the wire type.
+func (d *decoder) int32(wire int) (int32, error) {
+	if wire != wireVarint {
+		return 0, fmt.Errorf("%w: bad int32", ErrWire)
+	}
+	v, err := d.varint()
+	return int32(v), err
+}
+
+// bytes reads a length-delimited field, checking the wire type.
+func (d *decoder) bytes(wire int) ([]byte, error) {
+	if wire != wireBytes {
+		return nil, fmt.Errorf("%w: bad length-delimited field", ErrWire)
+	}
+	n, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.b)) {
+		return nil, fmt.Errorf("%w: truncated field", ErrWire)
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v, nil
+}
+
+// skip skips a field of an unknown number, for forward compatibility.
+func (d *decoder) skip(wire int) error {
+	switch wire {
+	case wireVarint:
+		_, err := d.varint()
+		return err
+	case wireFixed64:
+		if len(d.b) < 8 {
+			return fmt.Errorf("%w: truncated field", ErrWire)
+		}
+		d.b = d.b[8:]
+	case wireBytes:
+		_, err := d.bytes(wire)
+		return err
+	case wireFixed32:
+		if len(d.b) < 4 {
+			return fmt.Errorf("%w: truncated field", ErrWire)
+		}
+		d.b = d.b[4:]
+	default:
+		return fmt.Errorf("%w: unsupported wire type %d", ErrWire, wire)
+	}
+	return nil
+}
+
+// decodeFields calls field for each field of a message; field returns handled=false for
+// unknown fields, which are skipped.
+func decodeFields(b []byte, field func(d *decoder, num, wire int) (handled bool, err error)) error {
+	d := &decoder{b: b}
+	for {
+		num, wire, ok, err := d.next()
+		if err != nil || !ok {
+			return err
+		}
+		handled, err := field(d, num, wire)
+		if err != nil {
+			return err
+		}
+		if !handled {
+			if err := d.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+}
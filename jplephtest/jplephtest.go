@@ -0,0 +1,460 @@
+// ./jplephtest/jplephtest.go
+
+/*
+Package jplephtest generates small synthetic JPL DE binary ephemeris files for tests.
+
+The generated files follow the layout of the genuine JPL kernels (header record,
+constants record, and Chebyshev data records), but the bodies move on analytic
+Keplerian orbits whose exact states are known. Tests can therefore open a file of
+a few hundred kilobytes with jpleph.NewEphemeris and compare the interpolated
+states against Config.Expected instead of depending on a large JPL download.
+
+	cfg := jplephtest.DefaultConfig()
+	path := jplephtest.TempFile(t, cfg)
+	eph, err := jpleph.NewEphemeris(path, true)
+	...
+	want, _, _ := cfg.Expected(jpleph.Mars, jpleph.CenterSun, et)
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+// Package jplephtest generates small synthetic JPL DE binary ephemeris files for tests.
+package jplephtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+)
+
+// headerOffset is the file offset of the numerical header (start, end, step, ncon, au, emrat, ipt).
+const headerOffset = 2652
+
+// extraNamesOffset is the file offset of the names of constants beyond the first 400.
+const extraNamesOffset = 2856
+
+// Orbit describes a Keplerian orbit used to generate a synthetic body.
+// Angles are in radians, referred to the axes of the generated file.
+type Orbit struct {
+	SemiMajorAxis float64 // SemiMajorAxis is the semi-major axis in km.
+	Eccentricity  float64 // Eccentricity is the orbital eccentricity (0 <= e < 1).
+	Inclination   float64 // Inclination is the orbital inclination.
+	Node          float64 // Node is the longitude of the ascending node.
+	Periapsis     float64 // Periapsis is the argument of periapsis.
+	MeanAnomaly   float64 // MeanAnomaly is the mean anomaly at J2000.
+	Period        float64 // Period is the orbital period in days.
+}
+
+// Layout gives the number of Chebyshev coefficients and sub-intervals used for a quantity.
+// A zero Layout omits the quantity from the generated file.
+type Layout struct {
+	NCoeff       int // NCoeff is the number of coefficients per component (at most 17).
+	SubIntervals int // SubIntervals is the number of sub-intervals per record.
+}
+
+// Constant is a named constant stored in the generated file.
+type Constant struct {
+	Name  string  // Name is the constant name (at most 6 characters).
+	Value float64 // Value is the constant value.
+}
+
+// Config describes the synthetic ephemeris file to generate.
+//
+// Orbits and Layouts are indexed by the position of the quantity in the JPL IPT
+// array: 0-8 Mercury to Pluto (2 being the Earth-Moon barycenter), 9 the
+// geocentric Moon, 10 the Sun, 11 nutations, 12 librations, 13 lunar mantle
+// angular velocities, and 14 TT-TDB. Planetary and Earth-Moon barycenter orbits
+// are heliocentric, the Moon orbit is geocentric, and the Sun orbit is barycentric.
+type Config struct {
+	DENumber   int              // DENumber is written in the title line and header (e.g., 440).
+	Start      float64          // Start is the Julian Ephemeris Date of the first record.
+	Records    int              // Records is the number of data records.
+	RecordDays float64          // RecordDays is the time span of each record in days.
+	AU         float64          // AU is the number of kilometers per astronomical unit.
+	EMRAT      float64          // EMRAT is the Earth-Moon mass ratio (must be within the range accepted by jpleph).
+	Orbits     [11]Orbit        // Orbits holds the orbits of the bodies (indices 0-10).
+	Layouts    [15]Layout       // Layouts holds the Chebyshev layout of every quantity.
+	Constants  []Constant       // Constants are stored in the header and constants record.
+	ByteOrder  binary.ByteOrder // ByteOrder of the generated file; nil means little-endian.
+}
+
+// DefaultConfig returns a configuration for a DE440-like file of 20 records of 32 days
+// starting at JD 2451536.5, including nutations, librations, mantle angular velocities,
+// and TT-TDB, with approximately realistic planetary orbits.
+func DefaultConfig() Config {
+	deg := math.Pi / 180.0
+	cfg := Config{
+		DENumber:   440,
+		Start:      2451536.5,
+		Records:    20,
+		RecordDays: 32,
+		AU:         149597870.700,
+		EMRAT:      81.3005682168675747,
+		Orbits: [11]Orbit{
+			{57909050, 0.2056, 7.0 * deg, 48.3 * deg, 29.1 * deg, 174.8 * deg, 87.969},       // Mercury
+			{108208000, 0.0068, 3.39 * deg, 76.7 * deg, 54.9 * deg, 50.1 * deg, 224.701},     // Venus
+			{149598023, 0.0167, 23.44 * deg, 0.0, 102.9 * deg, 358.6 * deg, 365.256},         // Earth-Moon barycenter
+			{227939200, 0.0934, 1.85 * deg, 49.6 * deg, 286.5 * deg, 19.4 * deg, 686.980},    // Mars
+			{778570000, 0.0489, 1.30 * deg, 100.5 * deg, 273.9 * deg, 20.0 * deg, 4332.59},   // Jupiter
+			{1433530000, 0.0565, 2.49 * deg, 113.7 * deg, 339.4 * deg, 317.0 * deg, 10759.2}, // Saturn
+			{2872460000, 0.0464, 0.77 * deg, 74.0 * deg, 96.9 * deg, 142.2 * deg, 30688.5},   // Uranus
+			{4495060000, 0.0095, 1.77 * deg, 131.8 * deg, 273.2 * deg, 256.2 * deg, 60182},   // Neptune
+			{5906380000, 0.2488, 17.16 * deg, 110.3 * deg, 113.8 * deg, 14.5 * deg, 90560},   // Pluto
+			{384399, 0.0549, 5.145 * deg, 125.1 * deg, 318.1 * deg, 135.3 * deg, 27.3217},    // Moon (geocentric)
+			{742000, 0.0489, 1.30 * deg, 100.5 * deg, 93.9 * deg, 20.0 * deg, 4332.59},       // Sun (barycentric)
+		},
+		Layouts: [15]Layout{
+			{14, 4}, {10, 2}, {13, 2}, {11, 1}, {8, 1}, {7, 1}, {6, 1}, {6, 1}, {6, 1}, // Planets and EMB
+			{13, 8}, {11, 2}, // Moon, Sun
+			{10, 4}, {10, 4}, {10, 4}, {13, 8}, // Nutations, librations, mantle, TT-TDB
+		},
+	}
+	cfg.Constants = []Constant{
+		{"DENUM", float64(cfg.DENumber)}, {"LENUM", float64(cfg.DENumber)},
+		{"AU", cfg.AU}, {"EMRAT", cfg.EMRAT}, {"CLIGHT", 299792.458},
+		{"GMS", 2.9591220828411956e-04}, {"GM1", 4.9125001948893182e-11}, {"GM2", 7.2434523326441187e-10},
+		{"GM4", 9.5495488297258119e-11}, {"GM5", 2.8253458252257917e-07}, {"GM6", 8.4597059933762903e-08},
+		{"GM7", 1.2920265649682399e-08}, {"GM8", 1.5243573478851939e-08}, {"GM9", 2.1750964648933581e-12},
+		{"GMB", 8.9970113957473928e-10}, {"JDEPOC", 2440400.5}, {"CENTER", 12},
+	}
+	return cfg
+}
+
+// Expected returns the exact state of target relative to center at et, computed from
+// the analytic orbits, with the same body conventions and units as jpleph.Ephemeris.CalculatePV.
+// For Nutations, Librations, LunarMantleOmega, and TT_TDB the center is ignored and the
+// synthetic model values are returned in radians (per day) and seconds.
+//
+// Parameters:
+//   - target: Target body or quantity.
+//   - center: Center body.
+//   - et: Julian Ephemeris Date.
+//
+// Returns:
+//   - jpleph.Position: Exact position (AU, or the quantity's native unit).
+//   - jpleph.Velocity: Exact velocity (AU/day, or the quantity's native rate).
+//   - error: jpleph.ErrInvalidIndex for unknown bodies, jpleph.ErrQuantityNotInEphemeris for omitted quantities.
+func (c Config) Expected(target jpleph.Planet, center jpleph.CenterBody, et float64) (jpleph.Position, jpleph.Velocity, error) {
+	if target >= jpleph.Nutations && target <= jpleph.TT_TDB {
+		idx := int(target) - 3 // IPT index of the quantity (11-14)
+		if c.Layouts[idx].NCoeff == 0 {
+			return jpleph.Position{}, jpleph.Velocity{}, jpleph.ErrQuantityNotInEphemeris
+		}
+		p, v := specialQuantity(idx, et)
+		return jpleph.Position{X: p[0], Y: p[1], Z: p[2]}, jpleph.Velocity{DX: v[0], DY: v[1], DZ: v[2]}, nil
+	}
+	tp, tv, err := c.barycentric(int(target), et)
+	if err != nil {
+		return jpleph.Position{}, jpleph.Velocity{}, err
+	}
+	cp, cv, err := c.barycentric(int(center), et)
+	if err != nil {
+		return jpleph.Position{}, jpleph.Velocity{}, err
+	}
+	f := 1.0 / c.AU
+	return jpleph.Position{X: (tp[0] - cp[0]) * f, Y: (tp[1] - cp[1]) * f, Z: (tp[2] - cp[2]) * f},
+		jpleph.Velocity{DX: (tv[0] - cv[0]) * f, DY: (tv[1] - cv[1]) * f, DZ: (tv[2] - cv[2]) * f}, nil
+}
+
+// End returns the Julian Ephemeris Date at which the generated file ends.
+func (c Config) End() float64 {
+	return c.Start + float64(c.Records)*c.RecordDays
+}
+
+// Generate writes a synthetic binary ephemeris file described by cfg to w.
+//
+// Parameters:
+//   - w: Destination writer.
+//   - cfg: Description of the file.
+//
+// Returns:
+//   - error: An error if the configuration cannot be represented in the JPL format, or the error returned by w.
+func Generate(w io.Writer, cfg Config) error {
+	order := cfg.ByteOrder
+	if order == nil {
+		order = binary.LittleEndian
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	// Build the IPT table: offsets are 1-based double indices, after the two record dates.
+	var ipt [15][3]uint32
+	offset := uint32(3)
+	ncoeff := 2
+	for i, l := range cfg.Layouts {
+		if l.NCoeff == 0 {
+			continue
+		}
+		ipt[i] = [3]uint32{offset, uint32(l.NCoeff), uint32(l.SubIntervals)}
+		size := l.NCoeff * l.SubIntervals * dimension(i)
+		offset += uint32(size)
+		ncoeff += size
+	}
+	recsize := ncoeff * 8
+	nExtraNames := max(len(cfg.Constants)-400, 0)
+	if recsize < extraNamesOffset+6*nExtraNames+24 || len(cfg.Constants) > ncoeff {
+		return fmt.Errorf("jplephtest: record size %d bytes is too small for the header; increase the layouts", recsize)
+	}
+
+	header := make([]byte, recsize)
+	copy(header, padRight(fmt.Sprintf("JPL Planetary Ephemeris DE%d/LE%d", cfg.DENumber, cfg.DENumber), 84))
+	copy(header[84:], padRight(fmt.Sprintf("Start Epoch: JED= %11.1f", cfg.Start), 84))
+	copy(header[168:], padRight(fmt.Sprintf("Final Epoch: JED= %11.1f", cfg.End()), 84))
+	for i, c := range cfg.Constants {
+		pos := 252 + 6*i
+		if i >= 400 {
+			pos = extraNamesOffset + 6*(i-400)
+		}
+		copy(header[pos:], padRight(c.Name, 6))
+	}
+
+	var num bytes.Buffer
+	binary.Write(&num, order, [3]float64{cfg.Start, cfg.End(), cfg.RecordDays})
+	binary.Write(&num, order, uint32(len(cfg.Constants)))
+	binary.Write(&num, order, [2]float64{cfg.AU, cfg.EMRAT})
+	for i := 0; i < 12; i++ {
+		binary.Write(&num, order, ipt[i])
+	}
+	binary.Write(&num, order, uint32(cfg.DENumber))
+	binary.Write(&num, order, ipt[12]) // Libration pointers (lpt)
+	copy(header[headerOffset:], num.Bytes())
+
+	var ext bytes.Buffer // Lunar mantle and TT-TDB pointers, after any extra constant names
+	binary.Write(&ext, order, ipt[13])
+	binary.Write(&ext, order, ipt[14])
+	copy(header[extraNamesOffset+6*nExtraNames:], ext.Bytes())
+
+	values := make([]float64, ncoeff)
+	for i, c := range cfg.Constants {
+		values[i] = c.Value
+	}
+
+	var out bytes.Buffer
+	out.Write(header)
+	binary.Write(&out, order, values)
+	record := make([]float64, ncoeff)
+	for r := 0; r < cfg.Records; r++ {
+		if err := cfg.fillRecord(record, ipt, r); err != nil {
+			return err
+		}
+		binary.Write(&out, order, record)
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// GenerateFile writes a synthetic binary ephemeris file described by cfg to path.
+func GenerateFile(path string, cfg Config) error {
+	var buf bytes.Buffer
+	if err := Generate(&buf, cfg); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// TempFile generates a synthetic ephemeris file in a temporary directory owned by tb
+// and returns its path. The test fails immediately if the file cannot be generated.
+func TempFile(tb testing.TB, cfg Config) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), fmt.Sprintf("synthetic-de%d.bin", cfg.DENumber))
+	if err := GenerateFile(path, cfg); err != nil {
+		tb.Fatalf("jplephtest: %v", err)
+	}
+	return path
+}
+
+// validate checks that cfg can be written in the JPL format and read back by jpleph.
+func (c Config) validate() error {
+	switch {
+	case c.Records < 1 || !(c.RecordDays > 0):
+		return errors.New("jplephtest: at least one record with a positive time span is required")
+	case c.DENumber < 1:
+		return errors.New("jplephtest: DENumber must be positive")
+	}
+	for i, l := range c.Layouts {
+		if l.NCoeff < 0 || l.NCoeff >= 18 || (l.NCoeff > 0 && l.SubIntervals != 1 && l.SubIntervals != 2 &&
+			l.SubIntervals != 4 && l.SubIntervals != 8) {
+			return fmt.Errorf("jplephtest: invalid layout %+v for quantity %d", l, i)
+		}
+	}
+	return nil
+}
+
+// fillRecord computes the Chebyshev coefficients of data record r.
+func (c Config) fillRecord(record []float64, ipt [15][3]uint32, r int) error {
+	recStart := c.Start + float64(r)*c.RecordDays
+	record[0], record[1] = recStart, recStart+c.RecordDays
+	for i, entry := range ipt {
+		if entry[1] == 0 {
+			continue
+		}
+		ncf, nsub, dim := int(entry[1]), int(entry[2]), dimension(i)
+		subDays := c.RecordDays / float64(nsub)
+		for l := 0; l < nsub; l++ {
+			subStart := recStart + float64(l)*subDays
+			for k := 0; k < dim; k++ {
+				fn := func(et float64) float64 { return c.rawComponent(i, k, et) }
+				coeffs, err := jpleph.FitChebyshev(fn, subStart, subStart+subDays, ncf)
+				if err != nil {
+					return err
+				}
+				copy(record[int(entry[0])-1+ncf*(k+l*dim):], coeffs)
+			}
+		}
+	}
+	return nil
+}
+
+// rawComponent returns component k of quantity i as stored in the file (km or native units).
+func (c Config) rawComponent(i, k int, et float64) float64 {
+	switch {
+	case i <= 8: // Planets and Earth-Moon barycenter, barycentric
+		p, _ := kepler(c.Orbits[i], et)
+		s, _ := kepler(c.Orbits[10], et)
+		return p[k] + s[k]
+	case i == 9: // Moon, geocentric
+		p, _ := kepler(c.Orbits[9], et)
+		return p[k]
+	case i == 10: // Sun, barycentric
+		p, _ := kepler(c.Orbits[10], et)
+		return p[k]
+	default:
+		p, _ := specialQuantity(i, et)
+		return p[k]
+	}
+}
+
+// barycentric returns the exact barycentric state of a body in km and km/day.
+func (c Config) barycentric(body int, et float64) ([3]float64, [3]float64, error) {
+	sunP, sunV := kepler(c.Orbits[10], et)
+	add := func(a, b [3]float64, f float64) [3]float64 {
+		return [3]float64{a[0] + f*b[0], a[1] + f*b[1], a[2] + f*b[2]}
+	}
+	switch {
+	case body == int(jpleph.SolarSystemBarycenter):
+		return [3]float64{}, [3]float64{}, nil
+	case body == int(jpleph.Sun):
+		return sunP, sunV, nil
+	case body == int(jpleph.Earth) || body == int(jpleph.Moon) || body == int(jpleph.EarthMoonBarycenter):
+		embP, embV := kepler(c.Orbits[2], et)
+		embP, embV = add(sunP, embP, 1), add(sunV, embV, 1)
+		moonP, moonV := kepler(c.Orbits[9], et)
+		switch jpleph.Planet(body) {
+		case jpleph.Earth:
+			f := -1.0 / (1.0 + c.EMRAT)
+			return add(embP, moonP, f), add(embV, moonV, f), nil
+		case jpleph.Moon:
+			f := c.EMRAT / (1.0 + c.EMRAT)
+			return add(embP, moonP, f), add(embV, moonV, f), nil
+		}
+		return embP, embV, nil
+	case body >= int(jpleph.Mercury) && body <= int(jpleph.Pluto):
+		p, v := kepler(c.Orbits[body-1], et)
+		return add(sunP, p, 1), add(sunV, v, 1), nil
+	}
+	return [3]float64{}, [3]float64{}, jpleph.ErrInvalidIndex
+}
+
+// kepler returns the position (km) and velocity (km/day) on a Keplerian orbit at et.
+func kepler(o Orbit, et float64) ([3]float64, [3]float64) {
+	n := 2 * math.Pi / o.Period // Mean motion, rad/day
+	m := math.Mod(o.MeanAnomaly+n*(et-2451545.0), 2*math.Pi)
+	e := m
+	for iter := 0; iter < 50; iter++ { // Newton iteration on Kepler's equation
+		de := (e - o.Eccentricity*math.Sin(e) - m) / (1 - o.Eccentricity*math.Cos(e))
+		e -= de
+		if math.Abs(de) < 1e-15 {
+			break
+		}
+	}
+	sinE, cosE := math.Sincos(e)
+	q := math.Sqrt(1 - o.Eccentricity*o.Eccentricity)
+	denom := 1 - o.Eccentricity*cosE
+	x, y := o.SemiMajorAxis*(cosE-o.Eccentricity), o.SemiMajorAxis*q*sinE
+	vx, vy := -o.SemiMajorAxis*n*sinE/denom, o.SemiMajorAxis*n*q*cosE/denom
+
+	sw, cw := math.Sincos(o.Periapsis)
+	so, co := math.Sincos(o.Node)
+	si, ci := math.Sincos(o.Inclination)
+	rot := func(x, y float64) [3]float64 { // Rz(node) Rx(incl) Rz(periapsis) applied to an in-plane vector
+		xw, yw := cw*x-sw*y, sw*x+cw*y
+		return [3]float64{co*xw - so*ci*yw, so*xw + co*ci*yw, si * yw}
+	}
+	return rot(x, y), rot(vx, vy)
+}
+
+// specialQuantity returns the synthetic value and rate of the non-body quantities
+// (nutations, librations, mantle angular velocities, TT-TDB) at et.
+func specialQuantity(i int, et float64) ([3]float64, [3]float64) {
+	t := et - 2451545.0
+	wave := func(amp, period, phase float64) (float64, float64) {
+		w := 2 * math.Pi / period
+		s, c := math.Sincos(w*t + phase)
+		return amp * s, amp * w * c
+	}
+	var p, v [3]float64
+	switch i {
+	case 11: // Nutations in longitude and obliquity, radians
+		p[0], v[0] = wave(-8.3e-5, 6798.38, 2.18)
+		p[1], v[1] = wave(4.46e-5, 6798.38, 3.75)
+	case 12: // Lunar libration Euler angles, radians
+		p[0], v[0] = wave(0.05, 27.2122, 0.4)
+		p[1], v[1] = wave(0.01, 27.5546, 1.1)
+		p[1] += 0.4
+		w := 0.2299708345 // Mean lunar rotation rate, rad/day
+		p[2], v[2] = 1.2+w*t, w
+	case 13: // Lunar mantle angular velocity, rad/day
+		p[0], v[0] = wave(1e-5, 27.2122, 0.2)
+		p[1], v[1] = wave(1e-5, 27.5546, 0.7)
+		p[2], v[2] = wave(2e-6, 27.3217, 1.5)
+		p[2] += 0.2299708345
+	case 14: // TT-TDB at the geocenter, seconds
+		p[0], v[0] = wave(-0.001657, 365.2596, -6.24)
+	}
+	return p, v
+}
+
+// dimension returns the number of components of the quantity with IPT index i.
+func dimension(i int) int {
+	switch i {
+	case 11:
+		return 2
+	case 14:
+		return 1
+	}
+	return 3
+}
+
+// padRight pads s with spaces to n bytes.
+func padRight(s string, n int) []byte {
+	b := bytes.Repeat([]byte(" "), n)
+	copy(b, s)
+	return b
+}
@@ -0,0 +1,66 @@
+// ./julian_date.go
+package jpleph
+
+/*
+Package jpleph provides calendar and time-scale helpers for Julian Ephemeris Dates.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+	"time"
+)
+
+// J2000 is the Julian Ephemeris Date of the J2000.0 epoch (2000 January 1.5 TDB).
+const J2000 = 2451545.0
+
+// unixEpochJD is the Julian Date of 1970 January 1.0, the origin of Go's calendar arithmetic.
+const unixEpochJD = 2440587.5
+
+// secondsPerDay is the number of SI seconds in a day of the ephemeris time scale.
+const secondsPerDay = 86400.0
+
+// julianDateFromTime converts the calendar fields of t into a Julian Date.
+// The time scale of t is not interpreted: a TDB calendar label gives a TDB Julian Date.
+// The whole-day and fractional parts are accumulated separately to keep sub-millisecond
+// resolution for dates far from 1970.
+func julianDateFromTime(t time.Time) float64 {
+	y, m, d := t.Date()
+	days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400 // Whole days since 1970-01-01
+	secOfDay := float64(t.Hour()*3600+t.Minute()*60+t.Second()) + float64(t.Nanosecond())*1e-9
+	return unixEpochJD + float64(days) + secOfDay/secondsPerDay
+}
+
+// timeFromJulianDate converts a Julian Date into a calendar label, rounded to the microsecond.
+// The returned time uses the UTC location only as a container for the calendar fields.
+func timeFromJulianDate(jd float64) time.Time {
+	dayNumber := math.Floor(jd - unixEpochJD)                                         // Whole days since 1970-01-01
+	micros := int64(math.Round((jd - unixEpochJD - dayNumber) * secondsPerDay * 1e6)) // Microseconds into the day
+	return time.Unix(int64(dayNumber)*86400, 0).UTC().Add(time.Duration(micros) * time.Microsecond)
+}
+
+// tdbMinusTT returns the difference TDB - TT in seconds at the given Julian Date, using the
+// two-term periodic approximation of the USNO Circular 179 (accurate to about 10 microseconds for 1600-2200).
+func tdbMinusTT(jd float64) float64 {
+	g := (357.53 + 0.98560028*(jd-J2000)) * math.Pi / 180.0 // Mean anomaly of the Earth, radians
+	return 0.001657*math.Sin(g) + 0.000014*math.Sin(2*g)
+}
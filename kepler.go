@@ -0,0 +1,266 @@
+// ./kepler.go
+package jpleph
+
+/*
+Package jpleph provides two-body (Keplerian) propagation with universal variables, and the
+integrals of two-body motion.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// stumpff returns the Stumpff functions C(z) and S(z) used by the universal variable formulation.
+func stumpff(z float64) (float64, float64) {
+	switch {
+	case math.Abs(z) < 1e-3: // Series expansions avoid cancellation near parabolic orbits
+		return 0.5 - z/24 + z*z/720 - z*z*z/40320, 1.0/6 - z/120 + z*z/5040 - z*z*z/362880
+	case z > 0:
+		s := math.Sqrt(z)
+		return (1 - math.Cos(s)) / z, (s - math.Sin(s)) / (z * s)
+	default:
+		s := math.Sqrt(-z)
+		return (math.Cosh(s) - 1) / -z, (math.Sinh(s) - s) / (-z * s)
+	}
+}
+
+// keplerPropagate advances a two-body state by dt with the universal variable formulation,
+// which handles elliptic, parabolic, and hyperbolic orbits alike.
+//
+// Parameters:
+//   - r0, v0: Initial position and velocity (any consistent units, e.g. AU and AU/day).
+//   - mu: Gravitational parameter of the central body, in the same units (e.g. AU^3/day^2).
+//   - dt: Time to advance by (negative to propagate backwards).
+//
+// Returns:
+//   - [3]float64, [3]float64: Position and velocity after dt.
+//   - error: ErrKepler if the solver fails (degenerate orbits).
+func keplerPropagate(r0, v0 [3]float64, mu, dt float64) ([3]float64, [3]float64, error) {
+	r0n := math.Sqrt(r0[0]*r0[0] + r0[1]*r0[1] + r0[2]*r0[2])
+	v0sq := v0[0]*v0[0] + v0[1]*v0[1] + v0[2]*v0[2]
+	if r0n == 0 || !(mu > 0) {
+		return r0, v0, ErrKepler
+	}
+	sqrtMu := math.Sqrt(mu)
+	rv := (r0[0]*v0[0] + r0[1]*v0[1] + r0[2]*v0[2]) / sqrtMu // r0 . v0 / sqrt(mu)
+	alpha := 2/r0n - v0sq/mu                                 // Reciprocal of the semi-major axis
+
+	if alpha > 1e-12 { // Elliptic: remove whole revolutions to keep the solver well conditioned
+		period := 2 * math.Pi / (sqrtMu * math.Pow(alpha, 1.5))
+		dt = math.Remainder(dt, period)
+	}
+
+	// Solve the universal Kepler equation for chi with the Laguerre-Conway iteration.
+	chi := sqrtMu * math.Abs(alpha) * dt
+	if alpha <= 1e-12 || chi == 0 {
+		chi = sqrtMu * dt / r0n
+	}
+	const n = 5.0 // Laguerre-Conway order
+	converged := false
+	for iter := 0; iter < 200; iter++ {
+		z := alpha * chi * chi
+		c, s := stumpff(z)
+		chi2 := chi * chi
+		f := rv*chi2*c + (1-alpha*r0n)*chi2*chi*s + r0n*chi - sqrtMu*dt
+		df := rv*chi*(1-z*s) + (1-alpha*r0n)*chi2*c + r0n // Equals the radius at chi
+		ddf := rv*(1-z*c) + (1-alpha*r0n)*chi*(1-z*s)
+		root := math.Sqrt(math.Abs((n-1)*(n-1)*df*df - n*(n-1)*f*ddf))
+		delta := n * f / (df + math.Copysign(root, df))
+		chi -= delta
+		if math.Abs(delta) <= 1e-14*math.Max(1, math.Abs(chi)) {
+			converged = true
+			break
+		}
+	}
+	if !converged || math.IsNaN(chi) {
+		return r0, v0, ErrKepler
+	}
+
+	z := alpha * chi * chi
+	c, s := stumpff(z)
+	f := 1 - chi*chi*c/r0n
+	g := dt - chi*chi*chi*s/sqrtMu
+	var r, v [3]float64
+	for i := range r {
+		r[i] = f*r0[i] + g*v0[i]
+	}
+	rn := math.Sqrt(r[0]*r[0] + r[1]*r[1] + r[2]*r[2])
+	fdot := sqrtMu / (rn * r0n) * (z*chi*s - chi)
+	gdot := 1 - chi*chi*c/rn
+	for i := range v {
+		v[i] = fdot*r0[i] + gdot*v0[i]
+	}
+	return r, v, nil
+}
+
+// stateVectors returns the position and velocity of a state as vectors.
+func stateVectors(s StateVector) (linalg.Vec3, linalg.Vec3) {
+	return linalg.Vec3{s.Position.X, s.Position.Y, s.Position.Z}, linalg.Vec3{s.Velocity.DX, s.Velocity.DY, s.Velocity.DZ}
+}
+
+// AngularMomentum returns the specific angular momentum h = r x v of a state relative to its
+// central body. It is normal to the osculating orbital plane, and constant for a two-body
+// orbit.
+//
+// Parameters:
+//   - s: State relative to the central body, in AU and AU/day.
+//
+// Returns:
+//   - [3]float64: The angular momentum per unit mass, in AU^2/day, in the frame of s.
+func AngularMomentum(s StateVector) [3]float64 {
+	r, v := stateVectors(s)
+	return [3]float64(r.Cross(v))
+}
+
+// OrbitalEnergy returns the specific orbital energy v^2/2 - GM/r of a state: negative for
+// an elliptic orbit, with semi-major axis a = -GM/(2 energy), zero for a parabolic one, and
+// positive for a hyperbolic one.
+//
+// Parameters:
+//   - s: State relative to the central body, in AU and AU/day.
+//   - gm: Gravitational parameter in AU^3/day^2 (see Ephemeris.GM); for the orbit of a body
+//     of non-negligible mass, the sum of the parameters of both bodies.
+//
+// Returns:
+//   - float64: The energy per unit mass, in AU^2/day^2 (NaN for a position at the origin).
+func OrbitalEnergy(s StateVector, gm float64) float64 {
+	r, v := stateVectors(s)
+	rn := r.Norm()
+	if rn == 0 {
+		return math.NaN()
+	}
+	return v.Dot(v)/2 - gm/rn
+}
+
+// LaplaceRungeLenz returns the Laplace-Runge-Lenz vector A = v x h - GM r/|r| of a state,
+// per unit mass. It points to the periapsis, and A/GM is the eccentricity vector, whose
+// length is the eccentricity. Like h and the energy, it is constant for a two-body orbit,
+// so its drift measures the perturbations of a trajectory.
+//
+// Parameters:
+//   - s: State relative to the central body, in AU and AU/day.
+//   - gm: Gravitational parameter in AU^3/day^2, as for OrbitalEnergy.
+//
+// Returns:
+//   - [3]float64: The vector in AU^3/day^2, in the frame of s (NaN for a position at the origin).
+func LaplaceRungeLenz(s StateVector, gm float64) [3]float64 {
+	r, v := stateVectors(s)
+	rn := r.Norm()
+	if rn == 0 {
+		return [3]float64{math.NaN(), math.NaN(), math.NaN()}
+	}
+	return [3]float64(v.Cross(r.Cross(v)).Sub(r.Scale(gm / rn)))
+}
+
+// FlightPathAngle returns the flight-path angle of a state: the angle of the velocity above
+// the local horizontal, the plane normal to the position. It is zero at the apsides and for
+// a circular orbit, positive while the distance grows, and needs no gravitational parameter.
+//
+// Parameters:
+//   - s: State relative to the central body, in AU and AU/day.
+//
+// Returns:
+//   - float64: The angle in radians, in [-pi/2, pi/2] (NaN for a zero position or velocity).
+func FlightPathAngle(s StateVector) float64 {
+	r, v := stateVectors(s)
+	rn, vn := r.Norm(), v.Norm()
+	if rn == 0 || vn == 0 {
+		return math.NaN()
+	}
+	return math.Asin(math.Max(-1, math.Min(1, r.Dot(v)/(rn*vn))))
+}
+
+// TrueAnomaly returns the true anomaly of a state: the angle from the periapsis to the
+// position in the osculating orbital plane, measured in the direction of motion. It is
+// taken from the eccentricity vector (LaplaceRungeLenz / gm), so it is ill-conditioned for
+// nearly circular orbits, where the periapsis is barely defined.
+//
+// Parameters:
+//   - s: State relative to the central body, in AU and AU/day.
+//   - gm: Gravitational parameter in AU^3/day^2, as for OrbitalEnergy.
+//
+// Returns:
+//   - float64: The angle in radians, in [0, 2 pi) (NaN for a zero position or angular
+//     momentum, or an exactly circular orbit).
+func TrueAnomaly(s StateVector, gm float64) float64 {
+	r, v := stateVectors(s)
+	h := r.Cross(v)
+	ecc := linalg.Vec3(LaplaceRungeLenz(s, gm)).Scale(1 / gm)
+	if h.Norm() == 0 || ecc.Norm() == 0 {
+		return math.NaN()
+	}
+	nu := math.Atan2(ecc.Cross(r).Dot(h.Unit()), ecc.Dot(r))
+	if nu < 0 {
+		nu += 2 * math.Pi
+	}
+	return nu
+}
+
+// HeliocentricMotion is the orbital motion of a body around the Sun at one epoch, as
+// computed by Ephemeris.HeliocentricMotion.
+type HeliocentricMotion struct {
+	ET              float64 // Epoch (TDB Julian Date)
+	Distance        float64 // Distance from the Sun in AU
+	Speed           float64 // Heliocentric speed in AU/day
+	FlightPathAngle float64 // Angle of the velocity above the local horizontal, in radians
+	TrueAnomaly     float64 // Angle from the perihelion in the osculating orbit, in radians [0, 2 pi)
+	Eccentricity    float64 // Eccentricity of the osculating orbit
+}
+
+// HeliocentricMotion returns the distance, speed, flight-path angle, and true anomaly of a
+// body around the Sun, from its heliocentric state and the osculating two-body orbit with the
+// GM of the Sun and the body, for displays and teaching. Bodies without a GM in the file
+// (SPK and custom bodies) use that of the Sun alone.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - body: Body orbiting the Sun (e.g., jpleph.Mars).
+//
+// Returns:
+//   - HeliocentricMotion: The quantities of the motion.
+//   - error: ErrConstantNotFound if the constants of the file are not loaded (GMS), or an
+//     error from CalculatePV.
+func (e *Ephemeris) HeliocentricMotion(et float64, body Planet) (HeliocentricMotion, error) {
+	pos, vel, err := e.CalculatePV(et, body, CenterSun, true)
+	if err != nil {
+		return HeliocentricMotion{}, err
+	}
+	gm, err := e.bodyGM(Sun)
+	if err != nil {
+		return HeliocentricMotion{}, err
+	}
+	if gmBody, err := e.bodyGM(body); err == nil {
+		gm += gmBody
+	}
+	s := StateVector{ET: et, Position: pos, Velocity: vel}
+	return HeliocentricMotion{
+		ET:              et,
+		Distance:        float64(pos.Distance()),
+		Speed:           float64(vel.Speed()),
+		FlightPathAngle: FlightPathAngle(s),
+		TrueAnomaly:     TrueAnomaly(s, gm),
+		Eccentricity:    norm3(LaplaceRungeLenz(s, gm)) / gm,
+	}, nil
+}
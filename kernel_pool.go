@@ -0,0 +1,329 @@
+// ./kernel_pool.go
+package jpleph
+
+/*
+Package jpleph provides a kernel pool that combines several ephemeris sources.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// astronomicalUnitKM is the IAU 2012 astronomical unit in km, used by a KernelPool to convert
+// SPK states when no JPL ephemeris has been registered.
+const astronomicalUnitKM = 149597870.7
+
+// poolEntry is one source of states for a single target in a KernelPool.
+type poolEntry struct {
+	target     Planet                                                                  // Body the entry provides
+	start, end float64                                                                 // Coverage as Julian Dates; start > end means unbounded
+	source     string                                                                  // File or kind of the source, for error messages
+	state      func(et float64, calcVelocity bool) (Position, Velocity, Planet, error) // State (AU, AU/day) and its center
+}
+
+// covers reports whether the entry provides states at et.
+func (pe *poolEntry) covers(et float64) bool {
+	return pe.start > pe.end || (et >= pe.start && et <= pe.end)
+}
+
+// KernelPool combines planetary ephemerides, SPK kernels, custom bodies, and other state
+// sources, in the manner of the SPICE kernel pool. For each target the most recently
+// registered source covering the requested time is used, and states are chained through the
+// centers of the sources down to the Solar System Barycenter, so any target can be expressed
+// relative to any center. A KernelPool is not safe for concurrent use.
+type KernelPool struct {
+	ephemerides []*Ephemeris // Planetary ephemerides, in registration order
+	owned       []io.Closer  // Sources opened by Furnish, closed by Close
+	entries     []poolEntry  // State sources, in registration order
+}
+
+// NewKernelPool returns an empty kernel pool.
+func NewKernelPool() *KernelPool {
+	return &KernelPool{}
+}
+
+// Furnish opens an ephemeris file and registers it, detecting its type from the content:
+// SPK kernels (DAF/SPK), custom body files (JPLCUST1), or JPL DE binary files.
+//
+// Parameters:
+//   - filename: Path to the file.
+//
+// Returns:
+//   - error: A format or file access error from the matching reader.
+func (p *KernelPool) Furnish(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open kernel: %w", err)
+	}
+	magic := make([]byte, 8)
+	_, err = io.ReadFull(f, magic)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to read kernel header: %w", err)
+	}
+
+	switch string(magic) {
+	case "DAF/SPK ":
+		f.Close()
+		k, err := OpenSPK(filename)
+		if err != nil {
+			return err
+		}
+		p.owned = append(p.owned, k)
+		p.AddSPK(k)
+	case customBodyMagic:
+		defer f.Close()
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("%w: %v", ErrFileSeek, err)
+		}
+		bodies, err := ReadCustomBodies(f)
+		if err != nil {
+			return err
+		}
+		for _, b := range bodies {
+			p.AddCustomBody(b)
+		}
+	default:
+		f.Close()
+		e, err := NewEphemeris(filename, false)
+		if err != nil {
+			return err
+		}
+		p.owned = append(p.owned, e)
+		p.AddEphemeris(e)
+	}
+	return nil
+}
+
+// AddEphemeris registers a JPL ephemeris for its kernel bodies (Mercury to the Earth-Moon
+// barycenter) and special quantities over its time span. The caller keeps ownership of e.
+func (p *KernelPool) AddEphemeris(e *Ephemeris) {
+	p.ephemerides = append(p.ephemerides, e)
+	start := e.GetEphemerisDouble(EphemerisStartJD)
+	end := e.GetEphemerisDouble(EphemerisEndJD)
+	for body := Mercury; body <= EarthMoonBarycenter; body++ {
+		if body == SolarSystemBarycenter {
+			continue
+		}
+		body := body
+		p.entries = append(p.entries, poolEntry{target: body, start: start, end: end, source: ephemerisLabel(e),
+			state: func(et float64, calcVelocity bool) (Position, Velocity, Planet, error) {
+				pos, vel, err := e.CalculatePV(et, body, CenterSolarSystemBarycenter, calcVelocity)
+				return pos, vel, SolarSystemBarycenter, err
+			}})
+	}
+}
+
+// AddSPK registers every body of an SPK kernel over its coverage. The caller keeps ownership of k.
+func (p *KernelPool) AddSPK(k *SPKKernel) {
+	for _, naif := range k.Bodies() {
+		naif := naif
+		start, end, _ := k.Span(naif)
+		p.entries = append(p.entries, poolEntry{target: planetFromNAIF(naif), start: start, end: end, source: k.filename,
+			state: func(et float64, calcVelocity bool) (Position, Velocity, Planet, error) {
+				state, center, err := k.State(naif, et)
+				if err != nil {
+					return Position{}, Velocity{}, 0, err
+				}
+				aufac := 1.0 / p.au()
+				pos := Position{X: state[0] * aufac, Y: state[1] * aufac, Z: state[2] * aufac}
+				var vel Velocity
+				if calcVelocity {
+					vfac := aufac * secondsPerDay // km/s to AU/day
+					vel = Velocity{DX: state[3] * vfac, DY: state[4] * vfac, DZ: state[5] * vfac}
+				}
+				return pos, vel, planetFromNAIF(center), nil
+			}})
+	}
+}
+
+// AddCustomBody registers a custom body over its span, relative to its Center.
+func (p *KernelPool) AddCustomBody(b *CustomBody) {
+	start, end := b.Span()
+	p.entries = append(p.entries, poolEntry{target: b.ID, start: start, end: end, source: "custom body " + b.Name,
+		state: func(et float64, calcVelocity bool) (Position, Velocity, Planet, error) {
+			pos, vel, err := b.State(et, calcVelocity)
+			return pos, vel, Planet(b.Center), err
+		}})
+}
+
+// AddSource registers any StateSource, such as a SmallBody or a Trajectory, as the provider
+// of target relative to center. Sources with a Span method (like Trajectory) are used only
+// within their span; others are used at all times.
+//
+// Parameters:
+//   - target: Identifier under which the source is queried (e.g., a custom body ID).
+//   - center: Body the states of src are relative to.
+//   - src: Source of states in AU and AU/day.
+func (p *KernelPool) AddSource(target Planet, center CenterBody, src StateSource) {
+	start, end := 1.0, 0.0 // Unbounded
+	if s, ok := src.(interface{ Span() (float64, float64) }); ok {
+		start, end = s.Span()
+	}
+	p.entries = append(p.entries, poolEntry{target: target, start: start, end: end, source: fmt.Sprintf("source %T", src),
+		state: func(et float64, calcVelocity bool) (Position, Velocity, Planet, error) {
+			pos, vel, err := src.StateAt(et)
+			return pos, vel, Planet(center), err
+		}})
+}
+
+// CalculatePV computes the state of target relative to center from the registered sources.
+// Special quantities (Nutations to TT_TDB) come from the most recently registered ephemeris
+// covering et that contains them, so that files stitched over a span may lack some of them
+// (see QuantityCoverage).
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target: Target body.
+//   - center: Center body.
+//   - calcVelocity: Whether to compute the velocity.
+//
+// Returns:
+//   - Position: Position in AU.
+//   - Velocity: Velocity in AU/day (zero unless calcVelocity).
+//   - error: ErrOutsideRange if a body is registered but not covered at et, ErrInvalidIndex
+//     if no source provides it, ErrQuantityNotInEphemeris if the ephemerides covering et lack
+//     a special quantity, ErrSPKFormat for a center chain that does not end at the Solar
+//     System Barycenter, or an error from a source. The messages name the files and their
+//     spans.
+func (p *KernelPool) CalculatePV(et float64, target Planet, center CenterBody, calcVelocity bool) (Position, Velocity, error) {
+	if target >= Nutations && target <= TT_TDB {
+		var lacking []string
+		for i := len(p.ephemerides) - 1; i >= 0; i-- {
+			e := p.ephemerides[i]
+			if !ephemerisCovers(e, et) {
+				continue
+			}
+			if !ephemerisHas(e, target) {
+				lacking = append(lacking, ephemerisSpan(e))
+				continue
+			}
+			return e.CalculatePV(et, target, center, calcVelocity)
+		}
+		if len(lacking) > 0 {
+			return Position{}, Velocity{}, fmt.Errorf("%v at JD %f: not in %s: %w", target, et,
+				strings.Join(lacking, ", "), ErrQuantityNotInEphemeris)
+		}
+		return Position{}, Velocity{}, fmt.Errorf("%v at JD %f: %w", target, et, ErrOutsideRange)
+	}
+	if target == Planet(center) {
+		return Position{}, Velocity{}, nil
+	}
+	tPos, tVel, err := p.barycentric(et, target, calcVelocity)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	cPos, cVel, err := p.barycentric(et, Planet(center), calcVelocity)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	pos := Position{X: tPos.X - cPos.X, Y: tPos.Y - cPos.Y, Z: tPos.Z - cPos.Z}
+	vel := Velocity{DX: tVel.DX - cVel.DX, DY: tVel.DY - cVel.DY, DZ: tVel.DZ - cVel.DZ}
+	return pos, vel, nil
+}
+
+// Source returns a StateSource view of the pool for one target and center, for use as a
+// Perturber or with other code that consumes a StateSource.
+func (p *KernelPool) Source(target Planet, center CenterBody) StateSource {
+	return poolSource{pool: p, target: target, center: center}
+}
+
+// poolSource adapts a KernelPool target/center pair to the StateSource interface.
+type poolSource struct {
+	pool   *KernelPool
+	target Planet
+	center CenterBody
+}
+
+// StateAt implements StateSource.
+func (ps poolSource) StateAt(et float64) (Position, Velocity, error) {
+	return ps.pool.CalculatePV(et, ps.target, ps.center, true)
+}
+
+// Close closes the sources opened by Furnish. Sources added with the Add methods are left to
+// their owners.
+func (p *KernelPool) Close() error {
+	var first error
+	for _, c := range p.owned {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	p.owned = nil
+	return first
+}
+
+// au returns the length of the astronomical unit in km used by the pool.
+func (p *KernelPool) au() float64 {
+	if len(p.ephemerides) > 0 {
+		return p.ephemerides[0].ephemData.au
+	}
+	return astronomicalUnitKM
+}
+
+// barycentric returns the Solar System Barycentric state of body by chaining source centers.
+func (p *KernelPool) barycentric(et float64, body Planet, calcVelocity bool) (Position, Velocity, error) {
+	var pos Position
+	var vel Velocity
+	for hops := 0; body != SolarSystemBarycenter; hops++ {
+		if hops == maxSPKChain {
+			return Position{}, Velocity{}, fmt.Errorf("%w: center chain of body %d is too long", ErrSPKFormat, body)
+		}
+		entry, err := p.lookup(et, body)
+		if err != nil {
+			return Position{}, Velocity{}, err
+		}
+		sPos, sVel, center, err := entry.state(et, calcVelocity)
+		if err != nil {
+			return Position{}, Velocity{}, err
+		}
+		pos = Position{X: pos.X + sPos.X, Y: pos.Y + sPos.Y, Z: pos.Z + sPos.Z}
+		if calcVelocity {
+			vel = Velocity{DX: vel.DX + sVel.DX, DY: vel.DY + sVel.DY, DZ: vel.DZ + sVel.DZ}
+		}
+		body = center
+	}
+	return pos, vel, nil
+}
+
+// lookup returns the most recently registered entry providing body at et.
+func (p *KernelPool) lookup(et float64, body Planet) (*poolEntry, error) {
+	var known []string
+	for i := len(p.entries) - 1; i >= 0; i-- {
+		entry := &p.entries[i]
+		if entry.target != body {
+			continue
+		}
+		if entry.covers(et) {
+			return entry, nil
+		}
+		known = append(known, fmt.Sprintf("%s (JD %.1f to %.1f)", entry.source, entry.start, entry.end))
+	}
+	if len(known) > 0 {
+		return nil, fmt.Errorf("%v at JD %f: outside %s: %w", body, et, strings.Join(known, ", "), ErrOutsideRange)
+	}
+	return nil, fmt.Errorf("%v is not provided by any kernel: %w", body, ErrInvalidIndex)
+}
@@ -0,0 +1,327 @@
+// ./leap_seconds.go
+package jpleph
+
+/*
+Package jpleph provides the table of leap seconds for UTC conversions.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// mjdOffset is the Julian Date of the Modified Julian Date origin (1858 November 17.0).
+const mjdOffset = 2400000.5
+
+// ttMinusTAI is the constant offset TT - TAI in seconds.
+const ttMinusTAI = 32.184
+
+// LeapSecond is an entry of the table of TAI - UTC.
+type LeapSecond struct {
+	JD          float64 // JD is the UTC Julian Date from which the entry applies (0h UTC of the day).
+	TAIMinusUTC float64 // TAIMinusUTC is TAI - UTC in seconds from JD on (at RefMJD for drifting entries).
+	RefMJD      float64 // RefMJD is the reference MJD of the drift of pre-1972 entries (tai-utc.dat only).
+	Rate        float64 // Rate is the drift of TAI - UTC in seconds per day of pre-1972 entries, 0 since 1972.
+}
+
+// LeapSecondTable is a table of TAI - UTC, such as the IERS file Leap_Second.dat. It is
+// immutable and safe for concurrent use.
+type LeapSecondTable struct {
+	entries []LeapSecond // Entries by ascending JD
+	expires time.Time    // Date after which the table may miss announced leap seconds; zero if unknown
+}
+
+// embeddedLeapSeconds lists the dates (year, month) from which TAI - UTC took its values from
+// 10 s in 1972 to 37 s in 2017, as announced in the IERS Bulletins C.
+var embeddedLeapSeconds = [...][2]int{
+	{1972, 1}, {1972, 7}, {1973, 1}, {1974, 1}, {1975, 1}, {1976, 1}, {1977, 1}, {1978, 1},
+	{1979, 1}, {1980, 1}, {1981, 7}, {1982, 7}, {1983, 7}, {1985, 7}, {1988, 1}, {1990, 1},
+	{1991, 1}, {1992, 7}, {1993, 7}, {1994, 7}, {1996, 1}, {1997, 7}, {1999, 1}, {2006, 1},
+	{2009, 1}, {2012, 7}, {2015, 7}, {2017, 1},
+}
+
+// embeddedLeapSecondsExpire is the expiration date of the embedded table, from IERS Bulletin C 70
+// (July 2025), which announced no leap second at the end of December 2025.
+var embeddedLeapSecondsExpire = time.Date(2026, time.June, 28, 0, 0, 0, 0, time.UTC)
+
+// currentLeapSeconds is the table used by UTCToTDB and TDBToUTC.
+var currentLeapSeconds atomic.Pointer[LeapSecondTable]
+
+func init() {
+	currentLeapSeconds.Store(EmbeddedLeapSeconds())
+}
+
+// EmbeddedLeapSeconds returns the leap-second table built into the package.
+func EmbeddedLeapSeconds() *LeapSecondTable {
+	t := &LeapSecondTable{entries: make([]LeapSecond, len(embeddedLeapSeconds)), expires: embeddedLeapSecondsExpire}
+	for i, ym := range embeddedLeapSeconds {
+		jd := julianDateFromTime(time.Date(ym[0], time.Month(ym[1]), 1, 0, 0, 0, 0, time.UTC))
+		t.entries[i] = LeapSecond{JD: jd, TAIMinusUTC: float64(10 + i)}
+	}
+	return t
+}
+
+// CurrentLeapSeconds returns the table used by UTCToTDB and TDBToUTC: the embedded table,
+// unless replaced by SetLeapSeconds or UpdateLeapSeconds.
+func CurrentLeapSeconds() *LeapSecondTable {
+	return currentLeapSeconds.Load()
+}
+
+// SetLeapSeconds replaces the table used by UTCToTDB and TDBToUTC.
+func SetLeapSeconds(t *LeapSecondTable) {
+	currentLeapSeconds.Store(t)
+}
+
+// UpdateLeapSeconds reads a leap-second table from a file or an http(s) URL (e.g.,
+// https://hpiers.obspm.fr/iers/bul/bulc/Leap_Second.dat) and makes it the current table.
+// The current table is kept if the new one fails to parse or is older than it.
+//
+// Parameters:
+//   - source: Path or URL of a Leap_Second.dat or tai-utc.dat file.
+//
+// Returns:
+//   - error: ErrTimeData if the file is malformed or its last entry precedes that of the
+//     current table, or a file access or request error.
+func UpdateLeapSeconds(source string) error {
+	var r io.ReadCloser
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := http.Get(source)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("%w: %s: status %s", ErrTimeData, source, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return fmt.Errorf("failed to open leap-second file: %w", err)
+		}
+		r = f
+	}
+	defer r.Close()
+
+	t, err := ReadLeapSeconds(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	cur := CurrentLeapSeconds()
+	last := func(t *LeapSecondTable) float64 { return t.entries[len(t.entries)-1].JD }
+	if last(t) < last(cur) {
+		return fmt.Errorf("%w: %s ends before the current table", ErrTimeData, source)
+	}
+	SetLeapSeconds(t)
+	return nil
+}
+
+// leapExpiresPattern matches the expiration comment of Leap_Second.dat.
+var leapExpiresPattern = regexp.MustCompile(`(?i)expires on\s+(\d{1,2})\s+([a-z]+)\s+(\d{4})`)
+
+// ReadLeapSeconds parses a table of TAI - UTC in either of the common formats, detected per
+// line. The IERS Leap_Second.dat gives the MJD, day, month, year, and TAI - UTC:
+//
+//	41317.0    1  1 1972       10
+//
+// and its "File expires on 28 June 2026" comment sets Expires. The USNO tai-utc.dat also
+// gives the drift of the pre-1972 entries:
+//
+//	1972 JAN  1 =JD 2441317.5  TAI-UTC=  10.0       S + (MJD - 41317.) X 0.0      S
+//
+// Parameters:
+//   - r: Source of the table.
+//
+// Returns:
+//   - *LeapSecondTable: The table.
+//   - error: ErrTimeData for a malformed line or an empty table, or a read error.
+func ReadLeapSeconds(r io.Reader) (*LeapSecondTable, error) {
+	t := &LeapSecondTable{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if m := leapExpiresPattern.FindStringSubmatch(line); m != nil {
+				if d, err := time.Parse("2 January 2006", m[1]+" "+m[2]+" "+m[3]); err == nil {
+					t.expires = d
+				}
+			}
+			continue
+		}
+		var entry LeapSecond
+		var err error
+		if strings.Contains(line, "=JD") {
+			entry, err = parseTAIUTCLine(line)
+		} else {
+			entry, err = parseLeapSecondLine(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: leap-second line %d: %v", ErrTimeData, n, err)
+		}
+		t.entries = append(t.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.entries) == 0 {
+		return nil, fmt.Errorf("%w: no leap-second entries", ErrTimeData)
+	}
+	sort.Slice(t.entries, func(i, j int) bool { return t.entries[i].JD < t.entries[j].JD })
+	return t, nil
+}
+
+// parseLeapSecondLine parses an entry of Leap_Second.dat: MJD, day, month, year, TAI - UTC.
+func parseLeapSecondLine(line string) (LeapSecond, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 {
+		return LeapSecond{}, fmt.Errorf("%d fields", len(fields))
+	}
+	mjd, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return LeapSecond{}, err
+	}
+	offset, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return LeapSecond{}, err
+	}
+	return LeapSecond{JD: mjd + mjdOffset, TAIMinusUTC: offset}, nil
+}
+
+// parseTAIUTCLine parses an entry of tai-utc.dat.
+func parseTAIUTCLine(line string) (LeapSecond, error) {
+	value := func(key string) (float64, error) {
+		i := strings.Index(line, key)
+		if i < 0 {
+			return 0, fmt.Errorf("missing %q", key)
+		}
+		fields := strings.Fields(strings.TrimLeft(line[i+len(key):], " "))
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("missing value after %q", key)
+		}
+		return strconv.ParseFloat(strings.TrimSuffix(fields[0], ")"), 64)
+	}
+	var entry LeapSecond
+	var err error
+	if entry.JD, err = value("=JD"); err != nil {
+		return LeapSecond{}, err
+	}
+	if entry.TAIMinusUTC, err = value("TAI-UTC="); err != nil {
+		return LeapSecond{}, err
+	}
+	if entry.RefMJD, err = value("(MJD -"); err != nil {
+		return LeapSecond{}, err
+	}
+	if entry.Rate, err = value(") X"); err != nil {
+		return LeapSecond{}, err
+	}
+	return entry, nil
+}
+
+// Entries returns a copy of the entries by ascending date.
+func (t *LeapSecondTable) Entries() []LeapSecond {
+	return append([]LeapSecond(nil), t.entries...)
+}
+
+// Expires returns the date after which the table may miss announced leap seconds, or the
+// zero time if the source did not state one.
+func (t *LeapSecondTable) Expires() time.Time {
+	return t.expires
+}
+
+// Stale reports whether the table has expired at now, so that UTC conversions after its
+// expiration date may be off by the leap seconds announced since.
+func (t *LeapSecondTable) Stale(now time.Time) bool {
+	return !t.expires.IsZero() && now.After(t.expires)
+}
+
+// TAIMinusUTC returns TAI - UTC in seconds at a UTC Julian Date. Before the first entry it
+// returns the value of the first entry, and after the last entry the value of the last.
+func (t *LeapSecondTable) TAIMinusUTC(jdUTC float64) float64 {
+	i := sort.Search(len(t.entries), func(i int) bool { return t.entries[i].JD > jdUTC }) - 1
+	if i < 0 {
+		i = 0
+	}
+	e := t.entries[i]
+	return e.TAIMinusUTC + (jdUTC-mjdOffset-e.RefMJD)*e.Rate
+}
+
+// UTCToTDB converts a UTC Julian Date into a TDB Julian Date, the time argument of CalculatePV.
+func (t *LeapSecondTable) UTCToTDB(jdUTC float64) float64 {
+	tt := jdUTC + (t.TAIMinusUTC(jdUTC)+ttMinusTAI)/secondsPerDay
+	return tt + tdbMinusTT(tt)/secondsPerDay
+}
+
+// TDBToUTC converts a TDB Julian Date, such as the time of an event, into a UTC Julian Date.
+// Times within a leap second map onto the following second.
+func (t *LeapSecondTable) TDBToUTC(et float64) float64 {
+	tai := et - (tdbMinusTT(et)+ttMinusTAI)/secondsPerDay
+	utc := tai - t.TAIMinusUTC(tai)/secondsPerDay
+	return tai - t.TAIMinusUTC(utc)/secondsPerDay // Once more, in case a leap second lies in between
+}
+
+// CivilTime converts a TDB Julian Date, such as the time of an event, into the wall-clock
+// time of a location, through UTC with this table and then the time zone rules of loc
+// (daylight saving time included), rounded to the microsecond. As time.Time has no leap
+// seconds, times within a leap second map onto the following second.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - loc: Time zone of the result (e.g., from time.LoadLocation("Europe/Paris")); nil for UTC.
+//
+// Returns:
+//   - time.Time: The instant, in loc.
+func (t *LeapSecondTable) CivilTime(et float64, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return timeFromJulianDate(t.TDBToUTC(et)).In(loc)
+}
+
+// UTCToTDB converts a UTC Julian Date into a TDB Julian Date with the current leap-second table.
+func UTCToTDB(jdUTC float64) float64 {
+	return CurrentLeapSeconds().UTCToTDB(jdUTC)
+}
+
+// TDBToUTC converts a TDB Julian Date into a UTC Julian Date with the current leap-second table.
+func TDBToUTC(et float64) float64 {
+	return CurrentLeapSeconds().TDBToUTC(et)
+}
+
+// CivilTime converts a TDB Julian Date, such as the time of an event, into the wall-clock
+// time of a location with the current leap-second table; nil loc gives UTC. EpochFromTime
+// converts back: EpochFromTime(t).TDB().
+func CivilTime(et float64, loc *time.Location) time.Time {
+	return CurrentLeapSeconds().CivilTime(et, loc)
+}
@@ -0,0 +1,234 @@
+// ./light_time.go
+package jpleph
+
+/*
+Package jpleph provides light-time solutions with configurable convergence.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// defaultLightTimeIterations is the iteration limit of a converged light-time solution.
+const defaultLightTimeIterations = 10
+
+// LightTimeDirection selects the path of the signal in a light-time solution.
+type LightTimeDirection int
+
+const (
+	// LightTimeReception is the one-way downlink: the signal leaves the target and arrives at
+	// the observer at et.
+	LightTimeReception LightTimeDirection = iota
+	// LightTimeTransmission is the one-way uplink: the signal leaves the observer at et and
+	// arrives at the target.
+	LightTimeTransmission
+	// LightTimeRoundTrip is the two-way case of radiometric tracking: the signal leaves the
+	// observer, is returned by the target, and arrives back at the observer at et.
+	LightTimeRoundTrip
+)
+
+// lightTimeDirectionNames holds the names of the directions, indexed by LightTimeDirection value.
+var lightTimeDirectionNames = [...]string{LightTimeReception: "reception", LightTimeTransmission: "transmission",
+	LightTimeRoundTrip: "round trip"}
+
+// String returns the name of the direction (e.g., "round trip").
+func (d LightTimeDirection) String() string {
+	if d >= 0 && int(d) < len(lightTimeDirectionNames) {
+		return lightTimeDirectionNames[d]
+	}
+	return fmt.Sprintf("LightTimeDirection(%d)", int(d))
+}
+
+// LightTimeOptions configures the iteration of the light-time equation by LightTimeState.
+// The zero value solves the one-way downlink to the precision of float64 in at most 10
+// iterations, as the "CN" corrections of SpkEzr do.
+type LightTimeOptions struct {
+	Direction     LightTimeDirection // Path of the signal
+	Tolerance     float64            // Change of the light time between iterations at convergence, in seconds (0: 1e-15 of the light time)
+	MaxIterations int                // Iteration limit of each leg (0: 10; 1: single-pass "LT" solution)
+}
+
+// LightTimeResult is a light-time corrected state computed by LightTimeState.
+type LightTimeResult struct {
+	Position   Position // Target at the epoch it emits or receives the signal, relative to the observer at et, in AU
+	Velocity   Velocity // Rate of change of Position in AU/day, including that of the light time
+	LightTime  float64  // Light time of the signal in seconds: one way, or the sum of both legs for a round trip
+	Uplink     float64  // Light time from the observer to the target in seconds (round trip and transmission)
+	Downlink   float64  // Light time from the target to the observer in seconds (round trip and reception)
+	Iterations int      // Iterations done, over both legs for a round trip
+	Converged  bool     // Whether every leg met the tolerance within MaxIterations
+}
+
+// LightTimeState returns the state of a target relative to an observer corrected for light
+// time, with control over the iteration and the direction of the signal, and the converged
+// light time. For a round trip, the state is that of the downlink (the target at the epoch it
+// returns the signal), and the uplink leg is solved back from there to the transmission at
+// the observer. Stellar aberration is not applied.
+//
+// An iteration limit reached before Tolerance is met is not an error: the result holds the
+// last iterate with Converged false.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date of reception (or transmission, for
+//     LightTimeTransmission) at the observer.
+//   - target, observer: Bodies at the ends of the signal path.
+//   - opts: Direction and convergence of the solution.
+//
+// Returns:
+//   - LightTimeResult: The corrected state and light times.
+//   - error: ErrInvalidIndex if the target and observer coincide or the direction is
+//     unknown, or an error from CalculatePV.
+func (e *Ephemeris) LightTimeState(et float64, target, observer Planet, opts LightTimeOptions) (LightTimeResult, error) {
+	if target == observer {
+		return LightTimeResult{}, fmt.Errorf("target and observer are both %v: %w", target, ErrInvalidIndex)
+	}
+	corr := spiceCorrection{lightTime: true, converged: true, transmission: -1, tolerance: opts.Tolerance,
+		maxIterations: opts.MaxIterations}
+	switch opts.Direction {
+	case LightTimeReception, LightTimeRoundTrip:
+	case LightTimeTransmission:
+		corr.transmission = 1
+	default:
+		return LightTimeResult{}, fmt.Errorf("%v: %w", opts.Direction, ErrInvalidIndex)
+	}
+	if opts.MaxIterations == 1 {
+		corr.converged = false
+	}
+
+	sol, err := e.solveLightTime((et-J2000)*secondsPerDay, target, observer, corr, true)
+	if err != nil {
+		return LightTimeResult{}, err
+	}
+	km := e.ephemData.au
+	kms := km / secondsPerDay
+	res := LightTimeResult{
+		Position:   Position{X: sol.pos[0] / km, Y: sol.pos[1] / km, Z: sol.pos[2] / km},
+		Velocity:   Velocity{DX: sol.vel[0] / kms, DY: sol.vel[1] / kms, DZ: sol.vel[2] / kms},
+		LightTime:  sol.lt,
+		Iterations: sol.iterations,
+		Converged:  sol.converged,
+	}
+	switch opts.Direction {
+	case LightTimeReception:
+		res.Downlink = sol.lt
+		return res, nil
+	case LightTimeTransmission:
+		res.Uplink = sol.lt
+		return res, nil
+	}
+
+	// Uplink: up = |target(bounce) - observer(bounce - up)| / c, from the downlink value.
+	res.Downlink = sol.lt
+	bounce := et - sol.lt/secondsPerDay
+	obs, _, err := e.CalculatePV(et, observer, CenterSolarSystemBarycenter, false)
+	if err != nil {
+		return LightTimeResult{}, err
+	}
+	tgt := [3]float64{obs.X*km + sol.pos[0], obs.Y*km + sol.pos[1], obs.Z*km + sol.pos[2]} // At the bounce, in km
+	up, converged := sol.lt, corr.maxIterations == 1
+	limit := corr.maxIterations
+	if limit <= 0 {
+		limit = defaultLightTimeIterations
+	}
+	for iter := 0; iter < limit; iter++ {
+		res.Iterations++
+		o, _, err := e.CalculatePV(bounce-up/secondsPerDay, observer, CenterSolarSystemBarycenter, false)
+		if err != nil {
+			return LightTimeResult{}, err
+		}
+		prev := up
+		up = norm3([3]float64{tgt[0] - o.X*km, tgt[1] - o.Y*km, tgt[2] - o.Z*km}) / speedOfLightKMS
+		tol := opts.Tolerance
+		if tol <= 0 {
+			tol = 1e-15 * up
+		}
+		if math.Abs(up-prev) <= tol {
+			converged = true
+			break
+		}
+	}
+	res.Uplink = up
+	res.LightTime = res.Downlink + up
+	res.Converged = res.Converged && converged
+	return res, nil
+}
+
+// GeometricLightTimeStates are the geometric and the light-time corrected states of a target
+// relative to an observer at one epoch, computed together by LightTimeStates.
+type GeometricLightTimeStates struct {
+	Geometric  StateVector // Target and observer both at et, in AU and AU/day
+	Corrected  StateVector // Target at the epoch it emits (or receives) the signal, relative to the observer at et; ET is that epoch
+	LightTime  float64     // One-way light time in seconds
+	Iterations int         // Light-time iterations done
+	Converged  bool        // Whether the light time met the tolerance within MaxIterations
+}
+
+// LightTimeStates returns the geometric state of a target relative to an observer and the
+// state corrected for one-way light time, with the light time, for pipelines that need the
+// pair. The observer is interpolated once at et and the geometric state is the first step
+// of the light-time iteration, so the pair costs no more record accesses than
+// LightTimeState alone. Stellar aberration is not applied.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date at the observer.
+//   - target, observer: Bodies at the ends of the signal path.
+//   - opts: Convergence of the solution; Direction is LightTimeReception or
+//     LightTimeTransmission.
+//
+// Returns:
+//   - GeometricLightTimeStates: Both states and the light time.
+//   - error: ErrInvalidIndex if the target and observer coincide or the direction is not
+//     one-way, or an error from CalculatePV.
+func (e *Ephemeris) LightTimeStates(et float64, target, observer Planet, opts LightTimeOptions) (GeometricLightTimeStates, error) {
+	if target == observer {
+		return GeometricLightTimeStates{}, fmt.Errorf("target and observer are both %v: %w", target, ErrInvalidIndex)
+	}
+	corr := spiceCorrection{lightTime: true, converged: opts.MaxIterations != 1, transmission: -1,
+		tolerance: opts.Tolerance, maxIterations: opts.MaxIterations}
+	switch opts.Direction {
+	case LightTimeReception:
+	case LightTimeTransmission:
+		corr.transmission = 1
+	default:
+		return GeometricLightTimeStates{}, fmt.Errorf("%v is not one-way: %w", opts.Direction, ErrInvalidIndex)
+	}
+
+	sol, err := e.solveLightTime((et-J2000)*secondsPerDay, target, observer, corr, true)
+	if err != nil {
+		return GeometricLightTimeStates{}, err
+	}
+	km := e.ephemData.au
+	kms := km / secondsPerDay
+	state := func(jd float64, pos, vel [3]float64) StateVector {
+		return StateVector{ET: jd, Position: Position{X: pos[0] / km, Y: pos[1] / km, Z: pos[2] / km},
+			Velocity: Velocity{DX: vel[0] / kms, DY: vel[1] / kms, DZ: vel[2] / kms}}
+	}
+	return GeometricLightTimeStates{
+		Geometric:  state(et, sol.geoPos, sol.geoVel),
+		Corrected:  state(et+corr.transmission*sol.lt/secondsPerDay, sol.pos, sol.vel),
+		LightTime:  sol.lt,
+		Iterations: sol.iterations,
+		Converged:  sol.converged,
+	}, nil
+}
@@ -0,0 +1,272 @@
+// ./linalg/linalg.go
+
+/*
+Package linalg provides the small vector, rotation matrix, and quaternion types used by the
+reference frames of jpleph, for callers who need 3x3 rotations without a general matrix
+library.
+
+Matrices follow the convention of the frame functions of jpleph: a Mat3 transforms
+coordinates, so its rows are the axes of the target frame expressed in the source frame,
+and RotX, RotY, and RotZ rotate the coordinate axes. A Quaternion rotates vectors (q v q*),
+and Quaternion.Mat3 returns the matrix doing the same, so the coordinate rotation RotZ(a)
+corresponds to the quaternion of the vector rotation by -a about z.
+
+	m := linalg.RotZ(math.Pi / 2).Mul(linalg.RotX(0.1)) // Apply RotX first, then RotZ
+	v := m.Apply(linalg.Vec3{1, 0, 0})
+	q := linalg.QuaternionFromMat3(m)
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+// Package linalg provides 3-vectors, 3x3 rotation matrices, and quaternions.
+package linalg
+
+import "math"
+
+// Vec3 is a Cartesian 3-vector.
+type Vec3 [3]float64
+
+// Add returns v + w.
+func (v Vec3) Add(w Vec3) Vec3 {
+	return Vec3{v[0] + w[0], v[1] + w[1], v[2] + w[2]}
+}
+
+// Sub returns v - w.
+func (v Vec3) Sub(w Vec3) Vec3 {
+	return Vec3{v[0] - w[0], v[1] - w[1], v[2] - w[2]}
+}
+
+// Scale returns s * v.
+func (v Vec3) Scale(s float64) Vec3 {
+	return Vec3{s * v[0], s * v[1], s * v[2]}
+}
+
+// Dot returns the scalar product of v and w.
+func (v Vec3) Dot(w Vec3) float64 {
+	return v[0]*w[0] + v[1]*w[1] + v[2]*w[2]
+}
+
+// Cross returns the vector product v x w.
+func (v Vec3) Cross(w Vec3) Vec3 {
+	return Vec3{v[1]*w[2] - v[2]*w[1], v[2]*w[0] - v[0]*w[2], v[0]*w[1] - v[1]*w[0]}
+}
+
+// Norm returns the length of v.
+func (v Vec3) Norm() float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
+
+// Unit returns v scaled to unit length, or the zero vector if v is zero.
+func (v Vec3) Unit() Vec3 {
+	n := v.Norm()
+	if n == 0 {
+		return Vec3{}
+	}
+	return v.Scale(1 / n)
+}
+
+// Angle returns the angle between v and w in radians, in [0, pi]. It uses the arctangent of
+// |v x w| and v . w, which stays accurate for nearly parallel and antiparallel vectors.
+func (v Vec3) Angle(w Vec3) float64 {
+	return math.Atan2(v.Cross(w).Norm(), v.Dot(w))
+}
+
+// Mat3 is a 3x3 matrix; as a rotation, row i holds the axis i of the target frame expressed
+// in the source frame.
+type Mat3 [3][3]float64
+
+// Identity returns the identity matrix.
+func Identity() Mat3 {
+	return Mat3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+}
+
+// RotX returns the matrix rotating the coordinate axes by angle (radians) about the x axis.
+func RotX(angle float64) Mat3 {
+	s, c := math.Sincos(angle)
+	return Mat3{{1, 0, 0}, {0, c, s}, {0, -s, c}}
+}
+
+// RotY returns the matrix rotating the coordinate axes by angle (radians) about the y axis.
+func RotY(angle float64) Mat3 {
+	s, c := math.Sincos(angle)
+	return Mat3{{c, 0, -s}, {0, 1, 0}, {s, 0, c}}
+}
+
+// RotZ returns the matrix rotating the coordinate axes by angle (radians) about the z axis.
+func RotZ(angle float64) Mat3 {
+	s, c := math.Sincos(angle)
+	return Mat3{{c, s, 0}, {-s, c, 0}, {0, 0, 1}}
+}
+
+// Mul returns the product m * n (apply n first, then m).
+func (m Mat3) Mul(n Mat3) Mat3 {
+	var r Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[i][0]*n[0][j] + m[i][1]*n[1][j] + m[i][2]*n[2][j]
+		}
+	}
+	return r
+}
+
+// Add returns m + n.
+func (m Mat3) Add(n Mat3) Mat3 {
+	var r Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[i][j] + n[i][j]
+		}
+	}
+	return r
+}
+
+// Scale returns s * m.
+func (m Mat3) Scale(s float64) Mat3 {
+	var r Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = s * m[i][j]
+		}
+	}
+	return r
+}
+
+// Transpose returns the transpose of m, the inverse of a rotation.
+func (m Mat3) Transpose() Mat3 {
+	var r Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+// Apply returns the product m * x.
+func (m Mat3) Apply(x Vec3) Vec3 {
+	return Vec3{
+		m[0][0]*x[0] + m[0][1]*x[1] + m[0][2]*x[2],
+		m[1][0]*x[0] + m[1][1]*x[1] + m[1][2]*x[2],
+		m[2][0]*x[0] + m[2][1]*x[1] + m[2][2]*x[2],
+	}
+}
+
+// Det returns the determinant of m, +1 for a proper rotation.
+func (m Mat3) Det() float64 {
+	return Vec3(m[0]).Dot(Vec3(m[1]).Cross(Vec3(m[2])))
+}
+
+// Quaternion is a rotation quaternion W + X i + Y j + Z k.
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+// QuaternionFromAxisAngle returns the quaternion rotating vectors by angle (radians,
+// counterclockwise) about axis, which need not be of unit length.
+func QuaternionFromAxisAngle(axis Vec3, angle float64) Quaternion {
+	u := axis.Unit()
+	s, c := math.Sincos(angle / 2)
+	return Quaternion{W: c, X: s * u[0], Y: s * u[1], Z: s * u[2]}
+}
+
+// QuaternionFromMat3 returns the unit quaternion whose Mat3 is the rotation m (Shepperd's
+// method, choosing the largest component for accuracy). The sign is chosen with W >= 0.
+func QuaternionFromMat3(m Mat3) Quaternion {
+	tr := m[0][0] + m[1][1] + m[2][2]
+	var q Quaternion
+	switch {
+	case tr >= m[0][0] && tr >= m[1][1] && tr >= m[2][2]:
+		s := 2 * math.Sqrt(1+tr)
+		q = Quaternion{W: s / 4, X: (m[2][1] - m[1][2]) / s, Y: (m[0][2] - m[2][0]) / s, Z: (m[1][0] - m[0][1]) / s}
+	case m[0][0] >= m[1][1] && m[0][0] >= m[2][2]:
+		s := 2 * math.Sqrt(1+m[0][0]-m[1][1]-m[2][2])
+		q = Quaternion{W: (m[2][1] - m[1][2]) / s, X: s / 4, Y: (m[0][1] + m[1][0]) / s, Z: (m[0][2] + m[2][0]) / s}
+	case m[1][1] >= m[2][2]:
+		s := 2 * math.Sqrt(1-m[0][0]+m[1][1]-m[2][2])
+		q = Quaternion{W: (m[0][2] - m[2][0]) / s, X: (m[0][1] + m[1][0]) / s, Y: s / 4, Z: (m[1][2] + m[2][1]) / s}
+	default:
+		s := 2 * math.Sqrt(1-m[0][0]-m[1][1]+m[2][2])
+		q = Quaternion{W: (m[1][0] - m[0][1]) / s, X: (m[0][2] + m[2][0]) / s, Y: (m[1][2] + m[2][1]) / s, Z: s / 4}
+	}
+	if q.W < 0 {
+		q = Quaternion{W: -q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+	}
+	return q.Normalize()
+}
+
+// Mul returns the product q * r, the rotation r followed by q.
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+	}
+}
+
+// Conj returns the conjugate of q, the inverse rotation of a unit quaternion.
+func (q Quaternion) Conj() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Norm returns the length of q.
+func (q Quaternion) Norm() float64 {
+	return math.Sqrt(q.W*q.W + q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+}
+
+// Normalize returns q scaled to unit length.
+func (q Quaternion) Normalize() Quaternion {
+	n := q.Norm()
+	return Quaternion{W: q.W / n, X: q.X / n, Y: q.Y / n, Z: q.Z / n}
+}
+
+// Rotate returns the vector v rotated by the unit quaternion q (q v q*).
+func (q Quaternion) Rotate(v Vec3) Vec3 {
+	u := Vec3{q.X, q.Y, q.Z}
+	t := u.Cross(v).Scale(2)
+	return v.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
+
+// Mat3 returns the matrix rotating vectors as the unit quaternion q does.
+func (q Quaternion) Mat3() Mat3 {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return Mat3{
+		{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y)},
+		{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x)},
+		{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y)},
+	}
+}
+
+// Slerp returns the spherical linear interpolation between the unit quaternions q and r at
+// fraction t in [0, 1], taking the shorter way around.
+func (q Quaternion) Slerp(r Quaternion, t float64) Quaternion {
+	d := q.W*r.W + q.X*r.X + q.Y*r.Y + q.Z*r.Z
+	if d < 0 {
+		r, d = Quaternion{W: -r.W, X: -r.X, Y: -r.Y, Z: -r.Z}, -d
+	}
+	a, b := 1-t, t
+	if d < 0.9995 { // Linear interpolation is accurate, and safer, for nearby quaternions
+		theta := math.Acos(d)
+		s := math.Sin(theta)
+		a, b = math.Sin((1-t)*theta)/s, math.Sin(t*theta)/s
+	}
+	return Quaternion{W: a*q.W + b*r.W, X: a*q.X + b*r.X, Y: a*q.Y + b*r.Y, Z: a*q.Z + b*r.Z}.Normalize()
+}
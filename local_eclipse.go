@@ -0,0 +1,273 @@
+// ./local_eclipse.go
+package jpleph
+
+/*
+Package jpleph provides the local circumstances of solar eclipses.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Radii of the Moon in Earth equatorial radii used by the eclipse canons: the mean radius
+// for the partial phase, and a smaller one through the lunar valleys for the central phase.
+const (
+	moonRadiusPartial = 0.2725076
+	moonRadiusCentral = 0.272281
+)
+
+// Span and sampling of the search for local circumstances around the epoch given, in days.
+// The partial phase of a solar eclipse lasts less than four hours at any site.
+const (
+	eclipseHalfSpan = 0.25
+	eclipseStep     = 1.0 / 288 // 5 minutes
+)
+
+// EclipseKind is the kind of a solar eclipse as seen from a site.
+type EclipseKind int
+
+const (
+	EclipseNone    EclipseKind = iota // The Moon does not reach the Sun's disk at the site
+	EclipsePartial                    // The Moon covers part of the Sun's disk only
+	EclipseAnnular                    // The Moon passes inside the Sun's disk, leaving a ring
+	EclipseTotal                      // The Moon covers the whole of the Sun's disk
+	EclipseHybrid                     // Total along part of a path and annular along the rest (EclipsePath only)
+)
+
+var eclipseKindNames = [...]string{EclipseNone: "none", EclipsePartial: "partial", EclipseAnnular: "annular", EclipseTotal: "total", EclipseHybrid: "hybrid"}
+
+// String returns the name of the kind (e.g., "total").
+func (k EclipseKind) String() string {
+	if k < EclipseNone || k > EclipseHybrid {
+		return fmt.Sprintf("EclipseKind(%d)", int(k))
+	}
+	return eclipseKindNames[k]
+}
+
+// EclipseContact is one instant of the local circumstances of an eclipse.
+type EclipseContact struct {
+	ET           float64 // Epoch (TDB Julian Date); zero for a contact that does not occur
+	SunElevation float64 // Elevation of the Sun's center in degrees, without refraction; negative below the horizon
+}
+
+// LocalEclipse is the course of a solar eclipse at a site.
+type LocalEclipse struct {
+	Kind        EclipseKind    // Kind of the eclipse at the site; the other fields are zero for EclipseNone
+	C1          EclipseContact // First contact: start of the partial phase
+	C2          EclipseContact // Second contact: start of the total or annular phase; zero for a partial eclipse
+	Maximum     EclipseContact // Greatest eclipse: least separation of the centers of the disks
+	C3          EclipseContact // Third contact: end of the total or annular phase; zero for a partial eclipse
+	C4          EclipseContact // Fourth contact: end of the partial phase
+	Magnitude   float64        // Fraction of the Sun's diameter covered at Maximum; 1 or more when total
+	Obscuration float64        // Fraction of the Sun's disk area covered at Maximum
+	Separation  float64        // Separation of the centers at Maximum in degrees
+	SizeRatio   float64        // Ratio of the apparent diameters of the Moon and the Sun at Maximum
+}
+
+// eclipseGeometry is the apparent Sun and Moon from a site at one epoch.
+type eclipseGeometry struct {
+	separation   float64 // Separation of the centers in radians
+	sun          float64 // Apparent radius of the Sun in radians
+	moon         float64 // Apparent radius of the Moon for the partial phase in radians
+	moonCentral  float64 // Apparent radius of the Moon for the central phase in radians
+	sunElevation float64 // Elevation of the Sun in degrees
+}
+
+// partial is positive outside the partial phase and negative during it.
+func (g eclipseGeometry) partial() float64 { return g.separation - (g.sun + g.moon) }
+
+// central is positive outside the total or annular phase and negative during it.
+func (g eclipseGeometry) central() float64 {
+	return g.separation - math.Abs(g.sun-g.moonCentral)
+}
+
+// eclipseGeometry computes the apparent Sun and Moon from a site, corrected for light time
+// and aberration.
+func (r *FrameRegistry) eclipseGeometry(et float64, site BodySite) (eclipseGeometry, error) {
+	sun, err := r.SkyPosition(et, Sun, site, CorrectionLTS)
+	if err != nil {
+		return eclipseGeometry{}, err
+	}
+	moon, err := r.SkyPosition(et, Moon, site, CorrectionLTS)
+	if err != nil {
+		return eclipseGeometry{}, err
+	}
+	au := r.eph.ephemData.au
+	return eclipseGeometry{
+		separation:   angleDegrees(sun.ICRF, moon.ICRF) * math.Pi / 180,
+		sun:          math.Asin(bodyRadii[Sun][0] / (sun.Range * au)),
+		moon:         math.Asin(moonRadiusPartial * wgs84A / (moon.Range * au)),
+		moonCentral:  math.Asin(moonRadiusCentral * wgs84A / (moon.Range * au)),
+		sunElevation: sun.Elevation,
+	}, nil
+}
+
+// diskOverlap returns the fraction of the area of a disk of radius a covered by a disk of
+// radius b whose center is at distance d.
+func diskOverlap(a, b, d float64) float64 {
+	switch {
+	case d >= a+b:
+		return 0
+	case d <= math.Abs(a-b):
+		return math.Min(1, b*b/(a*a))
+	}
+	clamp := func(x float64) float64 { return math.Max(-1, math.Min(1, x)) }
+	area := a*a*math.Acos(clamp((d*d+a*a-b*b)/(2*d*a))) + b*b*math.Acos(clamp((d*d+b*b-a*a)/(2*d*b))) -
+		0.5*math.Sqrt(math.Max(0, (-d+a+b)*(d+a-b)*(d-a+b)*(d+a+b)))
+	return area / (math.Pi * a * a)
+}
+
+// LocalCircumstances computes the course of a solar eclipse at a site: the contact times, the
+// greatest eclipse, and its magnitude and obscuration. It works directly on the topocentric
+// Sun and Moon of SkyPosition, with the Earth orientation of the registry (see
+// SetEarthOrientation and SetDeltaT), rather than on Besselian elements; the contacts use
+// the lunar radii of the eclipse canons and ignore the lunar limb profile, which can shift
+// them by a few seconds. Refraction is not applied to the elevations.
+//
+// The eclipse is given by an epoch within a few hours of its maximum at the site, such as
+// the geocentric conjunction, or the middle of a window of SeparationWindows(Sun, Moon,
+// CenterEarth, 1.6, ...), which encloses every solar eclipse. The search covers six hours
+// either side of that epoch at a step of five minutes, so that grazing eclipses lasting
+// less than the step can be reported as EclipseNone.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) near the eclipse as Julian Date.
+//   - site: Observer location on the Earth.
+//
+// Returns:
+//   - LocalEclipse: The circumstances; Kind is EclipseNone when the Moon misses the Sun.
+//   - error: ErrEventSearch for an invalid site or when the partial phase extends beyond the
+//     search, or an error from SkyPosition.
+func (r *FrameRegistry) LocalCircumstances(et float64, site Site) (LocalEclipse, error) {
+	if !site.valid() {
+		return LocalEclipse{}, fmt.Errorf("%w: site %+v", ErrEventSearch, site)
+	}
+	el := site.ellipsoid()
+	bs := BodySite{Body: Earth, Latitude: site.Latitude, Longitude: site.Longitude, Height: site.Height,
+		Radii: [2]float64{el.A, el.B()}}
+	geometry := func(t float64) (eclipseGeometry, error) { return r.eclipseGeometry(t, bs) }
+
+	// Sample the partial function and refine its least value by golden-section search
+	n := int(math.Round(2 * eclipseHalfSpan / eclipseStep))
+	times := make([]float64, n+1)
+	samples := make([]eclipseGeometry, n+1)
+	best := 0
+	for k := range times {
+		times[k] = et - eclipseHalfSpan + float64(k)*eclipseStep
+		g, err := geometry(times[k])
+		if err != nil {
+			return LocalEclipse{}, err
+		}
+		samples[k] = g
+		if g.partial() < samples[best].partial() {
+			best = k
+		}
+	}
+	lo, hi := times[max(best-1, 0)], times[min(best+1, n)]
+	const invPhi = 0.6180339887498949
+	for hi-lo > eventTolerance {
+		a, b := hi-invPhi*(hi-lo), lo+invPhi*(hi-lo)
+		ga, err := geometry(a)
+		if err != nil {
+			return LocalEclipse{}, err
+		}
+		gb, err := geometry(b)
+		if err != nil {
+			return LocalEclipse{}, err
+		}
+		if ga.partial() < gb.partial() {
+			hi = b
+		} else {
+			lo = a
+		}
+	}
+	tMax := 0.5 * (lo + hi)
+	gMax, err := geometry(tMax)
+	if err != nil {
+		return LocalEclipse{}, err
+	}
+	if gMax.partial() >= 0 {
+		return LocalEclipse{}, nil
+	}
+
+	// Each contact lies between the maximum and the nearest sample outside the phase
+	contact := func(phase func(eclipseGeometry) float64, after bool) (EclipseContact, error) {
+		f := func(t float64) (float64, error) {
+			g, err := geometry(t)
+			return phase(g), err
+		}
+		k, dir := sort.SearchFloat64s(times, tMax), 1
+		if !after {
+			k, dir = k-1, -1
+		}
+		for ; k >= 0 && k <= n && phase(samples[k]) < 0; k += dir {
+		}
+		if k < 0 || k > n {
+			return EclipseContact{}, fmt.Errorf("%w: the eclipse extends beyond %g days of %f", ErrEventSearch, eclipseHalfSpan, et)
+		}
+		var t float64
+		var err error
+		if after {
+			t, err = findCrossing(f, tMax, times[k], phase(gMax), phase(samples[k]))
+		} else {
+			t, err = findCrossing(f, times[k], tMax, phase(samples[k]), phase(gMax))
+		}
+		if err != nil {
+			return EclipseContact{}, err
+		}
+		g, err := geometry(t)
+		return EclipseContact{ET: t, SunElevation: g.sunElevation}, err
+	}
+
+	res := LocalEclipse{
+		Kind:        EclipsePartial,
+		Maximum:     EclipseContact{ET: tMax, SunElevation: gMax.sunElevation},
+		Magnitude:   (gMax.sun + gMax.moon - gMax.separation) / (2 * gMax.sun),
+		Obscuration: diskOverlap(gMax.sun, gMax.moon, gMax.separation),
+		Separation:  gMax.separation * 180 / math.Pi,
+		SizeRatio:   gMax.moon / gMax.sun,
+	}
+	partial := eclipseGeometry.partial
+	if res.C1, err = contact(partial, false); err != nil {
+		return LocalEclipse{}, err
+	}
+	if res.C4, err = contact(partial, true); err != nil {
+		return LocalEclipse{}, err
+	}
+	if gMax.central() < 0 {
+		res.Kind = EclipseAnnular
+		if gMax.moonCentral >= gMax.sun {
+			res.Kind = EclipseTotal
+		}
+		central := eclipseGeometry.central
+		if res.C2, err = contact(central, false); err != nil {
+			return LocalEclipse{}, err
+		}
+		if res.C3, err = contact(central, true); err != nil {
+			return LocalEclipse{}, err
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,137 @@
+// ./localization.go
+package jpleph
+
+/*
+Package jpleph provides translations of body and event names for published tables.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"strings"
+	"sync"
+)
+
+// Localization translates the English display names of bodies (Planet.String) and of event
+// kinds (EclipseKind.String, ShadowKind.String, GalileanPhenomenonKind.String) into another
+// language, for almanac products published in it. Names without a translation are kept in
+// English, so a partial map is usable.
+type Localization map[string]string
+
+// Name returns the translation of an English name, or the name itself without one.
+func (l Localization) Name(english string) string {
+	if name, ok := l[english]; ok {
+		return name
+	}
+	return english
+}
+
+// Body returns the translated name of a body.
+func (l Localization) Body(p Planet) string {
+	return l.Name(p.String())
+}
+
+// localizations holds the registered translations by language tag, in lower case.
+var localizations = map[string]Localization{
+	"de": {
+		"Mercury": "Merkur", "Venus": "Venus", "Earth": "Erde", "Mars": "Mars", "Jupiter": "Jupiter",
+		"Saturn": "Saturn", "Uranus": "Uranus", "Neptune": "Neptun", "Pluto": "Pluto", "Moon": "Mond", "Sun": "Sonne",
+		"Solar System Barycenter": "Baryzentrum des Sonnensystems", "Earth-Moon Barycenter": "Baryzentrum Erde-Mond",
+		"Io": "Io", "Europa": "Europa", "Ganymede": "Ganymed", "Callisto": "Kallisto", "Titan": "Titan", "Charon": "Charon",
+		"transit": "Durchgang", "shadow transit": "Schattendurchgang", "occultation": "Bedeckung", "eclipse": "Verfinsterung",
+		"none": "keine", "partial": "partiell", "annular": "ringförmig", "total": "total", "hybrid": "hybrid",
+		"sunlit": "beleuchtet", "penumbra": "Halbschatten", "umbra": "Kernschatten",
+	},
+	"es": {
+		"Mercury": "Mercurio", "Venus": "Venus", "Earth": "Tierra", "Mars": "Marte", "Jupiter": "Júpiter",
+		"Saturn": "Saturno", "Uranus": "Urano", "Neptune": "Neptuno", "Pluto": "Plutón", "Moon": "Luna", "Sun": "Sol",
+		"Solar System Barycenter": "Baricentro del sistema solar", "Earth-Moon Barycenter": "Baricentro Tierra-Luna",
+		"Io": "Ío", "Europa": "Europa", "Ganymede": "Ganímedes", "Callisto": "Calisto", "Titan": "Titán", "Charon": "Caronte",
+		"transit": "tránsito", "shadow transit": "tránsito de la sombra", "occultation": "ocultación", "eclipse": "eclipse",
+		"none": "ninguno", "partial": "parcial", "annular": "anular", "total": "total", "hybrid": "híbrido",
+		"sunlit": "iluminado", "penumbra": "penumbra", "umbra": "umbra",
+	},
+	"fr": {
+		"Mercury": "Mercure", "Venus": "Vénus", "Earth": "Terre", "Mars": "Mars", "Jupiter": "Jupiter",
+		"Saturn": "Saturne", "Uranus": "Uranus", "Neptune": "Neptune", "Pluto": "Pluton", "Moon": "Lune", "Sun": "Soleil",
+		"Solar System Barycenter": "Barycentre du système solaire", "Earth-Moon Barycenter": "Barycentre Terre-Lune",
+		"Io": "Io", "Europa": "Europe", "Ganymede": "Ganymède", "Callisto": "Callisto", "Titan": "Titan", "Charon": "Charon",
+		"transit": "passage", "shadow transit": "passage de l'ombre", "occultation": "occultation", "eclipse": "éclipse",
+		"none": "aucune", "partial": "partielle", "annular": "annulaire", "total": "totale", "hybrid": "hybride",
+		"sunlit": "éclairé", "penumbra": "pénombre", "umbra": "ombre",
+	},
+}
+
+// localizationsMu protects localizations.
+var localizationsMu sync.RWMutex
+
+// LookupLocalization returns the translations registered for a language, such as the
+// builtin "de", "es", and "fr". A regional tag (e.g., "fr-CA") falls back to its language
+// when it has no translations of its own; the result then merges both, the regional names
+// taking precedence.
+//
+// Parameters:
+//   - lang: Language tag, in any case; "" or "en" for English.
+//
+// Returns:
+//   - Localization: A copy of the translations; nil for English.
+//   - bool: Whether the language is known (always true for English).
+func LookupLocalization(lang string) (Localization, bool) {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	if lang == "" || lang == "en" || strings.HasPrefix(lang, "en-") {
+		return nil, true
+	}
+	localizationsMu.RLock()
+	defer localizationsMu.RUnlock()
+	base, region := localizations[strings.SplitN(lang, "-", 2)[0]], localizations[lang]
+	if base == nil && region == nil {
+		return nil, false
+	}
+	res := make(Localization, len(base)+len(region))
+	for english, name := range base {
+		res[english] = name
+	}
+	for english, name := range region {
+		res[english] = name
+	}
+	return res, true
+}
+
+// RegisterLocalization adds translations for a language, or extends and overrides those
+// already registered for it, so that programs can provide languages and names (e.g., of
+// custom bodies) that are not builtin. It is safe for concurrent use.
+//
+// Parameters:
+//   - lang: Language tag, in any case (e.g., "it", "pt-BR").
+//   - names: Translations keyed by English name; the map is copied.
+func RegisterLocalization(lang string, names Localization) {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	localizationsMu.Lock()
+	defer localizationsMu.Unlock()
+	l := localizations[lang]
+	if l == nil {
+		l = make(Localization, len(names))
+		localizations[lang] = l
+	}
+	for english, name := range names {
+		l[english] = name
+	}
+}
@@ -0,0 +1,204 @@
+// ./lunar_events.go
+package jpleph
+
+/*
+Package jpleph provides the nodes, declination extremes, and standstills of the Moon.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// lunarEventStep is the sampling step of the lunar event searches in days, well below the
+// 13.6 days between successive nodes or declination extremes.
+const lunarEventStep = 1.0
+
+// standstillWindow is the half-width in days of the span over which a declination extreme
+// must be the largest or smallest to be a standstill: 4 years, less than half the 18.6-year
+// nodal cycle, so that it holds exactly one major and one minor standstill.
+const standstillWindow = 4 * 365.25
+
+// LunarNode is a crossing of the ecliptic by the Moon.
+type LunarNode struct {
+	ET        float64 // Epoch of the crossing (TDB Julian Date)
+	Ascending bool    // True at the ascending node (the Moon moves north), false at the descending node
+	Longitude float64 // Geocentric ecliptic longitude of the node in degrees (mean ecliptic and equinox of date)
+}
+
+// LunarDeclinationExtreme is a monthly extreme of the geocentric declination of the Moon.
+type LunarDeclinationExtreme struct {
+	ET          float64 // Epoch of the extreme (TDB Julian Date)
+	North       bool    // True at the northern extreme (maximum), false at the southern one (minimum)
+	Declination float64 // Declination in degrees (mean equator and equinox of date)
+}
+
+// LunarStandstill is a lunar standstill: the monthly extreme of declination that is the
+// largest (major standstill) or the smallest (minor standstill) in its 18.6-year nodal cycle.
+type LunarStandstill struct {
+	ET          float64 // Epoch of the extreme (TDB Julian Date)
+	Major       bool    // True for a major standstill, false for a minor one
+	North       bool    // True for the northern extreme, false for the southern one
+	Declination float64 // Declination in degrees (mean equator and equinox of date)
+}
+
+// validLunarSearch checks the interval of a lunar event search.
+func validLunarSearch(start, end float64) error {
+	if !(end > start) {
+		return fmt.Errorf("%w: interval [%f, %f]", ErrEventSearch, start, end)
+	}
+	return nil
+}
+
+// LunarNodes finds the crossings of the ecliptic by the Moon between start and end: the times
+// when its geometric geocentric ecliptic latitude, referred to the mean ecliptic of date, is
+// zero. The nodes regress along the ecliptic in 18.6 years; eclipses happen near them.
+//
+// Parameters:
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//
+// Returns:
+//   - []LunarNode: The crossings in chronological order.
+//   - error: ErrEventSearch for an invalid interval, or an error from CalculatePV.
+func (e *Ephemeris) LunarNodes(start, end float64) ([]LunarNode, error) {
+	if err := validLunarSearch(start, end); err != nil {
+		return nil, err
+	}
+	crossings, err := findCrossings(func(et float64) (float64, error) {
+		c, err := e.Ecliptic(et, Moon, CenterEarth, EclipticMeanOfDate)
+		return c.Latitude, err
+	}, start, end, lunarEventStep)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []LunarNode
+	for _, c := range crossings {
+		ecl, err := e.Ecliptic(c.et, Moon, CenterEarth, EclipticMeanOfDate)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, LunarNode{ET: c.et, Ascending: c.rising, Longitude: ecl.Longitude})
+	}
+	return nodes, nil
+}
+
+// lunarDeclination returns the geocentric declination of the Moon in degrees and a quantity
+// with the sign of its rate, referred to the mean equator of date.
+func (e *Ephemeris) lunarDeclination(et float64) (float64, float64, error) {
+	pos, vel, err := e.CalculatePV(et, Moon, CenterEarth, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	m := equatorialPrecession(et) // Its rate is negligible against the motion of the Moon
+	x := m.Apply([3]float64{pos.X, pos.Y, pos.Z})
+	v := m.Apply([3]float64{vel.DX, vel.DY, vel.DZ})
+	r2 := x[0]*x[0] + x[1]*x[1] + x[2]*x[2]
+	rate := v[2]*r2 - x[2]*(x[0]*v[0]+x[1]*v[1]+x[2]*v[2]) // r^3 d(z/r)/dt
+	return math.Asin(x[2]/math.Sqrt(r2)) * 180 / math.Pi, rate, nil
+}
+
+// LunarDeclinationExtremes finds the monthly northern and southern extremes of the geometric
+// geocentric declination of the Moon between start and end, referred to the mean equator of
+// date (nutation, below 10 arcseconds, is ignored). Their size varies between about 18.3 and
+// 28.7 degrees over the 18.6-year nodal cycle.
+//
+// Parameters:
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//
+// Returns:
+//   - []LunarDeclinationExtreme: The extremes in chronological order, alternately north and south.
+//   - error: ErrEventSearch for an invalid interval, or an error from CalculatePV.
+func (e *Ephemeris) LunarDeclinationExtremes(start, end float64) ([]LunarDeclinationExtreme, error) {
+	if err := validLunarSearch(start, end); err != nil {
+		return nil, err
+	}
+	crossings, err := findCrossings(func(et float64) (float64, error) {
+		_, rate, err := e.lunarDeclination(et)
+		return rate, err
+	}, start, end, lunarEventStep)
+	if err != nil {
+		return nil, err
+	}
+	var extremes []LunarDeclinationExtreme
+	for _, c := range crossings {
+		dec, _, err := e.lunarDeclination(c.et)
+		if err != nil {
+			return nil, err
+		}
+		extremes = append(extremes, LunarDeclinationExtreme{ET: c.et, North: !c.rising, Declination: dec})
+	}
+	return extremes, nil
+}
+
+// LunarStandstills finds the major and minor lunar standstills between start and end: the
+// northern and southern declination extremes (see LunarDeclinationExtremes) that are the
+// largest or the smallest within 4 years on either side, so about every 18.6 years for each
+// kind, the major and minor standstills 9.3 years apart. Extremes less than 4 years from
+// start or end cannot be compared over the whole span and are not reported: search over at
+// least 9 years to find a standstill of each kind.
+//
+// Parameters:
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//
+// Returns:
+//   - []LunarStandstill: The standstills in chronological order.
+//   - error: ErrEventSearch for an invalid interval, or an error from CalculatePV.
+func (e *Ephemeris) LunarStandstills(start, end float64) ([]LunarStandstill, error) {
+	extremes, err := e.LunarDeclinationExtremes(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return standstills(extremes, start, end), nil
+}
+
+// standstills selects the standstills among the declination extremes found between start
+// and end.
+func standstills(extremes []LunarDeclinationExtreme, start, end float64) []LunarStandstill {
+	var res []LunarStandstill
+	for i, x := range extremes {
+		if x.ET-standstillWindow < start || x.ET+standstillWindow > end {
+			continue
+		}
+		largest, smallest := true, true
+		for j := i - 1; j >= 0 && extremes[j].ET >= x.ET-standstillWindow && (largest || smallest); j-- {
+			largest, smallest = compareExtremes(x, extremes[j], largest, smallest)
+		}
+		for j := i + 1; j < len(extremes) && extremes[j].ET <= x.ET+standstillWindow && (largest || smallest); j++ {
+			largest, smallest = compareExtremes(x, extremes[j], largest, smallest)
+		}
+		if largest || smallest {
+			res = append(res, LunarStandstill{ET: x.ET, Major: largest, North: x.North, Declination: x.Declination})
+		}
+	}
+	return res
+}
+
+// compareExtremes updates whether x is the largest and the smallest extreme of its hemisphere
+// after comparing it with y; extremes of the other hemisphere are skipped.
+func compareExtremes(x, y LunarDeclinationExtreme, largest, smallest bool) (bool, bool) {
+	if y.North != x.North {
+		return largest, smallest
+	}
+	size, other := math.Abs(x.Declination), math.Abs(y.Declination)
+	return largest && size >= other, smallest && size <= other
+}
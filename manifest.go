@@ -0,0 +1,255 @@
+// ./manifest.go
+package jpleph
+
+/*
+Package jpleph provides the inventory of a directory of ephemeris files and the selection of files by coverage.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// KernelKind is the format of an ephemeris file found by ScanDirectory.
+type KernelKind int
+
+const (
+	KernelDE           KernelKind = iota // JPL DE binary file
+	KernelSPK                            // SPK kernel (DAF/SPK)
+	KernelCustomBodies                   // Custom body file (JPLCUST1)
+)
+
+// kernelKindNames are the names of the KernelKind values.
+var kernelKindNames = [...]string{"DE", "SPK", "custom bodies"}
+
+// String returns the name of the format.
+func (k KernelKind) String() string {
+	if k >= 0 && int(k) < len(kernelKindNames) {
+		return kernelKindNames[k]
+	}
+	return fmt.Sprintf("KernelKind(%d)", int(k))
+}
+
+// ManifestFile is an ephemeris file of a Manifest.
+type ManifestFile struct {
+	Path     string     // Path of the file
+	Kind     KernelKind // Format of the file
+	DENumber int        // DE version of a DE file (e.g., 440); 0 for the other formats
+	Start    float64    // First covered epoch (TDB Julian Date)
+	End      float64    // Last covered epoch (TDB Julian Date); for SPK and custom body files, the union of the spans of the bodies, gaps included
+	Bodies   []int      // NAIF codes of the bodies of an SPK kernel, or the IDs of the custom bodies; nil for a DE file
+}
+
+// Covers reports whether the file covers an epoch.
+func (f ManifestFile) Covers(et float64) bool {
+	return et >= f.Start && et <= f.End
+}
+
+// SkippedFile is a file of the directory that ScanDirectory could not read as an ephemeris.
+type SkippedFile struct {
+	Path string // Path of the file
+	Err  error  // Why it was skipped
+}
+
+// Manifest is the inventory of the ephemeris files of a directory, as returned by
+// ScanDirectory.
+type Manifest struct {
+	Dir     string         // Directory scanned
+	Files   []ManifestFile // Recognized files, in the order of their names
+	Skipped []SkippedFile  // Other regular files, in the order of their names
+}
+
+// ScanDirectory inventories the ephemeris files of a directory at startup: it opens each
+// regular file (subdirectories are not scanned) and records the DE binary files with their
+// DE numbers and time spans, the SPK kernels and custom body files with their bodies and
+// spans. Files that are not readable as ephemerides are listed in Skipped with the reason.
+// Every file is closed again; the manifest selects files for Open, Covering, and Furnish.
+//
+// Parameters:
+//   - dir: Directory to scan.
+//
+// Returns:
+//   - *Manifest: The inventory.
+//   - error: An error reading the directory itself.
+func ScanDirectory(dir string) (*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+	m := &Manifest{Dir: dir}
+	for _, entry := range entries { // Sorted by name
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		file, err := scanFile(path)
+		if err != nil {
+			m.Skipped = append(m.Skipped, SkippedFile{Path: path, Err: err})
+			continue
+		}
+		m.Files = append(m.Files, file)
+	}
+	return m, nil
+}
+
+// scanFile identifies an ephemeris file from its content and reads its coverage.
+func scanFile(path string) (ManifestFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	magic := make([]byte, 8)
+	_, err = io.ReadFull(f, magic)
+	if err != nil {
+		f.Close()
+		return ManifestFile{}, fmt.Errorf("failed to read kernel header: %w", err)
+	}
+
+	file := ManifestFile{Path: path, Start: math.Inf(1), End: math.Inf(-1)}
+	widen := func(start, end float64) {
+		file.Start, file.End = math.Min(file.Start, start), math.Max(file.End, end)
+	}
+	switch string(magic) {
+	case "DAF/SPK ":
+		f.Close()
+		k, err := OpenSPK(path)
+		if err != nil {
+			return ManifestFile{}, err
+		}
+		defer k.Close()
+		file.Kind, file.Bodies = KernelSPK, k.Bodies()
+		for _, naif := range file.Bodies {
+			start, end, _ := k.Span(naif)
+			widen(start, end)
+		}
+	case customBodyMagic:
+		defer f.Close()
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return ManifestFile{}, fmt.Errorf("%w: %v", ErrFileSeek, err)
+		}
+		bodies, err := ReadCustomBodies(f)
+		if err != nil {
+			return ManifestFile{}, err
+		}
+		file.Kind = KernelCustomBodies
+		for _, b := range bodies {
+			file.Bodies = append(file.Bodies, int(b.ID))
+			widen(b.Span())
+		}
+	default:
+		f.Close()
+		e, err := NewEphemeris(path, false)
+		if err != nil {
+			return ManifestFile{}, err
+		}
+		defer e.Close()
+		file.Kind, file.DENumber = KernelDE, int(e.GetEphemerisLong(EphemerisVersion))
+		widen(e.GetEphemerisDouble(EphemerisStartJD), e.GetEphemerisDouble(EphemerisEndJD))
+	}
+	if file.Start > file.End { // No bodies
+		file.Start, file.End = 0, 0
+	}
+	return file, nil
+}
+
+// Covering returns the files covering an epoch in the order of preference: the DE files
+// first, the latest DE number first and the longer span first among equal numbers, then
+// the SPK and custom body files in the order of their names.
+func (m *Manifest) Covering(et float64) []ManifestFile {
+	var files []ManifestFile
+	for _, f := range m.Files {
+		if f.Covers(et) {
+			files = append(files, f)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if (a.Kind == KernelDE) != (b.Kind == KernelDE) {
+			return a.Kind == KernelDE
+		}
+		if a.Kind != KernelDE {
+			return false
+		}
+		if a.DENumber != b.DENumber {
+			return a.DENumber > b.DENumber
+		}
+		return a.End-a.Start > b.End-b.Start
+	})
+	return files
+}
+
+// Open opens the preferred DE file covering an epoch (the first DE file of Covering).
+//
+// Parameters:
+//   - et: Epoch to cover (TDB Julian Date).
+//   - loadConstants: Whether to load and cache constant names and values (see NewEphemeris).
+//   - opts: Optional settings, such as WithClampToRange.
+//
+// Returns:
+//   - *Ephemeris: The opened file.
+//   - error: ErrOutsideRange if no DE file of the manifest covers et, or an error from NewEphemeris.
+func (m *Manifest) Open(et float64, loadConstants bool, opts ...Option) (*Ephemeris, error) {
+	for _, f := range m.Covering(et) {
+		if f.Kind == KernelDE {
+			return NewEphemeris(f.Path, loadConstants, opts...)
+		}
+	}
+	return nil, fmt.Errorf("no DE file of %s at JD %f: %w", m.Dir, et, ErrOutsideRange)
+}
+
+// Furnish registers every file of the manifest in a kernel pool, in the reverse order of
+// preference of Covering, so that for each target and epoch the pool uses the preferred
+// file covering it: the latest DE for the planets, and the SPK and custom body files for
+// their bodies. The pool owns the opened files and closes them in its Close.
+//
+// Parameters:
+//   - pool: Pool to register the files in.
+//
+// Returns:
+//   - error: An error from KernelPool.Furnish; the files registered before it stay in the pool.
+func (m *Manifest) Furnish(pool *KernelPool) error {
+	files := append([]ManifestFile(nil), m.Files...)
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if (a.Kind == KernelDE) != (b.Kind == KernelDE) {
+			return a.Kind == KernelDE // DE files first, overridden by the SPK kernels for their bodies
+		}
+		if a.Kind != KernelDE {
+			return false
+		}
+		if a.DENumber != b.DENumber {
+			return a.DENumber < b.DENumber
+		}
+		return a.End-a.Start < b.End-b.Start
+	})
+	for _, f := range files {
+		if err := pool.Furnish(f.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+// ./marshal.go
+package jpleph
+
+/*
+Package jpleph provides JSON and text marshaling of positions, velocities, and state vectors.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unit tags used in serialized positions and velocities.
+const (
+	unitAU       = "au"   // Astronomical units
+	unitKM       = "km"   // Kilometers, converted with the IAU 2012 astronomical unit
+	unitAUPerDay = "au/d" // Astronomical units per day
+	unitKMPerSec = "km/s" // Kilometers per second
+)
+
+// StateVector is the position and velocity of a body at an epoch.
+type StateVector struct {
+	ET       float64  // Epoch (TDB Julian Date)
+	Position Position // Position in AU
+	Velocity Velocity // Velocity in AU/day
+}
+
+// State returns the state vector of a body relative to a center, as computed by CalculatePV
+// with velocities.
+func (e *Ephemeris) State(et float64, target Planet, center CenterBody) (StateVector, error) {
+	pos, vel, err := e.CalculatePV(et, target, center, true)
+	if err != nil {
+		return StateVector{}, err
+	}
+	return StateVector{ET: et, Position: pos, Velocity: vel}, nil
+}
+
+// positionJSON is the JSON form of a Position.
+type positionJSON struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Z    float64 `json:"z"`
+	Unit string  `json:"unit"`
+}
+
+// velocityJSON is the JSON form of a Velocity.
+type velocityJSON struct {
+	DX   float64 `json:"vx"`
+	DY   float64 `json:"vy"`
+	DZ   float64 `json:"vz"`
+	Unit string  `json:"unit"`
+}
+
+// stateVectorJSON is the JSON form of a StateVector.
+type stateVectorJSON struct {
+	ET       float64  `json:"jd_tdb"`
+	Position Position `json:"position"`
+	Velocity Velocity `json:"velocity"`
+}
+
+// MarshalJSON encodes the position as {"x":..,"y":..,"z":..,"unit":"au"}.
+func (p Position) MarshalJSON() ([]byte, error) {
+	return json.Marshal(positionJSON{p.X, p.Y, p.Z, unitAU})
+}
+
+// UnmarshalJSON decodes a position written by MarshalJSON. The unit may also be "km", which
+// is converted to AU with the IAU 2012 astronomical unit.
+func (p *Position) UnmarshalJSON(data []byte) error {
+	var v positionJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	scale, err := distanceScale(v.Unit)
+	if err != nil {
+		return err
+	}
+	*p = Position{v.X * scale, v.Y * scale, v.Z * scale}
+	return nil
+}
+
+// MarshalJSON encodes the velocity as {"vx":..,"vy":..,"vz":..,"unit":"au/d"}.
+func (v Velocity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(velocityJSON{v.DX, v.DY, v.DZ, unitAUPerDay})
+}
+
+// UnmarshalJSON decodes a velocity written by MarshalJSON. The unit may also be "km/s", which
+// is converted to AU/day with the IAU 2012 astronomical unit.
+func (v *Velocity) UnmarshalJSON(data []byte) error {
+	var j velocityJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	scale, err := velocityScale(j.Unit)
+	if err != nil {
+		return err
+	}
+	*v = Velocity{j.DX * scale, j.DY * scale, j.DZ * scale}
+	return nil
+}
+
+// MarshalJSON encodes the state as {"jd_tdb":..,"position":{..},"velocity":{..}}.
+func (s StateVector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stateVectorJSON(s))
+}
+
+// UnmarshalJSON decodes a state written by MarshalJSON.
+func (s *StateVector) UnmarshalJSON(data []byte) error {
+	var j stateVectorJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = StateVector(j)
+	return nil
+}
+
+// MarshalText encodes the position as "x y z au".
+func (p Position) MarshalText() ([]byte, error) {
+	return appendVector(nil, p.X, p.Y, p.Z, unitAU), nil
+}
+
+// UnmarshalText decodes a position written by MarshalText; the unit may also be "km".
+func (p *Position) UnmarshalText(text []byte) error {
+	x, err := parseVector(strings.Fields(string(text)), distanceScale)
+	if err != nil {
+		return fmt.Errorf("position %q: %w", text, err)
+	}
+	*p = Position{x[0], x[1], x[2]}
+	return nil
+}
+
+// MarshalText encodes the velocity as "vx vy vz au/d".
+func (v Velocity) MarshalText() ([]byte, error) {
+	return appendVector(nil, v.DX, v.DY, v.DZ, unitAUPerDay), nil
+}
+
+// UnmarshalText decodes a velocity written by MarshalText; the unit may also be "km/s".
+func (v *Velocity) UnmarshalText(text []byte) error {
+	x, err := parseVector(strings.Fields(string(text)), velocityScale)
+	if err != nil {
+		return fmt.Errorf("velocity %q: %w", text, err)
+	}
+	*v = Velocity{x[0], x[1], x[2]}
+	return nil
+}
+
+// MarshalText encodes the state as "jd x y z au vx vy vz au/d".
+func (s StateVector) MarshalText() ([]byte, error) {
+	b := strconv.AppendFloat(nil, s.ET, 'f', -1, 64)
+	b = append(b, ' ')
+	b = appendVector(b, s.Position.X, s.Position.Y, s.Position.Z, unitAU)
+	b = append(b, ' ')
+	return appendVector(b, s.Velocity.DX, s.Velocity.DY, s.Velocity.DZ, unitAUPerDay), nil
+}
+
+// UnmarshalText decodes a state written by MarshalText.
+func (s *StateVector) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) != 9 {
+		return fmt.Errorf("state vector %q: expected 9 fields, got %d", text, len(fields))
+	}
+	et, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return fmt.Errorf("state vector %q: %w", text, err)
+	}
+	pos, err := parseVector(fields[1:5], distanceScale)
+	if err != nil {
+		return fmt.Errorf("state vector %q: %w", text, err)
+	}
+	vel, err := parseVector(fields[5:9], velocityScale)
+	if err != nil {
+		return fmt.Errorf("state vector %q: %w", text, err)
+	}
+	*s = StateVector{ET: et, Position: Position{pos[0], pos[1], pos[2]}, Velocity: Velocity{vel[0], vel[1], vel[2]}}
+	return nil
+}
+
+// appendVector appends three components and a unit tag, separated by spaces.
+func appendVector(b []byte, x, y, z float64, unit string) []byte {
+	for _, c := range [3]float64{x, y, z} {
+		b = strconv.AppendFloat(b, c, 'g', -1, 64)
+		b = append(b, ' ')
+	}
+	return append(b, unit...)
+}
+
+// parseVector parses three components followed by a unit tag, scaled by the factor that
+// scale returns for the unit.
+func parseVector(fields []string, scale func(string) (float64, error)) ([3]float64, error) {
+	var x [3]float64
+	if len(fields) != 4 {
+		return x, fmt.Errorf("expected 3 components and a unit, got %d fields", len(fields))
+	}
+	f, err := scale(fields[3])
+	if err != nil {
+		return x, err
+	}
+	for i := range x {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return x, err
+		}
+		x[i] = v * f
+	}
+	return x, nil
+}
+
+// distanceScale returns the factor converting a distance unit to AU.
+func distanceScale(unit string) (float64, error) {
+	switch unit {
+	case unitAU:
+		return 1, nil
+	case unitKM:
+		return 1 / astronomicalUnitKM, nil
+	}
+	return 0, fmt.Errorf("%w %q for a distance", ErrUnit, unit)
+}
+
+// velocityScale returns the factor converting a velocity unit to AU/day.
+func velocityScale(unit string) (float64, error) {
+	switch unit {
+	case unitAUPerDay:
+		return 1, nil
+	case unitKMPerSec:
+		return secondsPerDay / astronomicalUnitKM, nil
+	}
+	return 0, fmt.Errorf("%w %q for a velocity", ErrUnit, unit)
+}
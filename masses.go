@@ -0,0 +1,126 @@
+// ./masses.go
+package jpleph
+
+/*
+Package jpleph provides the masses of the bodies of the ephemeris, including the perturbing asteroids.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BodyMass is the gravitational parameter of a body of the ephemeris, as given by the
+// constants of the file.
+type BodyMass struct {
+	Name     string  // Body name (e.g., "Jupiter" or "(4) Vesta")
+	Constant string  // Header constant giving the GM (e.g., "GM5", "MA0004")
+	Asteroid int     // Number of the asteroid, 0 for the Sun, the planets, and the Moon
+	GM       float64 // Gravitational parameter in AU^3/day^2
+	GMKm     float64 // Gravitational parameter in km^3/s^2
+
+	Provenance []ConstantProvenance // Constants GM and GMKm derive from: the GM constant, EMRAT for the Earth and the Moon, and the AU
+}
+
+// asteroidNames names the largest of the asteroids that the DE integrations include as
+// perturbers; others are given by their number only.
+var asteroidNames = map[int]string{
+	1: "Ceres", 2: "Pallas", 3: "Juno", 4: "Vesta", 5: "Astraea", 6: "Hebe", 7: "Iris", 8: "Flora",
+	9: "Metis", 10: "Hygiea", 11: "Parthenope", 12: "Victoria", 13: "Egeria", 14: "Irene",
+	15: "Eunomia", 16: "Psyche", 19: "Fortuna", 20: "Massalia", 24: "Themis", 29: "Amphitrite",
+	31: "Euphrosyne", 52: "Europa", 65: "Cybele", 87: "Sylvia", 88: "Thisbe", 107: "Camilla",
+	511: "Davida", 704: "Interamnia",
+}
+
+// Masses returns the gravitational parameters of the bodies of the file: the Sun, the planets
+// (systems), the Earth-Moon barycenter, the Earth, and the Moon, followed by every asteroid
+// with an MAxxxx constant, in file order (343 of them in DE440 and DE441). Bodies whose
+// constant the file lacks are left out. Constants loaded with LoadTextConstants take
+// precedence over those of the file; the Provenance of each mass tells which were used.
+//
+// Returns:
+//   - []BodyMass: The masses.
+//   - error: ErrConstantNotFound if the file lacks GMS or the AU.
+func (e *Ephemeris) Masses() ([]BodyMass, error) {
+	auSource, err := e.ConstantProvenance("AU")
+	if err != nil {
+		auSource = e.headerProvenance("AU")
+	}
+	au := auSource.Value
+	if au == 0 {
+		return nil, fmt.Errorf("AU: %w", ErrConstantNotFound)
+	}
+	if _, ok := e.constantByName("GMS"); !ok {
+		return nil, fmt.Errorf("GMS: %w", ErrConstantNotFound)
+	}
+	toKm := au * au * au / (secondsPerDay * secondsPerDay)
+
+	var res []BodyMass
+	for _, b := range []struct {
+		body     Planet
+		constant string
+	}{
+		{Sun, "GMS"}, {Mercury, "GM1"}, {Venus, "GM2"}, {EarthMoonBarycenter, "GMB"}, {Mars, "GM4"},
+		{Jupiter, "GM5"}, {Saturn, "GM6"}, {Uranus, "GM7"}, {Neptune, "GM8"}, {Pluto, "GM9"},
+		{Earth, "GMB"}, {Moon, "GMB"},
+	} {
+		gm, err := e.bodyGM(b.body)
+		if err != nil {
+			continue
+		}
+		gmSource, _ := e.ConstantProvenance(b.constant)
+		sources := []ConstantProvenance{gmSource, auSource}
+		if b.body == Earth || b.body == Moon {
+			sources = []ConstantProvenance{gmSource, e.headerProvenance("EMRAT"), auSource}
+		}
+		res = append(res, BodyMass{Name: b.body.String(), Constant: b.constant, GM: gm, GMKm: gm * toKm, Provenance: sources})
+	}
+
+	nameBuf := make([]byte, 7)
+	for i := 0; i < int(e.ephemData.ncon); i++ {
+		getConstant(i, e.ephemData, nameBuf)
+		name := strings.TrimRight(string(nameBuf[:6]), "\x00 ")
+		if !strings.HasPrefix(name, "MA") {
+			continue
+		}
+		n, err := strconv.Atoi(name[2:])
+		if err != nil || n <= 0 {
+			continue
+		}
+		gmSource, _ := e.ConstantProvenance(name)
+		gm := gmSource.Value
+		res = append(res, BodyMass{Name: asteroidName(n), Constant: name, Asteroid: n, GM: gm, GMKm: gm * toKm,
+			Provenance: []ConstantProvenance{gmSource, auSource}})
+	}
+	return res, nil
+}
+
+// asteroidName formats the name of a numbered asteroid as "(4) Vesta", or "(n)" when the
+// asteroid is not in asteroidNames.
+func asteroidName(n int) string {
+	if name, ok := asteroidNames[n]; ok {
+		return fmt.Sprintf("(%d) %s", n, name)
+	}
+	return fmt.Sprintf("(%d)", n)
+}
@@ -0,0 +1,131 @@
+// ./memory_test.go
+package jpleph_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// heapGrowthLimit is the largest growth of the live heap accepted over the repeated runs of
+// TestBoundedMemory, once the first run has allocated what is retained for good.
+const heapGrowthLimit = 256 << 10
+
+// liveHeap returns the bytes of the heap in use after a collection.
+func liveHeap() uint64 {
+	var m runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// checkBounded runs op once to warm up, then n times, and fails if the live heap or the
+// number of goroutines grew in between.
+func checkBounded(t *testing.T, n int, op func(i int)) {
+	t.Helper()
+	op(0)
+	heap, goroutines := liveHeap(), runtime.NumGoroutine()
+	for i := 1; i <= n; i++ {
+		op(i)
+	}
+	if grown := int64(liveHeap()) - int64(heap); grown > heapGrowthLimit {
+		t.Errorf("live heap grew by %d bytes over %d runs, limit %d", grown, n, heapGrowthLimit)
+	}
+	if g := runtime.NumGoroutine(); g > goroutines+1 { // The background read in flight, at most
+		t.Errorf("%d goroutines after %d runs, %d before", g, n, goroutines)
+	}
+}
+
+// TestBoundedMemory checks that queries do not allocate and that the caches of an Ephemeris
+// stay bounded under long runs: record churn through the file, the Chebyshev value slots,
+// preloaded records, and the background reads of CalculatePVBefore.
+func TestBoundedMemory(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	e := openSynthetic(t, cfg)
+	step := cfg.RecordDays
+	churn := func(i int) float64 { // An epoch in a different record from that of i-1
+		r := (i * (cfg.Records/2 + 1)) % cfg.Records
+		return cfg.Start + (float64(r)+0.25+0.5*float64(i%7)/7)*step
+	}
+	bodies := []struct {
+		target jpleph.Planet
+		center jpleph.CenterBody
+	}{
+		{jpleph.Moon, jpleph.CenterEarth}, {jpleph.Mars, jpleph.CenterSun}, {jpleph.Earth, jpleph.CenterSolarSystemBarycenter},
+		{jpleph.Sun, jpleph.CenterEarthMoonBarycenter}, {jpleph.Jupiter, jpleph.CenterMoon}, {jpleph.Nutations, 0},
+		{jpleph.Librations, 0}, {jpleph.TT_TDB, 0},
+	}
+	fail := func(t *testing.T, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("record churn", func(t *testing.T) {
+		i := 0
+		allocs := testing.AllocsPerRun(2000, func() {
+			_, _, err := e.CalculatePV(churn(i), jpleph.Mars, jpleph.CenterSun, true)
+			fail(t, err)
+			i++
+		})
+		if allocs != 0 {
+			t.Errorf("CalculatePV with a record read per call: %v allocs/op, want 0", allocs)
+		}
+	})
+
+	t.Run("Chebyshev slots", func(t *testing.T) {
+		i := 0
+		allocs := testing.AllocsPerRun(2000, func() {
+			et := cfg.Start + 3*step + 0.5 + float64(i%997)*step/1000 // Distinct normalized times within one record
+			for _, b := range bodies {
+				_, _, err := e.CalculatePV(et, b.target, b.center, true)
+				fail(t, err)
+			}
+			var pv [13][6]float64
+			list := [14]int{2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
+			if rc := jpleph.JPLState(e, et, list, &pv, nil, 0); rc != 0 {
+				t.Fatalf("JPLState: %d", rc)
+			}
+			i++
+		})
+		if allocs != 0 {
+			t.Errorf("states at new epochs of one record: %v allocs/op, want 0", allocs)
+		}
+	})
+
+	t.Run("preloaded records", func(t *testing.T) {
+		p := openSynthetic(t, cfg)
+		_, err := p.PreloadRange(cfg.Start, cfg.End())
+		fail(t, err)
+		i := 0
+		allocs := testing.AllocsPerRun(2000, func() {
+			_, _, err := p.CalculatePV(churn(i), jpleph.Moon, jpleph.CenterEarth, true)
+			fail(t, err)
+			_, err = p.PreloadRange(cfg.Start, cfg.End()) // Already resident
+			fail(t, err)
+			i++
+		})
+		if allocs != 0 {
+			t.Errorf("queries and preloads of resident records: %v allocs/op, want 0", allocs)
+		}
+		checkBounded(t, 200, func(int) {
+			p.ReleasePreloaded()
+			_, err := p.PreloadRange(cfg.Start, cfg.End())
+			fail(t, err)
+		})
+	})
+
+	t.Run("deadline reads", func(t *testing.T) {
+		d := openSynthetic(t, cfg)
+		checkBounded(t, 2000, func(i int) {
+			b := bodies[i%len(bodies)]
+			opts := jpleph.DeadlineOptions{Deadline: time.Now().Add(time.Second)}
+			_, err := d.CalculatePVBefore(churn(i), b.target, b.center, true, opts)
+			fail(t, err)
+		})
+	})
+}
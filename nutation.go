@@ -0,0 +1,110 @@
+// ./nutation.go
+package jpleph
+
+/*
+Package jpleph provides the nutation matrix and the obliquity of the ecliptic.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// ObliquityKind selects the mean or the true obliquity of the ecliptic.
+type ObliquityKind int
+
+const (
+	// ObliquityMean is the IAU 2006 mean obliquity of the ecliptic of date.
+	ObliquityMean ObliquityKind = iota
+	// ObliquityTrue is the mean obliquity plus the nutation in obliquity of the file.
+	ObliquityTrue
+)
+
+// nutationAngles returns the nutation in longitude and obliquity (radians) at et, from the
+// file or, when it has none, from the theory of WithNutationFallback.
+func (e *Ephemeris) nutationAngles(et float64) (dpsi, deps float64, err error) {
+	nut, _, err := e.CalculatePV(et, Nutations, 0, false)
+	if errors.Is(err, ErrQuantityNotInEphemeris) && e.nutationFallback != nil {
+		dpsi, deps = e.nutationFallback(et)
+		return dpsi, deps, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return nut.X, nut.Y, nil
+}
+
+// Obliquity returns the obliquity of the ecliptic of date. The mean obliquity is the IAU
+// 2006 polynomial used by the of-date frames; the true obliquity adds the nutation in
+// obliquity of the file (or of WithNutationFallback), so both are consistent with
+// NutationMatrix.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB, used in place of TT) as Julian Date.
+//   - kind: ObliquityMean or ObliquityTrue.
+//
+// Returns:
+//   - float64: Obliquity in radians.
+//   - error: ErrInvalidIndex for an unknown kind, or, for the true obliquity, an error
+//     from CalculatePV (ErrQuantityNotInEphemeris if the file has no nutations).
+func (e *Ephemeris) Obliquity(et float64, kind ObliquityKind) (float64, error) {
+	switch kind {
+	case ObliquityMean:
+		return meanObliquity(et), nil
+	case ObliquityTrue:
+		_, deps, err := e.nutationAngles(et)
+		if err != nil {
+			return 0, err
+		}
+		return meanObliquity(et) + deps, nil
+	}
+	return 0, fmt.Errorf("obliquity kind %d: %w", kind, ErrInvalidIndex)
+}
+
+// NutationMatrix returns the rotation from the mean to the true equator and equinox of date,
+// N = R1(-(eps + deps)) R3(-dpsi) R1(eps), from the nutation angles of the file and the mean
+// obliquity of Obliquity. It is the rotation between FrameMeanOfDate and FrameTrueOfDate.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - [3][3]float64: The rotation matrix, applied to mean-of-date coordinates.
+//   - error: An error from CalculatePV (ErrQuantityNotInEphemeris if the file has no
+//     nutations).
+func (e *Ephemeris) NutationMatrix(et float64) ([3][3]float64, error) {
+	m, err := e.nutationMatrix(et)
+	return [3][3]float64(m), err
+}
+
+// nutationMatrix is NutationMatrix as a linalg.Mat3.
+func (e *Ephemeris) nutationMatrix(et float64) (linalg.Mat3, error) {
+	dpsi, deps, err := e.nutationAngles(et)
+	if err != nil {
+		return linalg.Mat3{}, err
+	}
+	eps := meanObliquity(et)
+	return linalg.RotX(-(eps + deps)).Mul(linalg.RotZ(-dpsi)).Mul(linalg.RotX(eps)), nil
+}
@@ -0,0 +1,155 @@
+// ./observer.go
+package jpleph
+
+/*
+Package jpleph provides Earth-fixed observer sites and the Earth orientation they need.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// WGS84 reference ellipsoid.
+const (
+	wgs84A = 6378.137          // Equatorial radius in km
+	wgs84F = 1 / 298.257223563 // Flattening
+)
+
+// obliquityIAU2006 is the mean obliquity at J2000.0 of the IAU 2006 precession model, in radians.
+const obliquityIAU2006 = 84381.406 * arcsecToRad
+
+// Site is an observer location on the Earth's surface.
+type Site struct {
+	Latitude  float64   // Geodetic latitude in degrees, north positive
+	Longitude float64   // Longitude in degrees, east positive
+	Height    float64   // Height above the ellipsoid in meters
+	Ellipsoid Ellipsoid // Reference ellipsoid of the coordinates; zero for WGS84
+}
+
+// valid reports whether the site coordinates are in range.
+func (s Site) valid() bool {
+	return s.Latitude >= -90 && s.Latitude <= 90 && !math.IsNaN(s.Longitude) && !math.IsInf(s.Longitude, 0) &&
+		!math.IsNaN(s.Height) && !math.IsInf(s.Height, 0) && s.ellipsoid().valid()
+}
+
+// ecef returns the Earth-fixed (ITRS) position of the site in km.
+func (s Site) ecef() [3]float64 {
+	return s.ellipsoid().Geocentric(s.Latitude, s.Longitude, s.Height)
+}
+
+// geodeticPosition returns the body-fixed position in km of a point at geodetic latitude and
+// longitude (degrees) and height h (km) above an ellipsoid of revolution with equatorial radius
+// a (km) and flattening f.
+func geodeticPosition(lat, lon, h, a, f float64) [3]float64 {
+	sinLat, cosLat := math.Sincos(lat * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(lon * math.Pi / 180)
+	e2 := f * (2 - f)
+	n := a / math.Sqrt(1-e2*sinLat*sinLat) // Prime vertical radius of curvature
+	return [3]float64{(n + h) * cosLat * cosLon, (n + h) * cosLat * sinLon, (n*(1-e2) + h) * sinLat}
+}
+
+// zenith returns the unit vector of the local vertical of the site in the Earth-fixed frame.
+func (s Site) zenith() [3]float64 {
+	sinLat, cosLat := math.Sincos(s.Latitude * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(s.Longitude * math.Pi / 180)
+	return [3]float64{cosLat * cosLon, cosLat * sinLon, sinLat}
+}
+
+// equatorialPrecession returns the rotation from the ICRF (frame bias ignored) to the mean
+// equator and equinox of date, built from the IAU 2006 ecliptic precession and mean obliquity.
+func equatorialPrecession(et float64) linalg.Mat3 {
+	return linalg.RotX(-meanObliquity(et)).Mul(eclipticPrecession(et)).Mul(linalg.RotX(obliquityIAU2006))
+}
+
+// meanObliquity returns the IAU 2006 mean obliquity of the ecliptic of date in radians.
+func meanObliquity(et float64) float64 {
+	t := (et - J2000) / daysPerJulianCentury
+	return obliquityIAU2006 + (-46.836769+(-0.0001831+(0.00200340+(-0.000000576-0.0000000434*t)*t)*t)*t)*t*arcsecToRad
+}
+
+// greenwichMeanSiderealTime returns the IAU 2006 Greenwich mean sidereal time in radians.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB, used in place of TT) as Julian Date.
+//   - deltaT: TT - UT1 in seconds.
+func greenwichMeanSiderealTime(et, deltaT float64) float64 {
+	du := et - deltaT/secondsPerDay - J2000 // UT1 days since J2000
+	era := 2 * math.Pi * math.Mod(0.7790572732640+0.00273781191135448*du+math.Mod(du, 1), 1)
+	t := (et - J2000) / daysPerJulianCentury
+	gmst := era + (0.014506+(4612.156534+(1.3915817+(-0.00000044+(-0.000029956-0.0000000368*t)*t)*t)*t)*t)*arcsecToRad
+	return math.Mod(gmst+2*math.Pi, 2*math.Pi)
+}
+
+// terrestrialRotation returns the rotation from the ICRF to the Earth-fixed frame, from precession
+// and mean sidereal time. Nutation (up to about 17 arcseconds) and polar motion are ignored.
+func terrestrialRotation(et, deltaT float64) linalg.Mat3 {
+	return linalg.RotZ(greenwichMeanSiderealTime(et, deltaT)).Mul(equatorialPrecession(et))
+}
+
+// topocentric returns the position (AU) of a body relative to a site, in the Earth-fixed frame.
+func (e *Ephemeris) topocentric(et float64, body Planet, site Site, deltaT float64) ([3]float64, error) {
+	pos, _, err := e.CalculatePV(et, body, CenterEarth, false)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	x := terrestrialRotation(et, deltaT).Apply([3]float64{pos.X, pos.Y, pos.Z})
+	s := site.ecef()
+	for i := range x {
+		x[i] -= s[i] / e.ephemData.au
+	}
+	return x, nil
+}
+
+// altitude returns the geometric altitude of a body above the site's horizon, in degrees.
+func (e *Ephemeris) altitude(et float64, body Planet, site Site, deltaT float64) (float64, error) {
+	x, err := e.topocentric(et, body, site, deltaT)
+	if err != nil {
+		return 0, err
+	}
+	z := site.zenith()
+	r := math.Sqrt(x[0]*x[0] + x[1]*x[1] + x[2]*x[2])
+	return math.Asin((x[0]*z[0]+x[1]*z[1]+x[2]*z[2])/r) * 180 / math.Pi, nil
+}
+
+// ObserverVelocity returns the velocity of a site on the Earth relative to the Solar System
+// Barycenter in the ICRF, as needed for Doppler predictions and barycentric corrections: the
+// orbital velocity of the geocenter plus the velocity of the rotation of the Earth at the
+// site (up to 465 m/s at the equator). The rotation is that of FrameITRF, with precession,
+// nutation, and the DeltaT model of TT - UT1; use FrameRegistry.SiteState with SetDeltaT
+// for measured values of UT1, or for the position of the site as well.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - site: Observer location.
+//
+// Returns:
+//   - Velocity: The velocity in AU/day.
+//   - error: ErrSite for an invalid site, or an error from CalculatePV or the frames.
+func (e *Ephemeris) ObserverVelocity(et float64, site Site) (Velocity, error) {
+	el := site.ellipsoid()
+	_, vel, err := e.NewFrameRegistry().SiteState(et, BodySite{Body: Earth, Latitude: site.Latitude,
+		Longitude: site.Longitude, Height: site.Height, Radii: [2]float64{el.A, el.B()}})
+	return vel, err
+}
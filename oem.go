@@ -0,0 +1,600 @@
+// ./oem.go
+package jpleph
+
+/*
+Package jpleph provides CCSDS Orbit Ephemeris Message import and export.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OEM support notes:
+//
+// Only the KVN (keyword = value) encoding of CCSDS 502.0-B is handled. Epochs are
+// converted to TDB Julian Dates when read and converted back to the segment's
+// TIME_SYSTEM when written; TDB, TT, and UTC are supported, UTC through the current
+// leap-second table (see SetLeapSeconds). Reference frames ICRF, GCRF
+// and EME2000 are accepted and treated as the ICRF axes of the JPL kernel (the
+// EME2000 frame bias of a few milliarcseconds is ignored). Covariance blocks are
+// skipped on input. The epochs of each segment must increase strictly, since equal epochs
+// leave the Lagrange and Hermite interpolation undefined.
+
+// oemTimeLayout is the epoch format written to OEM files.
+const oemTimeLayout = "2006-01-02T15:04:05.000000"
+
+// oemParseLayouts are the epoch formats accepted when reading OEM files.
+var oemParseLayouts = []string{"2006-01-02T15:04:05.999999999", "2006-002T15:04:05.999999999"}
+
+// OEM is a CCSDS Orbit Ephemeris Message.
+type OEM struct {
+	Version      string       // Version is the CCSDS_OEM_VERS value (e.g., "2.0").
+	CreationDate string       // CreationDate is the CREATION_DATE value, kept as written.
+	Originator   string       // Originator is the ORIGINATOR value.
+	Comments     []string     // Comments holds header COMMENT lines.
+	Segments     []OEMSegment // Segments holds the metadata and ephemeris data blocks.
+}
+
+// OEMMetadata holds the metadata block of an OEM segment.
+// All times are Julian Ephemeris Dates in TDB, regardless of TimeSystem.
+type OEMMetadata struct {
+	ObjectName          string  // ObjectName is the OBJECT_NAME value.
+	ObjectID            string  // ObjectID is the OBJECT_ID value.
+	CenterName          string  // CenterName is the CENTER_NAME value (e.g., "EARTH", "SUN").
+	RefFrame            string  // RefFrame is the REF_FRAME value (e.g., "ICRF").
+	TimeSystem          string  // TimeSystem is the TIME_SYSTEM value used for epochs in the file ("TDB", "TT", or "UTC").
+	StartTime           float64 // StartTime is the START_TIME value.
+	StopTime            float64 // StopTime is the STOP_TIME value.
+	UseableStartTime    float64 // UseableStartTime is the USEABLE_START_TIME value, or 0 if absent.
+	UseableStopTime     float64 // UseableStopTime is the USEABLE_STOP_TIME value, or 0 if absent.
+	Interpolation       string  // Interpolation is the INTERPOLATION value (e.g., "HERMITE", "LAGRANGE").
+	InterpolationDegree int     // InterpolationDegree is the INTERPOLATION_DEGREE value.
+}
+
+// OEMState is one ephemeris data line of an OEM segment.
+type OEMState struct {
+	Epoch    float64    // Epoch is the Julian Ephemeris Date (TDB) of the state.
+	Position [3]float64 // Position is the x, y, z position in km.
+	Velocity [3]float64 // Velocity is the x, y, z velocity in km/s.
+}
+
+// OEMSegment is a metadata block followed by its ephemeris data lines.
+type OEMSegment struct {
+	Metadata OEMMetadata // Metadata describes the states of the segment.
+	Comments []string    // Comments holds COMMENT lines found in the data block.
+	States   []OEMState  // States holds the ephemeris data lines, in increasing order of epoch.
+}
+
+// ReadOEM parses a CCSDS OEM in KVN encoding.
+//
+// Parameters:
+//   - r: Source of the OEM text.
+//
+// Returns:
+//   - *OEM: The parsed message, with all epochs converted to TDB Julian Dates.
+//   - error: ErrOEMFormat wrapped with the offending line number if the message is malformed,
+//     including epochs that do not increase within a segment.
+func ReadOEM(r io.Reader) (*OEM, error) {
+	oem := &OEM{}
+	scanner := bufio.NewScanner(r)
+	var seg *OEMSegment
+	inMeta, inCovariance := false, false
+	lineNo := 0
+
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("%w: line %d: %s", ErrOEMFormat, lineNo, fmt.Sprintf(format, args...))
+	}
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case inCovariance:
+			if line == "COVARIANCE_STOP" {
+				inCovariance = false
+			}
+			continue
+		case line == "COVARIANCE_START":
+			inCovariance = true
+			continue
+		case line == "META_START":
+			oem.Segments = append(oem.Segments, OEMSegment{})
+			seg = &oem.Segments[len(oem.Segments)-1]
+			inMeta = true
+			continue
+		case line == "META_STOP":
+			if !inMeta {
+				return nil, fail("META_STOP without META_START")
+			}
+			if err := seg.Metadata.validate(); err != nil {
+				return nil, fail("%v", err)
+			}
+			inMeta = false
+			continue
+		case strings.HasPrefix(line, "COMMENT"):
+			comment := strings.TrimSpace(strings.TrimPrefix(line, "COMMENT"))
+			if seg == nil {
+				oem.Comments = append(oem.Comments, comment)
+			} else {
+				seg.Comments = append(seg.Comments, comment)
+			}
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, "="); ok && (inMeta || seg == nil) {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			var err error
+			if seg == nil {
+				err = oem.setHeader(key, value)
+			} else {
+				err = seg.Metadata.set(key, value)
+			}
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			continue
+		}
+		if seg == nil || inMeta {
+			return nil, fail("unexpected line %q", line)
+		}
+
+		state, err := parseOEMState(line, seg.Metadata.TimeSystem)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		if n := len(seg.States); n > 0 && !(state.Epoch > seg.States[n-1].Epoch) {
+			return nil, fail("epoch %s does not follow the previous epoch", strings.Fields(line)[0])
+		}
+		seg.States = append(seg.States, state)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading OEM failed: %w", err)
+	}
+	if inMeta {
+		return nil, fail("missing META_STOP")
+	}
+	return oem, nil
+}
+
+// WriteOEM writes an OEM in KVN encoding. Epochs are written in each segment's TimeSystem.
+//
+// Parameters:
+//   - w: Destination writer.
+//   - oem: The message to write. Empty Version, CreationDate and Originator fields are filled with defaults.
+//
+// Returns:
+//   - error: ErrOEMFormat if a segment uses an unsupported time system, or the error returned by w.
+func WriteOEM(w io.Writer, oem *OEM) error {
+	bw := bufio.NewWriter(w)
+	version, created, originator := oem.Version, oem.CreationDate, oem.Originator
+	if version == "" {
+		version = "2.0"
+	}
+	if created == "" {
+		created = time.Now().UTC().Format("2006-01-02T15:04:05")
+	}
+	if originator == "" {
+		originator = "jpleph"
+	}
+	fmt.Fprintf(bw, "CCSDS_OEM_VERS = %s\n", version)
+	for _, c := range oem.Comments {
+		fmt.Fprintf(bw, "COMMENT %s\n", c)
+	}
+	fmt.Fprintf(bw, "CREATION_DATE = %s\n", created)
+	fmt.Fprintf(bw, "ORIGINATOR = %s\n", originator)
+
+	for _, seg := range oem.Segments {
+		md := seg.Metadata
+		if err := md.validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrOEMFormat, err)
+		}
+		epoch := func(jd float64) string { return formatOEMEpoch(jd, md.TimeSystem) }
+
+		fmt.Fprintf(bw, "\nMETA_START\n")
+		fmt.Fprintf(bw, "OBJECT_NAME = %s\n", md.ObjectName)
+		fmt.Fprintf(bw, "OBJECT_ID = %s\n", md.ObjectID)
+		fmt.Fprintf(bw, "CENTER_NAME = %s\n", md.CenterName)
+		fmt.Fprintf(bw, "REF_FRAME = %s\n", md.RefFrame)
+		fmt.Fprintf(bw, "TIME_SYSTEM = %s\n", md.TimeSystem)
+		fmt.Fprintf(bw, "START_TIME = %s\n", epoch(md.StartTime))
+		if md.UseableStartTime != 0 {
+			fmt.Fprintf(bw, "USEABLE_START_TIME = %s\n", epoch(md.UseableStartTime))
+		}
+		if md.UseableStopTime != 0 {
+			fmt.Fprintf(bw, "USEABLE_STOP_TIME = %s\n", epoch(md.UseableStopTime))
+		}
+		fmt.Fprintf(bw, "STOP_TIME = %s\n", epoch(md.StopTime))
+		if md.Interpolation != "" {
+			fmt.Fprintf(bw, "INTERPOLATION = %s\n", md.Interpolation)
+			fmt.Fprintf(bw, "INTERPOLATION_DEGREE = %d\n", md.InterpolationDegree)
+		}
+		fmt.Fprintf(bw, "META_STOP\n\n")
+		for _, c := range seg.Comments {
+			fmt.Fprintf(bw, "COMMENT %s\n", c)
+		}
+		for _, s := range seg.States {
+			fmt.Fprintf(bw, "%s %.6f %.6f %.6f %.9f %.9f %.9f\n", epoch(s.Epoch),
+				s.Position[0], s.Position[1], s.Position[2], s.Velocity[0], s.Velocity[1], s.Velocity[2])
+		}
+	}
+	return bw.Flush()
+}
+
+// GenerateOEM computes an OEM segment for a kernel (or custom) body from the ephemeris.
+// The segment uses the ICRF frame and the TDB time system, with states every step days
+// from start through end inclusive.
+//
+// Parameters:
+//   - target: Body whose states are written.
+//   - center: Body the states are relative to.
+//   - start: First Julian Ephemeris Date.
+//   - end: Last Julian Ephemeris Date.
+//   - step: Spacing between states, in days.
+//
+// Returns:
+//   - *OEMSegment: The computed segment.
+//   - error: ErrInvalidFit for an invalid time grid, or any error returned by CalculatePV.
+func (e *Ephemeris) GenerateOEM(target Planet, center CenterBody, start, end, step float64) (*OEMSegment, error) {
+	if !(end >= start) || !(step > 0) {
+		return nil, fmt.Errorf("%w: invalid time grid %f to %f step %f", ErrInvalidFit, start, end, step)
+	}
+	kmPerAU := e.ephemData.au
+	seg := &OEMSegment{Metadata: OEMMetadata{
+		ObjectName:          strings.ToUpper(target.String()),
+		ObjectID:            strconv.Itoa(int(target)),
+		CenterName:          strings.ToUpper(center.String()),
+		RefFrame:            "ICRF",
+		TimeSystem:          "TDB",
+		StartTime:           start,
+		StopTime:            end,
+		Interpolation:       "HERMITE",
+		InterpolationDegree: 7,
+	}}
+	if custom, ok := e.customBodies[target]; ok {
+		seg.Metadata.ObjectName = strings.ToUpper(custom.Name)
+	}
+
+	n := int(math.Floor((end-start)/step+1e-9)) + 1
+	for i := 0; i < n; i++ {
+		et := start + float64(i)*step
+		pos, vel, err := e.CalculatePV(et, target, center, true)
+		if err != nil {
+			return nil, fmt.Errorf("generating OEM state at JD %f failed: %w", et, err)
+		}
+		seg.States = append(seg.States, OEMState{
+			Epoch:    et,
+			Position: [3]float64{pos.X * kmPerAU, pos.Y * kmPerAU, pos.Z * kmPerAU},
+			Velocity: [3]float64{vel.DX * kmPerAU / secondsPerDay, vel.DY * kmPerAU / secondsPerDay, vel.DZ * kmPerAU / secondsPerDay},
+		})
+	}
+	seg.Metadata.StopTime = seg.States[len(seg.States)-1].Epoch
+	return seg, nil
+}
+
+// CustomBodyFromOEM fits the states of an OEM segment with Chebyshev polynomials so that
+// the trajectory can be queried through CalculatePV. The segment states are interpolated
+// with the method given in its metadata (Hermite by default, or Lagrange) and the result
+// is fitted over the useable time span (or the full span if none is given).
+// The returned body is not registered; pass it to AddCustomBody.
+//
+// Parameters:
+//   - seg: Segment to fit. Its CENTER_NAME must name a kernel body and REF_FRAME must be ICRF-aligned.
+//   - id: Identifier for the body (FirstCustomBody or above).
+//   - segmentDays: Length of each fitted Chebyshev segment, in days.
+//   - ncoeff: Number of Chebyshev coefficients per component (1 to 17).
+//
+// Returns:
+//   - *CustomBody: The fitted body.
+//   - error: ErrOEMFormat if the segment cannot be used (including epochs that do not increase
+//     strictly), or any error returned by FitCustomBody.
+func (e *Ephemeris) CustomBodyFromOEM(seg *OEMSegment, id Planet, segmentDays float64, ncoeff int) (*CustomBody, error) {
+	md := seg.Metadata
+	center, ok := centerBodyFromOEMName(md.CenterName)
+	if !ok {
+		return nil, fmt.Errorf("%w: center %q is not a kernel body", ErrOEMFormat, md.CenterName)
+	}
+	if !isICRFAligned(md.RefFrame) {
+		return nil, fmt.Errorf("%w: unsupported reference frame %q", ErrOEMFormat, md.RefFrame)
+	}
+	if len(seg.States) < 2 {
+		return nil, fmt.Errorf("%w: segment %q has fewer than two states", ErrOEMFormat, md.ObjectName)
+	}
+	for i := 1; i < len(seg.States); i++ {
+		if !(seg.States[i].Epoch > seg.States[i-1].Epoch) {
+			return nil, fmt.Errorf("%w: segment %q: state %d does not follow the previous epoch", ErrOEMFormat, md.ObjectName, i)
+		}
+	}
+
+	start, end := seg.States[0].Epoch, seg.States[len(seg.States)-1].Epoch
+	if md.UseableStartTime != 0 {
+		start = math.Max(start, md.UseableStartTime)
+	}
+	if md.UseableStopTime != 0 {
+		end = math.Min(end, md.UseableStopTime)
+	}
+
+	kmPerAU := e.ephemData.au
+	lagrange := strings.EqualFold(md.Interpolation, "LAGRANGE")
+	degree := md.InterpolationDegree
+	if degree <= 0 {
+		degree = 7
+	}
+	sample := func(et float64) (Position, error) {
+		var p [3]float64
+		if lagrange {
+			p = lagrangeInterpolate(seg.States, et, degree+1)
+		} else {
+			p = hermiteInterpolate(seg.States, et, (degree+2)/2)
+		}
+		return Position{X: p[0] / kmPerAU, Y: p[1] / kmPerAU, Z: p[2] / kmPerAU}, nil
+	}
+	name := md.ObjectName
+	if name == "" {
+		name = md.ObjectID
+	}
+	return FitCustomBody(id, name, center, start, end, segmentDays, ncoeff, sample)
+}
+
+// setHeader stores a header keyword of the OEM.
+func (oem *OEM) setHeader(key, value string) error {
+	switch key {
+	case "CCSDS_OEM_VERS":
+		oem.Version = value
+	case "CREATION_DATE":
+		oem.CreationDate = value
+	case "ORIGINATOR":
+		oem.Originator = value
+	default:
+		return fmt.Errorf("unknown header keyword %q", key)
+	}
+	return nil
+}
+
+// set stores a metadata keyword. TIME_SYSTEM must precede the time keywords, as in the standard.
+func (md *OEMMetadata) set(key, value string) error {
+	var err error
+	switch key {
+	case "OBJECT_NAME":
+		md.ObjectName = value
+	case "OBJECT_ID":
+		md.ObjectID = value
+	case "CENTER_NAME":
+		md.CenterName = value
+	case "REF_FRAME":
+		md.RefFrame = value
+	case "TIME_SYSTEM":
+		md.TimeSystem = strings.ToUpper(value)
+	case "START_TIME":
+		md.StartTime, err = parseOEMEpoch(value, md.TimeSystem)
+	case "STOP_TIME":
+		md.StopTime, err = parseOEMEpoch(value, md.TimeSystem)
+	case "USEABLE_START_TIME":
+		md.UseableStartTime, err = parseOEMEpoch(value, md.TimeSystem)
+	case "USEABLE_STOP_TIME":
+		md.UseableStopTime, err = parseOEMEpoch(value, md.TimeSystem)
+	case "INTERPOLATION":
+		md.Interpolation = strings.ToUpper(value)
+	case "INTERPOLATION_DEGREE":
+		md.InterpolationDegree, err = strconv.Atoi(value)
+	case "REF_FRAME_EPOCH":
+		// Only meaningful for frames with a time-dependent orientation, none of which are supported.
+	default:
+		return fmt.Errorf("unknown metadata keyword %q", key)
+	}
+	return err
+}
+
+// validate checks that the mandatory metadata keywords are present and supported.
+func (md *OEMMetadata) validate() error {
+	if md.ObjectName == "" || md.CenterName == "" || md.RefFrame == "" || md.TimeSystem == "" {
+		return fmt.Errorf("missing mandatory metadata keyword")
+	}
+	if md.TimeSystem != "TDB" && md.TimeSystem != "TT" && md.TimeSystem != "UTC" {
+		return fmt.Errorf("unsupported time system %q", md.TimeSystem)
+	}
+	return nil
+}
+
+// parseOEMState parses an ephemeris data line (epoch, position, velocity, optional acceleration).
+func parseOEMState(line, timeSystem string) (OEMState, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 7 && len(fields) != 10 {
+		return OEMState{}, fmt.Errorf("expected 7 or 10 fields, found %d", len(fields))
+	}
+	epoch, err := parseOEMEpoch(fields[0], timeSystem)
+	if err != nil {
+		return OEMState{}, err
+	}
+	state := OEMState{Epoch: epoch}
+	for i := 0; i < 6; i++ {
+		v, err := strconv.ParseFloat(fields[i+1], 64)
+		if err != nil {
+			return OEMState{}, fmt.Errorf("invalid number %q", fields[i+1])
+		}
+		if i < 3 {
+			state.Position[i] = v
+		} else {
+			state.Velocity[i-3] = v
+		}
+	}
+	return state, nil
+}
+
+// parseOEMEpoch converts an OEM epoch string in the given time system to a TDB Julian Date.
+// UTC epochs may fall in a leap second (second 60), which time.Parse does not accept: they
+// are read as second 59 and moved one second later on the TDB scale.
+func parseOEMEpoch(value, timeSystem string) (float64, error) {
+	value = strings.TrimSuffix(value, "Z")
+	leap := 0.0
+	if i := strings.Index(value, ":60"); timeSystem == "UTC" && i >= 0 && (i+3 == len(value) || value[i+3] == '.') {
+		value, leap = value[:i]+":59"+value[i+3:], 1
+	}
+	for _, layout := range oemParseLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		jd := julianDateFromTime(t)
+		switch timeSystem {
+		case "TDB":
+			return jd, nil
+		case "TT":
+			return jd + tdbMinusTT(jd)/secondsPerDay, nil
+		case "UTC":
+			return CurrentLeapSeconds().UTCToTDB(jd) + leap/secondsPerDay, nil
+		default:
+			return 0, fmt.Errorf("unsupported time system %q", timeSystem)
+		}
+	}
+	return 0, fmt.Errorf("invalid epoch %q", value)
+}
+
+// formatOEMEpoch converts a TDB Julian Date to an OEM epoch string in the given time system.
+// UTC epochs within a leap second are written with second 60, so that the order of the states
+// is kept.
+func formatOEMEpoch(jd float64, timeSystem string) string {
+	switch timeSystem {
+	case "TT":
+		jd -= tdbMinusTT(jd) / secondsPerDay
+	case "UTC":
+		leaps := CurrentLeapSeconds()
+		utc := leaps.TDBToUTC(jd)
+		if leaps.UTCToTDB(utc)-jd > 0.5/secondsPerDay { // TDBToUTC moved jd out of a leap second
+			s := timeFromJulianDate(utc - 1/secondsPerDay).Format(oemTimeLayout)
+			if s[17:19] == "59" {
+				return s[:17] + "60" + s[19:]
+			}
+		}
+		jd = utc
+	}
+	return timeFromJulianDate(jd).Format(oemTimeLayout)
+}
+
+// centerBodyFromOEMName maps a CCSDS CENTER_NAME to a kernel body.
+// Planetary system barycenters map to the corresponding kernel body, since the
+// JPL kernels give the states of the outer planets as system barycenters.
+func centerBodyFromOEMName(name string) (CenterBody, bool) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	key = strings.TrimSuffix(key, " BARYCENTER")
+	switch key {
+	case "SOLAR SYSTEM", "SSB":
+		return CenterSolarSystemBarycenter, true
+	case "EARTH-MOON", "EARTH MOON", "EMB":
+		return CenterEarthMoonBarycenter, true
+	}
+	for c := CenterMercury; c <= CenterEarthMoonBarycenter; c++ {
+		if strings.ToUpper(c.String()) == key {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// isICRFAligned reports whether an OEM reference frame shares the axes of the JPL kernel.
+func isICRFAligned(frame string) bool {
+	switch strings.ToUpper(frame) {
+	case "ICRF", "GCRF", "EME2000", "J2000":
+		return true
+	}
+	return false
+}
+
+// oemWindow returns the indices [lo, lo+n) of the n states closest to et.
+func oemWindow(states []OEMState, et float64, n int) (int, int) {
+	if n > len(states) {
+		n = len(states)
+	}
+	idx := sort.Search(len(states), func(i int) bool { return states[i].Epoch >= et })
+	lo := idx - n/2
+	if lo < 0 {
+		lo = 0
+	}
+	if lo+n > len(states) {
+		lo = len(states) - n
+	}
+	return lo, lo + n
+}
+
+// lagrangeInterpolate interpolates the position at et from the n nearest states.
+func lagrangeInterpolate(states []OEMState, et float64, n int) [3]float64 {
+	lo, hi := oemWindow(states, et, n)
+	var result [3]float64
+	for i := lo; i < hi; i++ {
+		weight := 1.0
+		for j := lo; j < hi; j++ {
+			if j != i {
+				weight *= (et - states[j].Epoch) / (states[i].Epoch - states[j].Epoch)
+			}
+		}
+		for k := 0; k < 3; k++ {
+			result[k] += weight * states[i].Position[k]
+		}
+	}
+	return result
+}
+
+// hermiteInterpolate interpolates the position at et from the positions and velocities of
+// the n nearest states, using Newton divided differences on doubled nodes.
+func hermiteInterpolate(states []OEMState, et float64, n int) [3]float64 {
+	lo, hi := oemWindow(states, et, n)
+	m := 2 * (hi - lo)
+	z := make([]float64, m)
+	q := make([][]float64, m)
+	for i := range q {
+		q[i] = make([]float64, m)
+	}
+
+	var result [3]float64
+	for k := 0; k < 3; k++ {
+		for i := lo; i < hi; i++ {
+			a, b := 2*(i-lo), 2*(i-lo)+1
+			z[a], z[b] = states[i].Epoch-et, states[i].Epoch-et // Nodes relative to et for conditioning
+			q[a][0], q[b][0] = states[i].Position[k], states[i].Position[k]
+			q[b][1] = states[i].Velocity[k] * secondsPerDay // Derivative in km/day
+			if a > 0 {
+				q[a][1] = (q[a][0] - q[a-1][0]) / (z[a] - z[a-1])
+			}
+		}
+		for j := 2; j < m; j++ {
+			for i := j; i < m; i++ {
+				q[i][j] = (q[i][j-1] - q[i-1][j-1]) / (z[i] - z[i-j])
+			}
+		}
+		value, product := q[0][0], 1.0
+		for j := 1; j < m; j++ {
+			product *= -z[j-1] // (et - z_{j-1}) with nodes already relative to et
+			value += q[j][j] * product
+		}
+		result[k] = value
+	}
+	return result
+}
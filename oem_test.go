@@ -0,0 +1,107 @@
+// ./oem_test.go
+package jpleph_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// oemText returns an OEM with one segment in the given time system and the given data lines.
+func oemText(timeSystem string, lines ...string) string {
+	return `CCSDS_OEM_VERS = 2.0
+CREATION_DATE = 2024-01-01T00:00:00
+ORIGINATOR = test
+
+META_START
+OBJECT_NAME = SC
+OBJECT_ID = 2024-001A
+CENTER_NAME = EARTH
+REF_FRAME = ICRF
+TIME_SYSTEM = ` + timeSystem + `
+START_TIME = 2016-12-31T23:59:00
+STOP_TIME = 2017-01-01T00:01:00
+META_STOP
+
+` + strings.Join(lines, "\n") + "\n"
+}
+
+// TestReadOEMEpochOrder checks that epochs which do not increase within a segment are rejected
+// with the line number, since equal epochs make the interpolation divide by zero.
+func TestReadOEMEpochOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{"duplicate", []string{
+			"2017-01-01T00:00:00 7000 0 0 0 7.5 0",
+			"2017-01-01T00:00:00 7000 1 0 0 7.5 0",
+		}},
+		{"decreasing", []string{
+			"2017-01-01T00:00:30 7000 0 0 0 7.5 0",
+			"2017-01-01T00:00:00 7000 1 0 0 7.5 0",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jpleph.ReadOEM(strings.NewReader(oemText("TDB", tt.lines...)))
+			if !errors.Is(err, jpleph.ErrOEMFormat) || !strings.Contains(err.Error(), "line 16") {
+				t.Errorf("ReadOEM: %v, want ErrOEMFormat at line 16", err)
+			}
+		})
+	}
+
+	e := openSynthetic(t, jplephtest.DefaultConfig())
+	seg := jpleph.OEMSegment{
+		Metadata: jpleph.OEMMetadata{ObjectName: "SC", CenterName: "EARTH", RefFrame: "ICRF", TimeSystem: "TDB"},
+		States:   []jpleph.OEMState{{Epoch: 2451545}, {Epoch: 2451546}, {Epoch: 2451546}, {Epoch: 2451547}},
+	}
+	if _, err := e.CustomBodyFromOEM(&seg, jpleph.FirstCustomBody, 1, 8); !errors.Is(err, jpleph.ErrOEMFormat) {
+		t.Errorf("CustomBodyFromOEM with a duplicate epoch: %v, want ErrOEMFormat", err)
+	}
+}
+
+// TestOEMUTC checks that UTC epochs, including one in the leap second at the end of 2016, are
+// read through the leap-second table and written back unchanged.
+func TestOEMUTC(t *testing.T) {
+	const midnight = 2457754.5 // 2017-01-01T00:00:00 UTC
+	text := oemText("UTC",
+		"2016-12-31T23:59:59.000000 7000.000000 0.000000 0.000000 0.000000 7.500000000 0.000000000",
+		"2016-12-31T23:59:60.500000 7000.000000 1.000000 0.000000 0.000000 7.500000000 0.000000000",
+		"2017-01-01T00:00:00.000000 7000.000000 2.000000 0.000000 0.000000 7.500000000 0.000000000")
+	oem, err := jpleph.ReadOEM(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tdb := jpleph.UTCToTDB(midnight)
+	states := oem.Segments[0].States
+	for i, want := range []float64{tdb - 2/86400.0, tdb - 0.5/86400.0, tdb} {
+		if d := math.Abs(states[i].Epoch-want) * 86400; d > 1e-4 {
+			t.Errorf("state %d: epoch %.10f, want %.10f (%.6f s off)", i, states[i].Epoch, want, d)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpleph.WriteOEM(&buf, oem); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"TIME_SYSTEM = UTC", "\n2016-12-31T23:59:60.", "\n2017-01-01T00:00:00."} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteOEM output lacks %q:\n%s", want, buf.String())
+		}
+	}
+	again, err := jpleph.ReadOEM(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range again.Segments[0].States {
+		if d := math.Abs(s.Epoch-states[i].Epoch) * 86400; d > 1e-4 {
+			t.Errorf("state %d: epoch %.10f after writing, want %.10f", i, s.Epoch, states[i].Epoch)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// ./options.go
+package jpleph
+
+/*
+Package jpleph provides options configuring an Ephemeris at creation.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "math"
+
+// Option configures an Ephemeris in NewEphemeris.
+type Option func(*Ephemeris)
+
+// DefaultClampTolerance is how far (in seconds) outside the time span of the file
+// WithClampToRange clamps requests.
+const DefaultClampTolerance = 60.0
+
+// WithClampToRange makes CalculatePV (and ComputeBatch) clamp epochs up to
+// DefaultClampTolerance outside the time span of the file to the nearest covered epoch,
+// instead of failing with ErrOutsideRange. This absorbs the rounding of UTC to TDB
+// conversions at file boundaries; Clamped reports whether the last request was clamped.
+// Requests further outside still fail with ErrOutsideRange.
+func WithClampToRange() Option {
+	return WithClampTolerance(DefaultClampTolerance)
+}
+
+// WithClampTolerance is WithClampToRange with a custom tolerance in seconds.
+func WithClampTolerance(seconds float64) Option {
+	return func(e *Ephemeris) {
+		e.clampTolerance = seconds / secondsPerDay
+	}
+}
+
+// WithCompensatedSummation selects a high-accuracy evaluation mode: the Chebyshev series are
+// summed with compensated (Kahan-style) summation and error-free products, and positions are
+// divided by the AU instead of multiplied by its reciprocal. Results then differ from the
+// default mode by a few units in the last place (femto-AU), bringing them closer to the
+// exactly rounded values of the series, for reproducibility studies against JPL's Fortran
+// reader. Evaluation is about twice as slow. It replaces WithFortranOrder, and the later of
+// the two options wins.
+func WithCompensatedSummation() Option {
+	return func(e *Ephemeris) {
+		e.ephemData.iinfo.compensated = true
+		e.ephemData.iinfo.fortranOrder = false
+	}
+}
+
+// IAUAstronomicalUnit is the astronomical unit in km fixed by IAU 2012 Resolution B2, for
+// WithAU.
+const IAUAstronomicalUnit = astronomicalUnitKM
+
+// WithAU sets the length of the astronomical unit in km by which the outputs are scaled,
+// for pipelines that must use IAUAstronomicalUnit or another fixed value instead of the AU
+// of the file. Interpolation is untouched, since the series of the file are in km; the
+// states of CalculatePV, State, and the functions built on them are divided by the given
+// AU, the conversions back to km (light time, sites, OEM output) multiply by it, and
+// GetEphemerisDouble(AUinKM) reports it. The header value remains in SelfTest and in
+// ScalingProvenance, which records the override. Values that are not positive and finite
+// are ignored. Clones and reloaded files keep the override.
+func WithAU(km float64) Option {
+	return func(e *Ephemeris) {
+		if km > 0 && !math.IsInf(km, 0) {
+			e.ephemData.au = km
+		}
+	}
+}
+
+// WithNutationFallback supplies a nutation theory, such as IAU2000B, for files without
+// nutations. The frames, sidereal times, and ecliptic coordinates of date then work with any
+// DE file instead of failing with ErrQuantityNotInEphemeris. Files with nutations keep
+// using their own; CalculatePV itself still reports the nutations as missing.
+func WithNutationFallback(theory NutationTheory) Option {
+	return func(e *Ephemeris) {
+		e.nutationFallback = theory
+	}
+}
+
+// Clamped reports whether the epoch of the most recent CalculatePV call was moved onto the
+// time span of the file by WithClampToRange.
+func (e *Ephemeris) Clamped() bool {
+	return e.clamped
+}
+
+// clampEpoch moves et onto the time span of the file when it is outside by no more than the
+// clamp tolerance, recording whether it did.
+func (e *Ephemeris) clampEpoch(et float64) float64 {
+	e.clamped = false
+	if e.clampTolerance <= 0 {
+		return et
+	}
+	start, end := e.ephemData.ephemStart, e.ephemData.ephemEnd
+	switch {
+	case et < start && start-et <= e.clampTolerance:
+		e.clamped = true
+		return start
+	case et > end && et-end <= e.clampTolerance:
+		e.clamped = true
+		return end
+	}
+	return et
+}
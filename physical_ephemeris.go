@@ -0,0 +1,138 @@
+// ./physical_ephemeris.go
+package jpleph
+
+/*
+Package jpleph provides the physical ephemeris of a body: its apparent place, size, phase, and
+orientation seen by an observer.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// PhysicalData is the physical ephemeris of a body seen by an observer at one epoch, as
+// tabulated by the almanacs and by the JPL Horizons OBSERVER quantities 2, 9, 10, 13, 14, 17,
+// 20, and 24.
+type PhysicalData struct {
+	ET              float64 // Epoch of observation (TDB Julian Date)
+	RA              float64 // Apparent right ascension in degrees (true equator and equinox of date), in [0, 360)
+	Dec             float64 // Apparent declination in degrees (true equator and equinox of date)
+	Distance        float64 // Distance in AU, at the light-time corrected epoch
+	LightTime       float64 // One-way light time in days
+	AngularDiameter float64 // Apparent equatorial diameter in arcseconds, NaN without a reference ellipsoid
+	PhaseAngle      float64 // Sun-Target-Observer angle in degrees
+	Illumination    float64 // Illuminated fraction of the disk, (1 + cos PhaseAngle) / 2
+	Magnitude       float64 // Apparent visual magnitude, NaN if there is no model for the body
+
+	HasRotation         bool    // Whether the body has a rotation model, giving the fields below
+	CentralMeridian     float64 // Planetocentric east longitude of the sub-observer point in degrees, in [0, 360)
+	SubObserverLatitude float64 // Planetocentric latitude of the sub-observer point in degrees
+	PolePositionAngle   float64 // Position angle of the north pole of the body, from the north of date through east, in degrees
+}
+
+// PhysicalEphemeris returns the physical ephemeris of a body seen by an observer: apparent
+// place, distance, apparent size, phase, magnitude, and, for the bodies with an IAU rotation
+// model or a body-fixed frame of the file (see BodySite), the central meridian and the
+// position angle of the pole. The place is corrected for light time and stellar aberration
+// and referred to the true equator and equinox of date; the orientation is that of the body
+// when it emitted the light. Central meridians in the west longitudes of the almanacs are
+// 360 minus CentralMeridian for the prograde rotators.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date of observation.
+//   - body: Body observed (e.g., Mars, or an SPK satellite such as Io).
+//   - observer: Body observing (e.g., Earth).
+//
+// Returns:
+//   - PhysicalData: The physical ephemeris.
+//   - error: ErrInvalidIndex if the body and observer coincide or either is a special
+//     quantity, ErrQuantityNotInEphemeris for a file without nutations (see
+//     WithNutationFallback), or an error from CalculatePV.
+func (e *Ephemeris) PhysicalEphemeris(et float64, body, observer Planet) (PhysicalData, error) {
+	if body == observer || (body >= Nutations && body <= TT_TDB) || (observer >= Nutations && observer <= TT_TDB) {
+		return PhysicalData{}, fmt.Errorf("%v seen from %v: %w", body, observer, ErrInvalidIndex)
+	}
+	au := e.ephemData.au
+	sec := (et - J2000) * secondsPerDay
+	geometric, _, lt, err := e.correctedState(sec, body, observer, spiceCorrection{lightTime: true, converged: true, transmission: -1}, false)
+	if err != nil {
+		return PhysicalData{}, err
+	}
+	apparent, _, _, err := e.correctedState(sec, body, observer, spiceCorrection{lightTime: true, converged: true, stellar: true, transmission: -1}, false)
+	if err != nil {
+		return PhysicalData{}, err
+	}
+	nut, err := e.nutationMatrix(et)
+	if err != nil {
+		return PhysicalData{}, err
+	}
+	ofDate := nut.Mul(equatorialPrecession(et))
+
+	// The Sun seen from the body when it emitted the light
+	emitted := et - lt/secondsPerDay
+	b, _, err := e.CalculatePV(emitted, body, CenterSolarSystemBarycenter, false)
+	if err != nil {
+		return PhysicalData{}, err
+	}
+	sun, err := e.lightTimePosition(emitted, Sun, [3]float64{b.X, b.Y, b.Z})
+	if err != nil {
+		return PhysicalData{}, err
+	}
+	toObserver := [3]float64{-geometric[0], -geometric[1], -geometric[2]}
+
+	d := PhysicalData{ET: et, LightTime: lt / secondsPerDay, AngularDiameter: math.NaN()}
+	app := ofDate.Apply(apparent)
+	d.RA, d.Dec, _ = sphericalDegrees(app)
+	d.Distance = norm3(geometric) / au
+	d.PhaseAngle = angleDegrees(sun, toObserver)
+	d.Illumination = (1 + math.Cos(d.PhaseAngle*math.Pi/180)) / 2
+	d.Magnitude = visualMagnitude(body, norm3(sun), d.Distance, d.PhaseAngle)
+
+	radiiBody := body
+	if body > 100 && body%100 == 99 { // Planet center
+		radiiBody /= 100
+	}
+	if radii, ok := bodyRadii[radiiBody]; ok {
+		d.AngularDiameter = 2 * math.Asin(math.Min(1, radii[0]/norm3(geometric))) * 180 / math.Pi * 3600
+	}
+
+	if frame, ok := bodyFrames[body]; ok {
+		rot, err := e.NewFrameRegistry().Rotation(FrameICRF, frame, emitted)
+		if err != nil {
+			return PhysicalData{}, err
+		}
+		m := linalg.Mat3(rot)
+		d.CentralMeridian, d.SubObserverLatitude, _ = sphericalDegrees(m.Apply(toObserver))
+		pole := ofDate.Apply(m[2]) // The body z axis in the ICRF is the last row of the rotation
+		sinRA, cosRA := math.Sincos(d.RA * math.Pi / 180)
+		sinDec, cosDec := math.Sincos(d.Dec * math.Pi / 180)
+		east := pole[1]*cosRA - pole[0]*sinRA
+		north := pole[2]*cosDec - (pole[0]*cosRA+pole[1]*sinRA)*sinDec
+		d.PolePositionAngle = math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360)
+		d.HasRotation = true
+	}
+	return d, nil
+}
@@ -0,0 +1,77 @@
+// ./pleph_compat.go
+package jpleph
+
+/*
+Package jpleph provides functions with the calling conventions of jpl_pleph and jpl_state of the C version.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+// JPLPleph is jpl_pleph of the C version, for ports of C and Fortran pipelines that compare
+// intermediate results with the originals: it takes the body numbers of the C interface,
+// writes into a caller-provided rrd, and returns the C error code. It applies none of the
+// options of the Ephemeris (clamping, nutation fallback, custom and SPK bodies), so its
+// results are those of the C reader for the same file; the position of a body relative to
+// itself is zero, as in C.
+//
+// Parameters:
+//   - ephem: Ephemeris to interpolate.
+//   - et: Julian Ephemeris Date (TDB).
+//   - ntarg: Target body number (1-17; see Pleph for the numbering).
+//   - ncent: Center body number (0-13).
+//   - rrd: Slice of at least 6 values receiving {x, y, z, dx, dy, dz} in AU and AU/day, or
+//     the special quantity in the layout of C; not modified on failure.
+//   - calcVelocity: Non-zero to compute the velocities (or rates).
+//
+// Returns:
+//   - int: 0 on success, or a JPL_EPH_* code (JPL_EPH_OTHER_ERROR for an rrd shorter than 6).
+func JPLPleph(ephem *Ephemeris, et float64, ntarg, ncent int, rrd []float64, calcVelocity int) int {
+	if len(rrd) < 6 {
+		return JPL_EPH_OTHER_ERROR
+	}
+	ephem.applyReload()
+	out, err := Pleph(ephem.ephemData, et, ntarg, ncent, calcVelocity)
+	if err != nil {
+		return ErrorCode(err)
+	}
+	copy(rrd, out[:6])
+	return 0
+}
+
+// JPLState is jpl_state of the C version, the interpolation of the quantities selected by a
+// list for JPLPleph: each list entry is 0 to skip the quantity, 1 for positions, or 2 for
+// positions and velocities.
+//
+// Parameters:
+//   - ephem: Ephemeris to interpolate.
+//   - et: Julian Ephemeris Date (TDB).
+//   - list: Selection of the quantities, in the order of the IPT (see State).
+//   - pv: Array receiving the states of the bodies selected (index 0-9), in AU and AU/day.
+//   - nut: Slice of up to 6 values receiving the special quantity selected by list[10..13].
+//   - bary: 1 for states relative to the Solar System Barycenter, 0 for the planets and the
+//     Earth-Moon barycenter relative to the Sun (the Moon stays geocentric).
+//
+// Returns:
+//   - int: 0 on success, or a JPL_EPH_* code.
+func JPLState(ephem *Ephemeris, et float64, list [14]int, pv *[13][6]float64, nut []float64, bary int) int {
+	ephem.applyReload()
+	return ErrorCode(State(ephem.ephemData, et, list, pv, nut, bary))
+}
@@ -0,0 +1,135 @@
+// ./pool_coverage.go
+package jpleph
+
+/*
+Package jpleph provides the coverage of the special quantities by the ephemerides of a kernel pool.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CoverageInterval is a time interval of a QuantityCoverage.
+type CoverageInterval struct {
+	Start  float64 // First epoch (TDB Julian Date)
+	End    float64 // Last epoch (TDB Julian Date)
+	Source string  // File providing the quantity; for a gap, the files covering the time without it ("" if none)
+}
+
+// QuantityCoverage is the coverage of one special quantity by the ephemerides of a pool,
+// over the span from the earliest start to the latest end of the ephemerides.
+type QuantityCoverage struct {
+	Quantity Planet             // Nutations, Librations, LunarMantleOmega, or TT_TDB
+	Covered  []CoverageInterval // Intervals with the quantity, each with the file CalculatePV uses there
+	Gaps     []CoverageInterval // Intervals without it, in time order
+}
+
+// Complete reports whether the quantity is available over the whole span of the pool.
+func (c QuantityCoverage) Complete() bool {
+	return len(c.Gaps) == 0 && len(c.Covered) > 0
+}
+
+// QuantityCoverage reports, for each special quantity, where the ephemerides of the pool
+// provide it and where they do not, for files stitched over a span of which some lack
+// librations or TT-TDB (such as DE440 and DE441, or a file with and without the "t"
+// quantities). The sources are those CalculatePV uses: the most recently registered
+// ephemeris that covers an epoch and contains the quantity.
+//
+// Returns:
+//   - []QuantityCoverage: One report for each of Nutations, Librations, LunarMantleOmega,
+//     and TT_TDB; the reports are empty for a pool without ephemerides.
+func (p *KernelPool) QuantityCoverage() []QuantityCoverage {
+	var cuts []float64
+	for _, e := range p.ephemerides {
+		cuts = append(cuts, e.GetEphemerisDouble(EphemerisStartJD), e.GetEphemerisDouble(EphemerisEndJD))
+	}
+	sort.Float64s(cuts)
+
+	var res []QuantityCoverage
+	for q := Nutations; q <= TT_TDB; q++ {
+		cov := QuantityCoverage{Quantity: q}
+		for k := 0; k+1 < len(cuts); k++ {
+			if cuts[k+1] == cuts[k] {
+				continue
+			}
+			mid := (cuts[k] + cuts[k+1]) / 2 // The sources are the same across the interval
+			provider, covered := "", false
+			var lacking []string
+			for i := len(p.ephemerides) - 1; i >= 0 && !covered; i-- {
+				e := p.ephemerides[i]
+				switch {
+				case !ephemerisCovers(e, mid):
+				case ephemerisHas(e, q):
+					provider, covered = ephemerisLabel(e), true
+				default:
+					lacking = append(lacking, ephemerisLabel(e))
+				}
+			}
+			if covered {
+				cov.Covered = appendInterval(cov.Covered, cuts[k], cuts[k+1], provider)
+			} else {
+				cov.Gaps = appendInterval(cov.Gaps, cuts[k], cuts[k+1], strings.Join(lacking, ", "))
+			}
+		}
+		res = append(res, cov)
+	}
+	return res
+}
+
+// appendInterval appends [start, end] to intervals, merging it with the last one when they
+// touch and have the same source.
+func appendInterval(intervals []CoverageInterval, start, end float64, source string) []CoverageInterval {
+	if n := len(intervals); n > 0 && intervals[n-1].End == start && intervals[n-1].Source == source {
+		intervals[n-1].End = end
+		return intervals
+	}
+	return append(intervals, CoverageInterval{Start: start, End: end, Source: source})
+}
+
+// ephemerisLabel names an ephemeris in reports and errors: the path it was opened from, or
+// the name in its header for files opened from memory or a reader.
+func ephemerisLabel(e *Ephemeris) string {
+	if e.ephemData.filename != "" {
+		return e.ephemData.filename
+	}
+	return strings.TrimSpace(e.GetEphemName())
+}
+
+// ephemerisSpan is ephemerisLabel with the time span of the file.
+func ephemerisSpan(e *Ephemeris) string {
+	return fmt.Sprintf("%s (JD %.1f to %.1f)", ephemerisLabel(e), e.GetEphemerisDouble(EphemerisStartJD),
+		e.GetEphemerisDouble(EphemerisEndJD))
+}
+
+// ephemerisCovers reports whether et is within the time span of an ephemeris.
+func ephemerisCovers(e *Ephemeris, et float64) bool {
+	return et >= e.GetEphemerisDouble(EphemerisStartJD) && et <= e.GetEphemerisDouble(EphemerisEndJD)
+}
+
+// ephemerisHas reports whether an ephemeris contains a special quantity (Nutations to TT_TDB).
+func ephemerisHas(e *Ephemeris, q Planet) bool {
+	_, _, _, err := e.IPT(QuantityNutations + Quantity(q-Nutations))
+	return err == nil
+}
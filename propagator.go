@@ -0,0 +1,343 @@
+// ./propagator.go
+package jpleph
+
+/*
+Package jpleph provides numerical propagation of test particles in the ephemeris force model.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// Runge-Kutta-Fehlberg 7(8) coefficients (Fehlberg, NASA TR R-287, 1968).
+var (
+	rkf78C = [13]float64{0, 2.0 / 27, 1.0 / 9, 1.0 / 6, 5.0 / 12, 1.0 / 2, 5.0 / 6, 1.0 / 6, 2.0 / 3, 1.0 / 3, 1, 0, 1}
+	rkf78A = [13][12]float64{
+		{},
+		{2.0 / 27},
+		{1.0 / 36, 1.0 / 12},
+		{1.0 / 24, 0, 1.0 / 8},
+		{5.0 / 12, 0, -25.0 / 16, 25.0 / 16},
+		{1.0 / 20, 0, 0, 1.0 / 4, 1.0 / 5},
+		{-25.0 / 108, 0, 0, 125.0 / 108, -65.0 / 27, 125.0 / 54},
+		{31.0 / 300, 0, 0, 0, 61.0 / 225, -2.0 / 9, 13.0 / 900},
+		{2, 0, 0, -53.0 / 6, 704.0 / 45, -107.0 / 9, 67.0 / 90, 3},
+		{-91.0 / 108, 0, 0, 23.0 / 108, -976.0 / 135, 311.0 / 54, -19.0 / 60, 17.0 / 6, -1.0 / 12},
+		{2383.0 / 4100, 0, 0, -341.0 / 164, 4496.0 / 1025, -301.0 / 82, 2133.0 / 4100, 45.0 / 82, 45.0 / 164, 18.0 / 41},
+		{3.0 / 205, 0, 0, 0, 0, -6.0 / 41, -3.0 / 205, -3.0 / 41, 3.0 / 41, 6.0 / 41},
+		{-1777.0 / 4100, 0, 0, -341.0 / 164, 4496.0 / 1025, -289.0 / 82, 2193.0 / 4100, 51.0 / 82, 33.0 / 164, 12.0 / 41, 0, 1},
+	}
+	rkf78B = [13]float64{0, 0, 0, 0, 0, 34.0 / 105, 9.0 / 35, 9.0 / 35, 9.0 / 280, 9.0 / 280, 0, 41.0 / 840, 41.0 / 840} // 8th order weights
+)
+
+// speedOfLightKMS is the speed of light in km/s.
+const speedOfLightKMS = 299792.458
+
+// Perturber is an additional point mass of the force model, such as an asteroid whose state
+// comes from a SmallBody or a Trajectory.
+type Perturber struct {
+	Source StateSource // Source gives the state of the body relative to the Solar System Barycenter.
+	GM     float64     // GM is the gravitational parameter of the body, in AU^3/day^2.
+}
+
+// ForceModel configures a Propagator. The zero value integrates the Newtonian attraction of
+// the Sun, the planets, and the Moon with the gravitational parameters of the ephemeris file.
+type ForceModel struct {
+	Bodies     []Planet    // Bodies are the attracting kernel bodies (nil for the Sun, Mercury to Pluto, and the Moon).
+	Perturbers []Perturber // Perturbers are additional point masses, e.g. the largest asteroids.
+	Relativity bool        // Relativity adds the Schwarzschild (1PN) acceleration of the Sun.
+	Center     CenterBody  // Center is the body input and output states are relative to (0 for the Solar System Barycenter).
+	Tolerance  float64     // Tolerance is the relative local error tolerance per step (0 for 1e-13).
+	MaxSteps   int         // MaxSteps bounds the number of steps of one propagation (0 for 1000000).
+}
+
+// propagatorBody is an attracting kernel body with its trajectory handle.
+type propagatorBody struct {
+	gm   float64     // gm is the gravitational parameter in AU^3/day^2.
+	traj *Trajectory // traj gives the barycentric state of the body.
+	sun  bool        // sun marks the body the relativistic term is computed for.
+}
+
+// Propagator integrates the motion of a massless body (spacecraft or test particle) under the
+// gravitational attraction of the bodies of the ephemeris with an adaptive Runge-Kutta-Fehlberg
+// 7(8) scheme, so that propagated trajectories are consistent with the DE dynamical model.
+// Only point-mass gravitation is modeled: figure effects, the Earth's tides, and
+// non-gravitational forces are not. A Propagator is not safe for concurrent use.
+type Propagator struct {
+	eph          *Ephemeris       // eph provides the states of the attracting bodies.
+	model        ForceModel       // model is the configuration, with defaults applied.
+	bodies       []propagatorBody // bodies are the attracting kernel bodies.
+	center       *Trajectory      // center gives the barycentric state of model.Center (nil for the barycenter).
+	c2           float64          // c2 is the squared speed of light, in AU^2/day^2.
+	lastStepSize float64          // lastStepSize is the final step size of the last propagation, reused as the next first guess.
+}
+
+// defaultPropagatorBodies are the attracting bodies used when ForceModel.Bodies is nil.
+var defaultPropagatorBodies = []Planet{Sun, Mercury, Venus, Earth, Moon, Mars, Jupiter, Saturn, Uranus, Neptune, Pluto}
+
+// NewPropagator prepares a numerical propagator for the given force model.
+//
+// Parameters:
+//   - model: The force model and integration settings.
+//
+// Returns:
+//   - *Propagator: The propagator on success.
+//   - error: ErrConstantNotFound if the file lacks a needed GM constant, or ErrInvalidIndex
+//     if a body cannot be used.
+func (e *Ephemeris) NewPropagator(model ForceModel) (*Propagator, error) {
+	if model.Bodies == nil {
+		model.Bodies = defaultPropagatorBodies
+	}
+	if model.Center == 0 {
+		model.Center = CenterSolarSystemBarycenter
+	}
+	if model.Tolerance <= 0 {
+		model.Tolerance = 1e-13
+	}
+	if model.MaxSteps <= 0 {
+		model.MaxSteps = 1000000
+	}
+	c := speedOfLightKMS * secondsPerDay / e.ephemData.au
+	p := &Propagator{eph: e, model: model, c2: c * c}
+
+	for _, body := range model.Bodies {
+		gm, err := e.bodyGM(body)
+		if err != nil {
+			return nil, err
+		}
+		traj, err := e.Trajectory(body, CenterSolarSystemBarycenter)
+		if err != nil {
+			return nil, err
+		}
+		p.bodies = append(p.bodies, propagatorBody{gm: gm, traj: traj, sun: body == Sun})
+	}
+	if model.Center != CenterSolarSystemBarycenter {
+		traj, err := e.Trajectory(Planet(model.Center), CenterSolarSystemBarycenter)
+		if err != nil {
+			return nil, err
+		}
+		p.center = traj
+	}
+	return p, nil
+}
+
+// cloneFor returns a propagator with the same force model reading from e, a clone of the
+// ephemeris of p. Perturbers that can be rebound are (see Clone); others are shared.
+func (p *Propagator) cloneFor(e *Ephemeris) (*Propagator, error) {
+	model := p.model
+	model.Perturbers = make([]Perturber, len(p.model.Perturbers))
+	for i, pert := range p.model.Perturbers {
+		src, err := cloneSource(pert.Source, p.eph, e)
+		if err != nil {
+			return nil, err
+		}
+		model.Perturbers[i] = Perturber{Source: src, GM: pert.GM}
+	}
+	q, err := e.NewPropagator(model)
+	if err != nil {
+		return nil, err
+	}
+	q.lastStepSize = p.lastStepSize
+	return q, nil
+}
+
+// GM returns the gravitational parameter of a kernel body from the constants of the file
+// (GMS, GM1, ..., GM9, and GMB split with the Earth-Moon mass ratio), for OrbitalEnergy,
+// LaplaceRungeLenz, and other two-body computations.
+//
+// Parameters:
+//   - body: The Sun, a planet (system), the Earth, the Moon, or the Earth-Moon barycenter.
+//
+// Returns:
+//   - float64: GM in AU^3/day^2.
+//   - error: ErrInvalidIndex for other bodies, or ErrConstantNotFound if the file lacks the constant.
+func (e *Ephemeris) GM(body Planet) (float64, error) {
+	return e.bodyGM(body)
+}
+
+// bodyGM returns the gravitational parameter (AU^3/day^2) of a kernel body from the constants
+// of the ephemeris file. The Earth and Moon are split from GMB with the Earth-Moon mass ratio.
+func (e *Ephemeris) bodyGM(body Planet) (float64, error) {
+	names := map[Planet]string{
+		Sun: "GMS", Mercury: "GM1", Venus: "GM2", Mars: "GM4", Jupiter: "GM5", Saturn: "GM6",
+		Uranus: "GM7", Neptune: "GM8", Pluto: "GM9", Earth: "GMB", Moon: "GMB", EarthMoonBarycenter: "GMB",
+	}
+	name, ok := names[body]
+	if !ok {
+		return 0, fmt.Errorf("%s has no gravitational parameter: %w", body, ErrInvalidIndex)
+	}
+	gm, ok := e.constantByName(name)
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", name, ErrConstantNotFound)
+	}
+	emrat := e.ephemData.emrat
+	switch body {
+	case Earth:
+		gm *= emrat / (1 + emrat)
+	case Moon:
+		gm /= 1 + emrat
+	}
+	return gm, nil
+}
+
+// Propagate integrates a state from et0 to et.
+//
+// Parameters:
+//   - et0: Julian Ephemeris Date of the initial state.
+//   - pos: Initial position relative to the Center of the force model, in AU.
+//   - vel: Initial velocity relative to the Center of the force model, in AU/day.
+//   - et: Julian Ephemeris Date to propagate to (earlier than et0 to propagate backwards).
+//
+// Returns:
+//   - Position: Position at et relative to the Center, in AU.
+//   - Velocity: Velocity at et relative to the Center, in AU/day.
+//   - error: ErrPropagation if the integration fails (step size underflow or MaxSteps
+//     exceeded), or an ephemeris error such as ErrOutsideRange.
+func (p *Propagator) Propagate(et0 float64, pos Position, vel Velocity, et float64) (Position, Velocity, error) {
+	y := [6]float64{pos.X, pos.Y, pos.Z, vel.DX, vel.DY, vel.DZ}
+	if err := p.shiftCenter(et0, &y, 1); err != nil {
+		return Position{}, Velocity{}, err
+	}
+
+	t := et0
+	h := p.lastStepSize
+	if h == 0 {
+		h = 1.0
+	}
+	h = math.Copysign(math.Min(math.Abs(h), math.Abs(et-et0)), et-et0)
+	var k [13][6]float64
+	for steps := 0; t != et; steps++ {
+		if steps == p.model.MaxSteps {
+			return Position{}, Velocity{}, fmt.Errorf("%w: more than %d steps", ErrPropagation, p.model.MaxSteps)
+		}
+		if math.Abs(h) < 1e-10 { // Step size underflow (about 10 microseconds)
+			return Position{}, Velocity{}, fmt.Errorf("%w: step size underflow at JD %f", ErrPropagation, t)
+		}
+		last := math.Abs(h) >= math.Abs(et-t)
+		if last {
+			h = et - t
+		}
+
+		for s := 0; s < 13; s++ {
+			ys := y
+			for j := 0; j < s; j++ {
+				if a := rkf78A[s][j]; a != 0 {
+					for i := range ys {
+						ys[i] += h * a * k[j][i]
+					}
+				}
+			}
+			d, err := p.derivatives(t+rkf78C[s]*h, ys)
+			if err != nil {
+				return Position{}, Velocity{}, err
+			}
+			k[s] = d
+		}
+
+		errNorm := 0.0 // Error relative to the tolerance; the step is accepted when <= 1
+		var yNew [6]float64
+		for i := range y {
+			yNew[i] = y[i]
+			for s := 0; s < 13; s++ {
+				yNew[i] += h * rkf78B[s] * k[s][i]
+			}
+			estimate := math.Abs(h * 41.0 / 840 * (k[0][i] + k[10][i] - k[11][i] - k[12][i]))
+			errNorm = math.Max(errNorm, estimate/(p.model.Tolerance*(1+math.Abs(y[i]))))
+		}
+
+		factor := 4.0
+		if errNorm > 0 {
+			factor = math.Min(4, math.Max(0.1, 0.9*math.Pow(errNorm, -1.0/8)))
+		}
+		if errNorm <= 1 {
+			y = yNew
+			if last {
+				t = et
+			} else {
+				t += h
+				p.lastStepSize = h * factor
+			}
+		}
+		h *= factor
+	}
+
+	if err := p.shiftCenter(et, &y, -1); err != nil {
+		return Position{}, Velocity{}, err
+	}
+	return Position{X: y[0], Y: y[1], Z: y[2]}, Velocity{DX: y[3], DY: y[4], DZ: y[5]}, nil
+}
+
+// shiftCenter adds (sign = 1) or subtracts (sign = -1) the barycentric state of the Center.
+func (p *Propagator) shiftCenter(et float64, y *[6]float64, sign float64) error {
+	if p.center == nil {
+		return nil
+	}
+	cPos, cVel, err := p.center.At(et)
+	if err != nil {
+		return err
+	}
+	for i, v := range [6]float64{cPos.X, cPos.Y, cPos.Z, cVel.DX, cVel.DY, cVel.DZ} {
+		y[i] += sign * v
+	}
+	return nil
+}
+
+// derivatives returns the time derivative of the barycentric state y at et.
+func (p *Propagator) derivatives(et float64, y [6]float64) ([6]float64, error) {
+	d := [6]float64{y[3], y[4], y[5]}
+	for _, b := range p.bodies {
+		bPos, bVel, err := b.traj.At(et)
+		if err != nil {
+			return d, err
+		}
+		r := [3]float64{y[0] - bPos.X, y[1] - bPos.Y, y[2] - bPos.Z}
+		rn := math.Sqrt(r[0]*r[0] + r[1]*r[1] + r[2]*r[2])
+		k := -b.gm / (rn * rn * rn)
+		for i := 0; i < 3; i++ {
+			d[3+i] += k * r[i]
+		}
+		if b.sun && p.model.Relativity { // Schwarzschild term of the Sun
+			v := [3]float64{y[3] - bVel.DX, y[4] - bVel.DY, y[5] - bVel.DZ}
+			v2 := v[0]*v[0] + v[1]*v[1] + v[2]*v[2]
+			rv := r[0]*v[0] + r[1]*v[1] + r[2]*v[2]
+			g := b.gm / (p.c2 * rn * rn * rn)
+			for i := 0; i < 3; i++ {
+				d[3+i] += g * ((4*b.gm/rn-v2)*r[i] + 4*rv*v[i])
+			}
+		}
+	}
+	for _, pert := range p.model.Perturbers {
+		bPos, _, err := pert.Source.StateAt(et)
+		if err != nil {
+			return d, err
+		}
+		r := [3]float64{y[0] - bPos.X, y[1] - bPos.Y, y[2] - bPos.Z}
+		rn := math.Sqrt(r[0]*r[0] + r[1]*r[1] + r[2]*r[2])
+		k := -pert.GM / (rn * rn * rn)
+		for i := 0; i < 3; i++ {
+			d[3+i] += k * r[i]
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,112 @@
+// ./provenance.go
+package jpleph
+
+/*
+Package jpleph provides the provenance of the constants behind derived values.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ConstantSource tells where the value of a constant was read.
+type ConstantSource int
+
+const (
+	SourceHeader     ConstantSource = iota // Fixed field of the header record of the ephemeris file (AU, EMRAT)
+	SourceConstants                        // Named constants of the ephemeris file
+	SourceTextKernel                       // Text kernel loaded with LoadTextConstants
+	SourceOption                           // Value set by an option of the Ephemeris (WithAU)
+)
+
+// String returns the name of the source.
+func (s ConstantSource) String() string {
+	switch s {
+	case SourceHeader:
+		return "header"
+	case SourceConstants:
+		return "constants"
+	case SourceTextKernel:
+		return "text kernel"
+	case SourceOption:
+		return "option"
+	}
+	return fmt.Sprintf("ConstantSource(%d)", int(s))
+}
+
+// ConstantProvenance traces a constant used for a derived value back to the kernel it was
+// read from, for pipelines that must account for every number they publish.
+type ConstantProvenance struct {
+	Name   string         // Constant name (e.g., "GM5", "EMRAT", "AU")
+	Value  float64        // Value as read, before any derivation
+	Source ConstantSource // Where the value was read
+	File   string         // Path of the ephemeris file or text kernel; empty for files opened from memory
+	Index  int            // Position among the named constants of the file (0-based), -1 for other sources
+}
+
+// ConstantProvenance returns where the value that ConstantByName gives for a name comes
+// from: a text kernel loaded with LoadTextConstants, or the named constants of the file.
+//
+// Parameters:
+//   - name: Name of the constant (case-sensitive).
+//
+// Returns:
+//   - ConstantProvenance: The value and its origin.
+//   - error: ErrConstantNotFound if neither the text kernels nor the file define the constant.
+func (e *Ephemeris) ConstantProvenance(name string) (ConstantProvenance, error) {
+	if values, ok := e.constOverlay[name]; ok && len(values) > 0 {
+		return ConstantProvenance{Name: name, Value: values[0], Source: SourceTextKernel, File: e.constOrigin[name], Index: -1}, nil
+	}
+	ephem := e.ephemData
+	for i := 0; i < int(ephem.ncon) && 6*i+6 <= len(ephem.constNames); i++ {
+		if string(bytes.TrimRight(ephem.constNames[6*i:6*i+6], "\x00 ")) == name {
+			return ConstantProvenance{Name: name, Value: getConstant(i, ephem, nil), Source: SourceConstants, File: ephem.filename, Index: i}, nil
+		}
+	}
+	return ConstantProvenance{}, fmt.Errorf("%w: %q", ErrConstantNotFound, name)
+}
+
+// ScalingProvenance returns the constants of the header record that the states are derived
+// with: the AU, which converts the kilometres of the file to the AU of CalculatePV and
+// State, and EMRAT, which splits the Earth-Moon barycenter into the Earth and the Moon.
+// Text kernels do not change them; an AU set with WithAU is reported with SourceOption.
+//
+// Returns:
+//   - []ConstantProvenance: The AU, then EMRAT.
+func (e *Ephemeris) ScalingProvenance() []ConstantProvenance {
+	au := e.headerProvenance("AU")
+	if e.ephemData.au != e.ephemData.headerAU {
+		au = ConstantProvenance{Name: "AU", Value: e.ephemData.au, Source: SourceOption, Index: -1}
+	}
+	return []ConstantProvenance{au, e.headerProvenance("EMRAT")}
+}
+
+// headerProvenance returns the provenance of the AU or EMRAT field of the header record.
+func (e *Ephemeris) headerProvenance(name string) ConstantProvenance {
+	value := e.ephemData.headerAU
+	if name == "EMRAT" {
+		value = e.ephemData.emrat
+	}
+	return ConstantProvenance{Name: name, Value: value, Source: SourceHeader, File: e.ephemData.filename, Index: -1}
+}
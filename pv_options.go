@@ -0,0 +1,76 @@
+// ./pv_options.go
+package jpleph
+
+/*
+Package jpleph provides states in an output frame chosen per call.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "fmt"
+
+// PVOptions selects the output of CalculatePVWith for one call, so that states in several
+// frames (for example ecliptic J2000 and ecliptic of date in one almanac) come from the same
+// Ephemeris.
+type PVOptions struct {
+	Frame     Frame          // Output frame (e.g., FrameEclipticOfDate); empty for the ICRF
+	Velocity  bool           // Whether to compute the velocity
+	FrameRate bool           // Whether the velocity includes the motion of the frame, as in StateTransform
+	Registry  *FrameRegistry // Registry resolving Frame; nil for the builtin frames of the ephemeris
+}
+
+// CalculatePVWith returns the state of a target relative to a center, as CalculatePV does, in
+// the frame given by the options. The velocity is rotated into the frame; with FrameRate, it
+// also includes the motion of the frame (the precession of the frames of date, the rotation
+// of body-fixed frames). Building the builtin registry on each call is cheap; pass one in
+// Registry to reuse it, or to use frames registered by the caller.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target: Target body, as for CalculatePV.
+//   - center: Center body, as for CalculatePV.
+//   - opts: Output frame and whether to compute the velocity.
+//
+// Returns:
+//   - Position: Position in AU in the output frame.
+//   - Velocity: Velocity in AU/day in the output frame, zero unless opts.Velocity is set.
+//   - error: An error from CalculatePV, ErrInvalidIndex for a special quantity with a frame
+//     other than the ICRF, or an error from Rotation (e.g., ErrFrame for an unknown frame).
+func (e *Ephemeris) CalculatePVWith(et float64, target Planet, center CenterBody, opts PVOptions) (Position, Velocity, error) {
+	if opts.Frame == "" || opts.Frame == FrameICRF {
+		return e.CalculatePV(et, target, center, opts.Velocity)
+	}
+	if target >= Nutations && target <= TT_TDB {
+		return Position{}, Velocity{}, fmt.Errorf("%v cannot be rotated into %s: %w", target, opts.Frame, ErrInvalidIndex)
+	}
+	frames := opts.Registry
+	if frames == nil {
+		frames = e.NewFrameRegistry()
+	}
+	pos, vel, err := e.CalculatePV(et, target, center, opts.Velocity)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	if opts.Velocity && opts.FrameRate {
+		return frames.StateTransform(pos, vel, FrameICRF, opts.Frame, et)
+	}
+	return frames.Transform(pos, vel, FrameICRF, opts.Frame, et)
+}
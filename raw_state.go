@@ -0,0 +1,202 @@
+// ./raw_state.go
+package jpleph
+
+/*
+Package jpleph provides typed results for the quantities interpolated by State.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "fmt"
+
+// RawStateRequest selects the quantities interpolated by RawState.
+type RawStateRequest struct {
+	Quantities   []Quantity // Quantities to interpolate; nil for every quantity in the file
+	Velocity     bool       // Whether to interpolate the rates as well
+	Heliocentric bool       // Whether the planets and the Earth-Moon barycenter are relative to the Sun instead of the Solar System Barycenter
+	Kilometers   bool       // Whether the bodies are in km and km/day as stored, instead of divided by the AU
+}
+
+// NutationState is the nutation of the file (IAU 1980 in the JPL kernels) with its rates.
+type NutationState struct {
+	Longitude     float64 // Nutation in longitude (delta psi) in radians
+	Obliquity     float64 // Nutation in obliquity (delta epsilon) in radians
+	LongitudeRate float64 // Rate of Longitude in radians/day
+	ObliquityRate float64 // Rate of Obliquity in radians/day
+}
+
+// LibrationState is the orientation of the lunar mantle as the 3-1-3 Euler angles of the
+// principal axes relative to the ICRF, with their rates.
+type LibrationState struct {
+	Phi       float64 // First rotation about z, in radians
+	Theta     float64 // Rotation about the new x axis, in radians
+	Psi       float64 // Last rotation about the new z axis, in radians
+	PhiRate   float64 // Rate of Phi in radians/day
+	ThetaRate float64 // Rate of Theta in radians/day
+	PsiRate   float64 // Rate of Psi in radians/day
+}
+
+// MantleOmegaState is the angular velocity of the lunar mantle in its principal-axis frame,
+// as held by the DE "t" kernels, with its rate.
+type MantleOmegaState struct {
+	Omega     [3]float64 // Angular velocity components in radians/day
+	OmegaRate [3]float64 // Rate of Omega in radians/day^2
+}
+
+// TTmTDBState is the difference TT - TDB at the geocenter, with its rate.
+type TTmTDBState struct {
+	Seconds float64 // TT - TDB in seconds
+	Rate    float64 // Rate of Seconds in seconds/day
+}
+
+// RawStates are the quantities of a file interpolated at one epoch, each in its own field.
+// Fields of quantities that were not requested are zero; Quantities lists those computed.
+type RawStates struct {
+	ET           float64    // Epoch (TDB Julian Date)
+	Heliocentric bool       // Whether the planets and the Earth-Moon barycenter are relative to the Sun
+	Kilometers   bool       // Whether the bodies are in km and km/day
+	Quantities   []Quantity // Quantities interpolated, in IPT order
+
+	Mercury             StateVector // Mercury, in AU and AU/day (km and km/day with Kilometers)
+	Venus               StateVector // Venus
+	EarthMoonBarycenter StateVector // Earth-Moon barycenter
+	Mars                StateVector // Mars system barycenter
+	Jupiter             StateVector // Jupiter system barycenter
+	Saturn              StateVector // Saturn system barycenter
+	Uranus              StateVector // Uranus system barycenter
+	Neptune             StateVector // Neptune system barycenter
+	Pluto               StateVector // Pluto system barycenter
+	Moon                StateVector // Moon relative to the Earth, as stored in the file
+	Sun                 StateVector // Sun relative to the Solar System Barycenter, even when Heliocentric
+
+	Nutations   NutationState    // Nutation angles
+	Librations  LibrationState   // Lunar mantle Euler angles
+	MantleOmega MantleOmegaState // Lunar mantle angular velocity
+	TTmTDB      TTmTDBState      // TT - TDB
+}
+
+// body returns the field of RawStates holding a body quantity.
+func (r *RawStates) body(q Quantity) *StateVector {
+	return [...]*StateVector{&r.Mercury, &r.Venus, &r.EarthMoonBarycenter, &r.Mars, &r.Jupiter, &r.Saturn,
+		&r.Uranus, &r.Neptune, &r.Pluto, &r.Moon, &r.Sun}[q]
+}
+
+// RawState interpolates the quantities of the file at an epoch, as State does, and returns
+// each in a named field instead of the overloaded pv and nut arrays: the states of the
+// bodies as stored (the Moon geocentric, the others barycentric or heliocentric), and the
+// special quantities in the units of the file. The record is read once for all of them.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - req: Quantities to interpolate, and whether with rates and heliocentric.
+//
+// Returns:
+//   - RawStates: The interpolated quantities.
+//   - error: ErrInvalidIndex for an unknown quantity, ErrQuantityNotInEphemeris for a
+//     requested quantity missing from the file, ErrOutsideRange, or a file access error.
+func (e *Ephemeris) RawState(et float64, req RawStateRequest) (RawStates, error) {
+	e.applyReload()
+	et = e.clampEpoch(et)
+	ephem := e.ephemData
+	quantities := req.Quantities
+	if quantities == nil {
+		for q := QuantityMercury; q <= QuantityTTmTDB; q++ {
+			if ephem.ipt[q][1] != 0 && ephem.ipt[q][2] != 0 {
+				quantities = append(quantities, q)
+			}
+		}
+	}
+	var wanted [QuantityTTmTDB + 1]bool
+	for _, q := range quantities {
+		if _, _, _, err := e.IPT(q); err != nil {
+			return RawStates{}, err
+		}
+		wanted[q] = true
+	}
+
+	flag, bary := 1, 1
+	if req.Velocity {
+		flag = 2
+	}
+	if req.Heliocentric {
+		bary = 0
+	}
+	res := RawStates{ET: et, Heliocentric: req.Heliocentric, Kilometers: req.Kilometers}
+	state := func(s []float64) StateVector {
+		v := StateVector{ET: et, Position: Position{X: s[0], Y: s[1], Z: s[2]}}
+		if req.Velocity {
+			v.Velocity = Velocity{DX: s[3], DY: s[4], DZ: s[5]}
+		}
+		return v
+	}
+
+	// The bodies, from one call, which leaves the Sun in pvsun when it is wanted
+	var list [14]int
+	for q := QuantityMercury; q <= QuantityMoon; q++ {
+		if wanted[q] {
+			list[q] = flag
+		}
+	}
+	sun := 0
+	if wanted[QuantitySun] {
+		sun = flag
+	}
+	var pv [13][6]float64
+	if err := stateSplit(ephem, et, 0, list, &pv, nil, bary, sun, req.Kilometers); err != nil {
+		return RawStates{}, err
+	}
+	for q := QuantityMercury; q <= QuantitySun; q++ {
+		if !wanted[q] {
+			continue
+		}
+		if q == QuantitySun {
+			res.Sun = state(ephem.pvsun[:6])
+		} else {
+			*res.body(q) = state(pv[q][:])
+		}
+		res.Quantities = append(res.Quantities, q)
+	}
+
+	// The special quantities, one call each, since State writes them all to nut
+	for q := QuantityNutations; q <= QuantityTTmTDB; q++ {
+		if !wanted[q] {
+			continue
+		}
+		var list [14]int
+		list[q-1] = flag
+		var nut [6]float64
+		if err := State(ephem, et, list, &pv, nut[:], bary); err != nil {
+			return RawStates{}, fmt.Errorf("%v: %w", q, err)
+		}
+		switch q {
+		case QuantityNutations:
+			res.Nutations = NutationState{Longitude: nut[0], Obliquity: nut[1], LongitudeRate: nut[2], ObliquityRate: nut[3]}
+		case QuantityLibrations:
+			res.Librations = LibrationState{Phi: nut[0], Theta: nut[1], Psi: nut[2], PhiRate: nut[3], ThetaRate: nut[4], PsiRate: nut[5]}
+		case QuantityLunarMantleOmega:
+			res.MantleOmega = MantleOmegaState{Omega: [3]float64{nut[0], nut[1], nut[2]}, OmegaRate: [3]float64{nut[3], nut[4], nut[5]}}
+		case QuantityTTmTDB:
+			res.TTmTDB = TTmTDBState{Seconds: nut[0], Rate: nut[1]}
+		}
+		res.Quantities = append(res.Quantities, q)
+	}
+	return res, nil
+}
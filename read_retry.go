@@ -0,0 +1,127 @@
+// ./read_retry.go
+package jpleph
+
+/*
+Package jpleph provides the retry of failed record reads, for files on network filesystems.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures the retry of record reads by WithReadRetry.
+type RetryPolicy struct {
+	Attempts   int                  // Reads of a record before giving up, the first included (values below 2 disable retries)
+	Backoff    time.Duration        // Wait before the first retry, doubled at each further retry (0: 10 ms)
+	MaxBackoff time.Duration        // Longest wait between two attempts (0: 1 s)
+	Retryable  func(err error) bool // Whether an error of the seek or read is worth retrying (nil: IsTransientReadError)
+}
+
+// WithReadRetry makes the reads of data records retry transient failures, for files on
+// network filesystems (NFS, FUSE mounts of object stores) that occasionally fail a read
+// with EIO. A failed seek or read is retried after a wait that doubles from Backoff up to
+// MaxBackoff, as long as Retryable classifies the error as transient and fewer than
+// Attempts reads were made; the error of the last attempt is then returned, wrapping
+// ErrFileSeek or ErrFileRead. Errors that are not transient, such as the end of a truncated
+// file, fail at once. The waits block the calling goroutine. Clones and reloaded files keep
+// the policy.
+func WithReadRetry(policy RetryPolicy) Option {
+	return func(e *Ephemeris) {
+		if policy.Attempts < 2 {
+			e.ephemData.retry = nil
+			return
+		}
+		if policy.Backoff <= 0 {
+			policy.Backoff = 10 * time.Millisecond
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = time.Second
+		}
+		if policy.Retryable == nil {
+			policy.Retryable = IsTransientReadError
+		}
+		e.ephemData.retry = &policy
+	}
+}
+
+// IsTransientReadError reports whether an error of a file access may succeed when retried:
+// an I/O error (EIO), or an error reporting itself as temporary or as a timeout, as the
+// errno values EINTR, EAGAIN, and ETIMEDOUT and the errors of network file sources do. The
+// end of the file is not transient.
+func IsTransientReadError(err error) bool {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return false
+	}
+	if errors.Is(err, syscall.EIO) {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &temporary) && temporary.Temporary() || errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// readRecord seeks to data record nr and reads it into buf, retrying transient failures
+// under the retry policy of the file.
+//
+// Returns:
+//   - error: ErrFileSeek or ErrFileRead, wrapping the error of the last attempt under a
+//     retry policy, or bare as in the C version without one.
+func readRecord(ephem *jplEphData, nr uint32, buf []float64) error {
+	attempt := func() (sentinel, err error) {
+		if _, err := ephem.ifile.Seek(int64((nr+2)*ephem.recsize), io.SeekStart); err != nil {
+			return ErrFileSeek, err
+		}
+		if err := readFloat64s(ephem.ifile, buf); err != nil {
+			return ErrFileRead, err
+		}
+		return nil, nil
+	}
+
+	policy := ephem.retry
+	sentinel, err := attempt()
+	if err == nil {
+		return nil
+	}
+	if policy == nil {
+		if debugFlag {
+			fmt.Printf("State: Error - %v: %v\n", sentinel, err)
+		}
+		return sentinel
+	}
+	n, wait := 1, policy.Backoff
+	for ; n < policy.Attempts && policy.Retryable(err); n++ {
+		if debugFlag {
+			fmt.Printf("State: Retrying record %d in %v after %v: %v\n", nr, wait, sentinel, err)
+		}
+		time.Sleep(wait)
+		wait = min(2*wait, policy.MaxBackoff)
+		if sentinel, err = attempt(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: record %d, attempt %d: %v", sentinel, nr, n, err)
+}
@@ -0,0 +1,220 @@
+// ./reader.go
+package jpleph
+
+/*
+Package jpleph provides ephemerides read from memory, HTTP range requests, or any io.ReaderAt.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sectionFile adapts a section of an io.ReaderAt to the file interface of the ephemeris data.
+type sectionFile struct {
+	*io.SectionReader
+}
+
+// Close does nothing; the io.ReaderAt belongs to the caller.
+func (sectionFile) Close() error { return nil }
+
+// NewEphemerisFromReader initializes an ephemeris from the contents of a JPL DE binary file
+// available through an io.ReaderAt, e.g. an in-memory buffer or an HTTPReaderAt, for
+// environments without a file system such as js/wasm in browsers. Clones share r, which must
+// therefore allow concurrent ReadAt calls, as the io.ReaderAt contract requires. Reopen and
+// WithFileWatch do not apply, since there is no path to reopen.
+//
+// Parameters:
+//   - r: Source of the file contents.
+//   - size: Size of the file in bytes.
+//   - loadConstants: Whether to load and cache constant names and values (see NewEphemeris).
+//   - opts: Optional settings, such as WithClampToRange.
+//
+// Returns:
+//   - *Ephemeris: The initialized Ephemeris.
+//   - error: An initialization error, as from NewEphemeris.
+func NewEphemerisFromReader(r io.ReaderAt, size int64, loadConstants bool, opts ...Option) (*Ephemeris, error) {
+	open := func() (io.ReadSeekCloser, error) {
+		return sectionFile{io.NewSectionReader(r, 0, size)}, nil
+	}
+	ifile, _ := open()
+	ephemData, err := initEphemerisFile(ifile, "", open, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("initialization failed: %w", err)
+	}
+	return finishEphemeris(ephemData, loadConstants, opts)
+}
+
+// NewEphemerisFromBytes initializes an ephemeris from the contents of a JPL DE binary file in
+// memory, e.g. a file fetched by a browser application into an ArrayBuffer and copied with
+// js.CopyBytesToGo. The slice must not be modified while the Ephemeris is in use.
+func NewEphemerisFromBytes(data []byte, loadConstants bool, opts ...Option) (*Ephemeris, error) {
+	return NewEphemerisFromReader(bytes.NewReader(data), int64(len(data)), loadConstants, opts...)
+}
+
+// httpBlockSize is the size of the blocks HTTPReaderAt fetches and caches. A block holds a
+// few records of the usual DE files, and the header and constants take one or two blocks.
+const httpBlockSize = 64 << 10
+
+// httpCacheBlocks is the number of blocks HTTPReaderAt keeps.
+const httpCacheBlocks = 32
+
+// HTTPReaderAt reads a remote file with HTTP range requests, for NewEphemerisFromReader. In
+// js/wasm programs the requests go through the browser's fetch API, so planetarium
+// applications can compute states client-side while downloading only the records they use.
+// It fetches aligned blocks and keeps the most recently used ones. It is safe for concurrent use.
+type HTTPReaderAt struct {
+	url    string       // URL of the file
+	client *http.Client // Client performing the requests
+	size   int64        // Size of the file in bytes
+
+	mu     sync.Mutex       // Protects blocks and order
+	blocks map[int64][]byte // Cached blocks by block number
+	order  []int64          // Cached block numbers, least recently used first
+}
+
+// NewHTTPReaderAt prepares reading a remote file with range requests. The server must support
+// range requests (and, in browsers, expose the Content-Range header to cross-origin requests).
+//
+// Parameters:
+//   - url: URL of the file.
+//   - client: HTTP client to use; nil uses http.DefaultClient.
+//
+// Returns:
+//   - *HTTPReaderAt: The reader; Size reports the file size.
+//   - error: ErrRangeRequest if the server does not honor range requests, or a request error.
+func NewHTTPReaderAt(url string, client *http.Client) (*HTTPReaderAt, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	h := &HTTPReaderAt{url: url, client: client, blocks: make(map[int64][]byte)}
+	// A one-byte request reveals the size in its Content-Range header ("bytes 0-0/size").
+	_, total, err := h.fetch(0, 1)
+	if err != nil {
+		return nil, err
+	}
+	h.size = total
+	return h, nil
+}
+
+// Size returns the size of the remote file in bytes.
+func (h *HTTPReaderAt) Size() int64 {
+	return h.size
+}
+
+// ReadAt reads len(p) bytes at offset off, fetching uncached blocks.
+func (h *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: negative offset", ErrRangeRequest)
+	}
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= h.size {
+			return n, io.EOF
+		}
+		block, err := h.block(pos / httpBlockSize)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], block[pos%httpBlockSize:])
+	}
+	return n, nil
+}
+
+// block returns a cached or freshly fetched block.
+func (h *HTTPReaderAt) block(number int64) ([]byte, error) {
+	h.mu.Lock()
+	if b, ok := h.blocks[number]; ok {
+		h.touch(number)
+		h.mu.Unlock()
+		return b, nil
+	}
+	h.mu.Unlock()
+
+	start := number * httpBlockSize
+	length := min(int64(httpBlockSize), h.size-start)
+	b, _, err := h.fetch(start, length)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.blocks[number]; !ok {
+		h.blocks[number] = b
+		h.order = append(h.order, number)
+		if len(h.order) > httpCacheBlocks {
+			delete(h.blocks, h.order[0])
+			h.order = h.order[1:]
+		}
+	}
+	return b, nil
+}
+
+// touch marks a cached block as most recently used. The caller holds h.mu.
+func (h *HTTPReaderAt) touch(number int64) {
+	for i, n := range h.order {
+		if n == number {
+			h.order = append(append(h.order[:i:i], h.order[i+1:]...), number)
+			return
+		}
+	}
+}
+
+// fetch requests length bytes at start and returns them with the total size of the file.
+func (h *HTTPReaderAt) fetch(start, length int64) ([]byte, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, start+length-1))
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, 0, fmt.Errorf("%w: %s: status %s", ErrRangeRequest, h.url, resp.Status)
+	}
+	// Content-Range: bytes start-end/total
+	cr := resp.Header.Get("Content-Range")
+	slash := strings.LastIndexByte(cr, '/')
+	total, err := strconv.ParseInt(cr[slash+1:], 10, 64)
+	if slash < 0 || err != nil {
+		return nil, 0, fmt.Errorf("%w: %s: bad Content-Range %q", ErrRangeRequest, h.url, cr)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, length))
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(data)) != length {
+		return nil, 0, fmt.Errorf("%w: %s: short response", ErrRangeRequest, h.url)
+	}
+	return data, total, nil
+}
@@ -0,0 +1,60 @@
+// ./reader_js.go
+
+//go:build js && wasm
+
+package jpleph
+
+/*
+Package jpleph provides ephemerides read from JavaScript buffers in js/wasm programs.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// NewEphemerisFromJS initializes an ephemeris from a JavaScript ArrayBuffer or Uint8Array
+// holding a JPL DE binary file (e.g., the result of fetch(url).then(r => r.arrayBuffer())).
+// The contents are copied into Go memory, so the buffer may be reused afterwards.
+//
+// Parameters:
+//   - buffer: An ArrayBuffer or Uint8Array.
+//   - loadConstants: Whether to load and cache constant names and values (see NewEphemeris).
+//   - opts: Optional settings, such as WithClampToRange.
+//
+// Returns:
+//   - *Ephemeris: The initialized Ephemeris.
+//   - error: ErrInitialization if buffer is neither an ArrayBuffer nor a Uint8Array, or an
+//     initialization error, as from NewEphemeris.
+func NewEphemerisFromJS(buffer js.Value, loadConstants bool, opts ...Option) (*Ephemeris, error) {
+	uint8Array := js.Global().Get("Uint8Array")
+	switch {
+	case buffer.InstanceOf(js.Global().Get("ArrayBuffer")):
+		buffer = uint8Array.New(buffer)
+	case !buffer.InstanceOf(uint8Array):
+		return nil, fmt.Errorf("%w: expected an ArrayBuffer or Uint8Array, got %s", ErrInitialization, buffer.Type())
+	}
+	data := make([]byte, buffer.Get("length").Int())
+	js.CopyBytesToGo(data, buffer)
+	return NewEphemerisFromBytes(data, loadConstants, opts...)
+}
@@ -0,0 +1,133 @@
+// ./record_layout.go
+package jpleph
+
+/*
+Package jpleph provides the verification of the record layout of ephemeris files.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// recordEpochTolerance is how far in days the time span of the first record may differ from
+// that of the header.
+const recordEpochTolerance = 1e-3
+
+// verifyRecordLayout checks the record size computed from the IPT against the file: its size
+// must hold every record of the time span of the header, and the first data record must
+// start at the start epoch and span one step. A mismatch is diagnosed as a truncated file
+// (ErrTruncatedFile), a wrong byte order (ErrByteOrder), or a layout that does not match the
+// header (ErrUnknownLayout), naming the known layout of the DE number when the records follow
+// it, instead of failing later in State.
+func verifyRecordLayout(ephem *jplEphData, ifile io.ReadSeeker) error {
+	if !(ephem.ephemStep > 0) || !(ephem.ephemEnd > ephem.ephemStart) {
+		return fmt.Errorf("%w: time span JD %.1f to %.1f with step %g days", ErrUnknownLayout,
+			ephem.ephemStart, ephem.ephemEnd, ephem.ephemStep)
+	}
+	if ephem.ncoeff <= 2 || ephem.ncon > ephem.ncoeff {
+		return fmt.Errorf("%w: the IPT gives %d doubles per record, too few for %d constants%s", ErrUnknownLayout,
+			ephem.ncoeff, ephem.ncon, knownLayoutHint(ephem.ephemerisVersion))
+	}
+
+	recsize := int64(ephem.recsize)
+	nrec := int64(math.Round((ephem.ephemEnd - ephem.ephemStart) / ephem.ephemStep))
+	first, swapped, err := readRecordSpan(ifile, 2*recsize, ephem.swapBytes != 0)
+	if err != nil {
+		return fmt.Errorf("%w: no data record at byte %d with %d-byte records%s", ErrTruncatedFile,
+			2*recsize, recsize, knownLayoutHint(ephem.ephemerisVersion))
+	}
+	if !ephem.matchesFirstRecord(first) {
+		if ephem.matchesFirstRecord(swapped) {
+			return fmt.Errorf("%w: the first record reads correctly only with the bytes swapped", ErrByteOrder)
+		}
+		for _, n := range knownRecordSizes(ephem.ephemerisVersion) {
+			if n == ephem.ncoeff {
+				continue
+			}
+			span, _, err := readRecordSpan(ifile, 2*8*int64(n), ephem.swapBytes != 0)
+			if err == nil && ephem.matchesFirstRecord(span) {
+				return fmt.Errorf("%w: the IPT gives %d doubles per record, but the records have %d, as in DE%d files",
+					ErrUnknownLayout, ephem.ncoeff, n, ephem.ephemerisVersion)
+			}
+		}
+		return fmt.Errorf("%w: the first record spans JD %g to %g instead of %.1f plus %g days with %d doubles per record%s",
+			ErrUnknownLayout, first[0], first[1], ephem.ephemStart, ephem.ephemStep, ephem.ncoeff,
+			knownLayoutHint(ephem.ephemerisVersion))
+	}
+
+	size, err := ifile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil // Sources without a known size are checked record by record when read
+	}
+	if need := (nrec + 2) * recsize; size < need {
+		have := size/recsize - 2
+		return fmt.Errorf("%w: %d bytes hold %d of the %d records of %d bytes (data end near JD %.1f instead of %.1f)",
+			ErrTruncatedFile, size, have, nrec, recsize, ephem.ephemStart+float64(have)*ephem.ephemStep, ephem.ephemEnd)
+	}
+	return nil
+}
+
+// matchesFirstRecord reports whether span, the two epochs opening a data record, are those
+// of the first record of the file.
+func (ephem *jplEphData) matchesFirstRecord(span [2]float64) bool {
+	return math.Abs(span[0]-ephem.ephemStart) <= recordEpochTolerance &&
+		math.Abs(span[1]-span[0]-ephem.ephemStep) <= recordEpochTolerance
+}
+
+// readRecordSpan reads the two epochs at offset in the byte order of the file, and in the
+// opposite order.
+func readRecordSpan(ifile io.ReadSeeker, offset int64, swap bool) (span, swapped [2]float64, err error) {
+	if _, err = ifile.Seek(offset, io.SeekStart); err != nil {
+		return span, swapped, err
+	}
+	var buf [16]byte
+	if _, err = io.ReadFull(ifile, buf[:]); err != nil {
+		return span, swapped, err
+	}
+	native, other := byteOrder, binary.ByteOrder(binary.BigEndian)
+	if native == binary.BigEndian {
+		other = binary.LittleEndian
+	}
+	if swap {
+		native, other = other, native
+	}
+	for i := range span {
+		span[i] = math.Float64frombits(native.Uint64(buf[8*i:]))
+		swapped[i] = math.Float64frombits(other.Uint64(buf[8*i:]))
+	}
+	return span, swapped, nil
+}
+
+// knownLayoutHint names the record sizes known for a DE number, for error messages. The
+// table (deVersions) only serves the diagnosis of files whose records do not match their
+// header: other layouts, such as those of INPOP or of synthetic files, are accepted when
+// consistent.
+func knownLayoutHint(version uint64) string {
+	if n := knownRecordSizes(version); n != nil {
+		return fmt.Sprintf(" (DE%d files have %v doubles per record)", version, n)
+	}
+	return ""
+}
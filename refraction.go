@@ -0,0 +1,145 @@
+// ./refraction.go
+package jpleph
+
+/*
+Package jpleph provides models of atmospheric refraction for elevations and rise and set times.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "math"
+
+// Refraction is a model of atmospheric refraction: how much higher a body appears than its
+// geometric (airless) elevation. Observatories with their own pressure- and temperature-
+// dependent model implement it, or wrap a function with RefractionFunc.
+type Refraction interface {
+	// Refract returns the refraction in degrees for a geometric elevation in degrees.
+	Refract(elevation float64) float64
+}
+
+// RefractionFunc adapts a function to the Refraction interface.
+type RefractionFunc func(elevation float64) float64
+
+// Refract calls f.
+func (f RefractionFunc) Refract(elevation float64) float64 {
+	return f(elevation)
+}
+
+// NoRefraction is the airless model: apparent and geometric elevations are equal.
+type NoRefraction struct{}
+
+// Refract returns 0.
+func (NoRefraction) Refract(float64) float64 {
+	return 0
+}
+
+// Standard conditions of the refraction formulas.
+const (
+	refractionPressure    = 1010.0 // hPa
+	refractionTemperature = 283.0  // K (10 °C)
+)
+
+// minRefractionElevation is the lowest elevation in degrees at which the formulas are
+// evaluated; below it the refraction at this elevation is kept.
+const minRefractionElevation = -1.0
+
+// refractionScale returns the factor of the refraction at a pressure (hPa) and temperature
+// (K) relative to the standard conditions, zero values standing for them.
+func refractionScale(pressure, temperature float64) float64 {
+	if pressure == 0 {
+		pressure = refractionPressure
+	}
+	if temperature == 0 {
+		temperature = refractionTemperature
+	}
+	return pressure / refractionPressure * refractionTemperature / temperature
+}
+
+// Saemundsson is the refraction formula of Saemundsson (Sky and Telescope, 1986) for
+// geometric elevations, the inverse of Bennett's, accurate to about 0.1 arcminute above the
+// horizon in standard conditions, scaled for pressure and temperature as in Meeus
+// (Astronomical Algorithms, ch. 16).
+type Saemundsson struct {
+	Pressure    float64 // Air pressure at the site in hPa; 0 for 1010
+	Temperature float64 // Air temperature in kelvin; 0 for 283 (10 °C)
+}
+
+// Refract returns the refraction in degrees at a geometric elevation in degrees.
+func (s Saemundsson) Refract(elevation float64) float64 {
+	h := math.Max(elevation, minRefractionElevation)
+	arcmin := 1.02/math.Tan((h+10.3/(h+5.11))*math.Pi/180) + 0.0019279 // Zero at the zenith
+	return arcmin / 60 * refractionScale(s.Pressure, s.Temperature)
+}
+
+// Bennett is the refraction formula of Bennett (Journal of Navigation, 1982), which gives the
+// refraction from the apparent elevation to within 0.07 arcminute, scaled for pressure and
+// temperature as Saemundsson. Refract inverts it for a geometric elevation by iteration.
+type Bennett struct {
+	Pressure    float64 // Air pressure at the site in hPa; 0 for 1010
+	Temperature float64 // Air temperature in kelvin; 0 for 283 (10 °C)
+}
+
+// Refract returns the refraction in degrees at a geometric elevation in degrees.
+func (b Bennett) Refract(elevation float64) float64 {
+	scale := refractionScale(b.Pressure, b.Temperature)
+	apparentRefraction := func(h float64) float64 {
+		h = math.Max(h, minRefractionElevation)
+		return (1/math.Tan((h+7.31/(h+4.4))*math.Pi/180) + 0.0013515) / 60 * scale // Zero at the zenith
+	}
+	r := apparentRefraction(elevation)
+	for i := 0; i < 10; i++ { // The apparent elevation is elevation + r
+		next := apparentRefraction(elevation + r)
+		if math.Abs(next-r) < 1e-12 {
+			return next
+		}
+		r = next
+	}
+	return r
+}
+
+// ApparentElevation returns the elevation in degrees at which a body at a geometric
+// elevation is seen through the atmosphere of a model.
+func ApparentElevation(model Refraction, elevation float64) float64 {
+	return elevation + model.Refract(elevation)
+}
+
+// GeometricElevation returns the geometric elevation in degrees of a body seen at an apparent
+// elevation, the inverse of ApparentElevation. Since refraction depends on the elevation
+// only, a threshold on the apparent elevation is a threshold on the geometric one, as used
+// by RiseSet and ElevationWindows.
+func GeometricElevation(model Refraction, apparent float64) float64 {
+	h := apparent - model.Refract(apparent)
+	for i := 0; i < 20; i++ {
+		next := apparent - model.Refract(h)
+		if math.Abs(next-h) < 1e-12 {
+			return next
+		}
+		h = next
+	}
+	return h
+}
+
+// Apparent returns the sky position as seen through the atmosphere: the elevation raised by
+// the refraction of the model. The vectors and the azimuth are unchanged.
+func (p SkyPosition) Apparent(model Refraction) SkyPosition {
+	p.Elevation = ApparentElevation(model, p.Elevation)
+	return p
+}
@@ -0,0 +1,171 @@
+// ./reload.go
+package jpleph
+
+/*
+Package jpleph provides reopening and hot reloading of replaced ephemeris files.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// reloadedFile is an opened replacement of the ephemeris file, waiting to be swapped in.
+type reloadedFile struct {
+	data        *jplEphData // Freshly initialized ephemeris data
+	constNames  [][]byte    // Constant cache for data, if the Ephemeris caches constants
+	constValues []float64   // Constant values matching constNames
+}
+
+// fileWatch polls the ephemeris file for replacement and stages the new file for swapping.
+type fileWatch struct {
+	pending atomic.Pointer[reloadedFile] // Replacement opened by the watcher, not yet swapped in
+	stop    chan struct{}                // Closed to stop the watcher goroutine
+	done    sync.WaitGroup               // Waits for the watcher goroutine to exit
+}
+
+// WithFileWatch makes the Ephemeris poll its file every interval and reload it when the file
+// is replaced (a different file at the same path, or a new size or modification time), as
+// when a server's DE file is upgraded in place. The replacement is opened in the background
+// and swapped in at the start of the next CalculatePV, so queries in progress finish on the
+// old file; a file that cannot be opened yet (e.g., still being copied) is retried at the
+// next poll. Clones do not watch the file. The watcher stops when the Ephemeris is closed.
+func WithFileWatch(interval time.Duration) Option {
+	return func(e *Ephemeris) {
+		if interval <= 0 || e.watch != nil {
+			return
+		}
+		info, err := os.Stat(e.ephemData.filename)
+		if err != nil {
+			return // Nothing to compare against; the Ephemeris works without watching
+		}
+		w := &fileWatch{stop: make(chan struct{})}
+		e.watch = w
+		loadConstants := e.constNames != nil
+		filename := e.ephemData.filename
+		w.done.Add(1)
+		go func() {
+			defer w.done.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-w.stop:
+					return
+				case <-ticker.C:
+				}
+				current, err := os.Stat(filename)
+				if err != nil || (os.SameFile(info, current) && current.Size() == info.Size() &&
+					current.ModTime().Equal(info.ModTime())) {
+					continue
+				}
+				r, err := openReplacement(filename, loadConstants)
+				if err != nil {
+					continue // Retry at the next poll
+				}
+				info = current
+				if old := w.pending.Swap(r); old != nil {
+					closeEphemeris(old.data) // Superseded before it was used
+				}
+			}
+		}()
+	}
+}
+
+// Reopen reopens the ephemeris file from its path, picking up a replaced file. The current
+// file stays in use if the new one cannot be opened. Like other methods, Reopen must not be
+// called concurrently with queries on the same Ephemeris.
+//
+// Returns:
+//   - error: An initialization error for the new file; the Ephemeris is unchanged in that case.
+func (e *Ephemeris) Reopen() error {
+	r, err := openReplacement(e.ephemData.filename, e.constNames != nil)
+	if err != nil {
+		return fmt.Errorf("reopen failed: %w", err)
+	}
+	if e.watch != nil {
+		if old := e.watch.pending.Swap(nil); old != nil {
+			closeEphemeris(old.data) // Superseded by the explicit reopen
+		}
+	}
+	return e.swapFile(r)
+}
+
+// openReplacement opens an ephemeris file for swapping in.
+func openReplacement(filename string, loadConstants bool) (*reloadedFile, error) {
+	data, err := initEphemeris(filename, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &reloadedFile{data: data}
+	if loadConstants {
+		r.constNames, r.constValues, err = loadConstantCache(data)
+		if err != nil {
+			closeEphemeris(data)
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// applyReload swaps in a replacement staged by the file watcher, if there is one.
+func (e *Ephemeris) applyReload() {
+	if e.watch == nil {
+		return
+	}
+	if r := e.watch.pending.Swap(nil); r != nil {
+		e.swapFile(r) // The old file's close error does not affect the new file
+	}
+}
+
+// swapFile installs a replacement file and closes the previous one.
+func (e *Ephemeris) swapFile(r *reloadedFile) error {
+	old := e.ephemData
+	r.data.iinfo.compensated = old.iinfo.compensated
+	r.data.iinfo.fortranOrder = old.iinfo.fortranOrder
+	r.data.retry = old.retry
+	if old.au != old.headerAU { // Set by WithAU
+		r.data.au = old.au
+	}
+	e.ephemData = r.data
+	if r.constNames != nil {
+		e.constNames, e.constValues = r.constNames, r.constValues
+	}
+	return closeEphemeris(old)
+}
+
+// stopWatch stops the file watcher and releases a replacement that was never swapped in.
+func (e *Ephemeris) stopWatch() {
+	if e.watch == nil {
+		return
+	}
+	close(e.watch.stop)
+	e.watch.done.Wait()
+	if r := e.watch.pending.Swap(nil); r != nil {
+		closeEphemeris(r.data)
+	}
+	e.watch = nil
+}
@@ -0,0 +1,191 @@
+// ./rise_set.go
+package jpleph
+
+/*
+Package jpleph provides rise, set, and twilight times for Earth-based observers.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// Solar depression angles (degrees below the horizon) of the standard twilights, for Twilight.
+const (
+	CivilTwilight        = 6.0
+	NauticalTwilight     = 12.0
+	AstronomicalTwilight = 18.0
+)
+
+// eventTolerance is the time resolution of event searches in days (about 10 ms).
+const eventTolerance = 1e-7
+
+// RiseSetOptions configures a rise/set search.
+type RiseSetOptions struct {
+	Altitude   float64    // Altitude of the body's center at the event in degrees (see StandardAltitude)
+	DeltaT     float64    // TT - UT1 in seconds (about 69 s in 2025; see DeltaT)
+	Step       float64    // Sampling step in days; 0 means 1/24. Events closer together than this can be missed
+	Refraction Refraction // Model making Altitude an apparent altitude (e.g., -0.2667 for the Sun); nil when Altitude includes the refraction
+}
+
+// RiseSetEvent is a crossing of the search altitude by a body.
+type RiseSetEvent struct {
+	ET     float64 // Ephemeris time (TDB) of the crossing as Julian Date
+	Rising bool    // True when the body moves above the altitude (rise, twilight begin), false when it sets
+}
+
+// StandardAltitude returns the conventional altitude of a body's center at rise and set for
+// the topocentric searches of RiseSet, in degrees: 34' of refraction below the horizon, plus
+// the mean semidiameter for the Sun and the Moon.
+func StandardAltitude(body Planet) float64 {
+	switch body {
+	case Sun, Moon:
+		return -0.8333
+	}
+	return -0.5667
+}
+
+// RiseSet finds the times between start and end when a body crosses the given altitude above
+// the site's horizon. Altitudes are topocentric and geometric, with refraction included
+// through opts.Altitude, or through opts.Refraction for the pressure and temperature of the
+// site. Earth orientation comes from IAU 2006 precession and mean sidereal time, which keeps
+// the timing error to about a second.
+//
+// Parameters:
+//   - body: Body to follow (any body accepted by CalculatePV with CenterEarth).
+//   - site: Observer location.
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//   - opts: Event altitude, Earth rotation offset, and sampling step.
+//
+// Returns:
+//   - []RiseSetEvent: The crossings in chronological order.
+//   - error: ErrEventSearch for an invalid interval, step, or site, or an error from CalculatePV.
+func (e *Ephemeris) RiseSet(body Planet, site Site, start, end float64, opts RiseSetOptions) ([]RiseSetEvent, error) {
+	step := opts.Step
+	if step == 0 {
+		step = 1.0 / 24
+	}
+	if !(end > start) || !(step > 0) || !site.valid() {
+		return nil, fmt.Errorf("%w: interval [%f, %f], step %g, site %+v", ErrEventSearch, start, end, step, site)
+	}
+	altitude := opts.Altitude
+	if opts.Refraction != nil {
+		altitude = GeometricElevation(opts.Refraction, altitude)
+	}
+	f := func(et float64) (float64, error) {
+		alt, err := e.altitude(et, body, site, opts.DeltaT)
+		return alt - altitude, err
+	}
+
+	crossings, err := findCrossings(f, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	var events []RiseSetEvent
+	for _, c := range crossings {
+		events = append(events, RiseSetEvent{ET: c.et, Rising: c.rising})
+	}
+	return events, nil
+}
+
+// Twilight finds the beginning (Rising) and end (not Rising) of twilight between start and end:
+// the times when the Sun's center crosses the given depression angle below the horizon.
+//
+// Parameters:
+//   - site: Observer location.
+//   - start, end: Search interval as ephemeris time (TDB) Julian Dates.
+//   - depression: Solar depression angle in degrees, e.g. CivilTwilight, NauticalTwilight, or
+//     AstronomicalTwilight.
+//   - deltaT: TT - UT1 in seconds.
+//
+// Returns:
+//   - []RiseSetEvent: The twilight boundaries in chronological order.
+//   - error: As for RiseSet.
+func (e *Ephemeris) Twilight(site Site, start, end, depression, deltaT float64) ([]RiseSetEvent, error) {
+	return e.RiseSet(Sun, site, start, end, RiseSetOptions{Altitude: -depression, DeltaT: deltaT})
+}
+
+// findCrossing locates a root of f bracketed by [t0, t1] with the Illinois variant of regula falsi.
+func findCrossing(f func(float64) (float64, error), t0, t1, f0, f1 float64) (float64, error) {
+	side := 0
+	for iter := 0; iter < 100 && t1-t0 > eventTolerance; iter++ {
+		t := t1 - f1*(t1-t0)/(f1-f0)
+		if !(t > t0 && t < t1) { // Guard against stagnation at the interval ends
+			t = 0.5 * (t0 + t1)
+		}
+		ft, err := f(t)
+		if err != nil {
+			return 0, err
+		}
+		if (ft < 0) == (f1 < 0) {
+			t1, f1 = t, ft
+			if side == -1 {
+				f0 *= 0.5
+			}
+			side = -1
+		} else {
+			t0, f0 = t, ft
+			if side == 1 {
+				f1 *= 0.5
+			}
+			side = 1
+		}
+		if ft == 0 {
+			return t, nil
+		}
+	}
+	return 0.5 * (t0 + t1), nil
+}
+
+// crossing is a change of sign found by findCrossings.
+type crossing struct {
+	et     float64 // Epoch of the change of sign (TDB Julian Date)
+	rising bool    // Whether f becomes non-negative
+}
+
+// findCrossings samples f at the given step from start to end and locates each change of sign
+// with findCrossing, in chronological order.
+func findCrossings(f func(float64) (float64, error), start, end, step float64) ([]crossing, error) {
+	var crossings []crossing
+	t0 := start
+	f0, err := f(t0)
+	if err != nil {
+		return nil, err
+	}
+	for t0 < end {
+		t1 := math.Min(t0+step, end)
+		f1, err := f(t1)
+		if err != nil {
+			return nil, err
+		}
+		if (f0 < 0) != (f1 < 0) {
+			et, err := findCrossing(f, t0, t1, f0, f1)
+			if err != nil {
+				return nil, err
+			}
+			crossings = append(crossings, crossing{et: et, rising: f1 >= 0})
+		}
+		t0, f0 = t1, f1
+	}
+	return crossings, nil
+}
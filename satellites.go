@@ -0,0 +1,172 @@
+// ./satellites.go
+package jpleph
+
+/*
+Package jpleph provides planetary satellites through SPK satellite kernels.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Planet values from 100 to 999 are NAIF codes of planetary satellites (p01 to p98) and
+// planet centers (p99), served by the SPK kernels loaded with LoadSPK. The planets of the
+// JPL kernel (Mercury to Pluto) are the system barycenters, NAIF codes 1 to 9.
+const (
+	// Phobos represents the Martian satellite Phobos (NAIF 401).
+	Phobos Planet = 401
+	// Deimos represents the Martian satellite Deimos (NAIF 402).
+	Deimos Planet = 402
+	// MarsCenter represents the center of mass of Mars itself (NAIF 499).
+	MarsCenter Planet = 499
+	// Io represents the Jovian satellite Io (NAIF 501).
+	Io Planet = 501
+	// Europa represents the Jovian satellite Europa (NAIF 502).
+	Europa Planet = 502
+	// Ganymede represents the Jovian satellite Ganymede (NAIF 503).
+	Ganymede Planet = 503
+	// Callisto represents the Jovian satellite Callisto (NAIF 504).
+	Callisto Planet = 504
+	// JupiterCenter represents the center of mass of Jupiter itself (NAIF 599).
+	JupiterCenter Planet = 599
+	// Mimas represents the Saturnian satellite Mimas (NAIF 601).
+	Mimas Planet = 601
+	// Enceladus represents the Saturnian satellite Enceladus (NAIF 602).
+	Enceladus Planet = 602
+	// Tethys represents the Saturnian satellite Tethys (NAIF 603).
+	Tethys Planet = 603
+	// Dione represents the Saturnian satellite Dione (NAIF 604).
+	Dione Planet = 604
+	// Rhea represents the Saturnian satellite Rhea (NAIF 605).
+	Rhea Planet = 605
+	// Titan represents the Saturnian satellite Titan (NAIF 606).
+	Titan Planet = 606
+	// Hyperion represents the Saturnian satellite Hyperion (NAIF 607).
+	Hyperion Planet = 607
+	// Iapetus represents the Saturnian satellite Iapetus (NAIF 608).
+	Iapetus Planet = 608
+	// Phoebe represents the Saturnian satellite Phoebe (NAIF 609).
+	Phoebe Planet = 609
+	// SaturnCenter represents the center of mass of Saturn itself (NAIF 699).
+	SaturnCenter Planet = 699
+	// Ariel represents the Uranian satellite Ariel (NAIF 701).
+	Ariel Planet = 701
+	// Umbriel represents the Uranian satellite Umbriel (NAIF 702).
+	Umbriel Planet = 702
+	// Titania represents the Uranian satellite Titania (NAIF 703).
+	Titania Planet = 703
+	// Oberon represents the Uranian satellite Oberon (NAIF 704).
+	Oberon Planet = 704
+	// Miranda represents the Uranian satellite Miranda (NAIF 705).
+	Miranda Planet = 705
+	// UranusCenter represents the center of mass of Uranus itself (NAIF 799).
+	UranusCenter Planet = 799
+	// Triton represents the Neptunian satellite Triton (NAIF 801).
+	Triton Planet = 801
+	// Nereid represents the Neptunian satellite Nereid (NAIF 802).
+	Nereid Planet = 802
+	// NeptuneCenter represents the center of mass of Neptune itself (NAIF 899).
+	NeptuneCenter Planet = 899
+	// Charon represents Pluto's satellite Charon (NAIF 901).
+	Charon Planet = 901
+	// PlutoCenter represents the center of mass of Pluto itself (NAIF 999).
+	PlutoCenter Planet = 999
+)
+
+// spkBodyNames holds the display names of the named SPK bodies.
+var spkBodyNames = map[Planet]string{
+	Phobos: "Phobos", Deimos: "Deimos", MarsCenter: "Mars (planet center)",
+	Io: "Io", Europa: "Europa", Ganymede: "Ganymede", Callisto: "Callisto", JupiterCenter: "Jupiter (planet center)",
+	Mimas: "Mimas", Enceladus: "Enceladus", Tethys: "Tethys", Dione: "Dione", Rhea: "Rhea", Titan: "Titan",
+	Hyperion: "Hyperion", Iapetus: "Iapetus", Phoebe: "Phoebe", SaturnCenter: "Saturn (planet center)",
+	Ariel: "Ariel", Umbriel: "Umbriel", Titania: "Titania", Oberon: "Oberon", Miranda: "Miranda",
+	UranusCenter: "Uranus (planet center)", Triton: "Triton", Nereid: "Nereid", NeptuneCenter: "Neptune (planet center)",
+	Charon: "Charon", PlutoCenter: "Pluto (planet center)",
+}
+
+// maxSPKChain bounds the number of centers (SPK segments, custom and source bodies) chained
+// to reach the Solar System Barycenter.
+const maxSPKChain = 16
+
+// LoadSPK opens an SPK kernel, such as a JPL planetary satellite ephemeris, and makes its
+// satellites and planet centers available as targets and centers of CalculatePV. States are
+// chained through the segment centers automatically: Titan relative to the Earth, for
+// example, goes through the Saturn barycenter of the JPL kernel. Kernels loaded later take
+// precedence where coverage overlaps. The kernel is closed by Close.
+//
+// Parameters:
+//   - filename: Path to the SPK file (e.g., "sat441.bsp").
+//
+// Returns:
+//   - error: ErrSPKFormat if the file is not a usable SPK file, or a file access error.
+func (e *Ephemeris) LoadSPK(filename string) error {
+	k, err := OpenSPK(filename)
+	if err != nil {
+		return err
+	}
+	e.spkKernels = append(e.spkKernels, k)
+	return nil
+}
+
+// isSPKBody reports whether id is in the range of NAIF satellite and planet center codes.
+func isSPKBody(id Planet) bool {
+	return id >= 100 && id < FirstCustomBody
+}
+
+// planetFromNAIF converts the NAIF code of a segment center into a Planet.
+// Barycenters 1-9 are the planets of the JPL kernel, except 3, the Earth-Moon barycenter.
+func planetFromNAIF(naif int) Planet {
+	switch naif {
+	case 0:
+		return SolarSystemBarycenter
+	case 3:
+		return EarthMoonBarycenter
+	case 10:
+		return Sun
+	case 199:
+		return Mercury // Mercury and Venus have no satellites; body and barycenter coincide
+	case 299:
+		return Venus
+	case 301:
+		return Moon
+	case 399:
+		return Earth
+	}
+	return Planet(naif)
+}
+
+// spkState returns the state of an SPK body from the most recently loaded kernel covering et.
+func (e *Ephemeris) spkState(naif int, et float64) ([6]float64, int, error) {
+	err := fmt.Errorf("SPK body %d: %w", naif, ErrInvalidIndex)
+	for i := len(e.spkKernels) - 1; i >= 0; i-- {
+		state, center, kerr := e.spkKernels[i].State(naif, et)
+		if kerr == nil {
+			return state, center, nil
+		}
+		if !errors.Is(kerr, ErrInvalidIndex) {
+			err = kerr // Keep the most informative error (e.g., outside range) seen so far
+		}
+	}
+	return [6]float64{}, 0, err
+}
@@ -0,0 +1,215 @@
+// ./shadow.go
+package jpleph
+
+/*
+Package jpleph provides the shadows of the Earth, the Moon, and the planets on spacecraft and other bodies.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// shadowStep is the default sampling step of ShadowEvents in days (one minute).
+const shadowStep = 1.0 / 1440
+
+// ShadowKind is the illumination of a body by the Sun with respect to an occulting body.
+type ShadowKind int
+
+const (
+	Sunlit   ShadowKind = iota // The whole of the Sun's disk is visible
+	Penumbra                   // Part of the Sun's disk is hidden, including the antumbra beyond the apex of the umbra
+	Umbra                      // The whole of the Sun's disk is hidden
+)
+
+var shadowKindNames = [...]string{Sunlit: "sunlit", Penumbra: "penumbra", Umbra: "umbra"}
+
+// String returns the name of the kind (e.g., "umbra").
+func (k ShadowKind) String() string {
+	if k < Sunlit || k > Umbra {
+		return fmt.Sprintf("ShadowKind(%d)", int(k))
+	}
+	return shadowKindNames[k]
+}
+
+// Shadow is the illumination of a body at one epoch.
+type Shadow struct {
+	Kind     ShadowKind // Deepest shadow the body is in
+	Body     Planet     // Occulting body casting the shadow; zero when Sunlit
+	Fraction float64    // Fraction of the area of the Sun's disk hidden, from 0 when Sunlit to 1 in the Umbra
+}
+
+// ShadowEvent is an entry into or an exit from the penumbra or the umbra of a body.
+type ShadowEvent struct {
+	ET    float64    // Epoch of the event (TDB Julian Date)
+	Body  Planet     // Occulting body
+	Kind  ShadowKind // Penumbra or Umbra
+	Entry bool       // True when the target enters the shadow, false when it leaves it
+}
+
+// shadowGeometry is the Sun and an occulting body seen from the target, as angles in radians.
+type shadowGeometry struct {
+	separation float64 // Separation of the centers
+	sun        float64 // Apparent radius of the Sun
+	body       float64 // Apparent radius of the occulting body
+}
+
+// penumbra is negative while the target is in the penumbra or the umbra.
+func (g shadowGeometry) penumbra() float64 { return g.separation - (g.sun + g.body) }
+
+// umbra is negative while the target is in the umbra.
+func (g shadowGeometry) umbra() float64 { return g.separation - (g.body - g.sun) }
+
+// shadow classifies the geometry.
+func (g shadowGeometry) shadow(body Planet) Shadow {
+	switch {
+	case g.umbra() < 0:
+		return Shadow{Kind: Umbra, Body: body, Fraction: 1}
+	case g.penumbra() < 0:
+		return Shadow{Kind: Penumbra, Body: body, Fraction: diskOverlap(g.sun, g.body, g.separation)}
+	}
+	return Shadow{}
+}
+
+// occultingRadius returns the equatorial radius in km of an occulting body.
+func occultingRadius(target, body Planet) (float64, error) {
+	b := body
+	if b > 100 && b%100 == 99 { // Planet center
+		b /= 100
+	}
+	radii, ok := bodyRadii[b]
+	if !ok || b == Sun || body == target {
+		return 0, fmt.Errorf("shadow of %v on %v: %w", body, target, ErrInvalidIndex)
+	}
+	return radii[0], nil
+}
+
+// shadowGeometry computes the Sun and an occulting body of radius km seen from a target,
+// corrected for light time.
+func (e *Ephemeris) shadowGeometry(et float64, target, body Planet, radius float64) (shadowGeometry, error) {
+	p, _, err := e.CalculatePV(et, target, CenterSolarSystemBarycenter, false)
+	if err != nil {
+		return shadowGeometry{}, err
+	}
+	obs := [3]float64{p.X, p.Y, p.Z}
+	sun, err := e.lightTimePosition(et, Sun, obs)
+	if err != nil {
+		return shadowGeometry{}, err
+	}
+	occ, err := e.lightTimePosition(et, body, obs)
+	if err != nil {
+		return shadowGeometry{}, err
+	}
+	au := e.ephemData.au
+	return shadowGeometry{
+		separation: angleDegrees(sun, occ) * math.Pi / 180,
+		sun:        math.Asin(math.Min(1, bodyRadii[Sun][0]/(norm3(sun)*au))),
+		body:       math.Asin(math.Min(1, radius/(norm3(occ)*au))),
+	}, nil
+}
+
+// ShadowAt returns the illumination of a body by the Sun at an epoch, for the deepest of the
+// shadows of the occulting bodies. The model is the conical one of spacecraft dynamics: the
+// Sun and the occulting bodies are spheres of their equatorial radii, seen from the target
+// with light-time corrected positions, and the hidden fraction is the overlap of their disks.
+// Atmospheres are ignored.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target: Body lit by the Sun, such as a spacecraft registered with AddCustomBody,
+//     CustomBodyFromOEM, or AddSource.
+//   - occulting: Bodies casting the shadows: the Earth, the Moon, planets, or planet centers.
+//
+// Returns:
+//   - Shadow: The deepest shadow, or Sunlit.
+//   - error: ErrInvalidIndex for the Sun or the target among the occulting bodies, or one
+//     without a reference radius, or an error from CalculatePV.
+func (e *Ephemeris) ShadowAt(et float64, target Planet, occulting []Planet) (Shadow, error) {
+	var res Shadow
+	for _, body := range occulting {
+		radius, err := occultingRadius(target, body)
+		if err != nil {
+			return Shadow{}, err
+		}
+		g, err := e.shadowGeometry(et, target, body, radius)
+		if err != nil {
+			return Shadow{}, err
+		}
+		if s := g.shadow(body); s.Fraction > res.Fraction {
+			res = s
+		}
+	}
+	return res, nil
+}
+
+// ShadowEvents finds the entries into and exits from the penumbrae and umbrae of the
+// occulting bodies by a target between search.Start and search.End, with the shadow model
+// of ShadowAt. Each body gives its events independently, so that a target crossing the
+// shadow of the Moon inside that of the Earth sees both. A shadow the target is already in
+// at the start of the search has no entry; use ShadowAt for the state then.
+//
+// Parameters:
+//   - target: Body lit by the Sun, as for ShadowAt.
+//   - occulting: Bodies casting the shadows, as for ShadowAt.
+//   - search: Search interval and sampling step; a Step of 0 means one minute, which suits
+//     low orbits, and search.Above is ignored. Passages through a shadow shorter than the
+//     step can be missed; a penumbra around a sampled umbra is always found.
+//
+// Returns:
+//   - []ShadowEvent: The events in chronological order.
+//   - error: ErrEventSearch for an invalid interval or step, or an error of ShadowAt.
+func (e *Ephemeris) ShadowEvents(target Planet, occulting []Planet, search WindowSearch) ([]ShadowEvent, error) {
+	step := search.Step
+	if step == 0 {
+		step = shadowStep
+	}
+	if !(search.End > search.Start) || !(step > 0) {
+		return nil, fmt.Errorf("%w: interval [%f, %f], step %g", ErrEventSearch, search.Start, search.End, step)
+	}
+	var events []ShadowEvent
+	for _, body := range occulting {
+		radius, err := occultingRadius(target, body)
+		if err != nil {
+			return nil, err
+		}
+		for _, kind := range []ShadowKind{Penumbra, Umbra} {
+			f := func(et float64) (float64, error) {
+				g, err := e.shadowGeometry(et, target, body, radius)
+				if kind == Umbra {
+					return g.umbra(), err
+				}
+				return g.penumbra(), err
+			}
+			crossings, err := findCrossings(f, search.Start, search.End, step)
+			if err != nil {
+				return nil, fmt.Errorf("%v %v: %w", body, kind, err)
+			}
+			for _, c := range crossings {
+				events = append(events, ShadowEvent{ET: c.et, Body: body, Kind: kind, Entry: !c.rising})
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ET < events[j].ET })
+	return events, nil
+}
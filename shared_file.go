@@ -0,0 +1,202 @@
+// ./shared_file.go
+package jpleph
+
+/*
+Package jpleph provides the sharing of one open file among the Ephemeris handles of a path.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileRegistry shares one open file among the Ephemeris handles opened on the same path, as
+// in servers that open the ephemeris per request, so that the handles do not exhaust the file
+// descriptors of the process. Each handle reads the shared file at its own offset with
+// positional reads. A file is closed when its last handle is closed and no handle opens it
+// again within the idle timeout. A FileRegistry is safe for concurrent use.
+type FileRegistry struct {
+	idleClose time.Duration // How long a file without handles stays open
+
+	mu    sync.Mutex             // Protects files and the reference counts
+	files map[string]*sharedFile // Open files by absolute path
+}
+
+// sharedFile is a file of a FileRegistry with the count of its handles.
+type sharedFile struct {
+	registry *FileRegistry // Registry holding the file
+	path     string        // Absolute path of the file, the key in the registry
+	file     *os.File      // Open file
+	info     os.FileInfo   // File information at opening, to detect a replaced file
+	refs     int           // Open handles
+	idle     *time.Timer   // Pending idle close, while refs is 0
+	detached bool          // Whether the file was replaced at its path and left the registry
+}
+
+// sharedHandle is one handle of a shared file, with its own offset.
+type sharedHandle struct {
+	*io.SectionReader
+	shared *sharedFile // File read
+	once   sync.Once   // Releases the file once
+}
+
+// NewFileRegistry returns an empty registry.
+//
+// Parameters:
+//   - idleClose: How long a file stays open after its last handle is closed, for the next
+//     open to reuse; 0 or less closes it at once.
+//
+// Returns:
+//   - *FileRegistry: The registry.
+func NewFileRegistry(idleClose time.Duration) *FileRegistry {
+	return &FileRegistry{idleClose: idleClose, files: make(map[string]*sharedFile)}
+}
+
+// NewEphemeris opens an ephemeris as the package function NewEphemeris does, on the file
+// shared by the handles of the same path. Clones of the Ephemeris share the file as well,
+// while Reopen and WithFileWatch open their replacement outside the registry. A file
+// replaced at its path (a different file, or a new size or modification time) is opened
+// anew for later handles, and the handles of the old file keep reading it.
+//
+// Parameters:
+//   - filename: Path to the JPL DE binary file.
+//   - loadConstants: Whether to load and cache constant names and values.
+//   - opts: Optional settings, such as WithClampToRange.
+//
+// Returns:
+//   - *Ephemeris: The initialized Ephemeris; Close releases its handle of the file.
+//   - error: An initialization error, as from NewEphemeris.
+func (r *FileRegistry) NewEphemeris(filename string, loadConstants bool, opts ...Option) (*Ephemeris, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("initialization failed: %w", initFailed(JPL_INIT_FILE_NOT_FOUND, err))
+	}
+	open := func() (io.ReadSeekCloser, error) {
+		return r.open(path)
+	}
+	ifile, err := open()
+	if err != nil {
+		return nil, fmt.Errorf("initialization failed: %w", initFailed(JPL_INIT_FILE_NOT_FOUND, fmt.Errorf("failed to open ephemeris file: %w", err)))
+	}
+	ephemData, err := initEphemerisFile(ifile, filename, open, nil, nil)
+	if err != nil {
+		ifile.Close()
+		return nil, fmt.Errorf("initialization failed: %w", err)
+	}
+	return finishEphemeris(ephemData, loadConstants, opts)
+}
+
+// OpenFiles returns the number of files the registry holds open, idle files included.
+func (r *FileRegistry) OpenFiles() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.files)
+}
+
+// open returns a new handle of the file at path, opening the file unless the registry holds it.
+func (r *FileRegistry) open(path string) (io.ReadSeekCloser, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.files[path]
+	if s != nil && !(os.SameFile(s.info, info) && s.info.Size() == info.Size() && s.info.ModTime().Equal(info.ModTime())) {
+		r.detach(s) // Replaced: later handles read the new file
+		s = nil
+	}
+	if s == nil {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		if info, err = f.Stat(); err != nil {
+			f.Close()
+			return nil, err
+		}
+		s = &sharedFile{registry: r, path: path, file: f, info: info}
+		r.files[path] = s
+	}
+	if s.idle != nil {
+		s.idle.Stop()
+		s.idle = nil
+	}
+	s.refs++
+	return &sharedHandle{SectionReader: io.NewSectionReader(s.file, 0, s.info.Size()), shared: s}, nil
+}
+
+// detach removes a file from the registry, closing it if it has no handles. The registry
+// must be locked.
+func (r *FileRegistry) detach(s *sharedFile) {
+	delete(r.files, s.path)
+	s.detached = true
+	if s.refs == 0 {
+		if s.idle != nil {
+			s.idle.Stop()
+			s.idle = nil
+		}
+		s.file.Close()
+	}
+}
+
+// release drops a handle of the file, closing the file once it has no handles left: at
+// once if it left the registry or without an idle timeout, else after the timeout.
+func (s *sharedFile) release() error {
+	r := s.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s.refs--
+	if s.refs > 0 {
+		return nil
+	}
+	if s.detached || r.idleClose <= 0 {
+		if !s.detached {
+			delete(r.files, s.path)
+		}
+		return s.file.Close()
+	}
+	var idle *time.Timer
+	idle = time.AfterFunc(r.idleClose, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if s.idle == idle { // Not stopped by a new handle or a detach meanwhile
+			s.idle = nil
+			delete(r.files, s.path)
+			s.file.Close()
+		}
+	})
+	s.idle = idle
+	return nil
+}
+
+// Close releases the handle; further calls do nothing.
+func (h *sharedHandle) Close() error {
+	var err error
+	h.once.Do(func() { err = h.shared.release() })
+	return err
+}
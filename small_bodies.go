@@ -0,0 +1,456 @@
+// ./small_bodies.go
+package jpleph
+
+/*
+Package jpleph provides asteroid and comet positions from osculating orbital elements.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// obliquityJ2000 is the obliquity of the J2000 ecliptic (84381.448 arcseconds) in radians,
+// the reference plane of MPC and JPL SBDB osculating elements. It is the IAU 1976 value that
+// SPICE uses for ECLIPJ2000; the of-date ecliptic frames use obliquityIAU2006 instead.
+const obliquityJ2000 = 84381.448 / 3600.0 * math.Pi / 180.0
+
+// gaussianGM is the square of the Gaussian gravitational constant (AU^3/day^2), used for the
+// Sun when the ephemeris file does not provide GMS.
+const gaussianGM = 0.01720209895 * 0.01720209895
+
+// StateSource is implemented by objects that give the state of one body at any epoch, such as
+// Trajectory and SmallBody, so that callers can treat kernel and approximate bodies alike.
+type StateSource interface {
+	// StateAt returns the position (AU) and velocity (AU/day) at the Julian Ephemeris Date et.
+	StateAt(et float64) (Position, Velocity, error)
+}
+
+// CloneableSource is a StateSource bound to an Ephemeris, such as a Trajectory or a SmallBody,
+// that can be rebound to another handle. Clone rebinds the sources registered with AddSource
+// that implement it (a Trajectory or SmallBody only if made from the ephemeris being cloned),
+// so that clones can be used concurrently.
+type CloneableSource interface {
+	StateSource
+	// CloneFor returns an equivalent source that reads from e and shares no mutable state
+	// with the receiver.
+	CloneFor(e *Ephemeris) (StateSource, error)
+}
+
+// rebindable reports whether a clone of e can rebind src: a Trajectory or SmallBody only if it
+// reads from e itself, any other CloneableSource always.
+func rebindable(src StateSource, e *Ephemeris) bool {
+	switch s := src.(type) {
+	case *Trajectory:
+		return s.eph == e
+	case *SmallBody:
+		return s.eph == e
+	}
+	_, ok := src.(CloneableSource)
+	return ok
+}
+
+// cloneSource returns src rebound to the clone to of from if it is rebindable, and src itself
+// otherwise.
+func cloneSource(src StateSource, from, to *Ephemeris) (StateSource, error) {
+	if !rebindable(src, from) {
+		return src, nil
+	}
+	return src.(CloneableSource).CloneFor(to)
+}
+
+// CloneFor returns a trajectory of the same target and center reading from e, which makes
+// Trajectory a CloneableSource.
+func (tr *Trajectory) CloneFor(e *Ephemeris) (StateSource, error) {
+	return e.Trajectory(tr.target, tr.center)
+}
+
+// StateAt returns the state of the target relative to the center at et. It is equivalent to
+// At and makes Trajectory a StateSource.
+func (tr *Trajectory) StateAt(et float64) (Position, Velocity, error) {
+	return tr.At(et)
+}
+
+// OrbitalElements holds heliocentric osculating elements referred to the J2000 ecliptic and
+// equinox. Elliptic, parabolic, and hyperbolic orbits are represented alike through the
+// perihelion distance and time.
+type OrbitalElements struct {
+	Designation          string  // Designation is the name, number, or provisional designation of the object.
+	Epoch                float64 // Epoch is the osculation epoch (Julian Ephemeris Date, TDB).
+	PerihelionDistance   float64 // PerihelionDistance is q, in AU.
+	Eccentricity         float64 // Eccentricity is e.
+	Inclination          float64 // Inclination is i, in degrees.
+	LongitudeOfNode      float64 // LongitudeOfNode is the longitude of the ascending node, in degrees.
+	ArgumentOfPerihelion float64 // ArgumentOfPerihelion is the argument of perihelion, in degrees.
+	PerihelionTime       float64 // PerihelionTime is the time of perihelion passage (Julian Ephemeris Date, TDB).
+	H                    float64 // H is the absolute magnitude (NaN if unknown).
+	G                    float64 // G is the slope parameter (NaN if unknown).
+}
+
+// perihelionTimeFromMeanAnomaly returns the time of perihelion of an elliptic orbit from its
+// mean anomaly (degrees) at epoch and its semi-major axis (AU).
+func perihelionTimeFromMeanAnomaly(epoch, meanAnomaly, a, mu float64) float64 {
+	n := math.Sqrt(mu / (a * a * a)) // Mean motion, radians per day
+	return epoch - math.Remainder(meanAnomaly*math.Pi/180, 2*math.Pi)/n
+}
+
+// ParseMPCORB reads orbital elements in the fixed-column format of the MPC's MPCORB.DAT
+// (also used by the distant and NEA extracts). Header lines up to the dashed separator and
+// blank lines are skipped.
+//
+// Parameters:
+//   - r: Source of the MPCORB text.
+//
+// Returns:
+//   - []OrbitalElements: The elements, in file order.
+//   - error: ErrElementsFormat for a malformed line, or the error returned by r.
+func ParseMPCORB(r io.Reader) ([]OrbitalElements, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "-----") { // End of the header: discard what came before
+			lines = lines[:0]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var elements []OrbitalElements
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		el, err := parseMPCORBLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("MPCORB line %d: %w", i+1, err)
+		}
+		elements = append(elements, el)
+	}
+	return elements, nil
+}
+
+// parseMPCORBLine decodes one MPCORB record.
+func parseMPCORBLine(line string) (OrbitalElements, error) {
+	if len(line) < 103 {
+		return OrbitalElements{}, fmt.Errorf("%w: record is %d columns long", ErrElementsFormat, len(line))
+	}
+	field := func(first, last int) string { // 1-based, inclusive columns as in the MPC documentation
+		return strings.TrimSpace(line[first-1 : min(last, len(line))])
+	}
+	number := func(first, last int, name string) (float64, error) {
+		v, err := strconv.ParseFloat(field(first, last), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s %q", ErrElementsFormat, name, field(first, last))
+		}
+		return v, nil
+	}
+
+	el := OrbitalElements{Designation: field(1, 7), H: math.NaN(), G: math.NaN()}
+	if len(line) >= 167 && field(167, 194) != "" {
+		el.Designation = field(167, 194)
+	}
+	if v, err := strconv.ParseFloat(field(9, 13), 64); err == nil {
+		el.H = v
+	}
+	if v, err := strconv.ParseFloat(field(15, 19), 64); err == nil {
+		el.G = v
+	}
+	epoch, err := unpackMPCEpoch(field(21, 25))
+	if err != nil {
+		return OrbitalElements{}, err
+	}
+	el.Epoch = epoch
+
+	var m, a float64
+	values := []struct {
+		first, last int
+		name        string
+		dst         *float64
+	}{
+		{27, 35, "mean anomaly", &m}, {38, 46, "argument of perihelion", &el.ArgumentOfPerihelion},
+		{49, 57, "longitude of node", &el.LongitudeOfNode}, {60, 68, "inclination", &el.Inclination},
+		{71, 79, "eccentricity", &el.Eccentricity}, {93, 103, "semi-major axis", &a},
+	}
+	for _, v := range values {
+		if *v.dst, err = number(v.first, v.last, v.name); err != nil {
+			return OrbitalElements{}, err
+		}
+	}
+	if !(a > 0) || el.Eccentricity < 0 || el.Eccentricity >= 1 {
+		return OrbitalElements{}, fmt.Errorf("%w: a = %g, e = %g is not an elliptic orbit", ErrElementsFormat, a, el.Eccentricity)
+	}
+	el.PerihelionDistance = a * (1 - el.Eccentricity)
+	el.PerihelionTime = perihelionTimeFromMeanAnomaly(el.Epoch, m, a, gaussianGM)
+	return el, nil
+}
+
+// unpackMPCEpoch decodes a packed MPC epoch (e.g., "K24AH" for 2024 October 17.0 TT) into a
+// Julian Ephemeris Date.
+func unpackMPCEpoch(packed string) (float64, error) {
+	const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUV" // Month and day digits: 1-9, then A = 10 to V = 31
+	if len(packed) != 5 || !strings.Contains("IJK", packed[:1]) {
+		return 0, fmt.Errorf("%w: packed epoch %q", ErrElementsFormat, packed)
+	}
+	year, err := strconv.Atoi(packed[1:3])
+	month, day := strings.IndexByte(digits, packed[3]), strings.IndexByte(digits, packed[4])
+	if err != nil || month < 1 || month > 12 || day < 1 {
+		return 0, fmt.Errorf("%w: packed epoch %q", ErrElementsFormat, packed)
+	}
+	year += 1800 + 100*int(packed[0]-'I')
+	jd := julianDateFromTime(time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC))
+	return jd + tdbMinusTT(jd)/secondsPerDay, nil // MPC epochs are in TT
+}
+
+// sbdbResponse is the subset of the JSON returned by the JPL SBDB API (sbdb.api) that holds the orbit.
+type sbdbResponse struct {
+	Object struct {
+		Fullname string `json:"fullname"`
+	} `json:"object"`
+	Orbit struct {
+		Epoch    json.RawMessage `json:"epoch"`
+		Elements []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"elements"`
+	} `json:"orbit"`
+	PhysPar []struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	} `json:"phys_par"`
+}
+
+// sbdbNumber decodes an SBDB value, which the API encodes as a JSON string or number.
+func sbdbNumber(raw json.RawMessage) (float64, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		s = string(raw)
+	}
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+// ReadSBDB reads the orbital elements of one object from a JPL Small-Body Database API
+// response (https://ssd-api.jpl.nasa.gov/sbdb.api). Comets and hyperbolic objects are supported.
+//
+// Parameters:
+//   - r: Source of the JSON response.
+//
+// Returns:
+//   - OrbitalElements: The elements of the object.
+//   - error: ErrElementsFormat if the response lacks a usable orbit, or a JSON decoding error.
+func ReadSBDB(r io.Reader) (OrbitalElements, error) {
+	var resp sbdbResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return OrbitalElements{}, fmt.Errorf("%w: %v", ErrElementsFormat, err)
+	}
+	values := make(map[string]float64)
+	for _, e := range resp.Orbit.Elements {
+		if v, err := sbdbNumber(e.Value); err == nil {
+			values[e.Name] = v
+		}
+	}
+	epoch, err := sbdbNumber(resp.Orbit.Epoch)
+	if err != nil {
+		return OrbitalElements{}, fmt.Errorf("%w: missing orbit epoch", ErrElementsFormat)
+	}
+	el := OrbitalElements{Designation: strings.TrimSpace(resp.Object.Fullname), Epoch: epoch, H: math.NaN(), G: math.NaN()}
+	for _, name := range []string{"e", "i", "om", "w"} {
+		if _, ok := values[name]; !ok {
+			return OrbitalElements{}, fmt.Errorf("%w: missing element %q", ErrElementsFormat, name)
+		}
+	}
+	el.Eccentricity, el.Inclination = values["e"], values["i"]
+	el.LongitudeOfNode, el.ArgumentOfPerihelion = values["om"], values["w"]
+
+	q, hasQ := values["q"]
+	if a, ok := values["a"]; !hasQ && ok {
+		q, hasQ = a*(1-el.Eccentricity), true
+	}
+	if !hasQ {
+		return OrbitalElements{}, fmt.Errorf("%w: missing perihelion distance", ErrElementsFormat)
+	}
+	el.PerihelionDistance = q
+	if tp, ok := values["tp"]; ok {
+		el.PerihelionTime = tp
+	} else if ma, ok := values["ma"]; ok && el.Eccentricity < 1 {
+		el.PerihelionTime = perihelionTimeFromMeanAnomaly(epoch, ma, q/(1-el.Eccentricity), gaussianGM)
+	} else {
+		return OrbitalElements{}, fmt.Errorf("%w: missing perihelion time", ErrElementsFormat)
+	}
+	for _, p := range resp.PhysPar {
+		if v, err := sbdbNumber(p.Value); err == nil && (p.Name == "H" || p.Name == "G") {
+			if p.Name == "H" {
+				el.H = v
+			} else {
+				el.G = v
+			}
+		}
+	}
+	return el, nil
+}
+
+// SmallBody gives approximate positions of an asteroid or comet from its osculating elements,
+// either by two-body propagation about the Sun or by numerical integration with a Propagator
+// in the force model of the ephemeris. It implements StateSource. A SmallBody is not
+// safe for concurrent use.
+type SmallBody struct {
+	Elements OrbitalElements // Elements are the osculating elements the body was created from.
+	eph      *Ephemeris      // eph provides the planetary states and the Sun-center offset.
+	center   CenterBody      // center is the body StateAt is relative to.
+	mu       float64         // mu is the gravitational parameter of the Sun (AU^3/day^2).
+	r0, v0   [3]float64      // r0 and v0 are the heliocentric equatorial state at the epoch.
+	prop     *Propagator     // prop integrates the heliocentric state (nil for two-body).
+	lastET   float64         // lastET is the epoch of the last integrated state.
+	lastPos  Position        // lastPos is the last integrated position.
+	lastVel  Velocity        // lastVel is the last integrated velocity.
+}
+
+// NewSmallBody prepares the propagation of a small body from its osculating elements.
+// The gravitational parameters of the Sun and planets are taken from the constants of the
+// ephemeris file (GMS, GM1, ..., GM9, GMB); perturbed propagation uses the default ForceModel.
+//
+// Parameters:
+//   - el: Osculating elements, for example from ParseMPCORB or ReadSBDB.
+//   - center: Body the states returned by StateAt are relative to (e.g., CenterSun, CenterEarth).
+//   - perturbed: Integrate the attraction of the Sun, planets, and Moon; otherwise propagate a two-body orbit.
+//
+// Returns:
+//   - *SmallBody: The small body on success.
+//   - error: ErrElementsFormat for invalid elements, ErrConstantNotFound if GM constants are
+//     missing for a perturbed propagation, or ErrKepler if the epoch state cannot be computed.
+func (e *Ephemeris) NewSmallBody(el OrbitalElements, center CenterBody, perturbed bool) (*SmallBody, error) {
+	if !(el.PerihelionDistance > 0) || el.Eccentricity < 0 {
+		return nil, fmt.Errorf("%w: q = %g, e = %g", ErrElementsFormat, el.PerihelionDistance, el.Eccentricity)
+	}
+	sb := &SmallBody{Elements: el, eph: e, center: center, mu: gaussianGM}
+	if gm, ok := e.constantByName("GMS"); ok {
+		sb.mu = gm
+	}
+	if perturbed {
+		prop, err := e.NewPropagator(ForceModel{Center: CenterSun})
+		if err != nil {
+			return nil, fmt.Errorf("perturbed propagation: %w", err)
+		}
+		sb.prop = prop
+	}
+
+	// State at perihelion in the ecliptic frame, rotated to the equator and advanced to the epoch.
+	deg := math.Pi / 180
+	sO, cO := math.Sincos(el.LongitudeOfNode * deg)
+	si, ci := math.Sincos(el.Inclination * deg)
+	sw, cw := math.Sincos(el.ArgumentOfPerihelion * deg)
+	p := [3]float64{cw*cO - sw*sO*ci, cw*sO + sw*cO*ci, sw * si}   // Unit vector towards perihelion
+	q := [3]float64{-sw*cO - cw*sO*ci, -sw*sO + cw*cO*ci, cw * si} // Unit vector 90 degrees ahead in the orbit
+	vp := math.Sqrt(sb.mu * (1 + el.Eccentricity) / el.PerihelionDistance)
+	var rPeri, vPeri [3]float64
+	for i := range rPeri {
+		rPeri[i], vPeri[i] = el.PerihelionDistance*p[i], vp*q[i]
+	}
+	r0, v0, err := keplerPropagate(eclipticToEquatorial(rPeri), eclipticToEquatorial(vPeri), sb.mu, el.Epoch-el.PerihelionTime)
+	if err != nil {
+		return nil, fmt.Errorf("small body %s: %w", el.Designation, err)
+	}
+	sb.r0, sb.v0 = r0, v0
+	sb.lastET = el.Epoch
+	sb.lastPos, sb.lastVel = Position{X: r0[0], Y: r0[1], Z: r0[2]}, Velocity{DX: v0[0], DY: v0[1], DZ: v0[2]}
+	return sb, nil
+}
+
+// StateAt returns the position and velocity of the small body relative to its center at et,
+// in the ICRF-aligned equatorial frame of the ephemeris.
+//
+// Parameters:
+//   - et: Julian Ephemeris Date.
+//
+// Returns:
+//   - Position: Position in AU.
+//   - Velocity: Velocity in AU/day.
+//   - error: ErrKepler if the two-body propagation fails, ErrPropagation if the integration fails,
+//     or an ephemeris error (e.g., ErrOutsideRange when the planets are not available between
+//     the epoch and et).
+func (sb *SmallBody) StateAt(et float64) (Position, Velocity, error) {
+	var pos Position
+	var vel Velocity
+	if sb.prop == nil {
+		r, v, err := keplerPropagate(sb.r0, sb.v0, sb.mu, et-sb.Elements.Epoch)
+		if err != nil {
+			return Position{}, Velocity{}, fmt.Errorf("small body %s: %w", sb.Elements.Designation, err)
+		}
+		pos, vel = Position{X: r[0], Y: r[1], Z: r[2]}, Velocity{DX: v[0], DY: v[1], DZ: v[2]}
+	} else {
+		// Continue from the last integrated state when it lies between the epoch and et.
+		t0, pos0, vel0 := sb.Elements.Epoch, sb.lastPos, sb.lastVel
+		if (sb.lastET-t0)*(et-t0) > 0 && math.Abs(et-t0) >= math.Abs(sb.lastET-t0) {
+			t0 = sb.lastET
+		} else {
+			pos0 = Position{X: sb.r0[0], Y: sb.r0[1], Z: sb.r0[2]}
+			vel0 = Velocity{DX: sb.v0[0], DY: sb.v0[1], DZ: sb.v0[2]}
+		}
+		var err error
+		if pos, vel, err = sb.prop.Propagate(t0, pos0, vel0, et); err != nil {
+			return Position{}, Velocity{}, err
+		}
+		sb.lastET, sb.lastPos, sb.lastVel = et, pos, vel
+	}
+
+	if sb.center != CenterSun {
+		sPos, sVel, err := sb.eph.CalculatePV(et, Sun, sb.center, true)
+		if err != nil {
+			return Position{}, Velocity{}, err
+		}
+		pos = Position{X: pos.X + sPos.X, Y: pos.Y + sPos.Y, Z: pos.Z + sPos.Z}
+		vel = Velocity{DX: vel.DX + sVel.DX, DY: vel.DY + sVel.DY, DZ: vel.DZ + sVel.DZ}
+	}
+	return pos, vel, nil
+}
+
+// CloneFor returns a copy of the small body reading the planets from e, with its own
+// propagator, which makes SmallBody a CloneableSource.
+func (sb *SmallBody) CloneFor(e *Ephemeris) (StateSource, error) {
+	copied := *sb
+	copied.eph = e
+	if sb.prop != nil {
+		prop, err := sb.prop.cloneFor(e)
+		if err != nil {
+			return nil, err
+		}
+		copied.prop = prop
+	}
+	return &copied, nil
+}
+
+// eclipticToEquatorial rotates a vector from the J2000 ecliptic to the ICRF-aligned equator.
+func eclipticToEquatorial(x [3]float64) [3]float64 {
+	s, c := math.Sincos(obliquityJ2000)
+	return [3]float64{x[0], c*x[1] - s*x[2], s*x[1] + c*x[2]}
+}
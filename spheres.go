@@ -0,0 +1,108 @@
+// ./spheres.go
+package jpleph
+
+/*
+Package jpleph provides the Hill sphere and sphere-of-influence radii of the bodies.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// primaryOf returns the body about which a body orbits, for the Hill sphere and sphere of
+// influence: the Earth for the Moon, the Sun for the planets and the Earth-Moon barycenter.
+func primaryOf(body Planet) (Planet, CenterBody, error) {
+	switch body {
+	case Moon:
+		return Earth, CenterEarth, nil
+	case Mercury, Venus, Earth, Mars, Jupiter, Saturn, Uranus, Neptune, Pluto, EarthMoonBarycenter:
+		return Sun, CenterSun, nil
+	}
+	return 0, 0, fmt.Errorf("%s does not orbit a kernel body: %w", body, ErrInvalidIndex)
+}
+
+// massRatioAndDistance returns the ratio of the GM of a body to that of its primary, and
+// their distance at an epoch in AU.
+func (e *Ephemeris) massRatioAndDistance(et float64, body Planet) (float64, float64, error) {
+	primary, center, err := primaryOf(body)
+	if err != nil {
+		return 0, 0, err
+	}
+	gm, err := e.bodyGM(body)
+	if err != nil {
+		return 0, 0, err
+	}
+	gmPrimary, err := e.bodyGM(primary)
+	if err != nil {
+		return 0, 0, err
+	}
+	pos, _, err := e.CalculatePV(et, body, center, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	return gm / gmPrimary, math.Sqrt(pos.X*pos.X + pos.Y*pos.Y + pos.Z*pos.Z), nil
+}
+
+// HillRadius returns the radius of the Hill sphere of a body at an epoch, r (m/3M)^(1/3),
+// where r is its instantaneous distance from its primary and m/M their mass ratio from the
+// GM constants of the file. Within it, the body dominates the attraction of its primary
+// and can hold satellites; stable orbits lie within about half of it.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - body: A planet (system), the Earth, or the Earth-Moon barycenter, orbiting the Sun;
+//     or the Moon, orbiting the Earth.
+//
+// Returns:
+//   - float64: The radius in AU.
+//   - error: ErrInvalidIndex for the Sun and other bodies without a primary,
+//     ErrConstantNotFound if the file lacks a GM constant, or an error from CalculatePV.
+func (e *Ephemeris) HillRadius(et float64, body Planet) (float64, error) {
+	ratio, r, err := e.massRatioAndDistance(et, body)
+	if err != nil {
+		return 0, err
+	}
+	return r * math.Cbrt(ratio/3), nil
+}
+
+// SOIRadius returns the radius of the sphere of influence (Laplace) of a body at an epoch,
+// r (m/M)^(2/5), with r and m/M as for HillRadius. It bounds the region where the motion of
+// a spacecraft is better described relative to the body than to its primary, and is where
+// patched-conic trajectories switch centers.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date; the distance, and therefore the radius,
+//     varies along the eccentric orbit of the body.
+//   - body: As for HillRadius.
+//
+// Returns:
+//   - float64: The radius in AU.
+//   - error: As for HillRadius.
+func (e *Ephemeris) SOIRadius(et float64, body Planet) (float64, error) {
+	ratio, r, err := e.massRatioAndDistance(et, body)
+	if err != nil {
+		return 0, err
+	}
+	return r * math.Pow(ratio, 0.4), nil
+}
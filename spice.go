@@ -0,0 +1,302 @@
+// ./spice.go
+package jpleph
+
+/*
+Package jpleph provides SPICE-style state functions (spkezr and spkpos analogs).
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// spiceBodyCodes maps the normalized SPICE names of the bodies known to this package to
+// their NAIF codes. Satellite names are added from spkBodyNames.
+var spiceBodyCodes = map[string]int{
+	"SOLAR SYSTEM BARYCENTER": 0, "SSB": 0, "SOLAR_SYSTEM_BARYCENTER": 0,
+	"MERCURY BARYCENTER": 1, "VENUS BARYCENTER": 2,
+	"EARTH BARYCENTER": 3, "EMB": 3, "EARTH MOON BARYCENTER": 3, "EARTH-MOON BARYCENTER": 3,
+	"MARS BARYCENTER": 4, "JUPITER BARYCENTER": 5, "SATURN BARYCENTER": 6, "URANUS BARYCENTER": 7,
+	"NEPTUNE BARYCENTER": 8, "PLUTO BARYCENTER": 9, "SUN": 10,
+	"MERCURY": 199, "VENUS": 299, "MOON": 301, "EARTH": 399,
+	"MARS": 499, "JUPITER": 599, "SATURN": 699, "URANUS": 799, "NEPTUNE": 899, "PLUTO": 999,
+}
+
+func init() {
+	for body, name := range spkBodyNames {
+		if !strings.Contains(name, "(") { // Planet centers already have their SPICE names
+			spiceBodyCodes[strings.ToUpper(name)] = int(body)
+		}
+	}
+}
+
+// BodN2C translates a SPICE body name (e.g., "EARTH", "Mars Barycenter", "TITAN") or a
+// NAIF code in decimal (e.g., "399") to its NAIF code, like bodn2c_c in SPICE. Names are
+// case-insensitive and runs of blanks count as one blank.
+//
+// Parameters:
+//   - name: Body name or code.
+//
+// Returns:
+//   - int: NAIF code of the body.
+//   - bool: Whether the name is known.
+func BodN2C(name string) (int, bool) {
+	key := strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	if code, ok := spiceBodyCodes[key]; ok {
+		return code, true
+	}
+	code, err := strconv.Atoi(key)
+	return code, err == nil
+}
+
+// spiceCorrection describes a parsed SPICE aberration correction string.
+type spiceCorrection struct {
+	lightTime     bool    // Whether light time is corrected for
+	converged     bool    // Whether the light-time equation is iterated to convergence (CN) or solved once (LT)
+	stellar       bool    // Whether stellar aberration is corrected for
+	transmission  float64 // -1 for reception (light arriving at the observer at et), +1 for transmission (X prefix)
+	tolerance     float64 // Convergence tolerance of the light time in seconds (0: 1e-15 of the light time)
+	maxIterations int     // Iterations of the converged solution (0: defaultLightTimeIterations)
+}
+
+// parseSPICECorrection parses an aberration correction: "NONE", "LT", "LT+S", "CN", "CN+S",
+// or one of the transmission variants "XLT", "XLT+S", "XCN", "XCN+S".
+func parseSPICECorrection(abcorr string) (spiceCorrection, error) {
+	key := strings.ToUpper(strings.ReplaceAll(abcorr, " ", ""))
+	c := spiceCorrection{transmission: -1}
+	if key == "NONE" {
+		return c, nil
+	}
+	if strings.HasPrefix(key, "X") {
+		c.transmission = 1
+		key = key[1:]
+	}
+	if strings.HasSuffix(key, "+S") {
+		c.stellar = true
+		key = strings.TrimSuffix(key, "+S")
+	}
+	switch key {
+	case "LT":
+		c.lightTime = true
+	case "CN":
+		c.lightTime, c.converged = true, true
+	default:
+		return spiceCorrection{}, fmt.Errorf("%w: aberration correction %q", ErrSPICEName, abcorr)
+	}
+	return c, nil
+}
+
+// spiceFrame returns the rotation from the ICRF to a SPICE inertial frame: "J2000" (taken as
+// the ICRF, as the DE files are), "ECLIPJ2000", or "GALACTIC".
+func spiceFrame(ref string) (linalg.Mat3, error) {
+	switch strings.ToUpper(strings.TrimSpace(ref)) {
+	case "J2000", "ICRF":
+		return linalg.Identity(), nil
+	case "ECLIPJ2000":
+		return linalg.RotX(obliquityJ2000), nil
+	case "GALACTIC":
+		return icrfToGalactic, nil
+	}
+	return linalg.Mat3{}, fmt.Errorf("%w: frame %q", ErrSPICEName, ref)
+}
+
+// spiceBody translates a SPICE body name to a Planet.
+func spiceBody(name string) (Planet, error) {
+	code, ok := BodN2C(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: body %q", ErrSPICEName, name)
+	}
+	return planetFromNAIF(code), nil
+}
+
+// SpkEzr returns the state of a target relative to an observer, like spkezr_c in SPICE, so
+// that SPICE-based code can be ported with few changes. As in SPICE, et is in TDB seconds
+// past J2000, states are in km and km/s, and the velocity is the time derivative of the
+// corrected position. Bodies beyond the JPL kernel (satellites, planet centers such as
+// "MARS" = 499) require a kernel loaded with LoadSPK; without one, use the barycenters
+// ("MARS BARYCENTER"), as with a DE kernel in SPICE.
+//
+// Parameters:
+//   - target: SPICE name or NAIF code of the target (see BodN2C).
+//   - et: Ephemeris time in TDB seconds past J2000.
+//   - ref: Reference frame: "J2000", "ECLIPJ2000", or "GALACTIC".
+//   - abcorr: Aberration correction: "NONE", "LT", "LT+S", "CN", "CN+S", or a transmission variant ("XLT", ...).
+//   - observer: SPICE name or NAIF code of the observer.
+//
+// Returns:
+//   - [6]float64: Position (km) and velocity (km/s) of the target relative to the observer.
+//   - float64: One-way light time between the observer and the target in seconds.
+//   - error: ErrSPICEName for an unknown name, ErrInvalidIndex if the target and observer
+//     coincide, or an error from CalculatePV.
+func (e *Ephemeris) SpkEzr(target string, et float64, ref, abcorr, observer string) ([6]float64, float64, error) {
+	return e.spiceState(target, et, ref, abcorr, observer, true)
+}
+
+// SpkPos returns the position of a target relative to an observer, like spkpos_c in SPICE.
+// It takes the arguments of SpkEzr and skips the velocity computations.
+//
+// Returns:
+//   - [3]float64: Position of the target relative to the observer in km.
+//   - float64: One-way light time between the observer and the target in seconds.
+//   - error: As for SpkEzr.
+func (e *Ephemeris) SpkPos(target string, et float64, ref, abcorr, observer string) ([3]float64, float64, error) {
+	state, lt, err := e.spiceState(target, et, ref, abcorr, observer, false)
+	return [3]float64{state[0], state[1], state[2]}, lt, err
+}
+
+// spiceState parses the arguments of SpkEzr and SpkPos and computes the rotated state.
+func (e *Ephemeris) spiceState(target string, et float64, ref, abcorr, observer string, calcVelocity bool) ([6]float64, float64, error) {
+	t, err := spiceBody(target)
+	if err != nil {
+		return [6]float64{}, 0, err
+	}
+	o, err := spiceBody(observer)
+	if err != nil {
+		return [6]float64{}, 0, err
+	}
+	if t == o {
+		return [6]float64{}, 0, fmt.Errorf("target and observer are both %v: %w", t, ErrInvalidIndex)
+	}
+	rot, err := spiceFrame(ref)
+	if err != nil {
+		return [6]float64{}, 0, err
+	}
+	corr, err := parseSPICECorrection(abcorr)
+	if err != nil {
+		return [6]float64{}, 0, err
+	}
+
+	pos, vel, lt, err := e.correctedState(et, t, o, corr, calcVelocity)
+	if err != nil {
+		return [6]float64{}, 0, err
+	}
+	if calcVelocity && corr.stellar {
+		// The derivative of the aberration correction, by central differences of the corrected position.
+		const h = 100.0 // seconds; shorter steps suffer from the rounding of Julian Dates
+		before, _, _, err := e.correctedState(et-h, t, o, corr, false)
+		if err != nil {
+			return [6]float64{}, 0, err
+		}
+		after, _, _, err := e.correctedState(et+h, t, o, corr, false)
+		if err != nil {
+			return [6]float64{}, 0, err
+		}
+		for i := range vel {
+			vel[i] = (after[i] - before[i]) / (2 * h)
+		}
+	}
+	p, v := rot.Apply(pos), rot.Apply(vel)
+	return [6]float64{p[0], p[1], p[2], v[0], v[1], v[2]}, lt, nil
+}
+
+// correctedState returns the position (km) and velocity (km/s) of the target relative to the
+// observer at et (TDB seconds past J2000) in the ICRF with the given corrections, and the
+// light time in seconds. The velocity accounts for the rate of change of the light time,
+// but not for that of the stellar aberration correction.
+func (e *Ephemeris) correctedState(et float64, target, observer Planet, corr spiceCorrection, calcVelocity bool) ([3]float64, [3]float64, float64, error) {
+	sol, err := e.solveLightTime(et, target, observer, corr, calcVelocity)
+	return sol.pos, sol.vel, sol.lt, err
+}
+
+// lightTimeSolution is the result of solveLightTime.
+type lightTimeSolution struct {
+	pos, vel   [3]float64 // State of the target relative to the observer in km and km/s
+	geoPos     [3]float64 // Geometric position of the target relative to the observer at et, in km
+	geoVel     [3]float64 // Geometric velocity in km/s
+	lt         float64    // One-way light time in seconds
+	iterations int        // Light-time iterations done
+	converged  bool       // Whether the light time met the tolerance (or was not iterated)
+}
+
+// solveLightTime is correctedState, also reporting how the light-time iteration went.
+func (e *Ephemeris) solveLightTime(et float64, target, observer Planet, corr spiceCorrection, calcVelocity bool) (lightTimeSolution, error) {
+	km := e.ephemData.au
+	kms := km / secondsPerDay
+	state := func(jd float64, body Planet, withVelocity bool) ([3]float64, [3]float64, error) {
+		p, v, err := e.CalculatePV(jd, body, CenterSolarSystemBarycenter, withVelocity)
+		return [3]float64{p.X * km, p.Y * km, p.Z * km}, [3]float64{v.DX * kms, v.DY * kms, v.DZ * kms}, err
+	}
+
+	jd := J2000 + et/secondsPerDay
+	obsPos, obsVel, err := state(jd, observer, calcVelocity || corr.stellar) // Stellar aberration needs the observer velocity
+	if err != nil {
+		return lightTimeSolution{}, err
+	}
+	tgtPos, tgtVel, err := state(jd, target, calcVelocity)
+	if err != nil {
+		return lightTimeSolution{}, err
+	}
+	rel := [3]float64{tgtPos[0] - obsPos[0], tgtPos[1] - obsPos[1], tgtPos[2] - obsPos[2]}
+	lt := norm3(rel) / speedOfLightKMS
+	geoPos, geoVel := rel, [3]float64{tgtVel[0] - obsVel[0], tgtVel[1] - obsVel[1], tgtVel[2] - obsVel[2]}
+	if !corr.lightTime {
+		return lightTimeSolution{pos: rel, vel: geoVel, geoPos: geoPos, geoVel: geoVel, lt: lt, converged: true}, nil
+	}
+
+	iterations, converged := 1, !corr.converged // One pass of LT is a complete solution
+	if corr.converged {
+		iterations = corr.maxIterations
+		if iterations <= 0 {
+			iterations = defaultLightTimeIterations
+		}
+	}
+	done := 0
+	for done < iterations {
+		done++
+		tgtPos, tgtVel, err = state(jd+corr.transmission*lt/secondsPerDay, target, calcVelocity)
+		if err != nil {
+			return lightTimeSolution{}, err
+		}
+		rel = [3]float64{tgtPos[0] - obsPos[0], tgtPos[1] - obsPos[1], tgtPos[2] - obsPos[2]}
+		prev := lt
+		lt = norm3(rel) / speedOfLightKMS
+		tol := corr.tolerance
+		if tol <= 0 {
+			tol = 1e-15 * lt
+		}
+		if math.Abs(lt-prev) <= tol {
+			converged = true
+			break
+		}
+	}
+
+	var vel [3]float64
+	if calcVelocity {
+		// d(lt)/dt from lt = |target(t + s*lt) - observer(t)| / c, with s the sign of the correction.
+		r := norm3(rel)
+		relVel := [3]float64{tgtVel[0] - obsVel[0], tgtVel[1] - obsVel[1], tgtVel[2] - obsVel[2]}
+		dlt := (rel[0]*relVel[0] + rel[1]*relVel[1] + rel[2]*relVel[2]) / (r * speedOfLightKMS) /
+			(1 - corr.transmission*(rel[0]*tgtVel[0]+rel[1]*tgtVel[1]+rel[2]*tgtVel[2])/(r*speedOfLightKMS))
+		f := 1 + corr.transmission*dlt
+		vel = [3]float64{tgtVel[0]*f - obsVel[0], tgtVel[1]*f - obsVel[1], tgtVel[2]*f - obsVel[2]}
+	}
+	if corr.stellar {
+		s := -corr.transmission // Transmission aberrates toward the opposite of the observer velocity
+		rel = ApplyAberration(rel, [3]float64{s * obsVel[0], s * obsVel[1], s * obsVel[2]})
+	}
+	return lightTimeSolution{pos: rel, vel: vel, geoPos: geoPos, geoVel: geoVel, lt: lt, iterations: done, converged: converged}, nil
+}
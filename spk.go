@@ -0,0 +1,343 @@
+// ./spk.go
+package jpleph
+
+/*
+Package jpleph provides a reader for Chebyshev segments of NAIF SPK files.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// SPK file structure notes:
+//
+// An SPK file is a NAIF Double precision Array File (DAF) made of 1024-byte records,
+// numbered from 1. Addresses of double precision words are also 1-based.
+//
+// File record (record 1):
+//
+//	Bytes 0-7:   Identification word "DAF/SPK " (or "NAIF/DAF" for old files)
+//	Bytes 8-11:  ND, number of double components of a segment summary (2 for SPK)
+//	Bytes 12-15: NI, number of integer components of a segment summary (6 for SPK)
+//	Bytes 76-79: FWARD, record number of the first summary record
+//	Bytes 88-95: Binary format, "LTL-IEEE" or "BIG-IEEE"
+//
+// Summary records hold NEXT, PREV, and NSUM (as doubles), followed by NSUM summaries of
+// ND doubles and NI 32-bit integers each. An SPK summary is: start and end of coverage
+// (TDB seconds past J2000), then target, center, frame, data type, and the first and last
+// addresses of the segment data. Each summary record is followed by a name record.
+//
+// Data types 2 (Chebyshev position) and 3 (Chebyshev position and velocity) store
+// fixed-length records of MID, RADIUS and the coefficients of x, y, z (and vx, vy, vz
+// for type 3), in km and km/s. The last four doubles of the segment are INIT, INTLEN,
+// RSIZE, and N: the start of the first record, the record length in seconds, the record
+// size in doubles, and the number of records.
+
+// spkRecordSize is the size in bytes of a DAF record.
+const spkRecordSize = 1024
+
+// spkFrameJ2000 is the NAIF code of the J2000 (ICRF-aligned) reference frame.
+const spkFrameJ2000 = 1
+
+// spkSegment describes one type 2 or type 3 segment of an SPK file.
+type spkSegment struct {
+	target   int       // target is the NAIF code of the body the segment gives the state of.
+	center   int       // center is the NAIF code of the body the state is relative to.
+	dataType int       // dataType is the SPK data type (2 or 3).
+	start    float64   // start is the beginning of the coverage, in TDB seconds past J2000.
+	end      float64   // end is the end of the coverage, in TDB seconds past J2000.
+	begin    int64     // begin is the address of the first double of the segment data.
+	init     float64   // init is the start of the first record, in TDB seconds past J2000.
+	intlen   float64   // intlen is the length of each record, in seconds.
+	rsize    int       // rsize is the number of doubles per record.
+	n        int       // n is the number of records.
+	cached   int       // cached is the index of the record held in record, or -1.
+	record   []float64 // record holds the last record read from the segment.
+}
+
+// SPKKernel is an open NAIF SPK file holding Chebyshev (type 2 and 3) segments, such as
+// the JPL planetary satellite ephemerides (jup365.bsp, sat441.bsp, ura111.bsp, ...).
+// Segments of other data types or in frames other than J2000 are ignored.
+// An SPKKernel is not safe for concurrent use.
+type SPKKernel struct {
+	filename string           // filename is the path the kernel was opened from.
+	file     *os.File         // file is the open kernel file.
+	order    binary.ByteOrder // order is the byte order of the binary data.
+	segments []*spkSegment    // segments are the usable segments, in file order.
+}
+
+// OpenSPK opens an SPK file and reads its segment summaries.
+//
+// Parameters:
+//   - filename: Path to the SPK file.
+//
+// Returns:
+//   - *SPKKernel: The open kernel on success.
+//   - error: ErrSPKFormat if the file is not a usable SPK file, or a file access error.
+func OpenSPK(filename string) (*SPKKernel, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SPK file: %w", err)
+	}
+	k := &SPKKernel{filename: filename, file: file}
+	if err := k.readSummaries(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return k, nil
+}
+
+// Close closes the SPK file.
+func (k *SPKKernel) Close() error {
+	return k.file.Close()
+}
+
+// Bodies returns the NAIF codes of the bodies with at least one usable segment, in ascending order.
+func (k *SPKKernel) Bodies() []int {
+	seen := make(map[int]bool)
+	var bodies []int
+	for _, seg := range k.segments {
+		if !seen[seg.target] {
+			seen[seg.target] = true
+			bodies = append(bodies, seg.target)
+		}
+	}
+	sort.Ints(bodies)
+	return bodies
+}
+
+// Span returns the first and last Julian Ephemeris Dates covered by the segments of a body.
+// Gaps between segments are not reported.
+//
+// Parameters:
+//   - naif: NAIF code of the body.
+//
+// Returns:
+//   - float64: First covered Julian Ephemeris Date.
+//   - float64: Last covered Julian Ephemeris Date.
+//   - bool: false if the kernel has no segment for the body.
+func (k *SPKKernel) Span(naif int) (float64, float64, bool) {
+	start, end, found := math.Inf(1), math.Inf(-1), false
+	for _, seg := range k.segments {
+		if seg.target == naif {
+			start, end, found = math.Min(start, seg.start), math.Max(end, seg.end), true
+		}
+	}
+	if !found {
+		return 0, 0, false
+	}
+	return J2000 + start/secondsPerDay, J2000 + end/secondsPerDay, true
+}
+
+// State returns the state of a body relative to the center of the segment covering et.
+// When several segments cover the epoch, the last one in the file takes precedence.
+//
+// Parameters:
+//   - naif: NAIF code of the body.
+//   - et: Julian Ephemeris Date.
+//
+// Returns:
+//   - [6]float64: Position (km) and velocity (km/s) relative to the segment center.
+//   - int: NAIF code of the segment center.
+//   - error: ErrInvalidIndex if the kernel has no segment for the body, ErrOutsideRange if no
+//     segment covers et, ErrSPKFormat if a record is malformed, or ErrFileRead.
+func (k *SPKKernel) State(naif int, et float64) ([6]float64, int, error) {
+	sec := (et - J2000) * secondsPerDay
+	found := false
+	for i := len(k.segments) - 1; i >= 0; i-- {
+		seg := k.segments[i]
+		if seg.target != naif {
+			continue
+		}
+		found = true
+		if sec < seg.start || sec > seg.end {
+			continue
+		}
+		state, err := k.evaluate(seg, sec)
+		return state, seg.center, err
+	}
+	if found {
+		return [6]float64{}, 0, fmt.Errorf("SPK body %d at JD %f: %w", naif, et, ErrOutsideRange)
+	}
+	return [6]float64{}, 0, fmt.Errorf("SPK body %d: %w", naif, ErrInvalidIndex)
+}
+
+// clone reopens the kernel with its own file handle and record caches.
+func (k *SPKKernel) clone() (*SPKKernel, error) {
+	file, err := os.Open(k.filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SPK file: %w", err)
+	}
+	c := &SPKKernel{filename: k.filename, file: file, order: k.order}
+	for _, seg := range k.segments {
+		copied := *seg
+		copied.cached, copied.record = -1, nil
+		c.segments = append(c.segments, &copied)
+	}
+	return c, nil
+}
+
+// evaluate interpolates a segment at sec (TDB seconds past J2000).
+func (k *SPKKernel) evaluate(seg *spkSegment, sec float64) ([6]float64, error) {
+	idx := int(math.Floor((sec - seg.init) / seg.intlen))
+	if idx >= seg.n { // The end of the coverage belongs to the last record
+		idx = seg.n - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx != seg.cached {
+		if err := k.readDoubles(seg.begin+int64(idx*seg.rsize), &seg.record, seg.rsize); err != nil {
+			return [6]float64{}, err
+		}
+		seg.cached = idx
+	}
+
+	mid, radius := seg.record[0], seg.record[1]
+	if !(radius > 0) {
+		return [6]float64{}, fmt.Errorf("%w: record %d has radius %g", ErrSPKFormat, idx, radius)
+	}
+	var state [6]float64
+	switch seg.dataType {
+	case 2:
+		ncoef := (seg.rsize - 2) / 3
+		for c := 0; c < 3; c++ {
+			coeffs := seg.record[2+c*ncoef : 2+(c+1)*ncoef]
+			state[c], state[c+3] = EvalChebyshev(coeffs, mid-radius, mid+radius, sec)
+		}
+	case 3:
+		ncoef := (seg.rsize - 2) / 6
+		for c := 0; c < 6; c++ {
+			coeffs := seg.record[2+c*ncoef : 2+(c+1)*ncoef]
+			state[c], _ = EvalChebyshev(coeffs, mid-radius, mid+radius, sec)
+		}
+	}
+	return state, nil
+}
+
+// readSummaries parses the file record and the summary records of the kernel.
+func (k *SPKKernel) readSummaries() error {
+	head := make([]byte, spkRecordSize)
+	if _, err := k.file.ReadAt(head, 0); err != nil {
+		return fmt.Errorf("%w: cannot read file record: %v", ErrSPKFormat, err)
+	}
+	if id := string(head[0:8]); id != "DAF/SPK " && id != "NAIF/DAF" {
+		return fmt.Errorf("%w: identification word %q", ErrSPKFormat, id)
+	}
+	switch string(head[88:96]) {
+	case "LTL-IEEE":
+		k.order = binary.LittleEndian
+	case "BIG-IEEE":
+		k.order = binary.BigEndian
+	default: // Old files lack the format string; ND is always 2 for SPK
+		k.order = binary.LittleEndian
+		if binary.LittleEndian.Uint32(head[8:12]) != 2 {
+			k.order = binary.BigEndian
+		}
+	}
+	nd, ni := int(int32(k.order.Uint32(head[8:12]))), int(int32(k.order.Uint32(head[12:16])))
+	if nd != 2 || ni != 6 {
+		return fmt.Errorf("%w: ND = %d, NI = %d", ErrSPKFormat, nd, ni)
+	}
+	summarySize := nd + (ni+1)/2 // Size of one summary in doubles
+
+	buf := make([]byte, spkRecordSize)
+	visited := make(map[int64]bool)
+	for rec := int64(int32(k.order.Uint32(head[76:80]))); rec > 0; {
+		if visited[rec] {
+			return fmt.Errorf("%w: summary records form a loop", ErrSPKFormat)
+		}
+		visited[rec] = true
+		if _, err := k.file.ReadAt(buf, (rec-1)*spkRecordSize); err != nil {
+			return fmt.Errorf("%w: cannot read summary record %d: %v", ErrSPKFormat, rec, err)
+		}
+		next := math.Float64frombits(k.order.Uint64(buf[0:8]))
+		nsum := int(math.Float64frombits(k.order.Uint64(buf[16:24])))
+		if nsum < 0 || 3+nsum*summarySize > spkRecordSize/8 {
+			return fmt.Errorf("%w: summary record %d holds %d summaries", ErrSPKFormat, rec, nsum)
+		}
+		for i := 0; i < nsum; i++ {
+			s := buf[(3+i*summarySize)*8:]
+			ints := func(j int) int { return int(int32(k.order.Uint32(s[16+4*j:]))) }
+			seg := &spkSegment{
+				start:    math.Float64frombits(k.order.Uint64(s[0:8])),
+				end:      math.Float64frombits(k.order.Uint64(s[8:16])),
+				target:   ints(0),
+				center:   ints(1),
+				dataType: ints(3),
+				begin:    int64(ints(4)),
+				cached:   -1,
+			}
+			if ints(2) != spkFrameJ2000 || (seg.dataType != 2 && seg.dataType != 3) {
+				continue // Unsupported frame or data type
+			}
+			if err := k.readDirectory(seg, int64(ints(5))); err != nil {
+				return err
+			}
+			k.segments = append(k.segments, seg)
+		}
+		rec = int64(next)
+	}
+	if len(k.segments) == 0 {
+		return fmt.Errorf("%w: no type 2 or 3 segments in the J2000 frame", ErrSPKFormat)
+	}
+	return nil
+}
+
+// readDirectory reads the trailing INIT, INTLEN, RSIZE, N words of a segment ending at address last.
+func (k *SPKKernel) readDirectory(seg *spkSegment, last int64) error {
+	var dir []float64
+	if err := k.readDoubles(last-3, &dir, 4); err != nil {
+		return err
+	}
+	seg.init, seg.intlen, seg.rsize, seg.n = dir[0], dir[1], int(dir[2]), int(dir[3])
+	components := 3
+	if seg.dataType == 3 {
+		components = 6
+	}
+	if !(seg.intlen > 0) || seg.n < 1 || seg.rsize < 2+components || (seg.rsize-2)%components != 0 ||
+		seg.begin+int64(seg.n*seg.rsize) > last-3 {
+		return fmt.Errorf("%w: segment of body %d has an invalid directory", ErrSPKFormat, seg.target)
+	}
+	return nil
+}
+
+// readDoubles reads n doubles starting at the 1-based double precision address addr into *dst.
+func (k *SPKKernel) readDoubles(addr int64, dst *[]float64, n int) error {
+	raw := make([]byte, 8*n)
+	if _, err := k.file.ReadAt(raw, (addr-1)*8); err != nil {
+		return fmt.Errorf("%w: SPK address %d: %v", ErrFileRead, addr, err)
+	}
+	if cap(*dst) < n {
+		*dst = make([]float64, n)
+	}
+	*dst = (*dst)[:n]
+	for i := range *dst {
+		(*dst)[i] = math.Float64frombits(k.order.Uint64(raw[8*i:]))
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+// ./table.go
+package jpleph
+
+/*
+Package jpleph provides generation of ephemeris tables and their export to CSV, JSON lines, and Parquet.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// TableQuantity selects a group of columns of a generated table.
+type TableQuantity int
+
+const (
+	// TablePosition adds the position components x, y, z.
+	TablePosition TableQuantity = iota
+	// TableVelocity adds the velocity components vx, vy, vz.
+	TableVelocity
+	// TableDistance adds the distance r from the center.
+	TableDistance
+	// TableRangeRate adds the rate of change of the distance, rdot.
+	TableRangeRate
+	// TableLonLat adds the longitude and latitude (degrees) in the table frame.
+	TableLonLat
+)
+
+// TableFrame selects the reference frame of the vector columns of a generated table.
+type TableFrame int
+
+const (
+	// TableICRF is the frame of the ephemeris file (ICRF/J2000 equator).
+	TableICRF TableFrame = iota
+	// TableEclipticJ2000 is the mean ecliptic and equinox of J2000.0.
+	TableEclipticJ2000
+	// TableEclipticOfDate is the mean ecliptic and equinox of date.
+	TableEclipticOfDate
+	// TableGalactic is the galactic frame (see ToGalactic).
+	TableGalactic
+	// TableInvariable is the invariable plane of the solar system (see ToInvariablePlane).
+	TableInvariable
+)
+
+// TableUnits selects the distance and velocity units of a generated table.
+type TableUnits int
+
+const (
+	// TableAU gives distances in AU and velocities in AU/day, as CalculatePV does.
+	TableAU TableUnits = iota
+	// TableKmPerSecond gives distances in km and velocities in km/s.
+	TableKmPerSecond
+	// TableKmPerDay gives distances in km and velocities in km/day.
+	TableKmPerDay
+)
+
+// TableSpec describes a table of states: every body at every epoch from Start to End.
+type TableSpec struct {
+	Bodies     []Planet        // Target bodies, one row per body and epoch
+	Center     CenterBody      // Center of the states
+	Start, End float64         // Time span (TDB Julian Dates), End included if on the step grid
+	Step       float64         // Spacing of the epochs in days
+	Quantities []TableQuantity // Column groups, in order; empty means TablePosition and TableVelocity
+	Frame      TableFrame      // Frame of the vector columns
+	Units      TableUnits      // Units of the distance and velocity columns
+}
+
+// TableColumn names a value column of a Table and its unit.
+type TableColumn struct {
+	Name string // Column name (e.g., "x", "vx", "r")
+	Unit string // Unit of the column (e.g., "km", "km/s", "deg")
+}
+
+// TableRow is one row of a Table.
+type TableRow struct {
+	ET     float64   // Epoch (TDB Julian Date)
+	Body   Planet    // Target body
+	Values []float64 // Values, one per Table column
+}
+
+// Table is a generated table of states. Every row has the columns jd_tdb and body followed
+// by the value columns.
+type Table struct {
+	Columns []TableColumn // Value columns
+	Rows    []TableRow    // Rows ordered by epoch, then by body in TableSpec order
+	Names   Localization  // Names of the body column when written (see LookupLocalization); nil for English
+}
+
+// GenerateTable computes a table of states, e.g. to feed analysis pipelines through
+// WriteCSV, WriteJSONLines, or WriteParquet.
+//
+// Parameters:
+//   - spec: Bodies, center, time span, step, columns, frame, and units of the table.
+//
+// Returns:
+//   - *Table: The generated table.
+//   - error: ErrInvalidIndex for an unknown quantity, frame, or unit or a special quantity
+//     as a body, ErrEventSearch for an empty span or a non-positive step, or an error from
+//     CalculatePV.
+func (e *Ephemeris) GenerateTable(spec TableSpec) (*Table, error) {
+	if !(spec.Step > 0) || !(spec.End >= spec.Start) {
+		return nil, fmt.Errorf("%w: table span %v to %v with step %v", ErrEventSearch, spec.Start, spec.End, spec.Step)
+	}
+	if spec.Frame < TableICRF || spec.Frame > TableInvariable {
+		return nil, fmt.Errorf("table frame %d: %w", spec.Frame, ErrInvalidIndex)
+	}
+	if spec.Units < TableAU || spec.Units > TableKmPerDay {
+		return nil, fmt.Errorf("table units %d: %w", spec.Units, ErrInvalidIndex)
+	}
+	for _, body := range spec.Bodies {
+		if body >= Nutations && body <= TT_TDB {
+			return nil, fmt.Errorf("%v is not a body: %w", body, ErrInvalidIndex)
+		}
+	}
+	quantities := spec.Quantities
+	if len(quantities) == 0 {
+		quantities = []TableQuantity{TablePosition, TableVelocity}
+	}
+
+	// Unit conversions from AU and AU/day.
+	distUnit, velUnit := "au", "au/d"
+	distScale, velScale := 1.0, 1.0
+	if spec.Units != TableAU {
+		distUnit, velUnit = "km", "km/d"
+		distScale = e.ephemData.au
+		velScale = e.ephemData.au
+		if spec.Units == TableKmPerSecond {
+			velUnit = "km/s"
+			velScale /= secondsPerDay
+		}
+	}
+
+	table := &Table{}
+	for _, q := range quantities {
+		switch q {
+		case TablePosition:
+			table.Columns = append(table.Columns, TableColumn{"x", distUnit}, TableColumn{"y", distUnit}, TableColumn{"z", distUnit})
+		case TableVelocity:
+			table.Columns = append(table.Columns, TableColumn{"vx", velUnit}, TableColumn{"vy", velUnit}, TableColumn{"vz", velUnit})
+		case TableDistance:
+			table.Columns = append(table.Columns, TableColumn{"r", distUnit})
+		case TableRangeRate:
+			table.Columns = append(table.Columns, TableColumn{"rdot", velUnit})
+		case TableLonLat:
+			table.Columns = append(table.Columns, TableColumn{"lon", "deg"}, TableColumn{"lat", "deg"})
+		default:
+			return nil, fmt.Errorf("table quantity %d: %w", q, ErrInvalidIndex)
+		}
+	}
+
+	n := int(math.Floor((spec.End-spec.Start)/spec.Step+1e-9)) + 1
+	table.Rows = make([]TableRow, 0, n*len(spec.Bodies))
+	for i := 0; i < n; i++ {
+		et := spec.Start + float64(i)*spec.Step
+		m := tableFrameMatrix(spec.Frame, et)
+		for _, body := range spec.Bodies {
+			pos, vel, err := e.CalculatePV(et, body, spec.Center, true)
+			if err != nil {
+				return nil, fmt.Errorf("%v at JD %.5f: %w", body, et, err)
+			}
+			pos, vel = applyState(m, pos, vel)
+			r := math.Sqrt(pos.X*pos.X + pos.Y*pos.Y + pos.Z*pos.Z)
+			row := TableRow{ET: et, Body: body, Values: make([]float64, 0, len(table.Columns))}
+			for _, q := range quantities {
+				switch q {
+				case TablePosition:
+					row.Values = append(row.Values, pos.X*distScale, pos.Y*distScale, pos.Z*distScale)
+				case TableVelocity:
+					row.Values = append(row.Values, vel.DX*velScale, vel.DY*velScale, vel.DZ*velScale)
+				case TableDistance:
+					row.Values = append(row.Values, r*distScale)
+				case TableRangeRate:
+					rdot := 0.0
+					if r > 0 {
+						rdot = (pos.X*vel.DX + pos.Y*vel.DY + pos.Z*vel.DZ) / r
+					}
+					row.Values = append(row.Values, rdot*velScale)
+				case TableLonLat:
+					lon, lat, _ := sphericalDegrees([3]float64{pos.X, pos.Y, pos.Z})
+					row.Values = append(row.Values, lon, lat)
+				}
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	return table, nil
+}
+
+// tableFrameMatrix returns the rotation from the ICRF to a table frame at et.
+func tableFrameMatrix(frame TableFrame, et float64) linalg.Mat3 {
+	switch frame {
+	case TableEclipticJ2000:
+		return linalg.RotX(obliquityJ2000)
+	case TableEclipticOfDate:
+		return eclipticOfDate(et)
+	case TableGalactic:
+		return icrfToGalactic
+	case TableInvariable:
+		return icrfToInvariable
+	}
+	return linalg.Identity()
+}
+
+// header returns the names of all columns of the table.
+func (t *Table) header() []string {
+	names := []string{"jd_tdb", "body"}
+	for _, c := range t.Columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// WriteCSV writes the table as CSV with a header line of column names.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.header()); err != nil {
+		return err
+	}
+	record := make([]string, 2+len(t.Columns))
+	for _, row := range t.Rows {
+		record[0] = strconv.FormatFloat(row.ET, 'f', -1, 64)
+		record[1] = t.Names.Body(row.Body)
+		for i, v := range row.Values {
+			record[2+i] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSONLines writes the table as JSON lines: one object per row, keyed by column name.
+func (t *Table) WriteJSONLines(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var line []byte
+	for _, row := range t.Rows {
+		line = append(line[:0], `{"jd_tdb":`...)
+		line = strconv.AppendFloat(line, row.ET, 'f', -1, 64)
+		line = append(line, `,"body":`...)
+		line = strconv.AppendQuote(line, t.Names.Body(row.Body))
+		for i, v := range row.Values {
+			line = append(line, ',')
+			line = strconv.AppendQuote(line, t.Columns[i].Name)
+			line = append(line, ':')
+			line = strconv.AppendFloat(line, v, 'g', -1, 64)
+		}
+		line = append(line, "}\n"...)
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,249 @@
+// ./table_parquet.go
+package jpleph
+
+/*
+Package jpleph provides a minimal Parquet writer for generated ephemeris tables.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// parquetMagic starts and ends every Parquet file.
+const parquetMagic = "PAR1"
+
+// Parquet physical types, encodings, and other enumerations of the format (parquet.thrift).
+const (
+	parquetDouble       = 5 // Type DOUBLE
+	parquetByteArray    = 6 // Type BYTE_ARRAY
+	parquetRequired     = 0 // FieldRepetitionType REQUIRED
+	parquetUTF8         = 0 // ConvertedType UTF8
+	parquetPlain        = 0 // Encoding PLAIN
+	parquetRLE          = 3 // Encoding RLE
+	parquetUncompressed = 0 // CompressionCodec UNCOMPRESSED
+	parquetDataPage     = 0 // PageType DATA_PAGE
+)
+
+// Thrift compact protocol type codes.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes Thrift structs with the compact protocol, as Parquet metadata uses.
+type thriftWriter struct {
+	buf    []byte  // Encoded bytes
+	fields []int16 // Last field id of each open struct
+}
+
+// varint appends an unsigned LEB128 integer.
+func (t *thriftWriter) varint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+// field appends the header of field id of a Thrift type in the innermost open struct.
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.fields[len(t.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(uint64(uint16((id << 1) ^ (id >> 15))))
+	}
+	*last = id
+}
+
+// begin opens a struct (the field header, if any, is written by the caller).
+func (t *thriftWriter) begin() {
+	t.fields = append(t.fields, 0)
+}
+
+// end closes the innermost struct.
+func (t *thriftWriter) end() {
+	t.buf = append(t.buf, 0)
+	t.fields = t.fields[:len(t.fields)-1]
+}
+
+// i32 appends an i32 field.
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+// i64 appends an i64 field.
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+// str appends a string field.
+func (t *thriftWriter) str(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// structField opens a struct-valued field.
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+// list appends the header of a list field of n elements of a Thrift type.
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elem)
+	t.varint(uint64(n))
+}
+
+// parquetColumn is a column chunk as laid out in the file.
+type parquetColumn struct {
+	name   string // Column name
+	typ    int32  // Physical type
+	utf8   bool   // Whether a BYTE_ARRAY column holds UTF-8 strings
+	offset int64  // File offset of the page header
+	size   int64  // Size of the page header and data
+}
+
+// WriteParquet writes the table as a Parquet file with one row group and one uncompressed,
+// PLAIN-encoded page per column: jd_tdb and the value columns as DOUBLE, body as a UTF-8
+// string. The output is readable by Arrow, pandas, Spark, DuckDB, and other Parquet readers.
+func (t *Table) WriteParquet(w io.Writer) error {
+	columns := []parquetColumn{{name: "jd_tdb", typ: parquetDouble}, {name: "body", typ: parquetByteArray, utf8: true}}
+	for _, c := range t.Columns {
+		columns = append(columns, parquetColumn{name: c.Name, typ: parquetDouble})
+	}
+	rows := len(t.Rows)
+
+	offset := int64(len(parquetMagic))
+	if _, err := io.WriteString(w, parquetMagic); err != nil {
+		return err
+	}
+	for i := range columns {
+		c := &columns[i]
+		var data []byte
+		for _, row := range t.Rows {
+			switch i {
+			case 0:
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(row.ET))
+			case 1:
+				name := t.Names.Body(row.Body)
+				data = binary.LittleEndian.AppendUint32(data, uint32(len(name)))
+				data = append(data, name...)
+			default:
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(row.Values[i-2]))
+			}
+		}
+
+		var h thriftWriter
+		h.begin()
+		h.i32(1, parquetDataPage)
+		h.i32(2, int32(len(data)))
+		h.i32(3, int32(len(data)))
+		h.structField(5)
+		h.i32(1, int32(rows))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.end()
+		h.end()
+
+		c.offset, c.size = offset, int64(len(h.buf)+len(data))
+		if _, err := w.Write(h.buf); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		offset += c.size
+	}
+
+	var m thriftWriter
+	m.begin()
+	m.i32(1, 1) // Format version
+	m.list(2, thriftStruct, 1+len(columns))
+	m.begin()
+	m.str(4, "schema")
+	m.i32(5, int32(len(columns)))
+	m.end()
+	for _, c := range columns {
+		m.begin()
+		m.i32(1, c.typ)
+		m.i32(3, parquetRequired)
+		m.str(4, c.name)
+		if c.utf8 {
+			m.i32(6, parquetUTF8)
+		}
+		m.end()
+	}
+	m.i64(3, int64(rows))
+	m.list(4, thriftStruct, 1)
+	m.begin()
+	m.list(1, thriftStruct, len(columns))
+	var totalSize int64
+	for _, c := range columns {
+		totalSize += c.size
+		m.begin()
+		m.i64(2, c.offset)
+		m.structField(3)
+		m.i32(1, c.typ)
+		m.list(2, thriftI32, 2)
+		m.varint(uint64(parquetPlain << 1))
+		m.varint(uint64(parquetRLE << 1))
+		m.list(3, thriftBinary, 1)
+		m.varint(uint64(len(c.name)))
+		m.buf = append(m.buf, c.name...)
+		m.i32(4, parquetUncompressed)
+		m.i64(5, int64(rows))
+		m.i64(6, c.size)
+		m.i64(7, c.size)
+		m.i64(9, c.offset)
+		m.end()
+		m.end()
+	}
+	m.i64(2, totalSize)
+	m.i64(3, int64(rows))
+	m.end()
+	m.str(6, "jpleph")
+	m.end()
+
+	if _, err := w.Write(m.buf); err != nil {
+		return err
+	}
+	var footer [4]byte
+	binary.LittleEndian.PutUint32(footer[:], uint32(len(m.buf)))
+	if _, err := w.Write(footer[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, parquetMagic)
+	return err
+}
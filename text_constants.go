@@ -0,0 +1,241 @@
+// ./text_constants.go
+package jpleph
+
+/*
+Package jpleph provides text kernel (PCK-style) constants that overlay the ephemeris constants.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Text kernel section markers.
+const (
+	textKernelData = `\begindata`
+	textKernelText = `\begintext`
+)
+
+// LoadTextConstants reads a text kernel of constants and overlays it on the constants of the
+// ephemeris file: its values take precedence in ConstantByName and ConstantValues, and GM
+// overrides are used by NewPropagator and TidalAcceleration. Kernels loaded later override
+// earlier ones, and "+=" assignments extend the values already loaded.
+//
+// Parameters:
+//   - filename: Path to the text kernel (e.g., "pck00011.tpc" or a file of "NAME = value" lines).
+//
+// Returns:
+//   - error: ErrConstantsFormat if the kernel is malformed, or a file access error.
+func (e *Ephemeris) LoadTextConstants(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open text constants: %w", err)
+	}
+	defer f.Close()
+
+	var appended []string
+	constants, err := readTextConstants(f, &appended)
+	if err != nil {
+		return err
+	}
+	appendSet := make(map[string]bool, len(appended))
+	for _, name := range appended {
+		appendSet[name] = true
+	}
+	if e.constOverlay == nil {
+		e.constOverlay = make(map[string][]float64, len(constants))
+		e.constOrigin = make(map[string]string, len(constants))
+	}
+	for name, values := range constants {
+		if appendSet[name] {
+			values = append(append([]float64(nil), e.constOverlay[name]...), values...)
+		}
+		e.constOverlay[name] = values
+		e.constOrigin[name] = filename
+	}
+	return nil
+}
+
+// ReadTextConstants parses a text kernel of constants in the SPICE text kernel format: data
+// between "\begindata" and "\begintext" markers (or the whole input if it has no markers),
+// with assignments "NAME = value" or "NAME = ( v1, v2, ... )", where "+=" appends to a
+// variable. Numbers may use Fortran "D" exponents. String and date values are skipped.
+//
+// Parameters:
+//   - r: Source of the kernel text.
+//
+// Returns:
+//   - map[string][]float64: The numeric variables by name.
+//   - error: ErrConstantsFormat if the kernel is malformed.
+func ReadTextConstants(r io.Reader) (map[string][]float64, error) {
+	return readTextConstants(r, nil)
+}
+
+// readTextConstants parses a text kernel, recording in appended the variables whose first
+// assignment in the kernel used "+=".
+func readTextConstants(r io.Reader, appended *[]string) (map[string][]float64, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConstantsFormat, err)
+	}
+	text := string(raw)
+
+	var data strings.Builder
+	inData := !strings.Contains(text, textKernelData)
+	for _, line := range strings.Split(text, "\n") {
+		switch strings.TrimSpace(line) {
+		case textKernelData:
+			inData = true
+			continue
+		case textKernelText:
+			inData = false
+			continue
+		}
+		if inData {
+			data.WriteString(line)
+			data.WriteByte('\n')
+		}
+	}
+
+	tokens, err := textKernelTokens(data.String())
+	if err != nil {
+		return nil, err
+	}
+	constants := make(map[string][]float64)
+	for i := 0; i < len(tokens); {
+		name := tokens[i]
+		if i+2 >= len(tokens) || (tokens[i+1] != "=" && tokens[i+1] != "+=") || !isTextKernelName(name) {
+			return nil, fmt.Errorf("%w: expected assignment near %q", ErrConstantsFormat, name)
+		}
+		appendValues := tokens[i+1] == "+="
+		i += 2
+
+		var values []string
+		if tokens[i] == "(" {
+			for i++; i < len(tokens) && tokens[i] != ")"; i++ {
+				if tokens[i] != "," {
+					values = append(values, tokens[i])
+				}
+			}
+			if i == len(tokens) {
+				return nil, fmt.Errorf("%w: unterminated value list of %s", ErrConstantsFormat, name)
+			}
+		} else {
+			values = []string{tokens[i]}
+		}
+		i++
+
+		numbers, numeric, err := parseTextKernelValues(name, values)
+		if err != nil {
+			return nil, err
+		}
+		if !numeric {
+			continue // Strings and dates are not constants
+		}
+		existing, seen := constants[name]
+		switch {
+		case appendValues && seen:
+			constants[name] = append(existing, numbers...)
+		case appendValues:
+			constants[name] = numbers
+			if appended != nil {
+				*appended = append(*appended, name)
+			}
+		default:
+			constants[name] = numbers
+		}
+	}
+	return constants, nil
+}
+
+// textKernelTokens splits the data sections of a text kernel into names, values, quoted
+// strings, and the punctuation "=", "+=", "(", ")" and ",".
+func textKernelTokens(data string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '+' && i+1 < len(data) && data[i+1] == '=':
+			tokens = append(tokens, "+=")
+			i += 2
+		case c == '\'':
+			j := i + 1
+			for ; j < len(data); j++ {
+				if data[j] == '\'' {
+					if j+1 < len(data) && data[j+1] == '\'' {
+						j++ // Doubled quote inside a string
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(data) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrConstantsFormat)
+			}
+			tokens = append(tokens, data[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(data) && !strings.ContainsRune(" \t\r\n(),='", rune(data[j])) &&
+				!(data[j] == '+' && j+1 < len(data) && data[j+1] == '=') {
+				j++
+			}
+			tokens = append(tokens, data[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// isTextKernelName reports whether token can be a variable name.
+func isTextKernelName(token string) bool {
+	return token != "" && !strings.ContainsAny(token, "'()=,")
+}
+
+// parseTextKernelValues converts the values of one assignment. It reports numeric=false for
+// string and date (@) values.
+func parseTextKernelValues(name string, values []string) ([]float64, bool, error) {
+	if len(values) == 0 {
+		return nil, false, fmt.Errorf("%w: %s has no values", ErrConstantsFormat, name)
+	}
+	if strings.HasPrefix(values[0], "'") || strings.HasPrefix(values[0], "@") {
+		return nil, false, nil
+	}
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		x, err := strconv.ParseFloat(strings.NewReplacer("D", "E", "d", "e").Replace(v), 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: %s: bad value %q", ErrConstantsFormat, name, v)
+		}
+		numbers[i] = x
+	}
+	return numbers, true, nil
+}
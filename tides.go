@@ -0,0 +1,171 @@
+// ./tides.go
+package jpleph
+
+/*
+Package jpleph provides the lunisolar tide-generating acceleration and potential, and the
+Sun and Moon positions that tide models take as inputs.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// Tide holds the tide-generating acceleration and potential of the Moon and the Sun at a site,
+// in SI units, for a rigid Earth (no Love number or ocean loading factors applied).
+type Tide struct {
+	Acceleration     [3]float64 // Tidal acceleration in the Earth-fixed frame, m/s^2
+	East             float64    // Eastward component of the acceleration, m/s^2
+	North            float64    // Northward component of the acceleration, m/s^2
+	Up               float64    // Upward component (the gravimetric tide, with sign reversed), m/s^2
+	Degree2Potential float64    // Degree-2 tide-generating potential, m^2/s^2
+	Degree3Potential float64    // Degree-3 tide-generating potential, m^2/s^2
+}
+
+// TidalAcceleration returns the lunisolar tide-generating acceleration and the degree-2 and
+// degree-3 tidal potential at an Earth-fixed site. The acceleration is the gradient of the
+// degree-2 and degree-3 potential, from the Moon and Sun states and GM constants of the file.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - site: Observer location.
+//   - deltaT: TT - UT1 in seconds, which orients the Earth.
+//
+// Returns:
+//   - Tide: Acceleration (total and local east/north/up components) and potentials.
+//   - error: ErrEventSearch for an invalid site, ErrConstantNotFound if the file constants
+//     were not loaded, or an error from CalculatePV.
+func (e *Ephemeris) TidalAcceleration(et float64, site Site, deltaT float64) (Tide, error) {
+	if !site.valid() {
+		return Tide{}, fmt.Errorf("%w: site %+v", ErrEventSearch, site)
+	}
+	auMeters := e.ephemData.au * 1000
+	gmFactor := auMeters * auMeters * auMeters / (secondsPerDay * secondsPerDay) // AU^3/day^2 to m^3/s^2
+	rot := terrestrialRotation(et, deltaT)
+
+	r := site.ecef()
+	for i := range r {
+		r[i] *= 1000
+	}
+	rr := r[0]*r[0] + r[1]*r[1] + r[2]*r[2]
+
+	var tide Tide
+	for _, body := range []Planet{Moon, Sun} {
+		gm, err := e.bodyGM(body)
+		if err != nil {
+			return Tide{}, err
+		}
+		gm *= gmFactor
+		pos, _, err := e.CalculatePV(et, body, CenterEarth, false)
+		if err != nil {
+			return Tide{}, err
+		}
+		x := rot.Apply([3]float64{pos.X * auMeters, pos.Y * auMeters, pos.Z * auMeters})
+		dist := math.Sqrt(x[0]*x[0] + x[1]*x[1] + x[2]*x[2])
+		u := [3]float64{x[0] / dist, x[1] / dist, x[2] / dist}
+		ru := r[0]*u[0] + r[1]*u[1] + r[2]*u[2] // r cos(psi)
+
+		d3 := dist * dist * dist
+		d4 := d3 * dist
+		tide.Degree2Potential += gm / (2 * d3) * (3*ru*ru - rr)
+		tide.Degree3Potential += gm / (2 * d4) * (5*ru*ru*ru - 3*ru*rr)
+		for i := range tide.Acceleration {
+			tide.Acceleration[i] += gm/d3*(3*ru*u[i]-r[i]) + gm/(2*d4)*((15*ru*ru-3*rr)*u[i]-6*ru*r[i])
+		}
+	}
+
+	sinLat, cosLat := math.Sincos(site.Latitude * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(site.Longitude * math.Pi / 180)
+	a := tide.Acceleration
+	tide.East = -sinLon*a[0] + cosLon*a[1]
+	tide.North = -sinLat*cosLon*a[0] - sinLat*sinLon*a[1] + cosLat*a[2]
+	tide.Up = cosLat*cosLon*a[0] + cosLat*sinLon*a[1] + sinLat*a[2]
+	return tide, nil
+}
+
+// SunMoonProvider is the narrow interface of the lunisolar inputs of the ocean and solid
+// Earth tide models, such as DEHANTTIDEINEL of the IERS Conventions: the geocentric positions
+// of the Sun and the Moon in the Earth-fixed frame. Geodesy packages can depend on it
+// instead of on Ephemeris, and substitute another source in tests.
+type SunMoonProvider interface {
+	// SunMoonITRF returns the geocentric positions of the Sun and the Moon in the ITRF, in
+	// meters, at the Julian Ephemeris Date et.
+	SunMoonITRF(et float64) (sun, moon [3]float64, err error)
+}
+
+// EphemerisSunMoon is the SunMoonProvider of an ephemeris, created by NewSunMoonProvider. Like
+// the Ephemeris, it is not safe for concurrent use; give each goroutine its own from a Clone.
+type EphemerisSunMoon struct {
+	eph    *Ephemeris     // Ephemeris of the Sun and the Moon
+	frames *FrameRegistry // Rotation from the ICRF to FrameITRF
+}
+
+// NewSunMoonProvider returns the SunMoonProvider of the ephemeris. The positions are geometric
+// (no light time or aberration, as the tide models expect), rotated into FrameITRF of the
+// frame registry: precession, the nutations of the file (or of WithNutationFallback), and the
+// sidereal time from TT - UT1, without polar motion.
+//
+// Parameters:
+//   - deltaT: TT - UT1 in seconds as a function of the Julian Date, such as the DeltaT method
+//     of a DeltaTTable; nil for the DeltaT model.
+//
+// Returns:
+//   - *EphemerisSunMoon: The provider.
+func (e *Ephemeris) NewSunMoonProvider(deltaT func(jd float64) float64) *EphemerisSunMoon {
+	frames := e.NewFrameRegistry()
+	if deltaT != nil {
+		frames.SetDeltaT(deltaT)
+	}
+	return &EphemerisSunMoon{eph: e, frames: frames}
+}
+
+// SunMoonITRF returns the geocentric positions of the Sun and the Moon in the ITRF, in meters
+// (with the AU of the file).
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - sun, moon: The positions in meters.
+//   - err: An error from CalculatePV, or from the rotation (e.g., ErrQuantityNotInEphemeris
+//     for a file without nutations and no fallback).
+func (p *EphemerisSunMoon) SunMoonITRF(et float64) (sun, moon [3]float64, err error) {
+	rot, err := p.frames.rotation(FrameICRF, FrameITRF, et)
+	if err != nil {
+		return sun, moon, err
+	}
+	auMeters := p.eph.ephemData.au * 1000
+	for _, b := range []struct {
+		body Planet
+		dest *[3]float64
+	}{{Sun, &sun}, {Moon, &moon}} {
+		pos, _, err := p.eph.CalculatePV(et, b.body, CenterEarth, false)
+		if err != nil {
+			return [3]float64{}, [3]float64{}, err
+		}
+		*b.dest = rot.Apply(linalg.Vec3{pos.X * auMeters, pos.Y * auMeters, pos.Z * auMeters})
+	}
+	return sun, moon, nil
+}
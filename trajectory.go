@@ -0,0 +1,207 @@
+// ./trajectory.go
+package jpleph
+
+/*
+Package jpleph provides a trajectory handle for dense evaluation of one body in time.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"math"
+	"slices"
+)
+
+// trajectorySign holds the signs of the target and center sides of a trajectory.
+var trajectorySign = [2]float64{1, -1}
+
+// Trajectory evaluates the state of one target relative to one center at many epochs.
+//
+// Only the IPT entries needed for the pair are interpolated, from a private copy of the
+// data record covering the last epoch, so consecutive calls within the same record never
+// touch the file and are unaffected by other calls on the Ephemeris. The entries are summed
+// and combined as CalculatePV does, with the summation options of the Ephemeris, so the
+// results are the same to the last bit. A Trajectory is not
+// safe for concurrent use, and it shares the file handle of the Ephemeris it was made from.
+type Trajectory struct {
+	eph     *Ephemeris        // eph is the ephemeris the trajectory reads from.
+	data    *jplEphData       // data is the file the terms were built for (see Ephemeris.Reopen).
+	target  Planet            // target is the target body.
+	center  CenterBody        // center is the center body.
+	bodies  [2]Planet         // bodies are the kernel bodies of the target and center sides.
+	entries []int             // entries are the IPT entries interpolated (0-9 as in State, 10 = Sun).
+	customs [2]*CustomBody    // customs are the custom target and center, if any.
+	record  int64             // record is the number of the cached record, or -1 if none is cached.
+	coeffs  []float64         // coeffs is the private copy of the cached record.
+	iinfo   interpolationInfo // iinfo caches Chebyshev polynomial values between calls.
+}
+
+// Trajectory returns a handle evaluating target relative to center, optimized for callers
+// sampling one body densely in time (ODE integrators, plotting). Custom bodies registered
+// with AddCustomBody may be used on either side. Nutations, librations, lunar mantle angles,
+// and TT-TDB are not bodies and are rejected.
+//
+// Parameters:
+//   - target: Target body.
+//   - center: Center body.
+//
+// Returns:
+//   - *Trajectory: The trajectory handle on success.
+//   - error: ErrInvalidIndex if target or center is not a body of the kernel or a custom body.
+func (e *Ephemeris) Trajectory(target Planet, center CenterBody) (*Trajectory, error) {
+	tr := &Trajectory{eph: e, data: e.ephemData, target: target, center: center, record: -1}
+	tr.iinfo.reset()
+	tr.iinfo.compensated = e.ephemData.iinfo.compensated // Same summation as CalculatePV
+	tr.iinfo.fortranOrder = e.ephemData.iinfo.fortranOrder
+
+	for side, body := range [2]Planet{target, Planet(center)} {
+		if custom, ok := e.customBodies[body]; ok {
+			tr.customs[side] = custom
+			body = Planet(custom.Center)
+		}
+		terms, err := barycentricTerms(body, e.ephemData.emrat)
+		if err != nil {
+			return nil, err
+		}
+		tr.bodies[side] = body
+		for idx := range terms {
+			if !slices.Contains(tr.entries, idx) {
+				tr.entries = append(tr.entries, idx)
+			}
+		}
+	}
+	slices.Sort(tr.entries)
+	return tr, nil
+}
+
+// Span returns the first and last Julian Ephemeris Dates at which the trajectory can be
+// evaluated: the span of the kernel, narrowed to the spans of any custom bodies involved.
+func (tr *Trajectory) Span() (float64, float64) {
+	start, end := tr.eph.ephemData.ephemStart, tr.eph.ephemData.ephemEnd
+	for _, b := range tr.customs {
+		if b != nil {
+			bStart, bEnd := b.Span()
+			start, end = math.Max(start, bStart), math.Min(end, bEnd)
+		}
+	}
+	return start, end
+}
+
+// At returns the position and velocity of the target relative to the center at et.
+//
+// Parameters:
+//   - et: Julian Ephemeris Date.
+//
+// Returns:
+//   - Position: Position in AU.
+//   - Velocity: Velocity in AU/day.
+//   - error: ErrOutsideRange if et is outside Span, or a file access error.
+func (tr *Trajectory) At(et float64) (Position, Velocity, error) {
+	tr.eph.applyReload()
+	if tr.data != tr.eph.ephemData { // The file was reloaded: rebuild the terms for its constants
+		fresh, err := tr.eph.Trajectory(tr.target, tr.center)
+		if err != nil {
+			return Position{}, Velocity{}, err
+		}
+		*tr = *fresh
+	}
+	ephem := tr.eph.ephemData
+	nr, frac, err := recordLocation(ephem, et)
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	if int64(nr) != tr.record {
+		if err := loadRecord(ephem, nr); err != nil {
+			return Position{}, Velocity{}, err
+		}
+		tr.coeffs = append(tr.coeffs[:0], ephem.cache...)
+		tr.record = int64(nr)
+	}
+
+	var pv [11][6]float64 // Interpolated IPT entries, as filled by State
+	t := [2]float64{frac, ephem.ephemStep}
+	aufac := 1.0 / ephem.au
+	for _, idx := range tr.entries {
+		entry := ephem.ipt[idx]
+		interp(&tr.iinfo, tr.coeffs[entry[0]-1:], t, uint(entry[1]), 3, uint(entry[2]), 2, pv[idx][:])
+		for i := range pv[idx] {
+			if tr.iinfo.compensated {
+				pv[idx][i] /= ephem.au // One rounding instead of two through aufac, as in State
+			} else {
+				pv[idx][i] *= aufac
+			}
+		}
+	}
+	rv := relativeState(&pv, tr.bodies[0], tr.bodies[1], ephem.emrat)
+
+	pos := Position{X: rv[0], Y: rv[1], Z: rv[2]}
+	vel := Velocity{DX: rv[3], DY: rv[4], DZ: rv[5]}
+	for side, b := range tr.customs {
+		if b == nil {
+			continue
+		}
+		bPos, bVel, err := b.State(et, true)
+		if err != nil {
+			return Position{}, Velocity{}, err
+		}
+		sign := trajectorySign[side]
+		pos = Position{X: pos.X + sign*bPos.X, Y: pos.Y + sign*bPos.Y, Z: pos.Z + sign*bPos.Z}
+		vel = Velocity{DX: vel.DX + sign*bVel.DX, DY: vel.DY + sign*bVel.DY, DZ: vel.DZ + sign*bVel.DZ}
+	}
+	return pos, vel, nil
+}
+
+// relativeState returns the state of target relative to center from the IPT entries of
+// State, with the same operations as plephInto: the Earth is the Earth-Moon barycenter less
+// the geocentric Moon over 1 + emrat, the Moon is the Earth plus the geocentric Moon, and the
+// Earth and Moon relative to one another take the geocentric Moon alone.
+func relativeState(pv *[11][6]float64, target, center Planet, emrat float64) [6]float64 {
+	var rv [6]float64
+	if target == center {
+		return rv
+	}
+	earthMoon := (target == Earth && center == Moon) || (target == Moon && center == Earth)
+	var side [2][6]float64
+	for k, body := range [2]Planet{target, center} {
+		for i := range side[k] {
+			switch {
+			case body == SolarSystemBarycenter:
+			case body == Sun:
+				side[k][i] = pv[10][i]
+			case body == EarthMoonBarycenter:
+				side[k][i] = pv[2][i]
+			case body == Earth && earthMoon:
+			case body == Earth:
+				side[k][i] = pv[2][i] - pv[9][i]/(1.0+emrat)
+			case body == Moon && earthMoon:
+				side[k][i] = pv[9][i]
+			case body == Moon:
+				side[k][i] = pv[9][i] + (pv[2][i] - pv[9][i]/(1.0+emrat))
+			default:
+				side[k][i] = pv[body-1][i]
+			}
+		}
+	}
+	for i := range rv {
+		rv[i] = side[0][i] - side[1][i]
+	}
+	return rv
+}
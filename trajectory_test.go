@@ -0,0 +1,58 @@
+// ./trajectory_test.go
+package jpleph_test
+
+import (
+	"testing"
+
+	"github.com/mshafiee/jpleph"
+	"github.com/mshafiee/jpleph/jplephtest"
+)
+
+// TestTrajectoryMatchesCalculatePV checks that Trajectory.At returns the states of
+// CalculatePV bit for bit, for every pair of kernel bodies and under each summation option,
+// both for new handles and for handles rebuilt after Reopen.
+func TestTrajectoryMatchesCalculatePV(t *testing.T) {
+	cfg := jplephtest.DefaultConfig()
+	options := []struct {
+		name string
+		opts []jpleph.Option
+	}{
+		{"default", nil},
+		{"compensated", []jpleph.Option{jpleph.WithCompensatedSummation()}},
+		{"fortran order", []jpleph.Option{jpleph.WithFortranOrder()}},
+	}
+	for _, o := range options {
+		t.Run(o.name, func(t *testing.T) {
+			e := openSynthetic(t, cfg, o.opts...)
+			var trajectories []*jpleph.Trajectory
+			for target := jpleph.Mercury; target <= jpleph.EarthMoonBarycenter; target++ {
+				for center := jpleph.CenterMercury; center <= jpleph.CenterEarthMoonBarycenter; center++ {
+					tr, err := e.Trajectory(target, center)
+					if err != nil {
+						t.Fatalf("Trajectory(%v, %v): %v", target, center, err)
+					}
+					trajectories = append(trajectories, tr)
+				}
+			}
+			check := func(when string) {
+				for i, tr := range trajectories {
+					target := jpleph.Mercury + jpleph.Planet(i/13)
+					center := jpleph.CenterMercury + jpleph.CenterBody(i%13)
+					for et := cfg.Start; et <= cfg.End(); et += 3.7 {
+						p, v, err1 := tr.At(et)
+						q, w, err2 := e.CalculatePV(et, target, center, true)
+						if err1 != nil || err2 != nil || p != q || v != w {
+							t.Fatalf("%s: %v from %v at %v: At %v %v (%v), CalculatePV %v %v (%v)",
+								when, target, center, et, p, v, err1, q, w, err2)
+						}
+					}
+				}
+			}
+			check("new handle")
+			if err := e.Reopen(); err != nil {
+				t.Fatal(err)
+			}
+			check("after Reopen")
+		})
+	}
+}
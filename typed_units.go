@@ -0,0 +1,191 @@
+// ./typed_units.go
+package jpleph
+
+/*
+Package jpleph provides typed unit quantities, for unit safety at compile time.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+)
+
+// The typed quantities below let code built on the library keep the units of its values in
+// their types: a distance in AU cannot be passed where kilometers are expected without a
+// conversion. Conversions between AU and kilometers use the IAU 2012 astronomical unit,
+// which the DE files since DE430 adopt; Ephemeris.Kilometers and
+// Ephemeris.KilometersPerSecond use the AU of the file instead.
+
+// AU is a length in astronomical units.
+type AU float64
+
+// AUPerDay is a speed in AU per day.
+type AUPerDay float64
+
+// Kilometer is a length in kilometers.
+type Kilometer float64
+
+// KilometerPerSecond is a speed in kilometers per second.
+type KilometerPerSecond float64
+
+// KilometerPerDay is a speed in kilometers per day.
+type KilometerPerDay float64
+
+// Meter is a length in meters.
+type Meter float64
+
+// MeterPerSecond is a speed in meters per second.
+type MeterPerSecond float64
+
+// Radian is an angle in radians.
+type Radian float64
+
+// Degree is an angle in degrees.
+type Degree float64
+
+// Second is a duration in seconds.
+type Second float64
+
+// Day is a duration in days of 86400 s.
+type Day float64
+
+// Kilometers converts to kilometers.
+func (a AU) Kilometers() Kilometer { return Kilometer(float64(a) * astronomicalUnitKM) }
+
+// Meters converts to meters.
+func (a AU) Meters() Meter { return Meter(float64(a) * astronomicalUnitKM * 1000) }
+
+// String formats the length with its unit.
+func (a AU) String() string { return fmt.Sprintf("%v AU", float64(a)) }
+
+// KilometersPerSecond converts to kilometers per second.
+func (v AUPerDay) KilometersPerSecond() KilometerPerSecond {
+	return KilometerPerSecond(float64(v) * astronomicalUnitKM / secondsPerDay)
+}
+
+// KilometersPerDay converts to kilometers per day.
+func (v AUPerDay) KilometersPerDay() KilometerPerDay {
+	return KilometerPerDay(float64(v) * astronomicalUnitKM)
+}
+
+// String formats the speed with its unit.
+func (v AUPerDay) String() string { return fmt.Sprintf("%v AU/day", float64(v)) }
+
+// AU converts to astronomical units.
+func (k Kilometer) AU() AU { return AU(float64(k) / astronomicalUnitKM) }
+
+// Meters converts to meters.
+func (k Kilometer) Meters() Meter { return Meter(float64(k) * 1000) }
+
+// String formats the length with its unit.
+func (k Kilometer) String() string { return fmt.Sprintf("%v km", float64(k)) }
+
+// AUPerDay converts to AU per day.
+func (v KilometerPerSecond) AUPerDay() AUPerDay {
+	return AUPerDay(float64(v) * secondsPerDay / astronomicalUnitKM)
+}
+
+// MetersPerSecond converts to meters per second.
+func (v KilometerPerSecond) MetersPerSecond() MeterPerSecond {
+	return MeterPerSecond(float64(v) * 1000)
+}
+
+// String formats the speed with its unit.
+func (v KilometerPerSecond) String() string { return fmt.Sprintf("%v km/s", float64(v)) }
+
+// KilometersPerSecond converts to kilometers per second.
+func (v KilometerPerDay) KilometersPerSecond() KilometerPerSecond {
+	return KilometerPerSecond(float64(v) / secondsPerDay)
+}
+
+// String formats the speed with its unit.
+func (v KilometerPerDay) String() string { return fmt.Sprintf("%v km/day", float64(v)) }
+
+// Kilometers converts to kilometers.
+func (m Meter) Kilometers() Kilometer { return Kilometer(float64(m) / 1000) }
+
+// String formats the length with its unit.
+func (m Meter) String() string { return fmt.Sprintf("%v m", float64(m)) }
+
+// KilometersPerSecond converts to kilometers per second.
+func (v MeterPerSecond) KilometersPerSecond() KilometerPerSecond {
+	return KilometerPerSecond(float64(v) / 1000)
+}
+
+// String formats the speed with its unit.
+func (v MeterPerSecond) String() string { return fmt.Sprintf("%v m/s", float64(v)) }
+
+// Degrees converts to degrees.
+func (r Radian) Degrees() Degree { return Degree(float64(r) * 180 / math.Pi) }
+
+// String formats the angle with its unit.
+func (r Radian) String() string { return fmt.Sprintf("%v rad", float64(r)) }
+
+// Radians converts to radians.
+func (d Degree) Radians() Radian { return Radian(float64(d) * math.Pi / 180) }
+
+// String formats the angle with its unit.
+func (d Degree) String() string { return fmt.Sprintf("%v deg", float64(d)) }
+
+// Days converts to days.
+func (s Second) Days() Day { return Day(float64(s) / secondsPerDay) }
+
+// String formats the duration with its unit.
+func (s Second) String() string { return fmt.Sprintf("%v s", float64(s)) }
+
+// Seconds converts to seconds.
+func (d Day) Seconds() Second { return Second(float64(d) * secondsPerDay) }
+
+// String formats the duration with its unit.
+func (d Day) String() string { return fmt.Sprintf("%v day", float64(d)) }
+
+// Typed returns the components of a position of a body, as CalculatePV returns it, in AU.
+func (p Position) Typed() [3]AU {
+	return [3]AU{AU(p.X), AU(p.Y), AU(p.Z)}
+}
+
+// Distance returns the norm of a position of a body in AU.
+func (p Position) Distance() AU {
+	return AU(norm3([3]float64{p.X, p.Y, p.Z}))
+}
+
+// Typed returns the components of a velocity of a body, as CalculatePV returns it, in AU/day.
+func (v Velocity) Typed() [3]AUPerDay {
+	return [3]AUPerDay{AUPerDay(v.DX), AUPerDay(v.DY), AUPerDay(v.DZ)}
+}
+
+// Speed returns the norm of a velocity of a body in AU/day.
+func (v Velocity) Speed() AUPerDay {
+	return AUPerDay(norm3([3]float64{v.DX, v.DY, v.DZ}))
+}
+
+// Kilometers converts a length to kilometers with the AU of the file, which differs from the
+// IAU 2012 value of AU.Kilometers in the files before DE430 (by 9 m for DE405).
+func (e *Ephemeris) Kilometers(a AU) Kilometer {
+	return Kilometer(float64(a) * e.ephemData.au)
+}
+
+// KilometersPerSecond converts a speed to kilometers per second with the AU of the file.
+func (e *Ephemeris) KilometersPerSecond(v AUPerDay) KilometerPerSecond {
+	return KilometerPerSecond(float64(v) * e.ephemData.au / secondsPerDay)
+}
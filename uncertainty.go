@@ -0,0 +1,180 @@
+// ./uncertainty.go
+package jpleph
+
+/*
+Package jpleph provides the first-order propagation of uncertainties into relative states.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mshafiee/jpleph/linalg"
+)
+
+// StateUncertainty are the uncertainties of the inputs of UncertainState, as standard
+// deviations and covariances; zero values are exact inputs.
+type StateUncertainty struct {
+	EpochSigma       float64       // Standard deviation of the epoch in seconds (e.g., of a time tag)
+	SiteCovariance   [3][3]float64 // Covariance of the site position in its body-fixed frame, in m^2 (e.g., from a station survey)
+	TargetCovariance [3][3]float64 // Covariance of the target position in the ICRF, in km^2 (e.g., from an orbit determination)
+}
+
+// UncertainState is the position of a target relative to a site with its first-order
+// covariance.
+type UncertainState struct {
+	Position   Position      // Target relative to the site in the output frame, in AU
+	Velocity   Velocity      // Rate of Position in AU/day, including the rotation of the frame
+	Covariance [3][3]float64 // Covariance of Position in AU^2
+	Sigma      [3]float64    // Standard deviations of the components of Position in AU
+}
+
+// UncertainState returns the geometric position of a target relative to a site in a frame,
+// with the covariance of the position propagated to first order from the uncertainties of
+// the epoch, the site, and the target:
+//
+//	C = J_t J_t^T s_t^2 + (R M) C_site (R M)^T + R C_target R^T
+//
+// where R is the rotation from the ICRF to the output frame, M the rotation from the
+// body-fixed frame of the site to the ICRF, and J_t = R v + (dR/dt) x the rate of the
+// position in the output frame. The three inputs are taken as independent. The
+// uncertainties of the ephemeris itself are not included; see EstimateInterpolationError
+// for the representation error of the file.
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target: Body observed (a kernel, SPK, custom, or source body).
+//   - site: Observer location.
+//   - frame: Output frame; empty for the ICRF.
+//   - u: Uncertainties of the epoch, site, and target.
+//
+// Returns:
+//   - UncertainState: The position and its covariance.
+//   - error: ErrCovariance for a negative or non-finite sigma or a covariance that is not
+//     symmetric with a non-negative diagonal, ErrSite for an invalid site, or an error from
+//     CalculatePV or the frames.
+func (r *FrameRegistry) UncertainState(et float64, target Planet, site BodySite, frame Frame, u StateUncertainty) (UncertainState, error) {
+	if !(u.EpochSigma >= 0) || math.IsInf(u.EpochSigma, 0) {
+		return UncertainState{}, fmt.Errorf("%w: epoch sigma %v", ErrCovariance, u.EpochSigma)
+	}
+	if err := checkCovariance(u.SiteCovariance); err != nil {
+		return UncertainState{}, fmt.Errorf("site: %w", err)
+	}
+	if err := checkCovariance(u.TargetCovariance); err != nil {
+		return UncertainState{}, fmt.Errorf("target: %w", err)
+	}
+	if frame == "" {
+		frame = FrameICRF
+	}
+	siteFrame, _, err := site.frame()
+	if err != nil {
+		return UncertainState{}, err
+	}
+	sitePos, siteVel, err := r.SiteState(et, site)
+	if err != nil {
+		return UncertainState{}, err
+	}
+	pos, vel, err := r.eph.CalculatePV(et, target, CenterSolarSystemBarycenter, true)
+	if err != nil {
+		return UncertainState{}, err
+	}
+	rot, rate, err := r.rotationRate(FrameICRF, frame, et)
+	if err != nil {
+		return UncertainState{}, err
+	}
+	toICRF, err := r.rotation(siteFrame, FrameICRF, et)
+	if err != nil {
+		return UncertainState{}, err
+	}
+
+	x := linalg.Vec3{pos.X - sitePos.X, pos.Y - sitePos.Y, pos.Z - sitePos.Z}
+	v := linalg.Vec3{vel.DX - siteVel.DX, vel.DY - siteVel.DY, vel.DZ - siteVel.DZ}
+	p := rot.Apply(x)
+	dp := rot.Apply(v).Add(rate.Apply(x))
+
+	au := r.eph.ephemData.au
+	st := u.EpochSigma / secondsPerDay
+	var cov linalg.Mat3
+	for i := range cov {
+		for j := range cov[i] {
+			cov[i][j] = dp[i] * dp[j] * st * st
+		}
+	}
+	cov = cov.Add(congruence(rot.Mul(toICRF), linalg.Mat3(u.SiteCovariance)).Scale(1 / (1e6 * au * au)))
+	cov = cov.Add(congruence(rot, linalg.Mat3(u.TargetCovariance)).Scale(1 / (au * au)))
+
+	res := UncertainState{
+		Position:   Position{X: p[0], Y: p[1], Z: p[2]},
+		Velocity:   Velocity{DX: dp[0], DY: dp[1], DZ: dp[2]},
+		Covariance: [3][3]float64(cov),
+	}
+	for i := range res.Sigma {
+		res.Sigma[i] = math.Sqrt(math.Max(cov[i][i], 0))
+	}
+	return res, nil
+}
+
+// RotateCovariance expresses a position covariance given in one frame in another at et, as
+// R C R^T with the rotation of Rotation, for propagating the uncertainties of states
+// computed by the caller through the frames of the registry.
+//
+// Parameters:
+//   - cov: Covariance in frame from (any units).
+//   - from, to: Names of registered frames.
+//   - et: Ephemeris time (TDB) as Julian Date.
+//
+// Returns:
+//   - [3][3]float64: The covariance in frame to, in the same units.
+//   - error: ErrCovariance for an invalid covariance, or an error as for Rotation.
+func (r *FrameRegistry) RotateCovariance(cov [3][3]float64, from, to Frame, et float64) ([3][3]float64, error) {
+	if err := checkCovariance(cov); err != nil {
+		return [3][3]float64{}, err
+	}
+	m, err := r.rotation(from, to, et)
+	if err != nil {
+		return [3][3]float64{}, err
+	}
+	return [3][3]float64(congruence(m, linalg.Mat3(cov))), nil
+}
+
+// congruence returns A C A^T.
+func congruence(a, c linalg.Mat3) linalg.Mat3 {
+	return a.Mul(c).Mul(a.Transpose())
+}
+
+// checkCovariance checks that a covariance is finite and symmetric, with a non-negative
+// diagonal.
+func checkCovariance(c [3][3]float64) error {
+	for i := range c {
+		if !(c[i][i] >= 0) || math.IsInf(c[i][i], 0) {
+			return fmt.Errorf("%w: variance %v", ErrCovariance, c[i][i])
+		}
+		for j := 0; j < i; j++ {
+			if d := c[i][j] - c[j][i]; math.IsNaN(d) || math.IsInf(c[i][j], 0) ||
+				math.Abs(d) > 1e-12*math.Max(math.Abs(c[i][j]), math.Sqrt(c[i][i]*c[j][j])) {
+				return fmt.Errorf("%w: elements %d,%d and %d,%d differ", ErrCovariance, i, j, j, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// ./units.go
+package jpleph
+
+/*
+Package jpleph provides the units of the returned quantities and their conversion to SI.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "fmt"
+
+// Unit is the unit of a value returned by the library.
+type Unit int
+
+const (
+	UnitNone                   Unit = iota // Dimensionless (e.g., the rate of TT - TDB in s/s, or an unused component)
+	UnitAU                                 // Astronomical unit of the file (see AUinKM)
+	UnitAUPerDay                           // AU per day
+	UnitKilometer                          // Kilometer
+	UnitKilometerPerDay                    // Kilometer per day
+	UnitKilometerPerSecond                 // Kilometer per second
+	UnitRadian                             // Radian
+	UnitRadianPerDay                       // Radian per day
+	UnitRadianPerDaySquared                // Radian per day squared
+	UnitSecond                             // Second
+	UnitSecondPerDay                       // Second per day
+	UnitMeter                              // Meter (SI)
+	UnitMeterPerSecond                     // Meter per second (SI)
+	UnitRadianPerSecond                    // Radian per second (SI)
+	UnitRadianPerSecondSquared             // Radian per second squared (SI)
+)
+
+// unitSymbols are the symbols of the Unit values.
+var unitSymbols = [...]string{"", "AU", "AU/day", "km", "km/day", "km/s", "rad", "rad/day", "rad/day^2",
+	"s", "s/day", "m", "m/s", "rad/s", "rad/s^2"}
+
+// String returns the symbol of the unit (e.g., "AU/day"), empty for UnitNone.
+func (u Unit) String() string {
+	if u >= 0 && int(u) < len(unitSymbols) {
+		return unitSymbols[u]
+	}
+	return fmt.Sprintf("Unit(%d)", int(u))
+}
+
+// Value is a number with its unit.
+type Value struct {
+	Value float64 // The number
+	Unit  Unit    // Its unit
+}
+
+// String formats the value with its unit symbol (e.g., "1.5 AU").
+func (v Value) String() string {
+	if v.Unit == UnitNone {
+		return fmt.Sprint(v.Value)
+	}
+	return fmt.Sprintf("%v %v", v.Value, v.Unit)
+}
+
+// StateUnits returns the units of the components of the Position and Velocity that
+// CalculatePV returns for a target. The bodies are in AU and AU/day. The special quantities
+// keep the layout of the C version, where the values and their rates fill the six components
+// in order: the nutations are Position {dpsi, deps, dpsi rate} and Velocity {deps rate, -, -}
+// in rad and rad/day, and TT - TDB is Position {TT - TDB in s, its rate in s/day, -}.
+//
+// Parameters:
+//   - target: Target of CalculatePV.
+//
+// Returns:
+//   - [3]Unit: Units of Position.X, Y, and Z.
+//   - [3]Unit: Units of Velocity.DX, DY, and DZ; UnitNone for the unused components.
+func StateUnits(target Planet) ([3]Unit, [3]Unit) {
+	switch target {
+	case Nutations:
+		return [3]Unit{UnitRadian, UnitRadian, UnitRadianPerDay}, [3]Unit{UnitRadianPerDay, UnitNone, UnitNone}
+	case Librations:
+		return [3]Unit{UnitRadian, UnitRadian, UnitRadian}, [3]Unit{UnitRadianPerDay, UnitRadianPerDay, UnitRadianPerDay}
+	case LunarMantleOmega:
+		return [3]Unit{UnitRadianPerDay, UnitRadianPerDay, UnitRadianPerDay},
+			[3]Unit{UnitRadianPerDaySquared, UnitRadianPerDaySquared, UnitRadianPerDaySquared}
+	case TT_TDB:
+		return [3]Unit{UnitSecond, UnitSecondPerDay, UnitNone}, [3]Unit{UnitNone, UnitNone, UnitNone}
+	}
+	return [3]Unit{UnitAU, UnitAU, UnitAU}, [3]Unit{UnitAUPerDay, UnitAUPerDay, UnitAUPerDay}
+}
+
+// QuantityUnits returns the units of a quantity as returned by RawState, and of its rate.
+// They are those of the file (see Coefficients) except for the bodies, which RawState
+// converts from km and km/day to AU and AU/day.
+//
+// Parameters:
+//   - q: Quantity of the file.
+//
+// Returns:
+//   - Unit: Unit of the values (e.g., UnitRadian for QuantityNutations).
+//   - Unit: Unit of their rates.
+func QuantityUnits(q Quantity) (Unit, Unit) {
+	switch q {
+	case QuantityNutations, QuantityLibrations:
+		return UnitRadian, UnitRadianPerDay
+	case QuantityLunarMantleOmega:
+		return UnitRadianPerDay, UnitRadianPerDaySquared
+	case QuantityTTmTDB:
+		return UnitSecond, UnitSecondPerDay
+	}
+	return UnitAU, UnitAUPerDay
+}
+
+// ToSI converts a value to the SI unit of its dimension: lengths to m, velocities to m/s,
+// angular rates to rad/s and rad/s^2, and the rate of TT - TDB to s/s (UnitNone). Values in
+// rad, s, and SI units are returned unchanged. The AU is that of the file.
+//
+// Parameters:
+//   - v: Value to convert.
+//
+// Returns:
+//   - Value: The value in SI units.
+func (e *Ephemeris) ToSI(v Value) Value {
+	au := e.ephemData.au * 1000 // m
+	switch v.Unit {
+	case UnitAU:
+		return Value{v.Value * au, UnitMeter}
+	case UnitAUPerDay:
+		return Value{v.Value * au / secondsPerDay, UnitMeterPerSecond}
+	case UnitKilometer:
+		return Value{v.Value * 1000, UnitMeter}
+	case UnitKilometerPerDay:
+		return Value{v.Value * 1000 / secondsPerDay, UnitMeterPerSecond}
+	case UnitKilometerPerSecond:
+		return Value{v.Value * 1000, UnitMeterPerSecond}
+	case UnitRadianPerDay:
+		return Value{v.Value / secondsPerDay, UnitRadianPerSecond}
+	case UnitRadianPerDaySquared:
+		return Value{v.Value / (secondsPerDay * secondsPerDay), UnitRadianPerSecondSquared}
+	case UnitSecondPerDay:
+		return Value{v.Value / secondsPerDay, UnitNone}
+	}
+	return v
+}
+
+// CalculatePVValues is CalculatePV returning each component with its unit (see StateUnits),
+// or converted to SI units with si (see ToSI).
+//
+// Parameters:
+//   - et: Ephemeris time (TDB) as Julian Date.
+//   - target, center: Bodies, as for CalculatePV.
+//   - calcVelocity: Whether to calculate the velocity (or the rates of a special quantity).
+//   - si: Whether to convert the components to SI units.
+//
+// Returns:
+//   - [3]Value: The components of the Position.
+//   - [3]Value: The components of the Velocity; zero without calcVelocity, as are the rates
+//     held in the Position of a special quantity.
+//   - error: An error from CalculatePV.
+func (e *Ephemeris) CalculatePVValues(et float64, target Planet, center CenterBody, calcVelocity, si bool) ([3]Value, [3]Value, error) {
+	pos, vel, err := e.CalculatePV(et, target, center, calcVelocity)
+	if err != nil {
+		return [3]Value{}, [3]Value{}, err
+	}
+	pu, vu := StateUnits(target)
+	p := [3]Value{{pos.X, pu[0]}, {pos.Y, pu[1]}, {pos.Z, pu[2]}}
+	v := [3]Value{{vel.DX, vu[0]}, {vel.DY, vu[1]}, {vel.DZ, vu[2]}}
+	if si {
+		for i := range p {
+			p[i], v[i] = e.ToSI(p[i]), e.ToSI(v[i])
+		}
+	}
+	return p, v, nil
+}
@@ -0,0 +1,165 @@
+// ./window_search.go
+package jpleph
+
+/*
+Package jpleph provides searches for the time windows when a distance, an angle, or the elevation of a body
+is within a threshold.
+
+This program is free software; you can redistribute it and/or
+modify it under the terms of the GNU General Public License
+as published by the Free Software Foundation; either version 2
+of the License, or (at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program; if not, write to the Free Software
+Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston, MA
+02110-1301, USA.
+
+Authorship:
+Mohammad Shafiee authored this Go code as a translation of the original C code.
+The C version was a translation of Fortran-77 code originally written by
+Piotr A. Dybczynski and later revised by Bill J Gray.
+*/
+
+import "fmt"
+
+// Window is a time interval found by a window search.
+type Window struct {
+	Start float64 // First epoch of the window (TDB Julian Date)
+	End   float64 // Last epoch of the window (TDB Julian Date)
+}
+
+// Duration returns the length of the window in days.
+func (w Window) Duration() float64 {
+	return w.End - w.Start
+}
+
+// WindowSearch configures a window search, in the manner of the SPICE GF routines: the
+// quantity is sampled at Step over [Start, End], and each change of side of the threshold
+// is refined to about 10 ms.
+type WindowSearch struct {
+	Start float64 // First epoch of the search (TDB Julian Date)
+	End   float64 // Last epoch of the search (TDB Julian Date)
+	Step  float64 // Sampling step in days; 0 means 1. Windows and gaps shorter than this can be missed
+	Above bool    // Whether to find the windows when the quantity exceeds the threshold instead of those when it is below
+}
+
+// findWindows returns the windows of a search during which f is negative, or non-negative
+// with search.Above. Windows are clipped to the search interval.
+func findWindows(f func(et float64) (float64, error), search WindowSearch) ([]Window, error) {
+	step := search.Step
+	if step == 0 {
+		step = 1
+	}
+	if !(search.End > search.Start) || !(step > 0) {
+		return nil, fmt.Errorf("%w: interval [%f, %f], step %g", ErrEventSearch, search.Start, search.End, step)
+	}
+	f0, err := f(search.Start)
+	if err != nil {
+		return nil, err
+	}
+	crossings, err := findCrossings(f, search.Start, search.End, step)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []Window
+	open, opened := (f0 < 0) != search.Above, search.Start
+	for _, c := range crossings {
+		if open {
+			windows = append(windows, Window{Start: opened, End: c.et})
+		} else {
+			opened = c.et
+		}
+		open = !open
+	}
+	if open {
+		windows = append(windows, Window{Start: opened, End: search.End})
+	}
+	return windows, nil
+}
+
+// DistanceWindows finds the time windows when the distance of a body from a center is below
+// a threshold, or above it with search.Above, as the SPICE routine GFDIST does. Distances are
+// geometric (uncorrected for light time).
+//
+// Parameters:
+//   - target: Body whose distance is measured (any body accepted by CalculatePV).
+//   - center: Body from which it is measured.
+//   - distance: Threshold distance in AU.
+//   - search: Search interval, sampling step, and side of the threshold.
+//
+// Returns:
+//   - []Window: The windows in chronological order.
+//   - error: ErrEventSearch for an invalid interval or step, or an error from CalculatePV.
+func (e *Ephemeris) DistanceWindows(target Planet, center CenterBody, distance float64, search WindowSearch) ([]Window, error) {
+	return findWindows(func(et float64) (float64, error) {
+		pos, _, err := e.CalculatePV(et, target, center, false)
+		if err != nil {
+			return 0, err
+		}
+		return norm3([3]float64{pos.X, pos.Y, pos.Z}) - distance, nil
+	}, search)
+}
+
+// SeparationWindows finds the time windows when the angular separation of two bodies seen
+// from an observer is below a threshold, or above it with search.Above, as the SPICE routine
+// GFSEP does for point targets. Directions are geometric (uncorrected for light time and
+// aberration), and the bodies are points: the threshold does not include their radii.
+//
+// Parameters:
+//   - a, b: Bodies whose separation is measured (any bodies accepted by CalculatePV).
+//   - observer: Body from which they are seen (e.g., CenterEarth).
+//   - angle: Threshold separation in degrees.
+//   - search: Search interval, sampling step, and side of the threshold.
+//
+// Returns:
+//   - []Window: The windows in chronological order.
+//   - error: ErrEventSearch for an invalid interval or step, or an error from CalculatePV.
+func (e *Ephemeris) SeparationWindows(a, b Planet, observer CenterBody, angle float64, search WindowSearch) ([]Window, error) {
+	return findWindows(func(et float64) (float64, error) {
+		pa, _, err := e.CalculatePV(et, a, observer, false)
+		if err != nil {
+			return 0, err
+		}
+		pb, _, err := e.CalculatePV(et, b, observer, false)
+		if err != nil {
+			return 0, err
+		}
+		return angleDegrees([3]float64{pa.X, pa.Y, pa.Z}, [3]float64{pb.X, pb.Y, pb.Z}) - angle, nil
+	}, search)
+}
+
+// ElevationWindows finds the time windows when a body is above an elevation at a site on the
+// Earth: the periods when it can be observed, for scheduling. The elevations are those of
+// RiseSet, topocentric and geometric, so refraction is included only through the threshold
+// (see GeometricElevation); a window open at search.Start or search.End is clipped to it.
+//
+// Parameters:
+//   - body: Body to observe (any body accepted by CalculatePV with CenterEarth).
+//   - site: Observer location.
+//   - elevation: Minimum elevation in degrees (e.g., 0 for the horizon, 30 for low airmass).
+//   - deltaT: TT - UT1 in seconds (see DeltaT).
+//   - search: Search interval and sampling step, 0 meaning 1/24 day as for RiseSet; with
+//     Above set, the windows are instead those when the body is below the elevation.
+//
+// Returns:
+//   - []Window: The windows in chronological order.
+//   - error: ErrEventSearch for an invalid interval, step, or site, or an error from CalculatePV.
+func (e *Ephemeris) ElevationWindows(body Planet, site Site, elevation, deltaT float64, search WindowSearch) ([]Window, error) {
+	if !site.valid() {
+		return nil, fmt.Errorf("%w: site %+v", ErrEventSearch, site)
+	}
+	if search.Step == 0 {
+		search.Step = 1.0 / 24
+	}
+	return findWindows(func(et float64) (float64, error) {
+		alt, err := e.altitude(et, body, site, deltaT)
+		return elevation - alt, err // Negative, inside the window, above the elevation
+	}, search)
+}